@@ -0,0 +1,333 @@
+// for requesting & renewing HTTP server certificates from ACME (RFC 8555) certificate authorities
+// certificates, accounts and challenges are stored in the database to be shared by all cluster nodes
+
+package acme
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"r3/cache"
+	"r3/cluster"
+	"r3/config"
+	"r3/db"
+	"r3/log"
+	"r3/tools"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	acmeLib "golang.org/x/crypto/acme"
+)
+
+var (
+	challengeTypes = []string{"http-01", "tls-alpn-01"}
+	timeoutHttp    = int64(30)       // timeout for single HTTP requests to ACME server in seconds
+	timeoutOrder   = 5 * time.Minute // timeout for entire certificate order
+)
+
+// requests new certificate if none exists, if it is about to expire or if domains have changed
+func Renew() error {
+	if config.GetUint64("acmeEnable") == 0 {
+		return nil
+	}
+
+	domains, err := getDomains()
+	if err != nil {
+		return err
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), timeoutOrder)
+	defer ctxCanc()
+
+	var domainsCert []string
+	var dateExpiry int64
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT domains, date_expiry
+		FROM instance.acme_cert
+	`).Scan(&domainsCert, &dateExpiry); err != nil && err != pgx.ErrNoRows {
+		return err
+	}
+
+	renewAfter := dateExpiry - int64(config.GetUint64("acmeRenewDays"))*86400
+	if slices.Equal(domains, domainsCert) && tools.GetTimeUnix() < renewAfter {
+		return nil
+	}
+
+	log.Info(log.ContextServer, fmt.Sprintf("requesting ACME certificate for '%s'",
+		strings.Join(domains, ", ")))
+
+	// remove left-over challenges from previous, aborted orders
+	if err := delChallenges(); err != nil {
+		return err
+	}
+	defer func() {
+		if err := delChallenges(); err != nil {
+			log.Warning(log.ContextServer, "failed to remove ACME challenges", err)
+		}
+	}()
+
+	client, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acmeLib.DomainIDs(domains...))
+	if err != nil {
+		return err
+	}
+	for _, url := range order.AuthzURLs {
+		if err := authorize(ctx, client, url); err != nil {
+			return err
+		}
+	}
+	if order, err = client.WaitOrder(ctx, order.URI); err != nil {
+		return err
+	}
+
+	// create new key & certificate request for all domains
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domains[0]},
+		DNSNames: domains,
+	}, key)
+	if err != nil {
+		return err
+	}
+
+	ders, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return err
+	}
+	if len(ders) == 0 {
+		return fmt.Errorf("ACME server did not return a certificate")
+	}
+	leaf, err := x509.ParseCertificate(ders[0])
+	if err != nil {
+		return err
+	}
+
+	certPem := make([]byte, 0)
+	for _, der := range ders {
+		certPem = append(certPem, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	keyPem, err := getKeyPem(key)
+	if err != nil {
+		return err
+	}
+
+	// store certificate and inform all cluster nodes to serve it
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM instance.acme_cert`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO instance.acme_cert (domains, cert_pem, key_pem, date_issued, date_expiry)
+		VALUES ($1,$2,$3,$4,$5)
+	`, domains, string(certPem), keyPem, tools.GetTimeUnix(), leaf.NotAfter.Unix()); err != nil {
+		return err
+	}
+	if err := cluster.CertRenewed_tx(ctx, tx, true); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	log.Info(log.ContextServer, fmt.Sprintf("received ACME certificate, valid until %s",
+		leaf.NotAfter.Format(time.RFC3339)))
+
+	return nil
+}
+
+// fulfills challenge of single authorization and waits for the ACME server to validate it
+func authorize(ctx context.Context, client *acmeLib.Client, url string) error {
+
+	authz, err := client.GetAuthorization(ctx, url)
+	if err != nil {
+		return err
+	}
+	if authz.Status == acmeLib.StatusValid {
+		return nil
+	}
+
+	challengeType := config.GetString("acmeChallenge")
+	if !slices.Contains(challengeTypes, challengeType) {
+		return fmt.Errorf("invalid ACME challenge type '%s'", challengeType)
+	}
+
+	var chal *acmeLib.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == challengeType {
+			chal = c
+			break
+		}
+	}
+	if chal == nil {
+		return fmt.Errorf("ACME server does not offer challenge type '%s' for domain '%s'",
+			challengeType, authz.Identifier.Value)
+	}
+
+	// challenge response is stored in the database, so that any cluster node can answer it
+	domain := authz.Identifier.Value
+	var keyAuth, certPem, keyPem string
+
+	switch challengeType {
+	case "http-01":
+		keyAuth, err = client.HTTP01ChallengeResponse(chal.Token)
+		if err != nil {
+			return err
+		}
+	case "tls-alpn-01":
+		var cert tls.Certificate
+		cert, err = client.TLSALPN01ChallengeCert(chal.Token, domain)
+		if err != nil {
+			return err
+		}
+		certPem = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}))
+		keyPem, err = getKeyPem(cert.PrivateKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := db.Pool.Exec(ctx, `
+		INSERT INTO instance.acme_challenge (domain, type, token,
+			key_auth, cert_pem, key_pem, date_expiry)
+		VALUES ($1,$2,$3,NULLIF($4,''),NULLIF($5,''),NULLIF($6,''),$7)
+	`, domain, challengeType, chal.Token, keyAuth, certPem, keyPem,
+		tools.GetTimeUnix()+int64(timeoutOrder.Seconds())); err != nil {
+
+		return err
+	}
+
+	if _, err := client.Accept(ctx, chal); err != nil {
+		return err
+	}
+	if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("failed to authorize domain '%s', %v", domain, err)
+	}
+	return nil
+}
+
+func delChallenges() error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	_, err := db.Pool.Exec(ctx, `DELETE FROM instance.acme_challenge`)
+	return err
+}
+
+// returns client with registered ACME account, creates new account if none exists for the ACME server
+func getClient(ctx context.Context) (*acmeLib.Client, error) {
+
+	httpClient, err := config.GetHttpClient(config.GetUint64("acmeSkipVerify") == 1, timeoutHttp)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &acmeLib.Client{
+		DirectoryURL: config.GetString("acmeDirectoryUrl"),
+		HTTPClient:   &httpClient,
+		UserAgent:    "REI3",
+	}
+
+	var keyPem, uri string
+	err = db.Pool.QueryRow(ctx, `
+		SELECT key_pem, uri
+		FROM instance.acme_account
+		WHERE directory_url = $1
+	`, client.DirectoryURL).Scan(&keyPem, &uri)
+
+	if err == nil {
+		block, _ := pem.Decode([]byte(keyPem))
+		if block == nil {
+			return nil, fmt.Errorf("failed to decode ACME account key")
+		}
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		client.Key = key
+		client.KID = acmeLib.KeyID(uri)
+		return client, nil
+	}
+	if err != pgx.ErrNoRows {
+		return nil, err
+	}
+
+	// register new account
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	client.Key = key
+
+	account := &acmeLib.Account{}
+	if email := config.GetString("acmeEmail"); email != "" {
+		account.Contact = []string{fmt.Sprintf("mailto:%s", email)}
+	}
+
+	account, err = client.Register(ctx, account, acmeLib.AcceptTOS)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyPem, err = getKeyPem(key); err != nil {
+		return nil, err
+	}
+	if _, err := db.Pool.Exec(ctx, `
+		INSERT INTO instance.acme_account (directory_url, key_pem, uri)
+		VALUES ($1,$2,$3)
+	`, client.DirectoryURL, keyPem, account.URI); err != nil {
+		return nil, err
+	}
+
+	log.Info(log.ContextServer, fmt.Sprintf("registered new ACME account at '%s'", client.DirectoryURL))
+	return client, nil
+}
+
+// returns sorted domains to request certificate for
+// public host name & its sub domains for direct app access (PWA)
+func getDomains() ([]string, error) {
+	host := cache.GetPublicHostDomain()
+	if host == "" || host == "localhost" || net.ParseIP(host) != nil {
+		return nil, fmt.Errorf("public host name '%s' is not a valid domain name for ACME certificates", host)
+	}
+
+	domains := []string{host}
+	for subdomain := range cache.GetPwaDomainMap() {
+		domains = append(domains, strings.ToLower(fmt.Sprintf("%s.%s", subdomain, host)))
+	}
+	slices.Sort(domains)
+	return slices.Compact(domains), nil
+}
+
+func getKeyPem(key crypto.PrivateKey) (string, error) {
+	k, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("unsupported private key type %T", key)
+	}
+	der, err := x509.MarshalECPrivateKey(k)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), nil
+}
@@ -0,0 +1,166 @@
+// audit log of administrative changes
+// entries are append-only (enforced by trigger) and hash chained: each hash covers the entry & the hash of the previous entry
+// changing or removing entries breaks the chain, which can be detected by verifying it
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"r3/tools"
+	"r3/types"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func Get_tx(ctx context.Context, tx pgx.Tx, dateFrom pgtype.Int8, dateTo pgtype.Int8, loginId pgtype.Int8,
+	ressource string, byString string, limit int, offset int) ([]types.AuditLog, int, error) {
+
+	logs := make([]types.AuditLog, 0)
+	total := 0
+
+	var qb tools.QueryBuilder
+	qb.UseDollarSigns()
+	qb.AddList("SELECT", []string{"id", "login_id", "login_name", "date", "ressource",
+		"action", "entity_id", "payload", "diff", "hash_prev", "hash"})
+	qb.SetFrom("instance.audit_log")
+
+	if dateFrom.Valid {
+		qb.Add("WHERE", "date >= {DATEFROM}")
+		qb.AddPara("{DATEFROM}", dateFrom.Int64)
+	}
+	if dateTo.Valid {
+		qb.Add("WHERE", "date <= {DATETO}")
+		qb.AddPara("{DATETO}", dateTo.Int64)
+	}
+	if loginId.Valid {
+		qb.Add("WHERE", "login_id = {LOGINID}")
+		qb.AddPara("{LOGINID}", loginId.Int64)
+	}
+	if ressource != "" {
+		qb.Add("WHERE", "ressource = {RESSOURCE}")
+		qb.AddPara("{RESSOURCE}", ressource)
+	}
+	if byString != "" {
+		qb.Add("WHERE", `(
+			entity_id    ILIKE {NAME} OR
+			payload::TEXT ILIKE {NAME}
+		)`)
+		qb.AddPara("{NAME}", fmt.Sprintf("%%%s%%", byString))
+	}
+
+	qb.Add("ORDER", "id DESC")
+	qb.SetOffset(offset)
+	qb.SetLimit(limit)
+
+	query, err := qb.GetQuery()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := tx.Query(ctx, query, qb.GetParaValues()...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l types.AuditLog
+		if err := rows.Scan(&l.Id, &l.LoginId, &l.LoginName, &l.Date, &l.Ressource, &l.Action,
+			&l.EntityId, &l.Payload, &l.Diff, &l.HashPrev, &l.Hash); err != nil {
+
+			return nil, 0, err
+		}
+		logs = append(logs, l)
+	}
+	rows.Close()
+
+	// get total count
+	qb.UseDollarSigns()
+	qb.Reset("SELECT")
+	qb.Reset("ORDER")
+	qb.Reset("LIMIT")
+	qb.Reset("OFFSET")
+	qb.Add("SELECT", "COUNT(*)")
+
+	query, err = qb.GetQuery()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.QueryRow(ctx, query, qb.GetParaValues()...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// verifies hash chain of all entries
+func Verify_tx(ctx context.Context, tx pgx.Tx) (types.AuditLogVerification, error) {
+	var v types.AuditLogVerification
+
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*), (
+			SELECT hash
+			FROM instance.audit_log
+			ORDER BY id DESC
+			LIMIT 1
+		)
+		FROM instance.audit_log
+	`).Scan(&v.Count, &v.HashLatest); err != nil {
+		return v, err
+	}
+
+	err := tx.QueryRow(ctx, `
+		SELECT id
+		FROM (
+			SELECT id, hash, hash_prev,
+				LAG(hash) OVER (ORDER BY id ASC) AS hash_prev_chain,
+				instance.audit_log_hash(hash_prev, date, login_id, login_name,
+					ressource, action, entity_id, payload, diff) AS hash_calc
+			FROM instance.audit_log
+		) AS a
+		WHERE hash      IS DISTINCT FROM hash_calc
+		OR    hash_prev IS DISTINCT FROM hash_prev_chain
+		ORDER BY id ASC
+		LIMIT 1
+	`).Scan(&v.IdBroken)
+
+	if err != nil && err != pgx.ErrNoRows {
+		return v, err
+	}
+	return v, nil
+}
+
+// appends entry to audit log, chained to the latest entry
+// table is locked until the transaction ends to keep the chain in order
+func Write_tx(ctx context.Context, tx pgx.Tx, loginId int64, ressource string, action string,
+	entityId pgtype.Text, payload []byte, diff []byte) error {
+
+	if _, err := tx.Exec(ctx, `LOCK TABLE instance.audit_log IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO instance.audit_log (login_id, login_name, date, ressource,
+			action, entity_id, payload, diff, hash_prev, hash)
+		SELECT e.login_id, e.login_name, e.date, e.ressource, e.action, e.entity_id,
+			e.payload, e.diff, p.hash, instance.audit_log_hash(p.hash, e.date, e.login_id,
+			e.login_name, e.ressource, e.action, e.entity_id, e.payload, e.diff)
+		FROM (
+			SELECT $1::INTEGER AS login_id, (
+				SELECT name
+				FROM instance.login
+				WHERE id = $1
+			) AS login_name, $2::BIGINT AS date, $3::TEXT AS ressource, $4::TEXT AS action,
+			$5::TEXT AS entity_id, $6::JSONB AS payload, $7::JSONB AS diff
+		) AS e
+		LEFT JOIN (
+			SELECT hash
+			FROM instance.audit_log
+			ORDER BY id DESC
+			LIMIT 1
+		) AS p ON TRUE
+	`, loginId, tools.GetTimeUnix(), ressource, action, entityId, payload, diff)
+	return err
+}
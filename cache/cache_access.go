@@ -99,6 +99,7 @@ func load_tx(ctx context.Context, tx pgx.Tx, loginId int64) error {
 		ClientEvent: make(map[uuid.UUID]types.Access),
 		Collection:  make(map[uuid.UUID]types.Access),
 		Menu:        make(map[uuid.UUID]types.Access),
+		PgFunction:  make(map[uuid.UUID]types.Access),
 		Relation:    make(map[uuid.UUID]types.Access),
 		SearchBar:   make(map[uuid.UUID]types.Access),
 		Widget:      make(map[uuid.UUID]types.Access),
@@ -143,6 +144,13 @@ func load_tx(ctx context.Context, tx pgx.Tx, loginId int64) error {
 				loginIdMapAccess[loginId].Menu[id] = access
 			}
 		}
+		for id, access := range role.AccessPgFunctions {
+			if _, exists := loginIdMapAccess[loginId].PgFunction[id]; !exists ||
+				loginIdMapAccess[loginId].PgFunction[id] < access {
+
+				loginIdMapAccess[loginId].PgFunction[id] = access
+			}
+		}
 		for id, access := range role.AccessRelations {
 			if _, exists := loginIdMapAccess[loginId].Relation[id]; !exists ||
 				loginIdMapAccess[loginId].Relation[id] < access {
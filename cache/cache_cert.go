@@ -1,26 +1,68 @@
 package cache
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"os"
+	"r3/config"
+	"r3/db"
 	"r3/log"
 	"r3/tools"
+	"slices"
+	"strings"
 	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/acme"
 )
 
 var (
-	cert_mx     sync.Mutex
-	cert        tls.Certificate      // cert to serve
-	certPath    string               // path to cert file
-	certPathKey string               // path to cert key file
-	certUnixMod int64           = -1 // cert file modification unix time (-1 if not loaded yet)
+	cert_mx        sync.Mutex
+	cert           tls.Certificate      // cert to serve
+	certPath       string               // path to cert file
+	certPathKey    string               // path to cert key file
+	certUnixMod    int64           = -1 // cert file modification unix time (-1 if not loaded yet)
+	certAcmeIssued int64           = -1 // ACME cert issue unix time (-1 if not loaded yet)
 )
 
 func CheckRenewCert() error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := CheckRenewCert_tx(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func CheckRenewCert_tx(ctx context.Context, tx pgx.Tx) error {
 	cert_mx.Lock()
 	defer cert_mx.Unlock()
 
+	// no cert paths are set if web server does not serve HTTPS
+	if certPath == "" {
+		return nil
+	}
+
+	// ACME cert from database takes precedence, if available
+	if config.GetUint64("acmeEnable") == 1 {
+		loaded, err := loadCertAcme_tx(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if loaded {
+			return nil
+		}
+	}
+
 	// get stats for cert file
 	file, err := os.Stat(certPath)
 	if err != nil {
@@ -65,11 +107,18 @@ func CheckRenewCert() error {
 			return err
 		}
 		certUnixMod = file.ModTime().Unix()
+		certAcmeIssued = -1
 	}
 	return nil
 }
 
-func GetCert(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+func GetCert(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+
+	// ACME TLS-ALPN-01 challenge, validation server only offers the ACME protocol
+	if len(hello.SupportedProtos) == 1 && hello.SupportedProtos[0] == acme.ALPNProto {
+		return getCertAcmeChallenge(hello.ServerName)
+	}
+
 	cert_mx.Lock()
 	defer cert_mx.Unlock()
 	return &cert, nil
@@ -79,3 +128,74 @@ func SetCertPaths(cert string, key string) {
 	certPath = cert
 	certPathKey = key
 }
+
+// loads ACME cert from database if it exists and covers the public host name
+// returns whether ACME cert is being served
+func loadCertAcme_tx(ctx context.Context, tx pgx.Tx) (bool, error) {
+	var domains []string
+	var certPem, keyPem string
+	var dateIssued int64
+	if err := tx.QueryRow(ctx, `
+		SELECT domains, cert_pem, key_pem, date_issued
+		FROM instance.acme_cert
+	`).Scan(&domains, &certPem, &keyPem, &dateIssued); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// public host name was changed since cert was issued, fall back to cert file until renewed
+	if !slices.Contains(domains, GetPublicHostDomain()) {
+		return false, nil
+	}
+
+	if dateIssued != certAcmeIssued {
+		log.Info(log.ContextServer, fmt.Sprintf("loading HTTP server certificate from ACME for '%s'",
+			strings.Join(domains, ", ")))
+
+		c, err := tls.X509KeyPair([]byte(certPem), []byte(keyPem))
+		if err != nil {
+			return false, err
+		}
+		cert = c
+		certAcmeIssued = dateIssued
+		certUnixMod = -1
+	}
+	return true, nil
+}
+
+// returns TLS-ALPN-01 challenge cert for domain, stored in database to be reachable on all cluster nodes
+func getCertAcmeChallenge(domain string) (*tls.Certificate, error) {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	var certPem, keyPem string
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT cert_pem, key_pem
+		FROM instance.acme_challenge
+		WHERE domain      = $1
+		AND   type        = 'tls-alpn-01'
+		AND   date_expiry > $2
+	`, strings.ToLower(domain), tools.GetTimeUnix()).Scan(&certPem, &keyPem); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("no ACME challenge available for domain '%s'", domain)
+		}
+		return nil, err
+	}
+
+	c, err := tls.X509KeyPair([]byte(certPem), []byte(keyPem))
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// returns domain of public host name, without port
+func GetPublicHostDomain() string {
+	host := strings.ToLower(config.GetString("publicHostName"))
+	if hostOnly, _, err := net.SplitHostPort(host); err == nil {
+		return hostOnly
+	}
+	return host
+}
@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"r3/log"
+	"r3/tools"
+	"sync"
+)
+
+var (
+	certClient_mx         sync.Mutex
+	certClientCaPath      string                // path to CA file, used to verify client certificates
+	certClientCrlPaths    []string              // paths to certificate revocation list files
+	certClientCrlChecked  int64                 // unix time of last check for updated CRL files
+	certClientCrlCheckSec int64            = 60 // check for updated CRL files every x seconds
+	certClientCrlUnixMod  map[string]int64      // CRL file modification unix times, key: file path
+	certClientRevoked     map[string]bool       // revoked certificates, key: issuer + serial number
+)
+
+// returns pool of CAs to verify client certificates with
+func GetCertClientCaPool() (*x509.CertPool, error) {
+	certClient_mx.Lock()
+	defer certClient_mx.Unlock()
+
+	caPem, err := os.ReadFile(certClientCaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPem) {
+		return nil, fmt.Errorf("failed to read any certificate from client CA file '%s'", certClientCaPath)
+	}
+	log.Info(log.ContextServer, fmt.Sprintf("loaded client CA certificates from '%s'", certClientCaPath))
+	return pool, nil
+}
+
+// verifies that no certificate in the verified client certificate chain is revoked
+// used as TLS connection verification, connections without client certificate are not affected
+func CheckCertClientRevoked(cs tls.ConnectionState) error {
+	if len(cs.VerifiedChains) == 0 {
+		return nil
+	}
+
+	certClient_mx.Lock()
+	defer certClient_mx.Unlock()
+
+	if err := checkRenewCrls(); err != nil {
+		// fail closed, if revocation lists cannot be read, certificates cannot be trusted
+		log.Error(log.ContextServer, "failed to load certificate revocation lists", err)
+		return err
+	}
+
+	for _, cert := range cs.VerifiedChains[0] {
+		if certClientRevoked[getCertRevokedKey(cert.RawIssuer, cert.SerialNumber.String())] {
+			return fmt.Errorf("client certificate '%s' has been revoked", cert.Subject.String())
+		}
+	}
+	return nil
+}
+
+func SetCertClientPaths(ca string, crls []string) {
+	certClientCaPath = ca
+	certClientCrlPaths = crls
+	certClientCrlUnixMod = make(map[string]int64)
+	certClientRevoked = make(map[string]bool)
+}
+
+// reloads all CRL files if any was updated since last load
+func checkRenewCrls() error {
+	now := tools.GetTimeUnix()
+	if now < certClientCrlChecked+certClientCrlCheckSec {
+		return nil
+	}
+
+	changed := false
+	for _, path := range certClientCrlPaths {
+		file, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if file.ModTime().Unix() != certClientCrlUnixMod[path] {
+			changed = true
+		}
+	}
+
+	if changed {
+		revoked := make(map[string]bool)
+		unixMod := make(map[string]int64)
+
+		// CRLs must be signed by a client CA, otherwise revocations could be removed by changing CRL files
+		caCerts, err := getCertClientCas()
+		if err != nil {
+			return err
+		}
+
+		for _, path := range certClientCrlPaths {
+			file, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			crlData, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			// CRL files can be PEM or DER encoded
+			if block, _ := pem.Decode(crlData); block != nil {
+				crlData = block.Bytes
+			}
+			crl, err := x509.ParseRevocationList(crlData)
+			if err != nil {
+				return fmt.Errorf("failed to parse CRL file '%s', %v", path, err)
+			}
+			if err := checkCrlSignature(crl, caCerts); err != nil {
+				return fmt.Errorf("failed to verify CRL file '%s', %v", path, err)
+			}
+			if !crl.NextUpdate.IsZero() && crl.NextUpdate.Unix() < now {
+				log.Warning(log.ContextServer, fmt.Sprintf("CRL file '%s' is outdated", path),
+					errors.New("next update date has passed"))
+			}
+
+			for _, entry := range crl.RevokedCertificateEntries {
+				revoked[getCertRevokedKey(crl.RawIssuer, entry.SerialNumber.String())] = true
+			}
+			unixMod[path] = file.ModTime().Unix()
+		}
+		certClientRevoked = revoked
+		certClientCrlUnixMod = unixMod
+
+		log.Info(log.ContextServer, fmt.Sprintf("loaded %d revoked client certificates from %d CRL file(s)",
+			len(revoked), len(certClientCrlPaths)))
+	}
+	certClientCrlChecked = now
+	return nil
+}
+
+// verifies CRL signature against client CA certificate that issued the CRL
+func checkCrlSignature(crl *x509.RevocationList, caCerts []*x509.Certificate) error {
+	for _, ca := range caCerts {
+		if !bytes.Equal(ca.RawSubject, crl.RawIssuer) {
+			continue
+		}
+		return crl.CheckSignatureFrom(ca)
+	}
+	return errors.New("CRL issuer is not a client CA")
+}
+
+// returns all certificates from client CA file
+func getCertClientCas() ([]*x509.Certificate, error) {
+	caPem, err := os.ReadFile(certClientCaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	certs := make([]*x509.Certificate, 0)
+	for {
+		var block *pem.Block
+		block, caPem = pem.Decode(caPem)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+func getCertRevokedKey(rawIssuer []byte, serial string) string {
+	return fmt.Sprintf("%x_%s", rawIssuer, serial)
+}
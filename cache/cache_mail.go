@@ -55,7 +55,9 @@ func LoadMailAccountMap_tx(ctx context.Context, tx pgx.Tx) error {
 
 	rows, err := tx.Query(ctx, `
 		SELECT id, oauth_client_id, name, mode, auth_method, username,
-			password, start_tls, send_as, host_name, host_port, comment
+			password, start_tls, send_as, host_name, host_port, comment,
+			dkim_domain, dkim_selector, dkim_private_key, imap_folder,
+			imap_folder_archive, imap_action, imap_idle, imap_store_raw
 		FROM instance.mail_account
 	`)
 	if err != nil {
@@ -72,7 +74,9 @@ func LoadMailAccountMap_tx(ctx context.Context, tx pgx.Tx) error {
 
 		if err := rows.Scan(&ma.Id, &ma.OauthClientId, &ma.Name, &ma.Mode,
 			&ma.AuthMethod, &ma.Username, &ma.Password, &ma.StartTls,
-			&ma.SendAs, &ma.HostName, &ma.HostPort, &ma.Comment); err != nil {
+			&ma.SendAs, &ma.HostName, &ma.HostPort, &ma.Comment, &ma.DkimDomain,
+			&ma.DkimSelector, &ma.DkimPrivateKey, &ma.ImapFolder,
+			&ma.ImapFolderArchive, &ma.ImapAction, &ma.ImapIdle, &ma.ImapStoreRaw); err != nil {
 
 			return err
 		}
@@ -31,6 +31,7 @@ import (
 	"r3/schema/widget"
 	"r3/tools"
 	"r3/types"
+	"regexp"
 	"sync"
 
 	"github.com/gofrs/uuid"
@@ -47,14 +48,16 @@ var (
 	moduleIdMapMeta = make(map[uuid.UUID]types.ModuleMeta) // ID map of module meta data
 
 	// cached entities for regular use during normal operation
-	ModuleIdMap        = make(map[uuid.UUID]types.Module)      // all modules by ID
-	ModuleApiNameMapId = make(map[string]map[string]uuid.UUID) // all API IDs by module+API name
-	RelationIdMap      = make(map[uuid.UUID]types.Relation)    // all relations by ID
-	AttributeIdMap     = make(map[uuid.UUID]types.Attribute)   // all attributes by ID
-	RoleIdMap          = make(map[uuid.UUID]types.Role)        // all roles by ID
-	PgFunctionIdMap    = make(map[uuid.UUID]types.PgFunction)  // all PG functions by ID
-	ApiIdMap           = make(map[uuid.UUID]types.Api)         // all APIs by ID
-	ClientEventIdMap   = make(map[uuid.UUID]types.ClientEvent) // all client events by ID
+	ModuleIdMap               = make(map[uuid.UUID]types.Module)      // all modules by ID
+	ModuleApiNameMapId        = make(map[string]map[string]uuid.UUID) // all API IDs by module+API name
+	ModulePgFunctionNameMapId = make(map[string]map[string]uuid.UUID) // all PG function IDs by module+function name
+	RelationIdMap             = make(map[uuid.UUID]types.Relation)    // all relations by ID
+	AttributeIdMap            = make(map[uuid.UUID]types.Attribute)   // all attributes by ID
+	AttributeIdMapRegex       = make(map[uuid.UUID]*regexp.Regexp)    // compiled validation regex of attributes by ID
+	RoleIdMap                 = make(map[uuid.UUID]types.Role)        // all roles by ID
+	PgFunctionIdMap           = make(map[uuid.UUID]types.PgFunction)  // all PG functions by ID
+	ApiIdMap                  = make(map[uuid.UUID]types.Api)         // all APIs by ID
+	ClientEventIdMap          = make(map[uuid.UUID]types.ClientEvent) // all client events by ID
 )
 
 func GetModuleIdMapMeta() map[uuid.UUID]types.ModuleMeta {
@@ -177,6 +180,7 @@ func updateSchemaCache_tx(ctx context.Context, tx pgx.Tx, moduleIds []uuid.UUID)
 		mod.Variables = make([]types.Variable, 0)
 		mod.Widgets = make([]types.Widget, 0)
 		ModuleApiNameMapId[mod.Name] = make(map[string]uuid.UUID)
+		ModulePgFunctionNameMapId[mod.Name] = make(map[string]uuid.UUID)
 
 		// get articles
 		log.Info(log.ContextCache, "load articles")
@@ -206,6 +210,16 @@ func updateSchemaCache_tx(ctx context.Context, tx pgx.Tx, moduleIds []uuid.UUID)
 			for _, atr := range atrs {
 				AttributeIdMap[atr.Id] = atr
 				rel.Attributes = append(rel.Attributes, atr)
+
+				// compile validation regex once, it is used on every value change
+				if atr.Validation.Regex.Valid && atr.Validation.Regex.String != "" {
+					AttributeIdMapRegex[atr.Id], err = regexp.Compile(atr.Validation.Regex.String)
+					if err != nil {
+						return err
+					}
+				} else {
+					delete(AttributeIdMapRegex, atr.Id)
+				}
 			}
 
 			// get indexes
@@ -285,6 +299,7 @@ func updateSchemaCache_tx(ctx context.Context, tx pgx.Tx, moduleIds []uuid.UUID)
 		}
 		for _, fnc := range mod.PgFunctions {
 			PgFunctionIdMap[fnc.Id] = fnc
+			ModulePgFunctionNameMapId[mod.Name][fnc.Name] = fnc.Id
 		}
 
 		// get JS functions
@@ -54,6 +54,16 @@ func CheckInNode() error {
 }
 
 // events relevant to all cluster nodes
+func CertRenewed_tx(ctx context.Context, tx pgx.Tx, updateNodes bool) error {
+	if updateNodes {
+		if err := createEventsForOtherNodes_tx(ctx, tx, "certRenewed", nil, types.ClusterEventTarget{}); err != nil {
+			return err
+		}
+	}
+
+	// load renewed ACME cert from database
+	return cache.CheckRenewCert_tx(ctx, tx)
+}
 func ClientEventsChanged_tx(ctx context.Context, tx pgx.Tx, updateNodes bool, address string, loginId int64) error {
 	target := types.ClusterEventTarget{Address: address, Device: types.WebsocketClientDeviceFatClient, LoginId: loginId}
 
@@ -142,6 +152,11 @@ func ConfigChanged_tx(ctx context.Context, tx pgx.Tx, updateNodes bool, loadConf
 	bruteforce.SetConfig()
 	config.ActivateLicense()
 	config.SetLogLevels()
+
+	// switch between ACME and file based HTTP certificate, if ACME was toggled
+	if err := cache.CheckRenewCert_tx(ctx, tx); err != nil {
+		log.Warning(log.ContextServer, "failed to reload HTTP server certificate", err)
+	}
 	return nil
 }
 func FilesCopied_tx(ctx context.Context, tx pgx.Tx, updateNodes bool, address string, loginId int64,
@@ -236,6 +251,16 @@ func LoginDisabled_tx(ctx context.Context, tx pgx.Tx, updateNodes bool, loginId
 	WebsocketClientEvents <- types.ClusterEvent{Content: "kick", Target: target}
 	return nil
 }
+func LoginTokenRevoked_tx(ctx context.Context, tx pgx.Tx, updateNodes bool, loginId int64, tokenId uuid.UUID) error {
+	target := types.ClusterEventTarget{LoginId: loginId, TokenId: tokenId}
+	if updateNodes {
+		if err := createEventsForOtherNodes_tx(ctx, tx, "loginTokenRevoked", tokenId, target); err != nil {
+			return err
+		}
+	}
+	WebsocketClientEvents <- types.ClusterEvent{Content: "kick", Target: target}
+	return nil
+}
 func LoginReauthorized_tx(ctx context.Context, tx pgx.Tx, updateNodes bool, loginId int64) error {
 	target := types.ClusterEventTarget{LoginId: loginId}
 	if updateNodes {
@@ -23,6 +23,7 @@ func Get_tx(ctx context.Context, tx pgx.Tx, id pgtype.UUID, target string) (type
 
 	caps.ArticleIdMap = make(map[uuid.UUID]types.CaptionMap)
 	caps.AttributeIdMap = make(map[uuid.UUID]types.CaptionMap)
+	caps.AttributeEnumValueIdMap = make(map[uuid.UUID]types.CaptionMap)
 	caps.ClientEventIdMap = make(map[uuid.UUID]types.CaptionMap)
 	caps.ColumnIdMap = make(map[uuid.UUID]types.CaptionMap)
 	caps.FieldIdMap = make(map[uuid.UUID]types.CaptionMap)
@@ -43,6 +44,7 @@ func Get_tx(ctx context.Context, tx pgx.Tx, id pgtype.UUID, target string) (type
 	sqlSelect := `SELECT CASE
 		WHEN article_id      IS NOT NULL THEN 'article'
 		WHEN attribute_id    IS NOT NULL THEN 'attribute'
+		WHEN attribute_enum_value_id IS NOT NULL THEN 'attributeEnumValue'
 		WHEN client_event_id IS NOT NULL THEN 'clientEvent'
 		WHEN column_id       IS NOT NULL THEN 'column'
 		WHEN field_id        IS NOT NULL THEN 'field'
@@ -63,6 +65,7 @@ func Get_tx(ctx context.Context, tx pgx.Tx, id pgtype.UUID, target string) (type
 	COALESCE(
 		article_id,
 		attribute_id,
+		attribute_enum_value_id,
 		client_event_id,
 		column_id,
 		field_id,
@@ -99,6 +102,13 @@ func Get_tx(ctx context.Context, tx pgx.Tx, id pgtype.UUID, target string) (type
 					SELECT id FROM app.relation WHERE module_id = $2
 				)
 			)
+			OR attribute_enum_value_id IN (
+				SELECT id FROM app.attribute_enum_value WHERE attribute_id IN (
+					SELECT id FROM app.attribute WHERE relation_id IN (
+						SELECT id FROM app.relation WHERE module_id = $22
+					)
+				)
+			)
 			OR column_id IN (
 				SELECT id FROM app.column WHERE field_id IN (
 					SELECT id FROM app.field WHERE form_id IN (
@@ -159,7 +169,7 @@ func Get_tx(ctx context.Context, tx pgx.Tx, id pgtype.UUID, target string) (type
 			OR role_id         IN (SELECT id FROM app.role         WHERE module_id = $19)
 			OR search_bar_id   IN (SELECT id FROM app.search_bar   WHERE module_id = $20)
 			OR widget_id       IN (SELECT id FROM app.widget       WHERE module_id = $21)
-		`, sqlSelect, target), id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id)
+		`, sqlSelect, target), id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id, id)
 	}
 
 	if err != nil {
@@ -185,6 +195,8 @@ func Get_tx(ctx context.Context, tx pgx.Tx, id pgtype.UUID, target string) (type
 			captionMap, exists = caps.ArticleIdMap[entityId]
 		case "attribute":
 			captionMap, exists = caps.AttributeIdMap[entityId]
+		case "attributeEnumValue":
+			captionMap, exists = caps.AttributeEnumValueIdMap[entityId]
 		case "clientEvent":
 			captionMap, exists = caps.ClientEventIdMap[entityId]
 		case "column":
@@ -229,6 +241,8 @@ func Get_tx(ctx context.Context, tx pgx.Tx, id pgtype.UUID, target string) (type
 			caps.ArticleIdMap[entityId] = captionMap
 		case "attribute":
 			caps.AttributeIdMap[entityId] = captionMap
+		case "attributeEnumValue":
+			caps.AttributeEnumValueIdMap[entityId] = captionMap
 		case "clientEvent":
 			caps.ClientEventIdMap[entityId] = captionMap
 		case "column":
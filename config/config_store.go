@@ -17,27 +17,31 @@ var (
 	storeUint64      = make(map[string]uint64)
 	storeUint64Slice = make(map[string][]uint64)
 
-	NamesString = []string{"adminMails", "appName", "appNameShort", "backupDir",
+	NamesString = []string{"acmeChallenge", "acmeDirectoryUrl", "acmeEmail",
+		"adminMails", "appName", "appNameShort", "backupDir",
 		"companyColorHeader", "companyColorLogin", "companyLoginImage",
 		"companyLogo", "companyLogoUrl", "companyName", "companyWelcome", "css",
 		"dbVersionCut", "exportPrivateKey", "iconPwa1", "iconPwa2",
-		"instanceId", "licenseFile", "publicHostName", "proxyUrl", "repoPass",
+		"instanceId", "licenseFile", "publicHostName", "proxyUrl", "pwBreachedPath", "repoPass",
 		"repoPublicKeys", "repoUrl", "repoUser", "systemMsgText", "tokenSecret",
 		"updateCheckUrl", "updateCheckVersion"}
 
-	NamesUint64 = []string{"backupDaily", "backupMonthly", "backupWeekly",
+	NamesUint64 = []string{"acmeEnable", "acmeRenewDays", "acmeSkipVerify",
+		"backupDaily", "backupMonthly", "backupWeekly",
 		"backupCountDaily", "backupCountMonthly", "backupCountWeekly",
 		"bruteforceAttempts", "bruteforceProtection", "builderMode",
 		"clusterNodeMissingAfter", "dbTimeoutCsv", "dbTimeoutDataRest",
 		"dbTimeoutDataWs", "dbTimeoutIcs", "filesKeepDaysDeleted",
 		"fileVersionsKeepCount", "fileVersionsKeepDays", "icsDaysPost",
-		"icsDaysPre", "icsDownload", "imagerThumbWidth", "logApi", "logBackup",
-		"logCache", "logCluster", "logCsv", "logFile", "logImager", "logLdap",
+		"icsDaysPre", "icsDownload", "imagerThumbWidth", "jobWorkers", "logApi",
+		"logBackup", "logCache", "logCluster", "logCsv", "logFile", "logImager", "logLdap",
 		"logMail", "logModule", "logOauth", "logServer", "logScheduler",
 		"logTransfer", "logWebsocket", "logsKeepDays", "mailTrafficKeepDays",
 		"productionMode", "pwForceDigit", "pwForceLower", "pwForceSpecial",
-		"pwForceUpper", "pwLengthMin", "repoChecked", "repoFeedback",
-		"repoSkipVerify", "systemMsgDate0", "systemMsgDate1",
+		"pwForceUpper", "pwHistoryCount", "pwLengthMin", "pwMaxAgeDays",
+		"repoChecked", "repoFeedback",
+		"repoSkipVerify", "schedulerAlertDurationSec", "schedulerAlertFailures",
+		"schedulerRunsKeepDays", "systemMsgDate0", "systemMsgDate1",
 		"systemMsgMaintenance", "tokenExpiryHours", "tokenKeepEnable"}
 
 	NamesUint64Slice = []string{"loginBackgrounds"}
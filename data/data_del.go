@@ -47,12 +47,29 @@ func Del_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
 		return err
 	}
 
-	_, err = tx.Exec(ctx, fmt.Sprintf(`
+	// move record to recycle bin before deletion
+	var trashId uuid.UUID
+	if rel.TrashDays.Valid {
+		trashId, err = setTrash_tx(ctx, tx, mod, rel, recordId, loginId)
+		if err != nil {
+			return err
+		}
+	}
+
+	tag, err := tx.Exec(ctx, fmt.Sprintf(`
 		DELETE FROM "%s"."%s" AS "%s"
 		WHERE "%s"."%s" = $1
 		%s
 	`, mod.Name, rel.Name, tableAlias, tableAlias,
 		schema.PkName, policyFilter), recordId)
 
+	if err != nil {
+		return err
+	}
+
+	// nothing was deleted (record did not exist or was blocked by policy), remove recycle bin entry
+	if rel.TrashDays.Valid && tag.RowsAffected() == 0 {
+		_, err = tx.Exec(ctx, `DELETE FROM instance.data_trash WHERE id = $1`, trashId)
+	}
 	return err
 }
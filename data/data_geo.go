@@ -0,0 +1,119 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// geospatial values are exchanged as GeoJSON geometries (WGS 84, longitude/latitude)
+// geometry attributes are stored as PostGIS geometries, point attributes as plain Postgres points
+
+// returns SQL expression to retrieve geospatial value as GeoJSON geometry
+func getGeoSelectCode(content string, code string) string {
+	if content == "geometry" {
+		return fmt.Sprintf("ST_AsGeoJSON(%s)::JSONB", code)
+	}
+	return fmt.Sprintf(`CASE WHEN %s IS NULL THEN NULL ELSE JSON_BUILD_OBJECT(
+		'type', 'Point', 'coordinates', JSON_BUILD_ARRAY(%s[0], %s[1])) END`, code, code, code)
+}
+
+// returns value and SQL parameter template (with placeholder for parameter index) to set geospatial value
+func getGeoValue(content string, value interface{}) (interface{}, string, error) {
+
+	if value == nil {
+		return nil, "$%d", nil
+	}
+
+	if content == "geometry" {
+		if v, ok := value.(string); ok {
+			return v, "ST_SetSRID(ST_GeomFromGeoJSON($%d::TEXT), 4326)", nil
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, "", err
+		}
+		return string(b), "ST_SetSRID(ST_GeomFromGeoJSON($%d::TEXT), 4326)", nil
+	}
+
+	// plain points only support GeoJSON points
+	var geometry struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+	}
+	switch v := value.(type) {
+	case string:
+		if err := json.Unmarshal([]byte(v), &geometry); err != nil {
+			return nil, "", err
+		}
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := json.Unmarshal(b, &geometry); err != nil {
+			return nil, "", err
+		}
+	}
+	if geometry.Type != "Point" || len(geometry.Coordinates) < 2 {
+		return nil, "", errors.New("invalid point value, expected GeoJSON point")
+	}
+
+	// shortest representation without loss of precision, %f would round to 6 decimals
+	return fmt.Sprintf("(%s,%s)",
+		strconv.FormatFloat(geometry.Coordinates[0], 'g', -1, 64),
+		strconv.FormatFloat(geometry.Coordinates[1], 'g', -1, 64)), "$%d::TEXT::POINT", nil
+}
+
+// returns spatial filter expression for geospatial attribute
+// 'WITHIN DISTANCE' expects [longitude, latitude, distance in meters]
+// 'WITHIN BOX' expects [longitude min, latitude min, longitude max, latitude max]
+func getGeoFilter(content string, code string, operator string, value interface{}, queryArgs *[]interface{}) (string, error) {
+
+	// values can be given as JSON array text (like from filter inputs)
+	if v, ok := value.(string); ok {
+		if err := json.Unmarshal([]byte(v), &value); err != nil {
+			return "", fmt.Errorf("invalid value for spatial operator '%s'", operator)
+		}
+	}
+
+	values, ok := value.([]interface{})
+	if !ok || (operator == "WITHIN DISTANCE" && len(values) != 3) || (operator == "WITHIN BOX" && len(values) != 4) {
+		return "", fmt.Errorf("invalid value for spatial operator '%s'", operator)
+	}
+
+	params := make([]interface{}, len(values))
+	for i, v := range values {
+		n, ok := v.(float64)
+		if !ok {
+			return "", fmt.Errorf("invalid value for spatial operator '%s', expected numbers", operator)
+		}
+		*queryArgs = append(*queryArgs, n)
+		params[i] = fmt.Sprintf("$%d::FLOAT8", len(*queryArgs))
+	}
+
+	switch operator {
+	case "WITHIN DISTANCE":
+		if content == "geometry" {
+			return fmt.Sprintf("ST_DWithin(%s::GEOGRAPHY, ST_SetSRID(ST_MakePoint(%s, %s), 4326)::GEOGRAPHY, %s)",
+				code, params[0], params[1], params[2]), nil
+		}
+
+		// great-circle distance (haversine) for plain points, in meters
+		return fmt.Sprintf(`(2 * 6371000 * ASIN(SQRT(
+			POWER(SIN(RADIANS(%[1]s[1] - %[3]s) / 2), 2) +
+			COS(RADIANS(%[3]s)) * COS(RADIANS(%[1]s[1])) *
+			POWER(SIN(RADIANS(%[1]s[0] - %[2]s) / 2), 2)))) <= %[4]s`,
+			code, params[0], params[1], params[2]), nil
+
+	case "WITHIN BOX":
+		if content == "geometry" {
+			return fmt.Sprintf("ST_Intersects(%s, ST_MakeEnvelope(%s, %s, %s, %s, 4326))",
+				code, params[0], params[1], params[2], params[3]), nil
+		}
+		return fmt.Sprintf("%s <@ BOX(POINT(%s, %s), POINT(%s, %s))",
+			code, params[0], params[1], params[2], params[3]), nil
+	}
+	return "", fmt.Errorf("unknown spatial operator '%s'", operator)
+}
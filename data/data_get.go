@@ -26,7 +26,8 @@ const (
 )
 
 var (
-	regexRelId = regexp.MustCompile(`^\_r(\d+)id`) // finds: _r3id
+	regexRelId      = regexp.MustCompile(`^\_r(\d+)id`)  // finds: _r3id
+	regexRelVersion = regexp.MustCompile(`^\_r(\d+)ver`) // finds: _r3ver
 )
 
 // get data
@@ -75,6 +76,7 @@ func Get_tx(ctx context.Context, tx pgx.Tx, data types.DataGet, loginId int64, q
 
 		indexRecordIds := make(map[int]interface{}) // ID for each relation tuple by index
 		indexRecordEncKeys := make(map[int]string)  // encrypted key for each relation tuple by index
+		indexRecordVersions := make(map[int]int64)  // record version for each relation tuple by index
 		values := make([]interface{}, 0)            // final values for selected attributes
 
 		// collect values for expressions
@@ -93,15 +95,28 @@ func Get_tx(ctx context.Context, tx pgx.Tx, data types.DataGet, loginId int64, q
 					return nil, 0, err
 				}
 				indexRecordIds[relIndex] = valuesAll[i]
+				continue
+			}
+
+			matches = regexRelVersion.FindStringSubmatch(string(rowColumns[i].Name))
+			if len(matches) == 2 {
+				relIndex, err := strconv.Atoi(matches[1])
+				if err != nil {
+					return nil, 0, err
+				}
+				if version, valid := valuesAll[i].(int64); valid {
+					indexRecordVersions[relIndex] = version
+				}
 			}
 		}
 
 		results = append(results, types.DataGetResult{
-			IndexRecordIds:     indexRecordIds,
-			IndexRecordEncKeys: indexRecordEncKeys,
-			IndexesPermNoDel:   make([]int, 0),
-			IndexesPermNoSet:   make([]int, 0),
-			Values:             values,
+			IndexRecordIds:      indexRecordIds,
+			IndexRecordEncKeys:  indexRecordEncKeys,
+			IndexRecordVersions: indexRecordVersions,
+			IndexesPermNoDel:    make([]int, 0),
+			IndexesPermNoSet:    make([]int, 0),
+			Values:              values,
 		})
 	}
 	if err := rows.Err(); err != nil {
@@ -278,12 +293,18 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 		if expr.AttributeId.Valid && !authorizedAttribute(loginId, expr.AttributeId.Bytes, types.AccessRead) {
 			return "", errors.New(handler.ErrUnauthorized)
 		}
+		for _, p := range expr.WindowPartitions {
+			if !authorizedAttribute(loginId, p.AttributeId, types.AccessRead) {
+				return "", errors.New(handler.ErrUnauthorized)
+			}
+		}
 	}
 
 	var (
 		inJoin     []string // relation joins
 		inSelect   []string // select expressions
 		inWhere    []string // filters
+		inHaving   []string // filters on grouped results
 		isSubQuery = nestingLevel != 0
 	)
 
@@ -298,6 +319,11 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 		return "", handler.ErrSchemaUnknownModule(rel.ModuleId)
 	}
 
+	// filters on aggregated attributes are separated into HAVING clause, combined with WHERE clause via AND
+	if err := checkFiltersHaving(data.Filters); err != nil {
+		return "", err
+	}
+
 	// add relations as joins via relationship attributes
 	indexRelationIds[data.IndexSource] = data.RelationId
 	for _, join := range data.Joins {
@@ -338,6 +364,27 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 	// add filters to query, replacing first AND with WHERE
 	queryWhere := strings.Replace(strings.Join(inWhere, ""), "AND", "WHERE", 1)
 
+	// add filters on aggregated attributes, these apply to grouped results
+	for _, filter := range getFiltersHaving(data.Filters) {
+		line, err := getQueryWhere(filter, queryArgs, loginId, nestingLevel)
+		if err != nil {
+			return "", err
+		}
+		inHaving = append(inHaving, line)
+	}
+
+	// build window order, used by all window function expressions
+	windowOrder := ""
+	for _, expr := range data.Expressions {
+		if expr.WindowFunction.Valid {
+			windowOrder, err = getQueryWindowOrder(data, nestingLevel)
+			if err != nil {
+				return "", err
+			}
+			break
+		}
+	}
+
 	// add expressions
 	mapIndex_agg := make(map[int]bool)        // map of indexes with aggregation
 	mapIndex_aggRecords := make(map[int]bool) // map of indexes with record aggregation
@@ -367,7 +414,7 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 		}
 
 		// attribute expression
-		line, err := getQuerySelect(pos, expr, nestingLevel)
+		line, err := getQuerySelect(pos, expr, windowOrder, nestingLevel)
 		if err != nil {
 			return "", err
 		}
@@ -396,6 +443,14 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 				getRelationCode(index, nestingLevel),
 				schema.PkName,
 				getTupleIdCode(index, nestingLevel)))
+
+			// add record versions for relations that use them, not available if results are aggregated
+			if len(mapIndex_agg) == 0 && cache.RelationIdMap[indexRelationIds[index]].RecordVersion {
+				inSelect = append(inSelect, fmt.Sprintf(`"%s"."%s" AS %s`,
+					getRelationCode(index, nestingLevel),
+					schema.RecordVersionName,
+					getTupleVersionCode(index, nestingLevel)))
+			}
 		}
 	}
 
@@ -431,6 +486,9 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 		queryGroup = fmt.Sprintf("\nGROUP BY %s", strings.Join(groupByItems, ", "))
 	}
 
+	// build HAVING line, replacing first AND with HAVING
+	queryHaving := strings.Replace(strings.Join(inHaving, ""), "AND", "HAVING", 1)
+
 	// build ORDER BY
 	queryOrder, err := getQueryLineOrderBy(data, nestingLevel)
 	if err != nil {
@@ -453,12 +511,13 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 	// build final data retrieval SQL query
 	query := fmt.Sprintf(
 		`SELECT %s`+"\n"+
-			`FROM "%s"."%s" AS "%s" %s%s%s%s%s%s`,
+			`FROM "%s"."%s" AS "%s" %s%s%s%s%s%s%s`,
 		strings.Join(inSelect, `, `), // SELECT
 		mod.Name, rel.Name, relCode,  // FROM
 		strings.Join(inJoin, ""), // JOINS
 		queryWhere,               // WHERE
 		queryGroup,               // GROUP BY
+		queryHaving,              // HAVING
 		queryOrder,               // ORDER BY
 		queryLimit,               // LIMIT
 		queryOffset)              // OFFSET
@@ -474,7 +533,8 @@ func prepareQuery(data types.DataGet, indexRelationIds map[int]uuid.UUID, queryA
 // add SELECT for attribute in given relation index
 // if attribute is from another relation than given index (relationship), attribute value = tuple IDs in relation with given index via given attribute
 // 'outside in' is important in cases of self reference, where direction cannot be ascertained by attribute
-func getQuerySelect(exprPos int, expr types.DataGetExpression, nestingLevel int) (string, error) {
+// window order is used for expressions with window function
+func getQuerySelect(exprPos int, expr types.DataGetExpression, windowOrder string, nestingLevel int) (string, error) {
 
 	relCode := getRelationCode(expr.Index, nestingLevel)
 
@@ -507,7 +567,32 @@ func getQuerySelect(exprPos int, expr types.DataGetExpression, nestingLevel int)
 
 	if !expr.OutsideIn {
 		// attribute is from index relation
-		return data_sql.GetExpression(expr, getAttributeCode(relCode, atr.Name), alias), nil
+		code := getAttributeCode(relCode, atr.Name)
+		if schema.IsContentRange(atr.Content) {
+			code = getRangeSelectCode(atr.Content, code)
+		} else if schema.IsContentGeo(atr.Content) {
+			code = getGeoSelectCode(atr.Content, code)
+		}
+
+		if expr.WindowFunction.Valid {
+			partitionItems := make([]string, 0)
+			for _, p := range expr.WindowPartitions {
+				atrPartition, exists := cache.AttributeIdMap[p.AttributeId]
+				if !exists {
+					return "", handler.ErrSchemaUnknownAttribute(p.AttributeId)
+				}
+				partitionItems = append(partitionItems, getAttributeCode(
+					getRelationCode(p.Index, nestingLevel), atrPartition.Name))
+			}
+
+			over := windowOrder
+			if len(partitionItems) != 0 {
+				over = strings.TrimSpace(fmt.Sprintf("PARTITION BY %s %s",
+					strings.Join(partitionItems, ", "), windowOrder))
+			}
+			return data_sql.GetWindowExpression(expr, code, over, alias), nil
+		}
+		return data_sql.GetExpression(expr, code, alias), nil
 	}
 
 	// attribute comes via relationship from other relation (or self reference from same relation)
@@ -665,6 +750,18 @@ func getQueryWhere(filter types.DataGetFilter, queryArgs *[]interface{}, loginId
 		}
 	}
 
+	// comparisons to range/JSON attributes require fixed values to be converted
+	var contentCompared string
+	for _, s := range []types.DataGetFilterSide{filter.Side0, filter.Side1} {
+		if s.AttributeId.Valid && !s.Aggregator.Valid {
+			if atr, exists := cache.AttributeIdMap[s.AttributeId.Bytes]; exists &&
+				(schema.IsContentRange(atr.Content) || schema.IsContentJson(atr.Content)) {
+
+				contentCompared = atr.Content
+			}
+		}
+	}
+
 	// define comparisons
 	var getComp = func(s types.DataGetFilterSide, isSide0 bool) (string, error) {
 
@@ -687,6 +784,15 @@ func getQueryWhere(filter types.DataGetFilter, queryArgs *[]interface{}, loginId
 			}
 			atrExpr := getAttributeCode(getRelationCode(s.AttributeIndex, s.AttributeNested), atr.Name)
 
+			// aggregated attribute, filter is part of HAVING clause
+			if s.Aggregator.Valid {
+				codeAgg, valid := data_sql.GetAggregation(s.Aggregator.String, false, pgtype.Float8{}, atrExpr)
+				if !valid {
+					return "", errors.New("bad filter aggregator")
+				}
+				atrExpr = codeAgg
+			}
+
 			if isOpFts {
 				exprRegconfig := exprRegconfigSimple
 				if opFtsDictAtrId.Valid {
@@ -729,6 +835,34 @@ func getQueryWhere(filter types.DataGetFilter, queryArgs *[]interface{}, loginId
 			}
 		}
 
+		if !isOpLike && !isOpFts && s.Value != nil {
+			if schema.IsContentRange(contentCompared) {
+				// range can be compared to single point in time (unix) or other range
+				if v, ok := s.Value.(float64); ok {
+					*queryArgs = append(*queryArgs, int64(v))
+					if contentCompared == "daterange" {
+						return fmt.Sprintf("(TO_TIMESTAMP($%d) AT TIME ZONE 'UTC')::DATE", len(*queryArgs)), nil
+					}
+					return fmt.Sprintf("TO_TIMESTAMP($%d)", len(*queryArgs)), nil
+				}
+
+				v, err := getRangeValue(contentCompared, s.Value)
+				if err != nil {
+					return "", err
+				}
+				*queryArgs = append(*queryArgs, v)
+				return fmt.Sprintf("$%d::TEXT::%s", len(*queryArgs), contentCompared), nil
+			}
+
+			if schema.IsContentJson(contentCompared) {
+				// string values are JSON documents
+				if v, ok := s.Value.(string); ok {
+					*queryArgs = append(*queryArgs, v)
+					return fmt.Sprintf("$%d::TEXT::JSONB", len(*queryArgs)), nil
+				}
+			}
+		}
+
 		// add value to query arguments and refer to it via placeholder
 		*queryArgs = append(*queryArgs, s.Value)
 
@@ -757,6 +891,29 @@ func getQueryWhere(filter types.DataGetFilter, queryArgs *[]interface{}, loginId
 	if err != nil {
 		return "", err
 	}
+
+	// spatial operators compare geospatial attribute (left side) with fixed value (right side)
+	if isSpatialOperator(filter.Operator) {
+		var atr types.Attribute
+		var exists bool
+		if filter.Side0.AttributeId.Valid {
+			atr, exists = cache.AttributeIdMap[filter.Side0.AttributeId.Bytes]
+		}
+		if !exists || !schema.IsContentGeo(atr.Content) || filter.Side0.Aggregator.Valid {
+			return "", errors.New("spatial operator requires geospatial attribute on left side")
+		}
+
+		comp, err := getGeoFilter(atr.Content, comp0, filter.Operator, filter.Side1.Value, queryArgs)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("\n%s %s%s%s",
+			filter.Connector,
+			getBrackets(filter.Side0.Brackets, false),
+			comp,
+			getBrackets(filter.Side1.Brackets, true)), nil
+	}
+
 	comp1 := ""
 
 	if !isOpNull {
@@ -832,6 +989,73 @@ func getQueryLineOrderBy(data types.DataGet, nestingLevel int) (string, error) {
 	return fmt.Sprintf("\nORDER BY %s", strings.Join(orderItems, ", ")), nil
 }
 
+// returns ORDER BY for window functions from data GET orders
+// aliases are not usable inside window definitions, attribute codes are used instead
+// orders on sub query and window function expressions are skipped
+func getQueryWindowOrder(data types.DataGet, nestingLevel int) (string, error) {
+
+	orderItems := make([]string, 0)
+	for _, ord := range data.Orders {
+
+		var atrId uuid.UUID
+		var expr types.DataGetExpression
+
+		if ord.AttributeId.Valid {
+			atrId = ord.AttributeId.Bytes
+			expr.Index = int(ord.Index.Int32)
+
+			// use aggregation if attribute is used in aggregated expression
+			for _, e := range data.Expressions {
+				if e.AttributeId.Bytes == ord.AttributeId.Bytes && e.Index == int(ord.Index.Int32) {
+					expr = e
+					break
+				}
+			}
+		} else if ord.ExpressionPos.Valid {
+			pos := int(ord.ExpressionPos.Int32)
+			if pos < 0 || pos >= len(data.Expressions) {
+				return "", errors.New("unknown data GET order parameter")
+			}
+			expr = data.Expressions[pos]
+
+			if !expr.AttributeId.Valid || expr.OutsideIn || expr.WindowFunction.Valid {
+				continue
+			}
+			atrId = expr.AttributeId.Bytes
+		} else {
+			return "", errors.New("unknown data GET order parameter")
+		}
+
+		atr, exists := cache.AttributeIdMap[atrId]
+		if !exists {
+			return "", handler.ErrSchemaUnknownAttribute(atrId)
+		}
+		if schema.IsContentFiles(atr.Content) {
+			continue
+		}
+
+		code := getAttributeCode(getRelationCode(expr.Index, nestingLevel), atr.Name)
+		if expr.Aggregator.Valid {
+			if codeAgg, valid := data_sql.GetAggregation(expr.Aggregator.String,
+				expr.Distincted, expr.Percentile, code); valid {
+
+				code = codeAgg
+			}
+		}
+
+		if ord.Ascending {
+			orderItems = append(orderItems, fmt.Sprintf("%s ASC", code))
+		} else {
+			orderItems = append(orderItems, fmt.Sprintf("%s DESC NULLS LAST", code))
+		}
+	}
+
+	if len(orderItems) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("ORDER BY %s", strings.Join(orderItems, ", ")), nil
+}
+
 // helpers
 
 // relation codes exist to uniquely reference a joined relation, even if the same relation is joined multiple times
@@ -850,6 +1074,11 @@ func getTupleIdCode(relationIndex int, nestingLevel int) string {
 	return fmt.Sprintf("%sid", getRelationCode(relationIndex, nestingLevel))
 }
 
+// tuple versions are uniquely identified by the relation code + the fixed string 'ver'
+func getTupleVersionCode(relationIndex int, nestingLevel int) string {
+	return fmt.Sprintf("%sver", getRelationCode(relationIndex, nestingLevel))
+}
+
 // an attribute is referenced by the relation code + the attribute name
 // due to the relation code, this will always uniquely identify an attribute from a specific index
 // example: _r3.surname maps to person.surname from index 3
@@ -878,19 +1107,97 @@ func getFiltersByIndex(filters []types.DataGetFilter, index int) []types.DataGet
 	out := make([]types.DataGetFilter, 0)
 
 	for _, filter := range filters {
-		if filter.Index == index {
+		if filter.Index == index && !isFilterHaving(filter) {
+			out = append(out, filter)
+		}
+	}
+	return getFiltersEnclosed(out)
+}
+
+// returns filters on aggregated attributes, regardless of relation index
+func getFiltersHaving(filters []types.DataGetFilter) []types.DataGetFilter {
+	out := make([]types.DataGetFilter, 0)
+
+	for _, filter := range filters {
+		if isFilterHaving(filter) {
 			out = append(out, filter)
 		}
 	}
+	return getFiltersEnclosed(out)
+}
+
+// checks that filters on aggregated attributes can be separated from other filters without changing their logic
+// both must not share a bracket group or be combined via OR
+func checkFiltersHaving(filters []types.DataGetFilter) error {
+	type group struct {
+		hasHaving bool
+		hasWhere  bool
+		hasOr     bool
+	}
+	groups := []group{{}} // first group is top level
+
+	for i, filter := range filters {
+		// connector applies to group in which new brackets are opened
+		if i != 0 && filter.Connector == "OR" {
+			groups[len(groups)-1].hasOr = true
+		}
+		for j := 0; j < filter.Side0.Brackets; j++ {
+			groups = append(groups, group{})
+		}
+		if isFilterHaving(filter) {
+			groups[len(groups)-1].hasHaving = true
+		} else {
+			groups[len(groups)-1].hasWhere = true
+		}
+
+		// closed groups count as filters of their parent group
+		for j := 0; j < filter.Side1.Brackets && len(groups) > 1; j++ {
+			g := groups[len(groups)-1]
+			if g.hasHaving && g.hasWhere {
+				return errors.New("filters on aggregated and non-aggregated attributes must not share brackets")
+			}
+			groups = groups[:len(groups)-1]
+			groups[len(groups)-1].hasHaving = groups[len(groups)-1].hasHaving || g.hasHaving
+			groups[len(groups)-1].hasWhere = groups[len(groups)-1].hasWhere || g.hasWhere
+		}
+	}
 
-	// overwrite first filter connector and add brackets in first and last filter line
-	//  so that query filters do not interfere with other filters
-	if len(out) != 0 {
-		out[0].Connector = "AND"
-		out[0].Side0.Brackets++
-		out[len(out)-1].Side1.Brackets++
+	for i, g := range groups {
+		if !g.hasHaving || !g.hasWhere {
+			continue
+		}
+		if i != 0 {
+			return errors.New("filters on aggregated and non-aggregated attributes must not share brackets")
+		}
+		if g.hasOr {
+			return errors.New("filters on aggregated and non-aggregated attributes must be combined with AND")
+		}
 	}
-	return out
+	return nil
+}
+
+// balances brackets of filters (filter lines can be separated into WHERE/HAVING clauses)
+// overwrites first filter connector and adds brackets in first and last filter line
+//
+//	so that query filters do not interfere with other filters
+func getFiltersEnclosed(filters []types.DataGetFilter) []types.DataGetFilter {
+	if len(filters) == 0 {
+		return filters
+	}
+
+	depth := 0
+	for i := range filters {
+		depth += filters[i].Side0.Brackets
+		if filters[i].Side1.Brackets > depth {
+			filters[i].Side1.Brackets = depth
+		}
+		depth -= filters[i].Side1.Brackets
+	}
+
+	filters[0].Connector = "AND"
+	filters[0].Side0.Brackets++
+	filters[len(filters)-1].Side1.Brackets += depth + 1
+	return filters
 }
 func getFtsExpression(exprRegconfig string, exprValue string, isSide0 bool) string {
 	// when using FTS operator, we assume vectorized text to be left (side0) and query to be right (side1)
@@ -908,6 +1215,9 @@ func getFtsExpression(exprRegconfig string, exprValue string, isSide0 bool) stri
 }
 
 // operator types
+func isFilterHaving(filter types.DataGetFilter) bool {
+	return filter.Side0.Aggregator.Valid || filter.Side1.Aggregator.Valid
+}
 func isArrayOperator(operator string) bool {
 	return slices.Contains([]string{"= ANY", "<> ALL"}, operator)
 }
@@ -920,3 +1230,6 @@ func isLikeOperator(operator string) bool {
 func isNullOperator(operator string) bool {
 	return slices.Contains([]string{"IS NULL", "IS NOT NULL"}, operator)
 }
+func isSpatialOperator(operator string) bool {
+	return slices.Contains([]string{"WITHIN DISTANCE", "WITHIN BOX"}, operator)
+}
@@ -0,0 +1,60 @@
+package data
+
+import (
+	"r3/types"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestCheckFiltersHaving(t *testing.T) {
+	// filter notation: brackets, W (WHERE) or H (HAVING), brackets, separated by connector
+	tests := []struct {
+		filters string
+		wantErr bool
+	}{
+		{"", false},
+		{"W OR W", false},
+		{"H OR H", false},
+		{"W AND H", false},
+		{"(W OR W) AND H", false},
+		{"W AND (H OR H)", false},
+		{"(W OR W) AND (H OR H)", false},
+		{"W OR H", true},
+		{"H OR W", true},
+		{"W OR W AND H", true},
+		{"W AND (W OR W) OR H", true},
+		{"(W AND H)", true},
+		{"((W) AND H)", true},
+		{"W AND (H AND (W))", true},
+		{"(W AND H", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filters, func(t *testing.T) {
+			err := checkFiltersHaving(getFilters(tt.filters))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func getFilters(notation string) []types.DataGetFilter {
+	filters := make([]types.DataGetFilter, 0)
+	connector := "AND"
+	for _, part := range strings.Fields(notation) {
+		if part == "AND" || part == "OR" {
+			connector = part
+			continue
+		}
+		code := strings.Trim(part, "()")
+		f := types.DataGetFilter{Connector: connector}
+		f.Side0.Brackets = strings.Index(part, code)
+		f.Side1.Brackets = len(part) - len(code) - f.Side0.Brackets
+		f.Side0.Aggregator = pgtype.Text{String: "sum", Valid: code == "H"}
+		filters = append(filters, f)
+	}
+	return filters
+}
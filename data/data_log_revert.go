@@ -0,0 +1,338 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"r3/cache"
+	"r3/handler"
+	"r3/schema"
+	"r3/types"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// attribute value reference inside data change logs
+type logValueKey struct {
+	attributeId   uuid.UUID
+	attributeIdNm pgtype.UUID
+	outsideIn     bool
+}
+
+// record reference inside data change logs
+type logRecordKey struct {
+	relationId uuid.UUID
+	recordId   int64
+}
+
+// reverts record to its logged state after the given change log entry
+func RevertToLog_tx(ctx context.Context, tx pgx.Tx, logId uuid.UUID, loginId int64) error {
+
+	var relationId uuid.UUID
+	var recordId, dateChange int64
+	if err := tx.QueryRow(ctx, `
+		SELECT relation_id, record_id_wofk, date_change
+		FROM instance.data_log
+		WHERE id = $1
+	`, logId).Scan(&relationId, &recordId, &dateChange); err != nil {
+		return err
+	}
+	return RevertToDate_tx(ctx, tx, relationId, recordId, dateChange, loginId)
+}
+
+// reverts record to its logged state at the given point in time (unix seconds)
+// attribute values without logged value at this point in time are kept as they are
+func RevertToDate_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordId int64, date int64, loginId int64) error {
+
+	exists, err := recordExists_tx(ctx, tx, relationId, recordId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("record %d does not exist, deleted records must be restored instead", recordId)
+	}
+
+	valueMap, err := getLogState_tx(ctx, tx, relationId, recordId, date, loginId)
+	if err != nil {
+		return err
+	}
+
+	attributes := make([]types.DataSetAttribute, 0)
+	for _, a := range valueMap {
+		attributes = append(attributes, a)
+	}
+	return revertRecord_tx(ctx, tx, relationId, recordId, attributes, loginId)
+}
+
+// restores deleted record from its latest logged state, record is created with a new ID
+// change logs of the deleted record are moved to the restored one
+// only possible if values of all required attributes are available in change logs
+func RestoreRecord_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordId int64, loginId int64) (int64, error) {
+
+	exists, err := recordExists_tx(ctx, tx, relationId, recordId)
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		return 0, fmt.Errorf("record %d still exists, it cannot be restored", recordId)
+	}
+
+	valueMap, err := getLogState_tx(ctx, tx, relationId, recordId, math.MaxInt64, loginId)
+	if err != nil {
+		return 0, err
+	}
+	if len(valueMap) == 0 {
+		return 0, fmt.Errorf("no change logs available for record %d", recordId)
+	}
+
+	// check for logged values of required attributes
+	cache.Schema_mx.RLock()
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		cache.Schema_mx.RUnlock()
+		return 0, handler.ErrSchemaUnknownRelation(relationId)
+	}
+	if rel.Encryption {
+		cache.Schema_mx.RUnlock()
+		return 0, fmt.Errorf("relation '%s' uses encryption, data keys of deleted records are lost", rel.Name)
+	}
+	for _, atr := range rel.Attributes {
+		if atr.Id == rel.AttributeIdPk || atr.Nullable || atr.Def != "" || schema.IsContentFiles(atr.Content) {
+			continue
+		}
+		v, exists := valueMap[logValueKey{attributeId: atr.Id}]
+		if !exists || v.Value == nil {
+			cache.Schema_mx.RUnlock()
+			return 0, fmt.Errorf("record %d cannot be restored, no logged value for required attribute '%s'",
+				recordId, atr.Name)
+		}
+	}
+	cache.Schema_mx.RUnlock()
+
+	attributes := make([]types.DataSetAttribute, 0)
+	for _, a := range valueMap {
+		if a.Value != nil {
+			attributes = append(attributes, a)
+		}
+	}
+
+	indexRecordIds, err := Set_tx(ctx, tx, map[int]types.DataSet{
+		0: types.DataSet{
+			RelationId:  relationId,
+			AttributeId: uuid.Nil,
+			IndexFrom:   -1,
+			RecordId:    0,
+			Attributes:  attributes,
+		},
+	}, loginId)
+	if err != nil {
+		return 0, err
+	}
+	recordIdNew := indexRecordIds[0]
+
+	// keep change history with restored record
+	if _, err := tx.Exec(ctx, `
+		UPDATE instance.data_log
+		SET record_id_wofk = $1
+		WHERE relation_id  = $2
+		AND record_id_wofk = $3
+	`, recordIdNew, relationId, recordId); err != nil {
+		return 0, err
+	}
+	return recordIdNew, nil
+}
+
+// reverts all attribute value changes of one login within the given time frame (unix seconds)
+// values that were changed again afterwards (by other logins or outside of the time frame) are kept
+// values without logged state before the time frame and deleted records are skipped
+// returns count of reverted records
+func RevertLoginChanges_tx(ctx context.Context, tx pgx.Tx, loginIdChanges int64,
+	dateFrom int64, dateTo int64, loginId int64) (int, error) {
+
+	recordKeys := make([]logRecordKey, 0)
+	recordKeyMapValueKeys := make(map[logRecordKey][]logValueKey)
+
+	rows, err := tx.Query(ctx, `
+		SELECT l.relation_id, l.record_id_wofk, v.attribute_id, v.attribute_id_nm, v.outside_in
+		FROM instance.data_log_value AS v
+		JOIN instance.data_log       AS l ON l.id = v.data_log_id
+		WHERE l.login_id_wofk = $1
+		AND   l.date_change BETWEEN $2 AND $3
+
+		-- skip values that were changed again afterwards
+		AND NOT EXISTS (
+			SELECT 1
+			FROM instance.data_log_value AS v2
+			JOIN instance.data_log       AS l2 ON l2.id = v2.data_log_id
+			WHERE l2.relation_id    = l.relation_id
+			AND   l2.record_id_wofk = l.record_id_wofk
+			AND   l2.date_change   >= l.date_change
+			AND   (l2.login_id_wofk <> l.login_id_wofk OR l2.date_change > $3)
+			AND   v2.attribute_id   = v.attribute_id
+			AND   v2.outside_in     = v.outside_in
+			AND   v2.attribute_id_nm IS NOT DISTINCT FROM v.attribute_id_nm
+		)
+		GROUP BY 1, 2, 3, 4, 5
+	`, loginIdChanges, dateFrom, dateTo)
+	if err != nil {
+		return 0, err
+	}
+
+	for rows.Next() {
+		var r logRecordKey
+		var v logValueKey
+		if err := rows.Scan(&r.relationId, &r.recordId, &v.attributeId,
+			&v.attributeIdNm, &v.outsideIn); err != nil {
+
+			rows.Close()
+			return 0, err
+		}
+		if _, exists := recordKeyMapValueKeys[r]; !exists {
+			recordKeys = append(recordKeys, r)
+		}
+		recordKeyMapValueKeys[r] = append(recordKeyMapValueKeys[r], v)
+	}
+	rows.Close()
+
+	cnt := 0
+	for _, r := range recordKeys {
+		exists, err := recordExists_tx(ctx, tx, r.relationId, r.recordId)
+		if err != nil {
+			return cnt, err
+		}
+		if !exists {
+			continue
+		}
+
+		valueMap, err := getLogState_tx(ctx, tx, r.relationId, r.recordId, dateFrom-1, loginId)
+		if err != nil {
+			return cnt, err
+		}
+
+		attributes := make([]types.DataSetAttribute, 0)
+		for _, k := range recordKeyMapValueKeys[r] {
+			if a, exists := valueMap[k]; exists {
+				attributes = append(attributes, a)
+			}
+		}
+		if len(attributes) == 0 {
+			continue
+		}
+
+		if err := revertRecord_tx(ctx, tx, r.relationId, r.recordId, attributes, loginId); err != nil {
+			return cnt, err
+		}
+		cnt++
+	}
+	return cnt, nil
+}
+
+// returns latest logged attribute values of record at the given point in time (unix seconds)
+// file attributes are excluded, as their logs only contain changes and not full values
+func getLogState_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordId int64, date int64, loginId int64) (map[logValueKey]types.DataSetAttribute, error) {
+
+	valueMap := make(map[logValueKey]types.DataSetAttribute)
+
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT ON (v.attribute_id, v.attribute_id_nm, v.outside_in)
+			v.attribute_id, v.attribute_id_nm, v.outside_in, v.value
+		FROM instance.data_log_value AS v
+		JOIN instance.data_log       AS l ON l.id = v.data_log_id
+		WHERE l.relation_id    = $1
+		AND   l.record_id_wofk = $2
+		AND   l.date_change   <= $3
+		ORDER BY v.attribute_id, v.attribute_id_nm, v.outside_in, l.date_change DESC
+	`, relationId, recordId, date)
+	if err != nil {
+		return valueMap, err
+	}
+	defer rows.Close()
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	for rows.Next() {
+		var a types.DataSetAttribute
+		var value pgtype.Text
+
+		if err := rows.Scan(&a.AttributeId, &a.AttributeIdNm, &a.OutsideIn, &value); err != nil {
+			return valueMap, err
+		}
+
+		atr, exists := cache.AttributeIdMap[a.AttributeId]
+		if !exists || schema.IsContentFiles(atr.Content) {
+			continue
+		}
+
+		// check for authorized access, READ(1) for GET
+		if !authorizedAttribute(loginId, a.AttributeId, types.AccessRead) {
+			return valueMap, errors.New(handler.ErrUnauthorized)
+		}
+
+		if value.Valid {
+			if err := json.Unmarshal([]byte(value.String), &a.Value); err != nil {
+				return valueMap, err
+			}
+		}
+		valueMap[logValueKey{
+			attributeId:   a.AttributeId,
+			attributeIdNm: a.AttributeIdNm,
+			outsideIn:     a.OutsideIn,
+		}] = a
+	}
+	return valueMap, nil
+}
+
+// sets given attribute values for existing record, regular data access checks & change logs apply
+func revertRecord_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordId int64, attributes []types.DataSetAttribute, loginId int64) error {
+
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	_, err := Set_tx(ctx, tx, map[int]types.DataSet{
+		0: types.DataSet{
+			RelationId:  relationId,
+			AttributeId: uuid.Nil,
+			IndexFrom:   -1,
+			RecordId:    recordId,
+			Attributes:  attributes,
+		},
+	}, loginId)
+	return err
+}
+
+func recordExists_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, recordId int64) (bool, error) {
+
+	cache.Schema_mx.RLock()
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		cache.Schema_mx.RUnlock()
+		return false, handler.ErrSchemaUnknownRelation(relationId)
+	}
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		cache.Schema_mx.RUnlock()
+		return false, handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+	cache.Schema_mx.RUnlock()
+
+	exists = false
+	err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT EXISTS(
+			SELECT 1
+			FROM "%s"."%s"
+			WHERE "%s" = $1
+		)
+	`, mod.Name, rel.Name, schema.PkName), recordId).Scan(&exists)
+
+	return exists, err
+}
@@ -20,11 +20,24 @@ func ConvertColumnToExpression(column types.Column, loginId int64, languageCode
 		Distincted:  column.Distincted,
 	}
 	if !column.SubQuery {
+		expr.Aggregator = column.Aggregator
+		expr.Percentile = column.Percentile
+		expr.WindowFunction = column.WindowFunction
+		expr.WindowOffset = column.WindowOffset
+		expr.WindowPartitions = make([]types.DataGetWindowPartition, 0)
+
+		if column.WindowPartitionAttributeId.Valid {
+			expr.WindowPartitions = append(expr.WindowPartitions, types.DataGetWindowPartition{
+				AttributeId: column.WindowPartitionAttributeId.Bytes,
+				Index:       int(column.WindowPartitionIndex.Int32),
+			})
+		}
 		return expr
 	}
 
 	return types.DataGetExpression{
 		Aggregator: column.Aggregator, // aggregation is done here
+		Percentile: column.Percentile,
 		Query: types.DataGet{
 			RelationId:  column.Query.RelationId.Bytes,
 			Joins:       ConvertQueryToDataJoins(column.Query.Joins),
@@ -65,6 +78,7 @@ func ConvertQueryToDataFilter(filters []types.QueryFilter, loginId int64,
 			Brackets:        side.Brackets,
 			Query:           types.DataGet{},
 			QueryAggregator: side.QueryAggregator,
+			Aggregator:      side.Aggregator,
 			Value:           side.Value,
 		}
 		switch side.Content {
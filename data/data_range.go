@@ -0,0 +1,54 @@
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+// returns range value as range literal (like '[2024-01-01,2024-01-31]') for date and timestamp ranges
+// range values are either arrays of unix timestamps as lower/upper bounds (NULL = unbounded) or range literals
+// date range bounds are inclusive, as dates are picked inclusively
+func getRangeValue(content string, value interface{}) (interface{}, error) {
+
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return v, nil
+	case []interface{}:
+		if len(v) != 2 {
+			return nil, fmt.Errorf("invalid range value, expected lower and upper bound")
+		}
+
+		bounds := make([]string, 2)
+		for i, b := range v {
+			switch unix := b.(type) {
+			case nil:
+				bounds[i] = ""
+			case float64:
+				if content == "daterange" {
+					bounds[i] = time.Unix(int64(unix), 0).UTC().Format("2006-01-02")
+				} else {
+					bounds[i] = time.Unix(int64(unix), 0).UTC().Format(time.RFC3339)
+				}
+			default:
+				return nil, fmt.Errorf("invalid range bound, expected unix timestamp")
+			}
+		}
+		return fmt.Sprintf("[%s,%s]", bounds[0], bounds[1]), nil
+	}
+	return nil, fmt.Errorf("invalid range value")
+}
+
+// returns SQL expression to retrieve range value as array of unix timestamps (lower/upper bound)
+// date ranges are stored with exclusive upper bound, inclusive upper bound is returned
+func getRangeSelectCode(content string, code string) string {
+	if content == "daterange" {
+		return fmt.Sprintf(`CASE WHEN %s IS NULL THEN NULL ELSE JSON_BUILD_ARRAY(
+			EXTRACT(EPOCH FROM LOWER(%s)::TIMESTAMP)::BIGINT,
+			EXTRACT(EPOCH FROM (UPPER(%s) - 1)::TIMESTAMP)::BIGINT) END`, code, code, code)
+	}
+	return fmt.Sprintf(`CASE WHEN %s IS NULL THEN NULL ELSE JSON_BUILD_ARRAY(
+		EXTRACT(EPOCH FROM LOWER(%s))::BIGINT,
+		EXTRACT(EPOCH FROM UPPER(%s))::BIGINT) END`, code, code, code)
+}
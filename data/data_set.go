@@ -66,6 +66,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, dataSetsByIndex map[int]types.DataSe
 				return indexRecordIds, errors.New(handler.ErrUnauthorized)
 			}
 
+			// computed attribute values are generated by the database
+			if atr, exists := cache.AttributeIdMap[attribute.AttributeId]; exists && atr.Computed != "" {
+				return indexRecordIds, fmt.Errorf("cannot set value of computed attribute '%s'", atr.Name)
+			}
+
 			// check for protected preset record values
 			for _, preset := range rel.Presets {
 
@@ -88,6 +93,20 @@ func Set_tx(ctx context.Context, tx pgx.Tx, dataSetsByIndex map[int]types.DataSe
 			}
 		}
 
+		// enforce validation rules of attributes
+		if err := validateDataSet_tx(ctx, tx, rel, dataSet); err != nil {
+			return indexRecordIds, err
+		}
+
+		// reject update if record was changed since its version was retrieved
+		if !isNewRecord && rel.RecordVersion && dataSet.RecordVersion.Valid {
+			if err := checkRecordVersion_tx(ctx, tx, rel, dataSet.RecordId,
+				dataSet.RecordVersion.Int64); err != nil {
+
+				return indexRecordIds, err
+			}
+		}
+
 		// set data for record of given relation index
 
 		// log data changes if retention is enabled
@@ -230,13 +249,39 @@ func setForIndex_tx(ctx context.Context, tx pgx.Tx, index int,
 		}
 
 		// process attribute values for this relation tuple
-		values = append(values, attribute.Value)
+		param := "$%d"
+		if schema.IsContentRange(atr.Content) {
+			// ranges are set via range literal
+			value, err := getRangeValue(atr.Content, attribute.Value)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+			param = fmt.Sprintf("$%%d::TEXT::%s", atr.Content)
+		} else if schema.IsContentGeo(atr.Content) {
+			// geospatial values are set via GeoJSON geometries
+			value, p, err := getGeoValue(atr.Content, attribute.Value)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+			param = p
+		} else if schema.IsContentJson(atr.Content) && attribute.Value != nil {
+			// JSON values are sent as encoded documents, plain strings would otherwise be taken as raw JSON
+			value, err := json.Marshal(attribute.Value)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		} else {
+			values = append(values, attribute.Value)
+		}
 
 		if isNewRecord {
 			names = append(names, fmt.Sprintf(`"%s"`, atr.Name))
-			params = append(params, fmt.Sprintf(`$%d`, len(values)))
+			params = append(params, fmt.Sprintf(param, len(values)))
 		} else {
-			params = append(params, fmt.Sprintf(`"%s" = $%d`, atr.Name, len(values)))
+			params = append(params, fmt.Sprintf(`"%s" = %s`, atr.Name, fmt.Sprintf(param, len(values))))
 		}
 	}
 
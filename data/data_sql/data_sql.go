@@ -3,6 +3,9 @@ package data_sql
 import (
 	"fmt"
 	"r3/types"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // alias for SELECT expression
@@ -33,27 +36,8 @@ func GetExpression(expr types.DataGetExpression, code string, alias string) stri
 			code = GetExpressionAlias(0)
 		}
 
-		switch expr.Aggregator.String {
-		case "array":
-			return fmt.Sprintf("%sARRAY_AGG(%s%s)%s AS %s", prefix, distinct, code, postfix, alias)
-		case "avg":
-			return fmt.Sprintf("%sAVG(%s%s)::NUMERIC(20,2)%s AS %s", prefix, distinct, code, postfix, alias)
-		case "count":
-			return fmt.Sprintf("%sCOUNT(%s%s)%s AS %s", prefix, distinct, code, postfix, alias)
-		case "json":
-			return fmt.Sprintf("%sJSON_AGG(%s%s)%s AS %s", prefix, distinct, code, postfix, alias)
-		case "list":
-			return fmt.Sprintf("%sSTRING_AGG(%s%s::TEXT, ', ')%s AS %s", prefix, distinct, code, postfix, alias)
-		case "max":
-			return fmt.Sprintf("%sMAX(%s)%s AS %s", prefix, code, postfix, alias)
-		case "min":
-			return fmt.Sprintf("%sMIN(%s)%s AS %s", prefix, code, postfix, alias)
-		case "sum":
-			return fmt.Sprintf("%sSUM(%s%s)%s AS %s", prefix, distinct, code, postfix, alias)
-		case "record":
-			// returns first result from set
-			// special use case: record IDs are still usable for record selection while other aggregations are active
-			return fmt.Sprintf("%sFIRST(%s)%s AS %s", prefix, code, postfix, alias)
+		if codeAgg, valid := GetAggregation(expr.Aggregator.String, expr.Distincted, expr.Percentile, code); valid {
+			return fmt.Sprintf("%s%s%s AS %s", prefix, codeAgg, postfix, alias)
 		}
 	}
 
@@ -63,3 +47,89 @@ func GetExpression(expr types.DataGetExpression, code string, alias string) stri
 	}
 	return fmt.Sprintf("%s%s AS %s", distinct, code, alias)
 }
+
+// returns aggregation of given code, false if aggregator is invalid
+// percentile is used as fraction (0-1) for the percentile aggregator, defaults to median (0.5)
+func GetAggregation(aggregator string, distincted bool, percentile pgtype.Float8, code string) (string, bool) {
+	var distinct = ""
+	if distincted {
+		distinct = "DISTINCT "
+	}
+
+	switch aggregator {
+	case "array":
+		return fmt.Sprintf("ARRAY_AGG(%s%s)", distinct, code), true
+	case "avg":
+		return fmt.Sprintf("AVG(%s%s)::NUMERIC(20,2)", distinct, code), true
+	case "count":
+		return fmt.Sprintf("COUNT(%s%s)", distinct, code), true
+	case "json":
+		return fmt.Sprintf("JSON_AGG(%s%s)", distinct, code), true
+	case "list":
+		return fmt.Sprintf("STRING_AGG(%s%s::TEXT, ', ')", distinct, code), true
+	case "max":
+		return fmt.Sprintf("MAX(%s)", code), true
+	case "median":
+		return fmt.Sprintf("PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY %s)", code), true
+	case "min":
+		return fmt.Sprintf("MIN(%s)", code), true
+	case "percentile":
+		fraction := 0.5
+		if percentile.Valid && percentile.Float64 >= 0 && percentile.Float64 <= 1 {
+			fraction = percentile.Float64
+		}
+		return fmt.Sprintf("PERCENTILE_CONT(%g) WITHIN GROUP (ORDER BY %s)", fraction, code), true
+	case "stddev":
+		return fmt.Sprintf("STDDEV(%s%s)::NUMERIC(20,2)", distinct, code), true
+	case "sum":
+		return fmt.Sprintf("SUM(%s%s)", distinct, code), true
+	case "record":
+		// returns first result from set
+		// special use case: record IDs are still usable for record selection while other aggregations are active
+		return fmt.Sprintf("FIRST(%s)", code), true
+	}
+	return "", false
+}
+
+// returns window function expression over given window definition (PARTITION BY/ORDER BY)
+// aggregated expressions are aggregated first, allowing for running totals over grouped results
+func GetWindowExpression(expr types.DataGetExpression, code string, over string, alias string) string {
+
+	if !slices.Contains(types.QueryWindowFunctions, expr.WindowFunction.String) {
+		// invalid window function, return standard expression
+		return GetExpression(expr, code, alias)
+	}
+
+	if expr.Aggregator.Valid {
+		if codeAgg, valid := GetAggregation(expr.Aggregator.String, expr.Distincted, expr.Percentile, code); valid {
+			code = codeAgg
+		}
+	}
+
+	offset := expr.WindowOffset
+	if offset < 1 {
+		offset = 1
+	}
+
+	switch expr.WindowFunction.String {
+	case "avg":
+		return fmt.Sprintf("AVG(%s) OVER (%s)::NUMERIC(20,2) AS %s", code, over, alias)
+	case "count":
+		return fmt.Sprintf("COUNT(%s) OVER (%s) AS %s", code, over, alias)
+	case "dense_rank":
+		return fmt.Sprintf("DENSE_RANK() OVER (%s) AS %s", over, alias)
+	case "lag":
+		return fmt.Sprintf("LAG(%s, %d) OVER (%s) AS %s", code, offset, over, alias)
+	case "lead":
+		return fmt.Sprintf("LEAD(%s, %d) OVER (%s) AS %s", code, offset, over, alias)
+	case "max":
+		return fmt.Sprintf("MAX(%s) OVER (%s) AS %s", code, over, alias)
+	case "min":
+		return fmt.Sprintf("MIN(%s) OVER (%s) AS %s", code, over, alias)
+	case "rank":
+		return fmt.Sprintf("RANK() OVER (%s) AS %s", over, alias)
+	case "row_number":
+		return fmt.Sprintf("ROW_NUMBER() OVER (%s) AS %s", over, alias)
+	}
+	return fmt.Sprintf("SUM(%s) OVER (%s) AS %s", code, over, alias)
+}
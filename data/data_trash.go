@@ -0,0 +1,415 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"r3/cache"
+	"r3/db"
+	"r3/handler"
+	"r3/schema"
+	"r3/tools"
+	"r3/types"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// delete recycle bin entries according to retention settings
+func DelTrashBackground() error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	now := tools.GetTimeUnix()
+	for _, r := range cache.RelationIdMap {
+
+		// recycle bin disabled, delete all remaining entries
+		keepUntil := now
+		if r.TrashDays.Valid {
+			keepUntil = now - (int64(r.TrashDays.Int32) * 86400)
+		}
+
+		// held files and references are deleted by cascade
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM instance.data_trash
+			WHERE relation_id = $1
+			AND   date_delete < $2
+		`, r.Id, keepUntil); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// get recycle bin entries of relation
+func GetTrash_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	loginId int64) ([]types.DataTrash, error) {
+
+	trash := make([]types.DataTrash, 0)
+
+	// same access as for deleting records
+	if !authorizedRelation(loginId, relationId, types.AccessDelete) {
+		return trash, errors.New(handler.ErrUnauthorized)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT t.id, t.record_id_wofk, t.date_delete, l.name, lm.name_display, r.record, (
+			SELECT COUNT(*)
+			FROM instance.data_trash_record
+			WHERE data_trash_id = t.id
+		)
+		FROM instance.data_trash AS t
+		JOIN      instance.data_trash_record AS r  ON r.data_trash_id = t.id AND r.position = 0
+		LEFT JOIN instance.login             AS l  ON l.id            = t.login_id_wofk
+		LEFT JOIN instance.login_meta        AS lm ON lm.login_id     = l.id
+		WHERE t.relation_id = $1
+		ORDER BY t.date_delete DESC
+	`, relationId)
+	if err != nil {
+		return trash, err
+	}
+	defer rows.Close()
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		return trash, handler.ErrSchemaUnknownRelation(relationId)
+	}
+
+	for rows.Next() {
+		var t types.DataTrash
+		var name pgtype.Text
+		var nameDisplay pgtype.Text
+		var record map[string]interface{}
+
+		if err := rows.Scan(&t.Id, &t.RecordId, &t.DateDelete, &name,
+			&nameDisplay, &record, &t.RecordCount); err != nil {
+
+			return trash, err
+		}
+		t.RelationId = relationId
+		t.LoginName = name.String
+		if nameDisplay.Valid && nameDisplay.String != "" {
+			t.LoginName = nameDisplay.String
+		}
+
+		// only include values of readable attributes
+		t.Values = make(map[uuid.UUID]interface{})
+		for _, atr := range rel.Attributes {
+			if value, exists := record[atr.Name]; exists &&
+				authorizedAttribute(loginId, atr.Id, types.AccessRead) {
+
+				t.Values[atr.Id] = value
+			}
+		}
+		trash = append(trash, t)
+	}
+	return trash, nil
+}
+
+// restores deleted records from recycle bin entry, records keep their IDs
+// references from other records, that were removed on deletion, are restored if they were not replaced since
+func RestoreTrash_tx(ctx context.Context, tx pgx.Tx, trashId uuid.UUID, loginId int64) error {
+
+	var relationId uuid.UUID
+	if err := tx.QueryRow(ctx, `
+		SELECT relation_id
+		FROM instance.data_trash
+		WHERE id = $1
+	`, trashId).Scan(&relationId); err != nil {
+		return err
+	}
+
+	// same access as for deleting records
+	if !authorizedRelation(loginId, relationId, types.AccessDelete) {
+		return errors.New(handler.ErrUnauthorized)
+	}
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	// restore records, parents before their children
+	type trashRecord struct {
+		relationId uuid.UUID
+		record     []byte
+	}
+	records := make([]trashRecord, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT relation_id, record
+		FROM instance.data_trash_record
+		WHERE data_trash_id = $1
+		ORDER BY position ASC
+	`, trashId)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var r trashRecord
+		if err := rows.Scan(&r.relationId, &r.record); err != nil {
+			rows.Close()
+			return err
+		}
+		records = append(records, r)
+	}
+	rows.Close()
+
+	for _, r := range records {
+		rel, exists := cache.RelationIdMap[r.relationId]
+		if !exists {
+			return handler.ErrSchemaUnknownRelation(r.relationId)
+		}
+		mod, exists := cache.ModuleIdMap[rel.ModuleId]
+		if !exists {
+			return handler.ErrSchemaUnknownModule(rel.ModuleId)
+		}
+
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+			INSERT INTO "%s"."%s"
+			SELECT * FROM JSONB_POPULATE_RECORD(NULL::"%s"."%s", $1)
+		`, mod.Name, rel.Name, mod.Name, rel.Name), r.record); err != nil {
+			return err
+		}
+	}
+
+	// restore file references
+	type trashFile struct {
+		attributeId uuid.UUID
+		fileId      uuid.UUID
+		recordId    int64
+		name        string
+		dateDelete  pgtype.Int8
+	}
+	files := make([]trashFile, 0)
+
+	rows, err = tx.Query(ctx, `
+		SELECT attribute_id, file_id, record_id_wofk, name, date_delete
+		FROM instance.data_trash_file
+		WHERE data_trash_id = $1
+	`, trashId)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var f trashFile
+		if err := rows.Scan(&f.attributeId, &f.fileId, &f.recordId, &f.name, &f.dateDelete); err != nil {
+			rows.Close()
+			return err
+		}
+		files = append(files, f)
+	}
+	rows.Close()
+
+	for _, f := range files {
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+			INSERT INTO instance_file."%s" (file_id, record_id, name, date_delete)
+			VALUES ($1,$2,$3,$4)
+		`, schema.GetFilesTableName(f.attributeId)), f.fileId, f.recordId, f.name, f.dateDelete); err != nil {
+			return err
+		}
+	}
+
+	// restore references from other records
+	type trashRef struct {
+		attributeId uuid.UUID
+		recordId    int64
+		value       int64
+	}
+	refs := make([]trashRef, 0)
+
+	rows, err = tx.Query(ctx, `
+		SELECT attribute_id, record_id_wofk, value
+		FROM instance.data_trash_ref
+		WHERE data_trash_id = $1
+	`, trashId)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var r trashRef
+		if err := rows.Scan(&r.attributeId, &r.recordId, &r.value); err != nil {
+			rows.Close()
+			return err
+		}
+		refs = append(refs, r)
+	}
+	rows.Close()
+
+	for _, r := range refs {
+		atr, exists := cache.AttributeIdMap[r.attributeId]
+		if !exists {
+			return handler.ErrSchemaUnknownAttribute(r.attributeId)
+		}
+		rel, exists := cache.RelationIdMap[atr.RelationId]
+		if !exists {
+			return handler.ErrSchemaUnknownRelation(atr.RelationId)
+		}
+		mod, exists := cache.ModuleIdMap[rel.ModuleId]
+		if !exists {
+			return handler.ErrSchemaUnknownModule(rel.ModuleId)
+		}
+
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+			UPDATE "%s"."%s"
+			SET   "%s" = $1
+			WHERE "%s" = $2
+			AND   "%s" IS NULL
+		`, mod.Name, rel.Name, atr.Name, schema.PkName, atr.Name), r.value, r.recordId); err != nil {
+			return err
+		}
+	}
+
+	// remove entry, releases held files
+	_, err = tx.Exec(ctx, `DELETE FROM instance.data_trash WHERE id = $1`, trashId)
+	return err
+}
+
+// moves record to recycle bin, before it is deleted
+// includes records deleted by cascade, file references and references from other records that are removed on deletion
+// schema cache must be locked by caller
+func setTrash_tx(ctx context.Context, tx pgx.Tx, mod types.Module, rel types.Relation,
+	recordId int64, loginId int64) (uuid.UUID, error) {
+
+	trashId, err := uuid.NewV4()
+	if err != nil {
+		return trashId, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO instance.data_trash (id, module_id, relation_id,
+			record_id_wofk, login_id_wofk, date_delete)
+		VALUES ($1,$2,$3,$4,$5,$6)
+	`, trashId, mod.Id, rel.Id, recordId, loginId, tools.GetTimeUnix()); err != nil {
+		return trashId, err
+	}
+
+	// records to store, processed in order to restore parents before their children
+	type trashStep struct {
+		relationId uuid.UUID
+		recordIds  []int64
+	}
+	steps := []trashStep{{relationId: rel.Id, recordIds: []int64{recordId}}}
+	relationIdMapRecordIds := make(map[uuid.UUID][]int64) // stored records
+	position := 0
+
+	for len(steps) != 0 {
+		s := steps[0]
+		steps = steps[1:]
+
+		r, exists := cache.RelationIdMap[s.relationId]
+		if !exists {
+			return trashId, handler.ErrSchemaUnknownRelation(s.relationId)
+		}
+		m, exists := cache.ModuleIdMap[r.ModuleId]
+		if !exists {
+			return trashId, handler.ErrSchemaUnknownModule(r.ModuleId)
+		}
+
+		// skip records already stored (circular relationships)
+		recordIds := make([]int64, 0)
+		for _, id := range s.recordIds {
+			if !slices.Contains(relationIdMapRecordIds[r.Id], id) {
+				recordIds = append(recordIds, id)
+			}
+		}
+		if len(recordIds) == 0 {
+			continue
+		}
+		relationIdMapRecordIds[r.Id] = append(relationIdMapRecordIds[r.Id], recordIds...)
+
+		// store records
+		var cnt int
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`
+			WITH records AS (
+				INSERT INTO instance.data_trash_record (data_trash_id,
+					position, relation_id, record_id_wofk, record)
+				SELECT $1, $2 + ROW_NUMBER() OVER (ORDER BY t."%s") - 1, $3, t."%s", TO_JSONB(t)
+				FROM "%s"."%s" AS t
+				WHERE t."%s" = ANY($4)
+				RETURNING 1
+			)
+			SELECT COUNT(*) FROM records
+		`, schema.PkName, schema.PkName, m.Name, r.Name, schema.PkName),
+			trashId, position, r.Id, recordIds).Scan(&cnt); err != nil {
+
+			return trashId, err
+		}
+		position += cnt
+
+		// store file references
+		for _, atr := range r.Attributes {
+			if !schema.IsContentFiles(atr.Content) {
+				continue
+			}
+			if _, err := tx.Exec(ctx, fmt.Sprintf(`
+				INSERT INTO instance.data_trash_file (data_trash_id,
+					attribute_id, file_id, record_id_wofk, name, date_delete)
+				SELECT $1, $2, file_id, record_id, name, date_delete
+				FROM instance_file."%s"
+				WHERE record_id = ANY($3)
+			`, schema.GetFilesTableName(atr.Id)), trashId, atr.Id, recordIds); err != nil {
+				return trashId, err
+			}
+		}
+
+		// follow records referencing stored records
+		for _, atr := range cache.AttributeIdMap {
+			if !schema.IsContentRelationship(atr.Content) || !atr.RelationshipId.Valid ||
+				atr.RelationshipId.Bytes != r.Id {
+
+				continue
+			}
+
+			rc, exists := cache.RelationIdMap[atr.RelationId]
+			if !exists {
+				return trashId, handler.ErrSchemaUnknownRelation(atr.RelationId)
+			}
+			mc, exists := cache.ModuleIdMap[rc.ModuleId]
+			if !exists {
+				return trashId, handler.ErrSchemaUnknownModule(rc.ModuleId)
+			}
+
+			switch atr.OnDelete {
+			case "CASCADE":
+				ids := make([]int64, 0)
+				if err := tx.QueryRow(ctx, fmt.Sprintf(`
+					SELECT ARRAY(
+						SELECT "%s"
+						FROM "%s"."%s"
+						WHERE "%s" = ANY($1)
+					)
+				`, schema.PkName, mc.Name, rc.Name, atr.Name), recordIds).Scan(&ids); err != nil {
+					return trashId, err
+				}
+				if len(ids) != 0 {
+					steps = append(steps, trashStep{relationId: rc.Id, recordIds: ids})
+				}
+			case "SET NULL":
+				if _, err := tx.Exec(ctx, fmt.Sprintf(`
+					INSERT INTO instance.data_trash_ref (data_trash_id,
+						attribute_id, record_id_wofk, value)
+					SELECT $1, $2, "%s", "%s"
+					FROM "%s"."%s"
+					WHERE "%s" = ANY($3)
+				`, schema.PkName, atr.Name, mc.Name, rc.Name, atr.Name),
+					trashId, atr.Id, recordIds); err != nil {
+					return trashId, err
+				}
+			}
+		}
+	}
+	return trashId, nil
+}
@@ -0,0 +1,153 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"r3/cache"
+	"r3/handler"
+	"r3/schema"
+	"r3/types"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// validates attribute values of data set against validation rules of their attributes
+// applies to all data changes (websocket, REST API, CSV and other data imports)
+// failed validations return error codes with the affected attribute, to be resolved by the requestor
+func validateDataSet_tx(ctx context.Context, tx pgx.Tx, rel types.Relation, dataSet types.DataSet) error {
+
+	attributeIdsSet := make(map[uuid.UUID]bool)
+	for _, a := range dataSet.Attributes {
+
+		// values from relationship attributes of other relations (outside in) belong to other records
+		if a.OutsideIn {
+			continue
+		}
+
+		atr, exists := cache.AttributeIdMap[a.AttributeId]
+		if !exists {
+			return handler.ErrSchemaUnknownAttribute(a.AttributeId)
+		}
+		attributeIdsSet[atr.Id] = true
+
+		if err := validateValue_tx(ctx, tx, atr, a.Value); err != nil {
+			return err
+		}
+	}
+
+	// new records require values for required attributes, unless defaults are available
+	if dataSet.RecordId == 0 {
+		for _, atr := range rel.Attributes {
+			if atr.Validation.Required && atr.Def == "" && !attributeIdsSet[atr.Id] {
+				return getValidationErr(handler.ErrCodeValRequired, atr.Id, nil)
+			}
+		}
+	}
+	return nil
+}
+
+func validateValue_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute, value interface{}) error {
+	v := atr.Validation
+
+	if value == nil || value == "" {
+		if v.Required {
+			return getValidationErr(handler.ErrCodeValRequired, atr.Id, nil)
+		}
+		return nil
+	}
+
+	// files are validated by their own rules (max. size), encrypted values cannot be checked
+	if schema.IsContentFiles(atr.Content) || atr.Encrypted {
+		return nil
+	}
+
+	// min/max check value of numbers and length of texts
+	if schema.IsContentNumber(atr.Content) && (v.Min.Valid || v.Max.Valid) {
+		number, ok := getValidationNumber(value)
+		if !ok {
+			return handler.CreateErrCode(handler.ErrContextDbs, handler.ErrCodeDbsInvalidTypeSyntax)
+		}
+		if v.Min.Valid && number < v.Min.Float64 {
+			return getValidationErr(handler.ErrCodeValMinValue, atr.Id, v.Min.Float64)
+		}
+		if v.Max.Valid && number > v.Max.Float64 {
+			return getValidationErr(handler.ErrCodeValMaxValue, atr.Id, v.Max.Float64)
+		}
+	}
+
+	if text, ok := value.(string); ok && (schema.IsContentText(atr.Content) || schema.IsContentEnum(atr.Content)) {
+		length := float64(utf8.RuneCountInString(text))
+
+		if v.Min.Valid && length < v.Min.Float64 {
+			return getValidationErr(handler.ErrCodeValMinLength, atr.Id, v.Min.Float64)
+		}
+		if v.Max.Valid && length > v.Max.Float64 {
+			return getValidationErr(handler.ErrCodeValMaxLength, atr.Id, v.Max.Float64)
+		}
+		if rx, exists := cache.AttributeIdMapRegex[atr.Id]; exists && !rx.MatchString(text) {
+			return getValidationErr(handler.ErrCodeValRegex, atr.Id, v.Regex.String)
+		}
+	}
+
+	// custom validation via backend function
+	if v.PgFunctionId.Valid {
+		fnc, exists := cache.PgFunctionIdMap[v.PgFunctionId.Bytes]
+		if !exists {
+			return handler.ErrSchemaUnknownPgFunction(v.PgFunctionId.Bytes)
+		}
+		mod, exists := cache.ModuleIdMap[fnc.ModuleId]
+		if !exists {
+			return handler.ErrSchemaUnknownModule(fnc.ModuleId)
+		}
+
+		valueJson, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		var valid bool
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`SELECT "%s"."%s"($1::JSONB)`,
+			mod.Name, fnc.Name), string(valueJson)).Scan(&valid); err != nil {
+
+			return err
+		}
+		if !valid {
+			return getValidationErr(handler.ErrCodeValPgFunction, atr.Id, nil)
+		}
+	}
+	return nil
+}
+
+func getValidationErr(number int, attributeId uuid.UUID, limit interface{}) error {
+	return handler.CreateErrCodeWithData(handler.ErrContextVal, number, struct {
+		AttributeId uuid.UUID   `json:"attributeId"`
+		Limit       interface{} `json:"limit"`
+	}{attributeId, limit})
+}
+
+func getValidationNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		// numeric values can be transferred as text to keep their precision
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,59 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"r3/cache"
+	"r3/handler"
+	"r3/schema"
+	"r3/types"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// checks whether given record version is still current
+// locks record until end of transaction to prevent concurrent updates in between
+func CheckRecordVersion_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID,
+	recordId int64, version int64) error {
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	rel, exists := cache.RelationIdMap[relationId]
+	if !exists {
+		return handler.ErrSchemaUnknownRelation(relationId)
+	}
+	if !rel.RecordVersion {
+		return fmt.Errorf("relation '%s' does not use record versions", rel.Name)
+	}
+	return checkRecordVersion_tx(ctx, tx, rel, recordId, version)
+}
+
+func checkRecordVersion_tx(ctx context.Context, tx pgx.Tx, rel types.Relation,
+	recordId int64, version int64) error {
+
+	mod, exists := cache.ModuleIdMap[rel.ModuleId]
+	if !exists {
+		return handler.ErrSchemaUnknownModule(rel.ModuleId)
+	}
+
+	var versionCurrent int64
+	err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT "%s"
+		FROM "%s"."%s"
+		WHERE "%s" = $1
+		FOR UPDATE
+	`, schema.RecordVersionName, mod.Name, rel.Name, schema.PkName), recordId).Scan(&versionCurrent)
+
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	// deleted records are treated as conflict as well
+	if errors.Is(err, pgx.ErrNoRows) || versionCurrent != version {
+		return handler.CreateErrCode(handler.ErrContextApp, handler.ErrCodeAppRecordVersionConflict)
+	}
+	return nil
+}
@@ -79,7 +79,7 @@ func PrepareDbIfNew() error {
 	}
 
 	// create initial login last, in case database upgrade is required beforehand
-	return login.CreateAdmin("admin", "admin")
+	return login.CreateAdmin("admin", "admin", false)
 }
 
 func renewDbUserPw_tx(ctx context.Context, tx pgx.Tx) error {
@@ -101,6 +101,896 @@ var upgradeFunctions = map[string]func(ctx context.Context, tx pgx.Tx) (string,
 			TYPE app.field_flag[] USING flags::CHARACTER VARYING(12)[]::app.field_flag[];
 	*/
 
+	"3.11": func(ctx context.Context, tx pgx.Tx) (string, error) {
+		_, err := tx.Exec(ctx, `
+			-- mail DKIM signing
+			ALTER TABLE instance.mail_account ADD COLUMN dkim_domain      TEXT;
+			ALTER TABLE instance.mail_account ADD COLUMN dkim_selector    TEXT;
+			ALTER TABLE instance.mail_account ADD COLUMN dkim_private_key TEXT;
+
+			-- mail templates
+			CREATE TABLE IF NOT EXISTS instance.mail_template (
+				id SERIAL NOT NULL,
+				name CHARACTER VARYING(64) NOT NULL,
+				comment TEXT,
+				CONSTRAINT mail_template_pkey PRIMARY KEY (id),
+				CONSTRAINT mail_template_name_key UNIQUE (name)
+			);
+			CREATE TABLE IF NOT EXISTS instance.mail_template_caption (
+				mail_template_id INTEGER NOT NULL,
+				language_code CHARACTER(5) NOT NULL,
+				subject TEXT NOT NULL,
+				body_html TEXT NOT NULL,
+				body_text TEXT NOT NULL,
+				CONSTRAINT mail_template_caption_pkey PRIMARY KEY (mail_template_id, language_code),
+				CONSTRAINT mail_template_caption_mail_template_id_fkey FOREIGN KEY (mail_template_id)
+					REFERENCES instance.mail_template (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+
+			-- mail threading and templates in spooler
+			ALTER TABLE instance.mail_spool ADD COLUMN message_id        TEXT;
+			ALTER TABLE instance.mail_spool ADD COLUMN in_reply_to       TEXT;
+			ALTER TABLE instance.mail_spool ADD COLUMN references_list   TEXT;
+			ALTER TABLE instance.mail_spool ADD COLUMN body_text         TEXT;
+			ALTER TABLE instance.mail_spool ADD COLUMN mail_template_id  INTEGER;
+			ALTER TABLE instance.mail_spool ADD COLUMN template_language CHARACTER(5);
+			ALTER TABLE instance.mail_spool ADD COLUMN template_values   JSONB;
+			ALTER TABLE instance.mail_spool ADD CONSTRAINT mail_spool_mail_template_id_fkey
+				FOREIGN KEY (mail_template_id)
+				REFERENCES instance.mail_template (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE CASCADE
+				DEFERRABLE INITIALLY DEFERRED;
+
+			CREATE INDEX IF NOT EXISTS fki_mail_spool_mail_template_id_fkey
+				ON instance.mail_spool USING btree (mail_template_id ASC NULLS LAST);
+
+			ALTER TABLE instance.mail_traffic ADD COLUMN message_id TEXT;
+
+			-- mail delivery status callbacks
+			ALTER TABLE instance.mail_spool ADD COLUMN pg_function_id_callback UUID;
+			ALTER TABLE instance.mail_spool ADD COLUMN callback_value TEXT;
+			ALTER TABLE instance.mail_spool ADD CONSTRAINT mail_spool_pg_function_id_callback_fkey
+				FOREIGN KEY (pg_function_id_callback)
+				REFERENCES app.pg_function (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE SET NULL
+				DEFERRABLE INITIALLY DEFERRED;
+
+			CREATE INDEX IF NOT EXISTS fki_mail_spool_pg_function_id_callback_fkey
+				ON instance.mail_spool USING btree (pg_function_id_callback ASC NULLS LAST);
+
+			DROP FUNCTION instance.mail_send;
+			CREATE OR REPLACE FUNCTION instance.mail_send(
+				subject TEXT,
+				body TEXT,
+				to_list TEXT DEFAULT ''::TEXT,
+				cc_list TEXT DEFAULT ''::TEXT,
+				bcc_list TEXT DEFAULT ''::TEXT,
+				account_name TEXT DEFAULT NULL::TEXT,
+				attach_record_id BIGINT DEFAULT NULL::BIGINT,
+				attach_attribute_id UUID DEFAULT NULL::UUID,
+				in_reply_to TEXT DEFAULT NULL::TEXT,
+				body_text TEXT DEFAULT NULL::TEXT,
+				callback_function_id UUID DEFAULT NULL::UUID,
+				callback_value TEXT DEFAULT NULL::TEXT)
+				RETURNS INTEGER
+				LANGUAGE 'plpgsql'
+				COST 100
+				VOLATILE PARALLEL UNSAFE
+			AS $BODY$
+			DECLARE
+				account_id INTEGER;
+			BEGIN
+				IF account_name IS NOT NULL THEN
+					SELECT id INTO account_id
+					FROM instance.mail_account
+					WHERE name = account_name;
+				END IF;
+
+				IF to_list  IS NULL THEN to_list  := ''; END IF;
+				IF cc_list  IS NULL THEN cc_list  := ''; END IF;
+				IF bcc_list IS NULL THEN bcc_list := ''; END IF;
+
+				INSERT INTO instance.mail_spool (to_list,cc_list,bcc_list,
+					subject,body,body_text,outgoing,date,mail_account_id,
+					record_id_wofk,attribute_id,in_reply_to,
+					pg_function_id_callback,callback_value)
+				VALUES (to_list,cc_list,bcc_list,subject,body,body_text,TRUE,
+					EXTRACT(epoch from now()),account_id,attach_record_id,
+					attach_attribute_id,in_reply_to,callback_function_id,
+					callback_value);
+
+				RETURN 0;
+			END;
+			$BODY$;
+
+			CREATE OR REPLACE FUNCTION instance.mail_send_template(
+				template_name TEXT,
+				template_values JSONB DEFAULT NULL::JSONB,
+				to_list TEXT DEFAULT ''::TEXT,
+				cc_list TEXT DEFAULT ''::TEXT,
+				bcc_list TEXT DEFAULT ''::TEXT,
+				account_name TEXT DEFAULT NULL::TEXT,
+				attach_record_id BIGINT DEFAULT NULL::BIGINT,
+				attach_attribute_id UUID DEFAULT NULL::UUID,
+				in_reply_to TEXT DEFAULT NULL::TEXT,
+				language_code TEXT DEFAULT NULL::TEXT,
+				callback_function_id UUID DEFAULT NULL::UUID,
+				callback_value TEXT DEFAULT NULL::TEXT)
+				RETURNS INTEGER
+				LANGUAGE 'plpgsql'
+				COST 100
+				VOLATILE PARALLEL UNSAFE
+			AS $BODY$
+			DECLARE
+				account_id  INTEGER;
+				template_id INTEGER;
+			BEGIN
+				SELECT id INTO template_id
+				FROM instance.mail_template
+				WHERE name = template_name;
+
+				IF template_id IS NULL THEN
+					RAISE EXCEPTION 'mail template "%" does not exist', template_name;
+				END IF;
+
+				IF account_name IS NOT NULL THEN
+					SELECT id INTO account_id
+					FROM instance.mail_account
+					WHERE name = account_name;
+				END IF;
+
+				IF to_list  IS NULL THEN to_list  := ''; END IF;
+				IF cc_list  IS NULL THEN cc_list  := ''; END IF;
+				IF bcc_list IS NULL THEN bcc_list := ''; END IF;
+
+				-- use language of current login if not specified
+				IF language_code IS NULL THEN
+					language_code := instance.get_login_language_code();
+				END IF;
+
+				INSERT INTO instance.mail_spool (to_list,cc_list,bcc_list,
+					subject,body,outgoing,date,mail_account_id,record_id_wofk,
+					attribute_id,in_reply_to,mail_template_id,template_language,
+					template_values,pg_function_id_callback,callback_value)
+				VALUES (to_list,cc_list,bcc_list,'','',TRUE,EXTRACT(epoch from now()),
+					account_id,attach_record_id,attach_attribute_id,in_reply_to,
+					template_id,language_code,template_values,callback_function_id,
+					callback_value);
+
+				RETURN 0;
+			END;
+			$BODY$;
+
+			-- mail raw messages, headers and threading
+			ALTER TABLE instance.mail_spool ADD COLUMN raw           BYTEA;
+			ALTER TABLE instance.mail_spool ADD COLUMN attach_raw    BOOLEAN NOT NULL DEFAULT FALSE;
+			ALTER TABLE instance.mail_spool ADD COLUMN headers       JSONB;
+			ALTER TABLE instance.mail_spool ADD COLUMN reply_to_list TEXT;
+			ALTER TABLE instance.mail_spool ADD COLUMN thread_id     TEXT;
+
+			ALTER TABLE instance.mail_traffic ADD COLUMN references_list TEXT;
+			ALTER TABLE instance.mail_traffic ADD COLUMN thread_id       TEXT;
+
+			CREATE INDEX IF NOT EXISTS ind_mail_traffic_message_id
+				ON instance.mail_traffic USING btree (message_id ASC NULLS LAST);
+
+			DROP FUNCTION instance.mail_get_next;
+			DROP TYPE instance.mail;
+			CREATE TYPE instance.mail AS (
+				id integer,
+				from_list text,
+				to_list text,
+				cc_list text,
+				reply_to_list text,
+				subject text,
+				body text,
+				message_id text,
+				in_reply_to text,
+				references_list text,
+				thread_id text,
+				headers jsonb
+			);
+
+			CREATE FUNCTION instance.mail_get_next(
+				account_name text DEFAULT NULL::text)
+			    RETURNS instance.mail
+			    LANGUAGE 'plpgsql'
+			    COST 100
+			    STABLE PARALLEL UNSAFE
+			AS $BODY$
+			DECLARE
+				m instance.mail;
+			BEGIN
+				SELECT id, from_list, to_list, cc_list, reply_to_list, subject, body,
+					message_id, in_reply_to, references_list, thread_id, headers
+				INTO m.id, m.from_list, m.to_list, m.cc_list, m.reply_to_list, m.subject, m.body,
+					m.message_id, m.in_reply_to, m.references_list, m.thread_id, m.headers
+				FROM instance.mail_spool
+				WHERE outgoing = FALSE
+				AND record_id_wofk IS NULL
+				AND attribute_id IS NULL
+				AND (
+					account_name IS NULL
+					OR mail_account_id = (
+						SELECT id
+						FROM instance.mail_account
+						WHERE name = account_name
+					)
+				)
+				ORDER BY id ASC
+				LIMIT 1;
+
+				RETURN m;
+			END;
+			$BODY$;
+
+			DROP FUNCTION instance.mail_delete_after_attach;
+			CREATE FUNCTION instance.mail_delete_after_attach(
+				mail_id integer,
+				attach_record_id bigint,
+				attach_attribute_id uuid,
+				attach_raw boolean DEFAULT FALSE)
+				RETURNS integer
+				LANGUAGE 'plpgsql'
+			AS $BODY$
+				DECLARE
+				BEGIN
+					UPDATE instance.mail_spool SET
+						record_id_wofk = attach_record_id,
+						attribute_id = attach_attribute_id,
+						attach_raw = mail_delete_after_attach.attach_raw
+					WHERE id = mail_id
+					AND outgoing = FALSE;
+
+					RETURN 0;
+				END;
+			$BODY$;
+
+			CREATE OR REPLACE FUNCTION instance.mail_get_thread(thread_id TEXT)
+				RETURNS TABLE (message_id TEXT, outgoing BOOLEAN, date BIGINT,
+					from_list TEXT, to_list TEXT, subject TEXT)
+				LANGUAGE 'sql'
+				STABLE
+			AS $BODY$
+				SELECT t.message_id, t.outgoing, t.date, t.from_list, t.to_list, t.subject
+				FROM instance.mail_traffic AS t
+				WHERE t.thread_id = mail_get_thread.thread_id
+				ORDER BY t.date ASC;
+			$BODY$;
+
+			-- IMAP folders, processing action and IDLE push retrieval
+			CREATE TYPE instance.mail_account_imap_action AS ENUM ('delete', 'move', 'seen');
+			ALTER TABLE instance.mail_account ADD COLUMN imap_folder         TEXT;
+			ALTER TABLE instance.mail_account ADD COLUMN imap_folder_archive TEXT;
+			ALTER TABLE instance.mail_account ADD COLUMN imap_action instance.mail_account_imap_action NOT NULL DEFAULT 'delete';
+			ALTER TABLE instance.mail_account ADD COLUMN imap_idle   BOOLEAN NOT NULL DEFAULT FALSE;
+			ALTER TABLE instance.mail_account ADD COLUMN imap_store_raw BOOLEAN NOT NULL DEFAULT FALSE;
+			ALTER TABLE instance.mail_account ALTER COLUMN imap_action DROP DEFAULT;
+			ALTER TABLE instance.mail_account ALTER COLUMN imap_idle   DROP DEFAULT;
+			ALTER TABLE instance.mail_account ALTER COLUMN imap_store_raw DROP DEFAULT;
+
+			-- mail delivery status (from DSN/bounce messages)
+			CREATE TYPE instance.mail_delivery_action AS ENUM ('delivered', 'delayed', 'expanded', 'failed', 'relayed');
+			ALTER TABLE instance.mail_traffic ADD COLUMN pg_function_id_callback UUID;
+			ALTER TABLE instance.mail_traffic ADD COLUMN callback_value TEXT;
+			ALTER TABLE instance.mail_traffic ADD COLUMN delivery_action instance.mail_delivery_action;
+			ALTER TABLE instance.mail_traffic ADD COLUMN delivery_date BIGINT;
+			ALTER TABLE instance.mail_traffic ADD CONSTRAINT mail_traffic_pg_function_id_callback_fkey
+				FOREIGN KEY (pg_function_id_callback)
+				REFERENCES app.pg_function (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE SET NULL
+				DEFERRABLE INITIALLY DEFERRED;
+
+			CREATE INDEX IF NOT EXISTS fki_mail_traffic_pg_function_id_callback_fkey
+				ON instance.mail_traffic USING btree (pg_function_id_callback ASC NULLS LAST);
+
+			CREATE TABLE IF NOT EXISTS instance.mail_delivery (
+				message_id TEXT NOT NULL,
+				recipient TEXT NOT NULL,
+				action instance.mail_delivery_action NOT NULL,
+				status TEXT,
+				diagnostic TEXT,
+				date BIGINT NOT NULL,
+				CONSTRAINT mail_delivery_pkey PRIMARY KEY (message_id, recipient)
+			);
+
+			CREATE OR REPLACE FUNCTION instance.mail_get_delivery(message_id TEXT)
+				RETURNS TABLE (recipient TEXT, action TEXT, status TEXT, diagnostic TEXT, date BIGINT)
+				LANGUAGE 'sql'
+				STABLE
+			AS $BODY$
+				SELECT d.recipient, d.action::TEXT, d.status, d.diagnostic, d.date
+				FROM instance.mail_delivery AS d
+				WHERE d.message_id = mail_get_delivery.message_id
+				ORDER BY d.recipient ASC;
+			$BODY$;
+
+			-- backend function permissions (REST RPC calls)
+			ALTER TABLE app.role_access ADD COLUMN pg_function_id uuid;
+			ALTER TABLE app.role_access ADD CONSTRAINT role_access_pg_function_id_fkey FOREIGN KEY (pg_function_id)
+				REFERENCES app.pg_function (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE CASCADE
+				DEFERRABLE INITIALLY DEFERRED;
+
+			CREATE INDEX IF NOT EXISTS fki_role_access_pg_function_id_fkey
+				ON app.role_access USING btree (pg_function_id ASC NULLS LAST);
+
+			-- record versions (optimistic concurrency control)
+			ALTER TABLE app.relation ADD COLUMN record_version BOOLEAN NOT NULL DEFAULT FALSE;
+			ALTER TABLE app.relation ALTER COLUMN record_version DROP DEFAULT;
+
+			CREATE OR REPLACE FUNCTION instance.trg_record_version_update()
+				RETURNS TRIGGER
+				LANGUAGE 'plpgsql'
+			AS $BODY$
+				BEGIN
+					NEW._version := OLD._version + 1;
+					RETURN NEW;
+				END;
+			$BODY$;
+
+			-- recycle bin for deleted records
+			ALTER TABLE app.relation ADD COLUMN trash_days INTEGER;
+
+			CREATE TABLE IF NOT EXISTS instance.data_trash (
+				id UUID NOT NULL,
+				module_id UUID NOT NULL,
+				relation_id UUID NOT NULL,
+				record_id_wofk BIGINT NOT NULL,
+				login_id_wofk INTEGER,
+				date_delete BIGINT NOT NULL,
+				CONSTRAINT data_trash_pkey PRIMARY KEY (id),
+				CONSTRAINT data_trash_module_id_fkey FOREIGN KEY (module_id)
+					REFERENCES app.module (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT data_trash_relation_id_fkey FOREIGN KEY (relation_id)
+					REFERENCES app.relation (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_data_trash_module_id_fkey
+				ON instance.data_trash USING btree (module_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS fki_data_trash_relation_id_fkey
+				ON instance.data_trash USING btree (relation_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS ind_data_trash_date_delete
+				ON instance.data_trash USING btree (date_delete ASC NULLS LAST);
+
+			CREATE TABLE IF NOT EXISTS instance.data_trash_record (
+				data_trash_id UUID NOT NULL,
+				position INTEGER NOT NULL,
+				relation_id UUID NOT NULL,
+				record_id_wofk BIGINT NOT NULL,
+				record JSONB NOT NULL,
+				CONSTRAINT data_trash_record_pkey PRIMARY KEY (data_trash_id, position),
+				CONSTRAINT data_trash_record_data_trash_id_fkey FOREIGN KEY (data_trash_id)
+					REFERENCES instance.data_trash (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT data_trash_record_relation_id_fkey FOREIGN KEY (relation_id)
+					REFERENCES app.relation (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_data_trash_record_relation_id_fkey
+				ON instance.data_trash_record USING btree (relation_id ASC NULLS LAST);
+
+			CREATE TABLE IF NOT EXISTS instance.data_trash_file (
+				data_trash_id UUID NOT NULL,
+				attribute_id UUID NOT NULL,
+				file_id UUID NOT NULL,
+				record_id_wofk BIGINT NOT NULL,
+				name TEXT NOT NULL,
+				date_delete BIGINT,
+				CONSTRAINT data_trash_file_pkey PRIMARY KEY (data_trash_id, attribute_id, file_id, record_id_wofk),
+				CONSTRAINT data_trash_file_data_trash_id_fkey FOREIGN KEY (data_trash_id)
+					REFERENCES instance.data_trash (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT data_trash_file_attribute_id_fkey FOREIGN KEY (attribute_id)
+					REFERENCES app.attribute (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT data_trash_file_file_id_fkey FOREIGN KEY (file_id)
+					REFERENCES instance.file (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_data_trash_file_attribute_id_fkey
+				ON instance.data_trash_file USING btree (attribute_id ASC NULLS LAST);
+			CREATE INDEX IF NOT EXISTS fki_data_trash_file_file_id_fkey
+				ON instance.data_trash_file USING btree (file_id ASC NULLS LAST);
+
+			-- files of deleted records are kept while they are in the recycle bin
+			CREATE TRIGGER trg_data_trash_file_ref_counter BEFORE INSERT OR DELETE ON instance.data_trash_file
+				FOR EACH ROW EXECUTE FUNCTION instance.trg_file_ref_counter_update();
+
+			CREATE TABLE IF NOT EXISTS instance.data_trash_ref (
+				data_trash_id UUID NOT NULL,
+				attribute_id UUID NOT NULL,
+				record_id_wofk BIGINT NOT NULL,
+				value BIGINT NOT NULL,
+				CONSTRAINT data_trash_ref_pkey PRIMARY KEY (data_trash_id, attribute_id, record_id_wofk),
+				CONSTRAINT data_trash_ref_data_trash_id_fkey FOREIGN KEY (data_trash_id)
+					REFERENCES instance.data_trash (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT data_trash_ref_attribute_id_fkey FOREIGN KEY (attribute_id)
+					REFERENCES app.attribute (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_data_trash_ref_attribute_id_fkey
+				ON instance.data_trash_ref USING btree (attribute_id ASC NULLS LAST);
+
+			INSERT INTO instance.task
+				(name,interval_seconds,cluster_master_only,embedded_only,active,active_only)
+			VALUES ('cleanupDataTrash',86400,true,false,true,false);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('cleanupDataTrash',0,0);
+
+			-- statistical aggregators, window functions and filters on aggregated attributes
+			ALTER TYPE app.aggregator ADD VALUE 'median';
+			ALTER TYPE app.aggregator ADD VALUE 'percentile';
+			ALTER TYPE app.aggregator ADD VALUE 'stddev';
+
+			CREATE TYPE app.window_function AS ENUM (
+				'avg','count','dense_rank','lag','lead','max','min','rank','row_number','sum');
+
+			ALTER TABLE app.column
+				ADD COLUMN percentile DOUBLE PRECISION,
+				ADD COLUMN window_function app.window_function,
+				ADD COLUMN window_offset SMALLINT NOT NULL DEFAULT 1,
+				ADD COLUMN window_partition_attribute_id UUID,
+				ADD COLUMN window_partition_index SMALLINT,
+				ADD CONSTRAINT column_window_partition_attribute_id_fkey FOREIGN KEY (window_partition_attribute_id)
+					REFERENCES app.attribute (id) MATCH SIMPLE
+					ON UPDATE NO ACTION
+					ON DELETE NO ACTION
+					DEFERRABLE INITIALLY DEFERRED;
+			ALTER TABLE app.column ALTER COLUMN window_offset DROP DEFAULT;
+			CREATE INDEX IF NOT EXISTS fki_column_window_partition_attribute_id_fkey
+				ON app.column USING btree (window_partition_attribute_id ASC NULLS LAST);
+
+			ALTER TABLE app.query_filter_side ADD COLUMN aggregator app.aggregator;
+
+			-- JSON, date/time range and enumeration attributes
+			ALTER TYPE app.attribute_content ADD VALUE 'jsonb';
+			ALTER TYPE app.attribute_content ADD VALUE 'daterange';
+			ALTER TYPE app.attribute_content ADD VALUE 'tstzrange';
+			ALTER TYPE app.attribute_content ADD VALUE 'enum';
+
+			CREATE TABLE IF NOT EXISTS app.attribute_enum_value (
+				id UUID NOT NULL,
+				attribute_id UUID NOT NULL,
+				position INTEGER NOT NULL,
+				value TEXT NOT NULL,
+				CONSTRAINT attribute_enum_value_pkey PRIMARY KEY (id),
+				CONSTRAINT attribute_enum_value_value_key UNIQUE (attribute_id, value)
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT attribute_enum_value_attribute_id_fkey FOREIGN KEY (attribute_id)
+					REFERENCES app.attribute (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX IF NOT EXISTS fki_attribute_enum_value_attribute_id_fkey
+				ON app.attribute_enum_value USING btree (attribute_id ASC NULLS LAST);
+
+			ALTER TYPE app.caption_content ADD VALUE 'attributeEnumValueTitle';
+
+			ALTER TABLE app.caption ADD COLUMN attribute_enum_value_id uuid;
+			ALTER TABLE app.caption ADD CONSTRAINT caption_attribute_enum_value_id_fkey FOREIGN KEY (attribute_enum_value_id)
+				REFERENCES app.attribute_enum_value (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE CASCADE
+				DEFERRABLE INITIALLY DEFERRED;
+			CREATE INDEX fki_caption_attribute_enum_value_id_fkey
+				ON app.caption USING BTREE (attribute_enum_value_id ASC NULLS LAST);
+
+			ALTER TABLE instance.caption ADD COLUMN attribute_enum_value_id uuid;
+			ALTER TABLE instance.caption ADD CONSTRAINT caption_attribute_enum_value_id_fkey FOREIGN KEY (attribute_enum_value_id)
+				REFERENCES app.attribute_enum_value (id) MATCH SIMPLE
+				ON UPDATE CASCADE
+				ON DELETE CASCADE
+				DEFERRABLE INITIALLY DEFERRED;
+			CREATE INDEX fki_caption_attribute_enum_value_id_fkey
+				ON instance.caption USING BTREE (attribute_enum_value_id ASC NULLS LAST);
+
+			-- geospatial attributes & filters
+			ALTER TYPE app.attribute_content ADD VALUE 'geometry';
+			ALTER TYPE app.attribute_content ADD VALUE 'point';
+			ALTER TYPE app.condition_operator ADD VALUE 'WITHIN DISTANCE';
+			ALTER TYPE app.condition_operator ADD VALUE 'WITHIN BOX';
+
+			-- computed attributes
+			ALTER TABLE app.attribute ADD COLUMN computed TEXT NOT NULL DEFAULT '';
+			ALTER TABLE app.attribute ALTER COLUMN computed DROP DEFAULT;
+
+			-- attribute validation rules
+			ALTER TABLE app.attribute ADD COLUMN validation_required BOOLEAN NOT NULL DEFAULT FALSE;
+			ALTER TABLE app.attribute ALTER COLUMN validation_required DROP DEFAULT;
+			ALTER TABLE app.attribute ADD COLUMN validation_min DOUBLE PRECISION;
+			ALTER TABLE app.attribute ADD COLUMN validation_max DOUBLE PRECISION;
+			ALTER TABLE app.attribute ADD COLUMN validation_regex TEXT;
+			ALTER TABLE app.attribute ADD COLUMN validation_pg_function_id UUID;
+			ALTER TABLE app.attribute ADD CONSTRAINT attribute_validation_pg_function_id_fkey FOREIGN KEY (validation_pg_function_id)
+				REFERENCES app.pg_function (id) MATCH SIMPLE
+				ON UPDATE NO ACTION
+				ON DELETE NO ACTION
+				DEFERRABLE INITIALLY DEFERRED;
+			CREATE INDEX IF NOT EXISTS fki_attribute_validation_pg_function_id_fkey
+				ON app.attribute USING btree (validation_pg_function_id ASC NULLS LAST);
+
+			-- cron schedules, time zones & catch-up policies for PG functions
+			ALTER TYPE app.pg_function_schedule_interval ADD VALUE 'cron';
+			CREATE TYPE app.pg_function_schedule_catch_up AS ENUM ('skip','once','all');
+			ALTER TABLE app.pg_function_schedule
+				ADD COLUMN cron TEXT NOT NULL DEFAULT '',
+				ADD COLUMN timezone TEXT NOT NULL DEFAULT '',
+				ADD COLUMN catch_up app.pg_function_schedule_catch_up NOT NULL DEFAULT 'once';
+			ALTER TABLE app.pg_function_schedule
+				ALTER COLUMN cron DROP DEFAULT,
+				ALTER COLUMN timezone DROP DEFAULT,
+				ALTER COLUMN catch_up DROP DEFAULT;
+
+			-- scheduler run history
+			CREATE TABLE IF NOT EXISTS instance.schedule_run (
+				schedule_id integer NOT NULL,
+				node_id uuid,
+				date_start bigint NOT NULL,
+				date_end bigint NOT NULL,
+				duration_ms bigint NOT NULL,
+				success boolean NOT NULL,
+				error_text text,
+				rows_affected bigint,
+				CONSTRAINT schedule_run_schedule_id_fkey FOREIGN KEY (schedule_id)
+					REFERENCES instance.schedule (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED,
+				CONSTRAINT schedule_run_node_id_fkey FOREIGN KEY (node_id)
+					REFERENCES instance_cluster.node (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE SET NULL
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_schedule_run_schedule_id_fkey ON instance.schedule_run USING btree (schedule_id ASC NULLS LAST);
+			CREATE INDEX fki_schedule_run_node_id_fkey     ON instance.schedule_run USING btree (node_id ASC NULLS LAST);
+			CREATE INDEX ind_schedule_run_date_start       ON instance.schedule_run USING btree (date_start DESC NULLS LAST);
+
+			-- scheduler run history config, alerts & cleanup task
+			INSERT INTO instance.config (name,value) VALUES
+				('schedulerAlertDurationSec','0'),
+				('schedulerAlertFailures','3'),
+				('schedulerRunsKeepDays','30');
+
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('cleanupSchedulerRuns',86400,true,false,false,true);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('cleanupSchedulerRuns',0,0);
+
+			-- background job queue
+			CREATE TYPE instance.job_state AS ENUM ('pending','failed');
+
+			CREATE TABLE instance.job (
+				id uuid NOT NULL DEFAULT gen_random_uuid(),
+				pg_function_id uuid NOT NULL,
+				queue text NOT NULL,
+				args jsonb,
+				unique_key text,
+				priority integer NOT NULL,
+				state instance.job_state NOT NULL,
+				attempt_count integer NOT NULL,
+				date_added bigint NOT NULL,
+				date_run_at bigint NOT NULL,
+				date_attempt bigint,
+				error_text text,
+				CONSTRAINT job_pkey PRIMARY KEY (id),
+				CONSTRAINT job_pg_function_id_fkey FOREIGN KEY (pg_function_id)
+					REFERENCES app.pg_function (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_job_pg_function_id_fkey ON instance.job USING btree (pg_function_id ASC NULLS LAST);
+			CREATE INDEX ind_job_state_date_run_at   ON instance.job USING btree (state ASC NULLS LAST, date_run_at ASC NULLS LAST);
+			CREATE UNIQUE INDEX ind_job_unique_key   ON instance.job USING btree (unique_key ASC NULLS LAST) WHERE state = 'pending';
+
+			CREATE TABLE instance.job_queue (
+				name text NOT NULL,
+				concurrency integer NOT NULL,
+				CONSTRAINT job_queue_pkey PRIMARY KEY (name)
+			);
+
+			CREATE OR REPLACE FUNCTION instance.job_enqueue(pg_function_id UUID, args JSONB DEFAULT NULL, run_at BIGINT DEFAULT NULL, priority INTEGER DEFAULT 0, unique_key TEXT DEFAULT NULL, queue TEXT DEFAULT 'default')
+				RETURNS UUID
+				LANGUAGE 'plpgsql'
+				COST 100
+				VOLATILE PARALLEL UNSAFE
+			AS $BODY$
+				#variable_conflict use_column
+				DECLARE
+					job_id UUID;
+				BEGIN
+					IF (
+						SELECT is_trigger
+						FROM app.pg_function
+						WHERE id = job_enqueue.pg_function_id
+					) THEN
+						RAISE EXCEPTION 'trigger functions cannot be executed as jobs';
+					END IF;
+
+					-- pending job with the same unique key is not enqueued again
+					INSERT INTO instance.job (pg_function_id, queue, args, unique_key, priority,
+						state, attempt_count, date_added, date_run_at)
+					VALUES (job_enqueue.pg_function_id, COALESCE(job_enqueue.queue,'default'),
+						job_enqueue.args, job_enqueue.unique_key, COALESCE(job_enqueue.priority,0),
+						'pending', 0, EXTRACT(EPOCH FROM NOW()),
+						COALESCE(job_enqueue.run_at,EXTRACT(EPOCH FROM NOW())))
+					ON CONFLICT (unique_key) WHERE state = 'pending' DO NOTHING
+					RETURNING id INTO job_id;
+
+					IF job_id IS NULL THEN
+						SELECT id INTO job_id
+						FROM instance.job
+						WHERE unique_key = job_enqueue.unique_key
+						AND   state      = 'pending';
+					END IF;
+
+					RETURN job_id;
+				END;
+			$BODY$;
+
+			-- job processing config & task
+			INSERT INTO instance.config (name,value) VALUES ('jobWorkers','4');
+
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('jobsProcess',5,false,false,false,true);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('jobsProcess',0,0);
+
+			-- ACME certificate management
+			CREATE TYPE instance.acme_challenge_type AS ENUM ('http-01','tls-alpn-01');
+
+			CREATE TABLE instance.acme_account (
+				directory_url text NOT NULL,
+				key_pem text NOT NULL,
+				uri text NOT NULL,
+				CONSTRAINT acme_account_pkey PRIMARY KEY (directory_url)
+			);
+
+			CREATE TABLE instance.acme_cert (
+				domains text[] NOT NULL,
+				cert_pem text NOT NULL,
+				key_pem text NOT NULL,
+				date_issued bigint NOT NULL,
+				date_expiry bigint NOT NULL
+			);
+
+			CREATE TABLE instance.acme_challenge (
+				domain text NOT NULL,
+				type instance.acme_challenge_type NOT NULL,
+				token text NOT NULL,
+				key_auth text,
+				cert_pem text,
+				key_pem text,
+				date_expiry bigint NOT NULL,
+				CONSTRAINT acme_challenge_pkey PRIMARY KEY (domain, type)
+			);
+
+			INSERT INTO instance.config (name,value) VALUES
+				('acmeChallenge','tls-alpn-01'),
+				('acmeDirectoryUrl','https://acme-v02.api.letsencrypt.org/directory'),
+				('acmeEmail',''),
+				('acmeEnable','0'),
+				('acmeRenewDays','30'),
+				('acmeSkipVerify','0');
+
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('acmeRenew',43200,true,false,false,true);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('acmeRenew',0,0);
+
+			-- TLS client certificate authentication
+			CREATE TYPE instance.login_client_cert_type AS ENUM ('san','subject');
+
+			CREATE TABLE instance.login_client_cert (
+				login_id integer NOT NULL,
+				type instance.login_client_cert_type NOT NULL,
+				value text NOT NULL,
+				CONSTRAINT login_client_cert_pkey PRIMARY KEY (type, value),
+				CONSTRAINT login_client_cert_login_id_fkey FOREIGN KEY (login_id)
+					REFERENCES instance.login (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_login_client_cert_login_id_fkey ON instance.login_client_cert USING btree (login_id ASC NULLS LAST);
+
+			-- WebAuthn credentials & MFA recovery codes
+			CREATE TABLE instance.login_webauthn (
+				id serial NOT NULL,
+				login_id integer NOT NULL,
+				name character varying(64) COLLATE pg_catalog."default" NOT NULL,
+				credential_id bytea NOT NULL,
+				credential jsonb NOT NULL,
+				date_create bigint NOT NULL,
+				date_used bigint,
+				CONSTRAINT login_webauthn_pkey PRIMARY KEY (id),
+				CONSTRAINT login_webauthn_credential_id_key UNIQUE (credential_id),
+				CONSTRAINT login_webauthn_login_id_fkey FOREIGN KEY (login_id)
+					REFERENCES instance.login (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_login_webauthn_login_id_fkey ON instance.login_webauthn USING btree (login_id ASC NULLS LAST);
+
+			CREATE TABLE instance.login_webauthn_session (
+				id uuid NOT NULL,
+				login_id integer NOT NULL,
+				session jsonb NOT NULL,
+				date_expiry bigint NOT NULL,
+				CONSTRAINT login_webauthn_session_pkey PRIMARY KEY (id),
+				CONSTRAINT login_webauthn_session_login_id_fkey FOREIGN KEY (login_id)
+					REFERENCES instance.login (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_login_webauthn_session_login_id_fkey ON instance.login_webauthn_session USING btree (login_id ASC NULLS LAST);
+
+			CREATE TABLE instance.login_recovery_code (
+				login_id integer NOT NULL,
+				code_hash text NOT NULL,
+				date_create bigint NOT NULL,
+				CONSTRAINT login_recovery_code_pkey PRIMARY KEY (login_id, code_hash),
+				CONSTRAINT login_recovery_code_login_id_fkey FOREIGN KEY (login_id)
+					REFERENCES instance.login (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+
+			-- password policy: history, maximum age & breached passwords
+			ALTER TABLE instance.login ADD COLUMN date_password bigint;
+			UPDATE instance.login
+			SET date_password = EXTRACT(EPOCH FROM NOW())::bigint
+			WHERE hash IS NOT NULL;
+
+			CREATE TABLE instance.login_password_history (
+				login_id integer NOT NULL,
+				salt character(32) NOT NULL,
+				hash character(64) NOT NULL,
+				date_change bigint NOT NULL,
+				CONSTRAINT login_password_history_login_id_fkey FOREIGN KEY (login_id)
+					REFERENCES instance.login (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_login_password_history_login_id_fkey ON instance.login_password_history USING btree (login_id ASC NULLS LAST);
+
+			INSERT INTO instance.config (name,value) VALUES
+				('pwBreachedPath',''),
+				('pwHistoryCount','0'),
+				('pwMaxAgeDays','0');
+
+			-- issued login tokens, for session management & token revocation
+			CREATE TABLE instance.login_token (
+				id uuid NOT NULL,
+				login_id integer NOT NULL,
+				date_create bigint NOT NULL,
+				date_expiry bigint NOT NULL,
+				date_used bigint,
+				date_revoked bigint,
+				CONSTRAINT login_token_pkey PRIMARY KEY (id),
+				CONSTRAINT login_token_login_id_fkey FOREIGN KEY (login_id)
+					REFERENCES instance.login (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+					DEFERRABLE INITIALLY DEFERRED
+			);
+			CREATE INDEX fki_login_token_login_id_fkey ON instance.login_token USING btree (login_id ASC NULLS LAST);
+			CREATE INDEX ind_login_token_date_expiry ON instance.login_token USING btree (date_expiry ASC NULLS LAST);
+
+			INSERT INTO instance.task (
+				name,interval_seconds,cluster_master_only,
+				embedded_only,active_only,active
+			) VALUES ('cleanupLoginTokens',86400,true,false,false,true);
+
+			INSERT INTO instance.schedule (task_name,date_attempt,date_success)
+			VALUES ('cleanupLoginTokens',0,0);
+
+			ALTER TABLE instance.login_session ADD COLUMN token_id uuid;
+
+			-- admin impersonation, log entries are kept even if logins are deleted
+			CREATE TABLE instance.login_impersonation_log (
+				login_id integer NOT NULL,
+				login_id_impersonator integer NOT NULL,
+				date bigint NOT NULL,
+				ressource text NOT NULL,
+				action text NOT NULL,
+				payload jsonb,
+				blocked boolean NOT NULL
+			);
+			CREATE INDEX ind_login_impersonation_log_login_id ON instance.login_impersonation_log USING btree (login_id ASC NULLS LAST);
+			CREATE INDEX ind_login_impersonation_log_date ON instance.login_impersonation_log USING btree (date DESC NULLS LAST);
+
+			-- audit log of administrative changes, append-only & hash chained
+			CREATE TABLE instance.audit_log (
+				id bigserial NOT NULL,
+				login_id integer NOT NULL,
+				login_name text NOT NULL,
+				date bigint NOT NULL,
+				ressource text NOT NULL,
+				action text NOT NULL,
+				entity_id text,
+				payload jsonb,
+				diff jsonb,
+				hash_prev text,
+				hash text NOT NULL,
+				CONSTRAINT audit_log_pkey PRIMARY KEY (id)
+			);
+			CREATE INDEX ind_audit_log_date ON instance.audit_log USING btree (date DESC NULLS LAST);
+			CREATE INDEX ind_audit_log_login_id ON instance.audit_log USING btree (login_id ASC NULLS LAST);
+
+			CREATE OR REPLACE FUNCTION instance.audit_log_hash(
+				hash_prev TEXT, date BIGINT, login_id INTEGER, login_name TEXT, ressource TEXT,
+				action TEXT, entity_id TEXT, payload JSONB, diff JSONB)
+				RETURNS TEXT
+				LANGUAGE 'sql'
+				IMMUTABLE
+			AS $BODY$
+				SELECT encode(sha256(convert_to(concat_ws(chr(31),
+					COALESCE($1,''), $2::TEXT, $3::TEXT, $4, $5, $6,
+					COALESCE($7,''), COALESCE($8::TEXT,''), COALESCE($9::TEXT,'')
+				),'UTF8')),'hex');
+			$BODY$;
+
+			CREATE OR REPLACE FUNCTION instance.trg_audit_log_append_only()
+				RETURNS TRIGGER
+				LANGUAGE 'plpgsql'
+			AS $BODY$
+				BEGIN
+					RAISE EXCEPTION 'audit log is append-only';
+				END;
+			$BODY$;
+
+			CREATE TRIGGER trg_audit_log_append_only BEFORE UPDATE OR DELETE ON instance.audit_log
+				FOR EACH ROW EXECUTE FUNCTION instance.trg_audit_log_append_only();
+			CREATE TRIGGER trg_audit_log_append_only_truncate BEFORE TRUNCATE ON instance.audit_log
+				FOR EACH STATEMENT EXECUTE FUNCTION instance.trg_audit_log_append_only();
+		`)
+		return "3.12", err
+	},
 	"3.10": func(ctx context.Context, tx pgx.Tx) (string, error) {
 		_, err := tx.Exec(ctx, `
 			-- cleanup from last release
@@ -0,0 +1,54 @@
+package acme_challenge
+
+import (
+	"context"
+	"net/http"
+	"r3/db"
+	"r3/handler"
+	"r3/tools"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// answers ACME HTTP-01 challenges, responses are stored in the database by the cluster node requesting the certificate
+func Handler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != "GET" {
+		handler.AbortRequestNoLog(w, handler.ErrGeneral)
+		return
+	}
+
+	/*
+		Parse URL, such as:
+		GET /.well-known/acme-challenge/LoqXcYV8q5ONbJQxbmR7SCTNo3tiAXDfowyjxAjEuX0
+	*/
+	token := strings.TrimPrefix(r.URL.Path, "/.well-known/acme-challenge/")
+	if token == "" || strings.Contains(token, "/") {
+		handler.AbortRequestNoLog(w, handler.ErrGeneral)
+		return
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	var keyAuth string
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT key_auth
+		FROM instance.acme_challenge
+		WHERE token       = $1
+		AND   type        = 'http-01'
+		AND   date_expiry > $2
+	`, token, tools.GetTimeUnix()).Scan(&keyAuth); err != nil {
+		if err == pgx.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		handler.AbortRequest(w, handler.ContextAcmeChallenge, err, handler.ErrGeneral)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(keyAuth))
+}
@@ -28,7 +28,8 @@ import (
 )
 
 var (
-	defaultGetters = []string{"limit", "offset", "verbose"}
+	defaultGetters  = []string{"limit", "offset", "verbose"}
+	regexApiVersion = regexp.MustCompile(`^v\d+$`)
 )
 
 func Handler(w http.ResponseWriter, r *http.Request) {
@@ -55,14 +56,41 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 
 	defer ctxCanc()
 
-	// authenticate via token
-	login, err := login_auth.Token(ctx, token)
+	// authenticate via token, or verified TLS client certificate if no token is given
+	// certificates authenticate each request, no token is issued
+	var login types.LoginAuthResult
+	var err error
+	if cert := handler.GetCertClient(r); token == "" && cert != nil {
+		login, err = login_auth.Cert(ctx, cert, false)
+	} else {
+		login, err = login_auth.TokenHttp(ctx, token, "api", r)
+	}
 	if err != nil {
 		abort(http.StatusUnauthorized, err, handler.ErrUnauthorized)
 		bruteforce.BadAttempt(r)
 		return
 	}
 
+	/*
+		Parse URL, such as:
+		GET    /api/lsw_invoices/contracts/v1?limit=10
+		GET    /api/lsw_invoices/contracts/v1/45
+		DELETE /api/lsw_invoices/contracts/v1/45
+		POST   /api/lsw_invoices/rpc/invoice_create (backend function call)
+
+		Rules:
+		Path must contain 5-6 elements (see examples above, split by '/')
+		6th element is the record ID, required by DELETE
+		GET can also have record ID (single record lookup)
+	*/
+	elements := strings.Split(r.URL.Path, "/")
+
+	// backend function call, API versions are always prefixed with 'v' followed by a number
+	if len(elements) == 5 && elements[3] == "rpc" && !regexApiVersion.MatchString(elements[4]) {
+		handleRpc(ctx, w, r, login, elements[2], elements[4], abort)
+		return
+	}
+
 	var isDelete, isGet, isPost bool
 	switch r.Method {
 	case "DELETE":
@@ -76,18 +104,6 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	/*
-		Parse URL, such as:
-		GET    /api/lsw_invoices/contracts/v1?limit=10
-		GET    /api/lsw_invoices/contracts/v1/45
-		DELETE /api/lsw_invoices/contracts/v1/45
-
-		Rules:
-		Path must contain 5-6 elements (see examples above, split by '/')
-		6th element is the record ID, required by DELETE
-		GET can also have record ID (single record lookup)
-	*/
-	elements := strings.Split(r.URL.Path, "/")
 	recordIdProvided := len(elements) == 6
 
 	if len(elements) < 5 || len(elements) > 6 || (isDelete && !recordIdProvided) {
@@ -119,6 +135,18 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// optional record version, checked on DELETE/POST to prevent overwriting concurrent changes
+	// accepts ETag as returned by GET of single record: "123" or W/"123"
+	var ifMatchVersion pgtype.Int8
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && !isGet {
+		ifMatchVersion.Int64, err = strconv.ParseInt(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`), 10, 64)
+		if err != nil {
+			abort(http.StatusBadRequest, err, fmt.Sprintf("invalid If-Match header '%s', record version ETag expected", ifMatch))
+			return
+		}
+		ifMatchVersion.Valid = true
+	}
+
 	// URL processing complete, actually use API
 	log.Info(log.ContextApi, fmt.Sprintf("'%s.%s' (v%d) is called with %s (record ID: %d)",
 		modName, apiName, version, r.Method, recordId))
@@ -146,6 +174,10 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		abort(http.StatusServiceUnavailable, nil, "query has no base relation")
 		return
 	}
+	if ifMatchVersion.Valid && !cache.RelationIdMap[api.Query.RelationId.Bytes].RecordVersion {
+		abort(http.StatusBadRequest, nil, "If-Match is not supported, base relation does not use record versions")
+		return
+	}
 
 	// check role access
 	access, err := cache.GetAccessById(login.Id)
@@ -219,11 +251,28 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// check given record version of base relation record, returns false if request was aborted
+	var checkIfMatchVersion = func(recordId int64) bool {
+		if !ifMatchVersion.Valid {
+			return true
+		}
+		if err := data.CheckRecordVersion_tx(ctx, tx, api.Query.RelationId.Bytes,
+			recordId, ifMatchVersion.Int64); err != nil {
+
+			abort(http.StatusPreconditionFailed, nil, err.Error())
+			return false
+		}
+		return true
+	}
+
 	if isDelete {
 		if recordId < 1 {
 			abort(http.StatusBadRequest, nil, "record ID must be > 0")
 			return
 		}
+		if !checkIfMatchVersion(recordId) {
+			return
+		}
 
 		// look up all records from joined relations
 		// continue even if some joins do not have DELETE enabled, as its necessary for later joins that might require a DELETE
@@ -431,6 +480,13 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
 			return
 		}
+
+		// single record lookup, return record version of base relation record for later updates
+		if recordId != 0 && len(results) == 1 {
+			if v, exists := results[0].IndexRecordVersions[0]; exists {
+				w.Header().Set("ETag", fmt.Sprintf(`"%d"`, v))
+			}
+		}
 		w.WriteHeader(http.StatusOK)
 		w.Write(payloadJson)
 	}
@@ -501,6 +557,27 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		// record version can only be checked for updates, record ID of base relation must be given
+		if ifMatchVersion.Valid {
+			var recordIdPost int64
+			atrIdPk := cache.RelationIdMap[api.Query.RelationId.Bytes].AttributeIdPk
+			for i, column := range api.Columns {
+				if column.Index == 0 && column.AttributeId == atrIdPk {
+					if n, ok := values[i].(float64); ok {
+						recordIdPost = int64(n)
+					}
+					break
+				}
+			}
+			if recordIdPost < 1 {
+				abort(http.StatusPreconditionFailed, nil, "If-Match requires record ID of base relation")
+				return
+			}
+			if !checkIfMatchVersion(recordIdPost) {
+				return
+			}
+		}
+
 		indexRecordIds, err := data_import.FromInterfaceValues_tx(ctx, tx,
 			login.Id, values, api.Columns, api.Query.Joins, api.Query.Lookups,
 			data_import.ResolveQueryLookups(api.Query.Joins, api.Query.Lookups))
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"r3/cache"
+	"r3/db"
+	"r3/handler"
+	"r3/log"
+	"r3/request"
+	"r3/schema/pgFunction"
+	"r3/types"
+	"slices"
+
+	"github.com/gofrs/uuid"
+)
+
+// executes backend function via REST RPC call
+// only functions that can be called from the frontend and that the login has role access to can be executed
+// arguments are given as JSON array (positional) or JSON object (named, by argument name)
+//
+//	POST /api/lsw_invoices/rpc/invoice_create
+//	[123,"text"] or {"invoice_id":123,"comment":"text"}
+func handleRpc(ctx context.Context, w http.ResponseWriter, r *http.Request,
+	login types.LoginAuthResult, modName string, fncName string,
+	abort func(httpCode int, errToLog error, errMsgUser string)) {
+
+	if r.Method != "POST" {
+		abort(http.StatusBadRequest, nil, "invalid HTTP method, expected: POST")
+		return
+	}
+
+	log.Info(log.ContextApi, fmt.Sprintf("backend function '%s.%s' is called via RPC", modName, fncName))
+
+	cache.Schema_mx.RLock()
+	fncId, exists := cache.ModulePgFunctionNameMapId[modName][fncName]
+	fnc := cache.PgFunctionIdMap[fncId]
+	cache.Schema_mx.RUnlock()
+
+	if !exists {
+		abort(http.StatusNotFound, nil, fmt.Sprintf("function '%s.%s' does not exist", modName, fncName))
+		return
+	}
+	if fnc.IsTrigger || !fnc.IsFrontendExec {
+		abort(http.StatusForbidden, handler.ErrSchemaBadFrontendExecPgFunctionCall(fnc.Id), handler.ErrUnauthorized)
+		return
+	}
+
+	// check role access
+	access, err := cache.GetAccessById(login.Id)
+	if err != nil {
+		abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+	if _, exists := access.PgFunction[fnc.Id]; !exists {
+		abort(http.StatusForbidden, nil, handler.ErrUnauthorized)
+		return
+	}
+
+	// parse arguments
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		abort(http.StatusBadRequest, err, "failed to read request body")
+		return
+	}
+	body = bytes.TrimSpace(body)
+
+	var req struct {
+		Id       uuid.UUID     `json:"id"`
+		Args     []interface{} `json:"args"`
+		ArgNames []string      `json:"argNames"`
+	}
+	req.Id = fnc.Id
+	req.Args = make([]interface{}, 0)
+
+	switch {
+	case len(body) == 0:
+		// function without arguments
+	case body[0] == '[':
+		if err := json.Unmarshal(body, &req.Args); err != nil {
+			abort(http.StatusBadRequest, err, "invalid JSON array")
+			return
+		}
+	case body[0] == '{':
+		var argMap map[string]interface{}
+		if err := json.Unmarshal(body, &argMap); err != nil {
+			abort(http.StatusBadRequest, err, "invalid JSON object")
+			return
+		}
+
+		// named notation, ordered by function arguments
+		argNames := pgFunction.GetArgNames(fnc.CodeArgs)
+		for name := range argMap {
+			if !slices.Contains(argNames, name) {
+				abort(http.StatusBadRequest, nil, fmt.Sprintf("unknown function argument '%s'", name))
+				return
+			}
+		}
+		req.ArgNames = make([]string, 0)
+		for _, name := range argNames {
+			if value, exists := argMap[name]; exists {
+				req.Args = append(req.Args, value)
+				req.ArgNames = append(req.ArgNames, name)
+			}
+		}
+	default:
+		abort(http.StatusBadRequest, nil, "invalid arguments, expected: JSON array or object")
+		return
+	}
+
+	reqJson, err := json.Marshal(req)
+	if err != nil {
+		abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+
+	// execute function
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if err := db.SetSessionConfig_tx(ctx, tx, login.Id); err != nil {
+		abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+
+	returnIf, err := request.PgFunctionExec_tx(ctx, tx, reqJson, true)
+	if err != nil {
+		abort(http.StatusConflict, err, handler.ErrGeneral)
+		return
+	}
+
+	payloadJson, err := json.Marshal(returnIf)
+	if err != nil {
+		abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		abort(http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(payloadJson)
+}
@@ -10,6 +10,7 @@ import (
 	"r3/config"
 	"r3/handler"
 	"r3/login/login_auth"
+	"r3/types"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -35,6 +36,11 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
+
+		// MFA details, required if MFA is enabled for login
+		MfaTokenId      pgtype.Int4 `json:"mfaTokenId"`
+		MfaTokenPin     pgtype.Text `json:"mfaTokenPin"`
+		MfaRecoveryCode pgtype.Text `json:"mfaRecoveryCode"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		handler.AbortRequestWithCode(w, handler.ContextApiAuth, http.StatusBadRequest,
@@ -49,7 +55,8 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	defer ctxCanc()
 
 	// authenticate requestor
-	res, err := login_auth.User(ctx, req.Username, req.Password, pgtype.Int4{}, pgtype.Text{})
+	res, err := login_auth.User(ctx, req.Username, req.Password, types.LoginPasswordNew{}, req.MfaTokenId,
+		req.MfaTokenPin, req.MfaRecoveryCode, types.LoginWebAuthnAssertion{}, true)
 	if err != nil {
 		handler.AbortRequestWithCode(w, handler.ContextApiAuth, http.StatusUnauthorized,
 			err, handler.ErrAuthFailed)
@@ -58,12 +65,29 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(res.MfaTokens) != 0 {
-		handler.AbortRequestWithCode(w, handler.ContextApiAuth, http.StatusBadRequest,
-			nil, "failed to authenticate, MFA is currently not supported")
+	// passwordless authentication requires a browser, fails like any other attempt without password
+	if res.Passwordless {
+		handler.AbortRequestWithCode(w, handler.ContextApiAuth, http.StatusUnauthorized,
+			errors.New("password not given"), handler.ErrAuthFailed)
 
+		bruteforce.BadAttempt(r)
 		return
+	}
+
+	// expired passwords must be changed interactively
+	if res.PwExpired {
+		handler.AbortRequestWithCode(w, handler.ContextApiAuth, http.StatusUnauthorized,
+			nil, "failed to authenticate, password has expired and must be changed")
 
+		return
+	}
+
+	// WebAuthn ceremonies require a browser, API clients must use TOTP or recovery codes as MFA
+	if res.Token == "" {
+		handler.AbortRequestWithCode(w, handler.ContextApiAuth, http.StatusUnauthorized,
+			nil, "failed to authenticate, MFA required (TOTP via 'mfaTokenId' & 'mfaTokenPin' or 'mfaRecoveryCode')")
+
+		return
 	}
 	w.Write([]byte(fmt.Sprintf(`{"token": "%s"}`, res.Token)))
 }
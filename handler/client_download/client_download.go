@@ -31,7 +31,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	defer ctxCanc()
 
 	// authenticate via token
-	if _, err := login_auth.Token(ctx, token); err != nil {
+	if _, err := login_auth.TokenHttp(ctx, token, "client_download", r); err != nil {
 		handler.AbortRequest(w, handler.ContextClientDownload, err, handler.ErrAuthFailed)
 		bruteforce.BadAttempt(r)
 		return
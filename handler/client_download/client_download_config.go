@@ -33,7 +33,7 @@ func HandlerConfig(w http.ResponseWriter, r *http.Request) {
 	defer ctxCanc()
 
 	// check token
-	login, err := login_auth.Token(ctx, token)
+	login, err := login_auth.TokenHttp(ctx, token, "client_download_config", r)
 	if err != nil {
 		handler.AbortRequest(w, handler.ContextClientDownload, err, handler.ErrAuthFailed)
 		bruteforce.BadAttempt(r)
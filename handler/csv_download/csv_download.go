@@ -158,7 +158,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	defer ctxCanc()
 
 	// authenticate via token
-	login, err := login_auth.Token(ctx, token)
+	login, err := login_auth.TokenHttp(ctx, token, "csv_download", r)
 	if err != nil {
 		handler.AbortRequest(w, handler.ContextCsvDownload, err, handler.ErrUnauthorized)
 		bruteforce.BadAttempt(r)
@@ -245,7 +245,8 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// store attribute content use for each column
+	// store attribute content & content use for each column
+	columnAttributeContent := make([]string, len(columns))
 	columnAttributeContentUse := make([]string, len(columns))
 	for i, column := range columns {
 		atr, exists := cache.AttributeIdMap[column.AttributeId]
@@ -255,12 +256,13 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 
 			return
 		}
+		columnAttributeContent[i] = atr.Content
 		columnAttributeContentUse[i] = atr.ContentUse
 	}
 
 	for {
 		total, err := dataToCsv(ctx, writer, get, locUser, boolTrue, boolFalse,
-			dateFormat, columnAttributeContentUse, login.Id)
+			dateFormat, columnAttributeContent, columnAttributeContentUse, login.Id)
 
 		if err != nil {
 			handler.AbortRequest(w, handler.ContextCsvDownload, err, handler.ErrGeneral)
@@ -290,7 +292,8 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 }
 
 func dataToCsv(ctx context.Context, writer *csv.Writer, get types.DataGet, locUser *time.Location, boolTrue string,
-	boolFalse string, dateFormat string, columnAttributeContentUse []string, loginId int64) (int64, error) {
+	boolFalse string, dateFormat string, columnAttributeContent []string,
+	columnAttributeContentUse []string, loginId int64) (int64, error) {
 
 	tx, err := db.Pool.Begin(ctx)
 	if err != nil {
@@ -369,6 +372,34 @@ func dataToCsv(ctx context.Context, writer *csv.Writer, get types.DataGet, locUs
 					return 0, err
 				}
 				stringValues[pos] = string(b)
+			case []interface{}:
+				// ranges are exported as 'from - to', unbounded limits stay empty
+				if len(v) == 2 && (columnAttributeContent[pos] == "daterange" || columnAttributeContent[pos] == "tstzrange") {
+					display := "date"
+					if columnAttributeContent[pos] == "tstzrange" {
+						display = "datetime"
+					}
+					bounds := make([]string, 2)
+					for j, bound := range v {
+						if unix, ok := bound.(float64); ok {
+							bounds[j] = parseIntegerValues(display, int64(unix))
+						}
+					}
+					stringValues[pos] = fmt.Sprintf("%s - %s", bounds[0], bounds[1])
+					break
+				}
+
+				b, err := json.Marshal(v)
+				if err != nil {
+					return 0, err
+				}
+				stringValues[pos] = string(b)
+			case map[string]interface{}:
+				b, err := json.Marshal(v)
+				if err != nil {
+					return 0, err
+				}
+				stringValues[pos] = string(b)
 			default:
 				stringValues[pos] = fmt.Sprintf("%v", value)
 			}
@@ -20,6 +20,7 @@ import (
 	"r3/tools"
 	"r3/types"
 	"strconv"
+	"strings"
 	"time"
 	"unicode/utf8"
 
@@ -106,7 +107,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		defer ctxCanc()
 
 		// authenticate via token
-		login, err := login_auth.Token(ctx, token)
+		login, err := login_auth.TokenHttp(ctx, token, "csv_upload", r)
 		if err != nil {
 			handler.AbortRequest(w, handler.ContextCsvUpload, err, handler.ErrUnauthorized)
 			bruteforce.BadAttempt(r)
@@ -271,20 +272,7 @@ func importLine_tx(ctx context.Context, tx pgx.Tx, loginId int64,
 			case "date", "datetime":
 				// date values are always stored as UTC at midnight
 				loc := time.UTC
-				format := "2006-01-02"
-
-				switch dateFormat {
-				case "Y-m-d":
-					format = "2006-01-02"
-				case "Y/m/d":
-					format = "2006/01/02"
-				case "d.m.Y":
-					format = "02.01.2006"
-				case "d/m/Y":
-					format = "02/01/2006"
-				case "m/d/Y":
-					format = "01/02/2006"
-				}
+				format := getDateFormat(dateFormat)
 
 				// datetime values are in context of user timezone
 				if atr.ContentUse == "datetime" {
@@ -332,9 +320,54 @@ func importLine_tx(ctx context.Context, tx pgx.Tx, loginId int64,
 			}
 
 		// numeric must be handled as text as conversion to float is not 1:1
-		case "numeric", "text", "uuid", "varchar":
+		// geospatial values are given as GeoJSON text
+		case "enum", "geometry", "numeric", "point", "text", "uuid", "varchar":
 			valuesIn[i] = valuesString[i]
 
+		case "jsonb":
+			var v interface{}
+			if err := json.Unmarshal([]byte(valuesString[i]), &v); err != nil {
+				return handler.CreateErrCodeWithData(handler.ErrContextCsv, handler.ErrCodeCsvParseJson, struct {
+					Value string `json:"value"`
+				}{valuesString[i]})
+			}
+			valuesIn[i] = v
+
+		case "daterange", "tstzrange":
+			// ranges are given as 'from - to', empty bounds are unbounded
+			// date ranges are stored as UTC at midnight, timestamp ranges are in context of user timezone
+			loc := time.UTC
+			format := getDateFormat(dateFormat)
+			if atr.Content == "tstzrange" {
+				loc = locUser
+				format = fmt.Sprintf("%s 15:04:05", format)
+			}
+
+			bounds := strings.Split(valuesString[i], " - ")
+			if len(bounds) != 2 {
+				return handler.CreateErrCodeWithData(handler.ErrContextCsv, handler.ErrCodeCsvParseDateTime, struct {
+					Expect string `json:"expect"`
+					Value  string `json:"value"`
+				}{fmt.Sprintf("%s - %s", format, format), valuesString[i]})
+			}
+
+			values := make([]interface{}, 2)
+			for j, bound := range bounds {
+				bound = strings.TrimSpace(bound)
+				if bound == "" {
+					continue
+				}
+				t, err := time.ParseInLocation(format, bound, loc)
+				if err != nil {
+					return handler.CreateErrCodeWithData(handler.ErrContextCsv, handler.ErrCodeCsvParseDateTime, struct {
+						Expect string `json:"expect"`
+						Value  string `json:"value"`
+					}{format, bound})
+				}
+				values[j] = float64(t.Unix())
+			}
+			valuesIn[i] = values
+
 		case "boolean":
 			valuesIn[i] = valuesString[i] == boolTrue
 
@@ -350,3 +383,18 @@ func importLine_tx(ctx context.Context, tx pgx.Tx, loginId int64,
 
 	return err
 }
+
+// returns Go date layout for date format setting
+func getDateFormat(dateFormat string) string {
+	switch dateFormat {
+	case "Y/m/d":
+		return "2006/01/02"
+	case "d.m.Y":
+		return "02.01.2006"
+	case "d/m/Y":
+		return "02/01/2006"
+	case "m/d/Y":
+		return "01/02/2006"
+	}
+	return "2006-01-02"
+}
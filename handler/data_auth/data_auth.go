@@ -10,6 +10,7 @@ import (
 	"r3/config"
 	"r3/handler"
 	"r3/login/login_auth"
+	"r3/types"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -47,11 +48,25 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	defer ctxCanc()
 
 	// authenticate requestor
-	res, err := login_auth.User(ctx, req.Username, req.Password, pgtype.Int4{}, pgtype.Text{})
+	res, err := login_auth.User(ctx, req.Username, req.Password, types.LoginPasswordNew{}, pgtype.Int4{},
+		pgtype.Text{}, pgtype.Text{}, types.LoginWebAuthnAssertion{}, true)
 	if err != nil {
 		handler.AbortRequest(w, handler.ContextDataAuth, err, handler.ErrAuthFailed)
 		bruteforce.BadAttempt(r)
 		return
 	}
+	if res.Passwordless {
+		handler.AbortRequest(w, handler.ContextDataAuth, errors.New("password not given"), handler.ErrAuthFailed)
+		bruteforce.BadAttempt(r)
+		return
+	}
+	if res.PwExpired {
+		handler.AbortRequest(w, handler.ContextDataAuth, nil, "failed to authenticate, password has expired and must be changed")
+		return
+	}
+	if res.Token == "" {
+		handler.AbortRequest(w, handler.ContextDataAuth, nil, "failed to authenticate, MFA is currently not supported")
+		return
+	}
 	w.Write([]byte(fmt.Sprintf(`{"token": "%s"}`, res.Token)))
 }
@@ -41,7 +41,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	defer ctxCanc()
 
 	// authenticate via token
-	login, err := login_auth.Token(ctx, token)
+	login, err := login_auth.TokenHttp(ctx, token, "data_download_thumb", r)
 	if err != nil {
 		handler.AbortRequest(w, handler.ContextDataDownloadThumb, err, handler.ErrAuthFailed)
 		bruteforce.BadAttempt(r)
@@ -71,7 +71,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		defer ctxCanc()
 
 		// authenticate via token
-		login, err := login_auth.Token(ctx, token)
+		login, err := login_auth.TokenHttp(ctx, token, "data_upload", r)
 		if err != nil {
 			handler.AbortRequest(w, handler.ContextDataUpload, err, handler.ErrAuthFailed)
 			bruteforce.BadAttempt(r)
@@ -0,0 +1,259 @@
+package geojson_download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"r3/bruteforce"
+	"r3/cache"
+	"r3/config"
+	"r3/data"
+	"r3/db"
+	"r3/handler"
+	"r3/login/login_auth"
+	"r3/schema"
+	"r3/types"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type feature struct {
+	Type       string                 `json:"type"`
+	Geometry   interface{}            `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+// exports list query results as GeoJSON feature collection
+// first geospatial column is used as feature geometry, other columns become feature properties
+func Handler(w http.ResponseWriter, r *http.Request) {
+
+	if blocked := bruteforce.Check(r); blocked {
+		handler.AbortRequestNoLog(w, handler.ErrBruteforceBlock)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", "attachment; filename=export.geojson")
+	w.Header().Set("Content-Type", "application/geo+json")
+
+	// read getters from URL
+	token, err := handler.ReadGetterFromUrl(r, "token")
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	relationIdString, err := handler.ReadGetterFromUrl(r, "relation_id")
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	joinsString, err := handler.ReadGetterFromUrl(r, "joins")
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	expressionsString, err := handler.ReadGetterFromUrl(r, "expressions")
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	filtersString, err := handler.ReadGetterFromUrl(r, "filters")
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	ordersString, err := handler.ReadGetterFromUrl(r, "orders")
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	totalLimitString, err := handler.ReadGetterFromUrl(r, "total_limit")
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	columnsString, err := handler.ReadGetterFromUrl(r, "columns")
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	var columns []types.Column
+	if err := json.Unmarshal([]byte(columnsString), &columns); err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+
+	// parse data getters
+	var get types.DataGet
+
+	get.RelationId, err = uuid.FromString(relationIdString)
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	if err := json.Unmarshal([]byte(joinsString), &get.Joins); err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	if err := json.Unmarshal([]byte(expressionsString), &get.Expressions); err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	if err := json.Unmarshal([]byte(filtersString), &get.Filters); err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	if err := json.Unmarshal([]byte(ordersString), &get.Orders); err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+
+	get.Limit, err = strconv.Atoi(totalLimitString)
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+
+	// check invalid parameters
+	if len(get.Expressions) != len(columns) {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, errors.New("expression count != column count"),
+			handler.ErrGeneral)
+
+		return
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(),
+		time.Duration(int64(config.GetUint64("dbTimeoutCsv")))*time.Second)
+
+	defer ctxCanc()
+
+	// authenticate via token
+	login, err := login_auth.TokenHttp(ctx, token, "geojson_download", r)
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrUnauthorized)
+		bruteforce.BadAttempt(r)
+		return
+	}
+
+	// start work
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	// resolve geometry column & property names
+	geometryPos := -1
+	propertyNames := make([]string, len(get.Expressions))
+	for i, expr := range get.Expressions {
+
+		// handle non-attribute expression
+		if !expr.AttributeId.Valid {
+			propertyNames[i] = fmt.Sprintf("column_%d", i)
+			continue
+		}
+
+		atr, exists := cache.AttributeIdMap[expr.AttributeId.Bytes]
+		if !exists {
+			handler.AbortRequest(w, handler.ContextGeojsonDownload, handler.ErrSchemaUnknownAttribute(expr.AttributeId.Bytes), handler.ErrGeneral)
+			return
+		}
+		if geometryPos == -1 && schema.IsContentGeo(atr.Content) && !expr.Aggregator.Valid {
+			geometryPos = i
+			continue
+		}
+
+		// choose best caption for property name
+		propertyNames[i] = getCaption(columns[i].Captions, "columnTitle", login.LanguageCode)
+		if propertyNames[i] != "" {
+			continue
+		}
+		propertyNames[i] = getCaption(atr.Captions, "attributeTitle", login.LanguageCode)
+		if propertyNames[i] != "" {
+			continue
+		}
+
+		// fallback to attribute + relation name
+		rel, exists := cache.RelationIdMap[atr.RelationId]
+		if !exists {
+			handler.AbortRequest(w, handler.ContextGeojsonDownload, handler.ErrSchemaUnknownRelation(atr.RelationId), handler.ErrGeneral)
+			return
+		}
+		propertyNames[i] = rel.Name + "." + atr.Name
+	}
+
+	if geometryPos == -1 {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, errors.New("no geospatial column available"), handler.ErrGeneral)
+		return
+	}
+
+	// execute GET data request
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if err := db.SetSessionConfig_tx(ctx, tx, login.Id); err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+
+	var query string
+	rows, _, err := data.Get_tx(ctx, tx, get, login.Id, &query)
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, fmt.Errorf("%s, SQL: %s", err, query), handler.ErrGeneral)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+
+	// records without geometry are kept as features without location
+	collection := featureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]feature, 0),
+	}
+	for _, row := range rows {
+		f := feature{
+			Type:       "Feature",
+			Geometry:   row.Values[geometryPos],
+			Properties: make(map[string]interface{}),
+		}
+		for i, value := range row.Values {
+			if i == geometryPos {
+				continue
+			}
+			if v, ok := value.([16]uint8); ok {
+				value = uuid.UUID(v).String()
+			}
+			f.Properties[propertyNames[i]] = value
+		}
+		collection.Features = append(collection.Features, f)
+	}
+
+	payload, err := json.Marshal(collection)
+	if err != nil {
+		handler.AbortRequest(w, handler.ContextGeojsonDownload, err, handler.ErrGeneral)
+		return
+	}
+	w.Write(payload)
+}
+
+func getCaption(captionMap map[string]map[string]string, contentName string, languageCode string) string {
+	content, exists := captionMap[contentName]
+	if !exists {
+		return ""
+	}
+	value, exists := content[languageCode]
+	if !exists {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,223 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"r3/bruteforce"
+	"r3/cache"
+	"r3/config"
+	"r3/db"
+	"r3/handler"
+	"r3/log"
+	"r3/login/login_auth"
+	"r3/types"
+	"strings"
+	"time"
+)
+
+// GraphQL request, as sent via POST body or GET parameters
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+/*
+GraphQL endpoint for module data, such as:
+POST /graphql/lsw_invoices
+{"query":"{ invoice(where:{state:{eq:\"open\"}}) { id date customer { name } invoice_item_invoice { amount } } }"}
+
+Schema is generated from module relations, the login can read:
+* relations become object types, with record ID, attribute values and nested records
+* 1:1/n:1 attributes become nested records, referring records of other relations become nested lists
+* root fields query records of module relations, mutations create, update and delete records
+Data access runs through regular data requests, so role access and relation policies apply
+Responses are limited in selection depth & number of records, 'limit' & 'offset' of nested lists apply to each parent record
+
+Authentication via API token (Bearer)
+*/
+func Handler(w http.ResponseWriter, r *http.Request) {
+
+	if blocked := bruteforce.Check(r); blocked {
+		handler.AbortRequestNoLog(w, handler.ErrBruteforceBlock)
+		return
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(),
+		time.Duration(int64(config.GetUint64("dbTimeoutDataRest")))*time.Second)
+
+	defer ctxCanc()
+
+	login, err := login_auth.TokenHttp(ctx, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), "graphql", r)
+	if err != nil {
+		abort(w, http.StatusUnauthorized, err, handler.ErrUnauthorized)
+		bruteforce.BadAttempt(r)
+		return
+	}
+
+	// process path elements
+	// 0 is empty, 1 = "graphql", 2 = MODULE_NAME
+	elements := strings.Split(strings.TrimSuffix(r.URL.Path, "/"), "/")
+	if len(elements) != 3 || elements[2] == "" {
+		abort(w, http.StatusBadRequest, nil, "invalid URL, expected: /graphql/APP_NAME")
+		return
+	}
+	modName := elements[2]
+
+	// parse request
+	var req request
+	switch r.Method {
+	case "GET":
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if v := r.URL.Query().Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				abort(w, http.StatusBadRequest, err, "invalid variables, expected: JSON object")
+				return
+			}
+		}
+	case "POST":
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			abort(w, http.StatusBadRequest, err, "invalid request, expected: JSON object with 'query'")
+			return
+		}
+	default:
+		abort(w, http.StatusMethodNotAllowed, nil, "invalid HTTP method, allowed: GET, POST")
+		return
+	}
+
+	doc, err := parseDocument(req.Query)
+	if err != nil {
+		abort(w, http.StatusBadRequest, err, "invalid GraphQL document")
+		return
+	}
+
+	// get operation to execute
+	var op operation
+	switch {
+	case req.OperationName != "":
+		found := false
+		for _, o := range doc.operations {
+			if o.name == req.OperationName {
+				op = o
+				found = true
+				break
+			}
+		}
+		if !found {
+			abort(w, http.StatusBadRequest, nil, fmt.Sprintf("unknown operation '%s'", req.OperationName))
+			return
+		}
+	case len(doc.operations) == 1:
+		op = doc.operations[0]
+	default:
+		abort(w, http.StatusBadRequest, nil, "operation name is required if document contains multiple operations")
+		return
+	}
+
+	if op.kind == "mutation" && r.Method != "POST" {
+		abort(w, http.StatusMethodNotAllowed, nil, "mutations require HTTP method POST")
+		return
+	}
+
+	log.Info(log.ContextApi, fmt.Sprintf("'%s' is called via GraphQL (operation: %s)", modName, op.kind))
+
+	// generate schema for login access
+	access, err := cache.GetAccessById(login.Id)
+	if err != nil {
+		abort(w, http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+
+	cache.Schema_mx.RLock()
+	var mod types.Module
+	var modExists bool
+	for _, m := range cache.ModuleIdMap {
+		if m.Name == modName {
+			mod = m
+			modExists = true
+			break
+		}
+	}
+	if !modExists {
+		cache.Schema_mx.RUnlock()
+		abort(w, http.StatusNotFound, nil, fmt.Sprintf("app '%s' does not exist", modName))
+		return
+	}
+	s := getSchema(mod, access)
+	cache.Schema_mx.RUnlock()
+
+	// execute operation
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		abort(w, http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if err := db.SetSessionConfig_tx(ctx, tx, login.Id); err != nil {
+		abort(w, http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+
+	e := executor{
+		ctx:     ctx,
+		tx:      tx,
+		loginId: login.Id,
+		doc:     doc,
+		schema:  s,
+	}
+	if err := e.setVariables(op, req.Variables); err != nil {
+		abort(w, http.StatusBadRequest, err, err.Error())
+		return
+	}
+
+	out, err := e.execute(op)
+	if err != nil {
+		// errors are part of GraphQL response, request itself was valid
+		// data errors can contain database details and are only logged
+		var errData dataError
+		if errors.As(err, &errData) {
+			abort(w, http.StatusOK, err, handler.ErrGeneral)
+			return
+		}
+		abort(w, http.StatusOK, err, err.Error())
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		abort(w, http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+
+	payloadJson, err := json.Marshal(map[string]interface{}{"data": out})
+	if err != nil {
+		abort(w, http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(payloadJson)
+}
+
+// aborts request with GraphQL error response
+func abort(w http.ResponseWriter, httpCode int, errToLog error, errMsgUser string) {
+	if errToLog == nil {
+		errToLog = errors.New(errMsgUser)
+	}
+	log.Error(log.ContextServer, fmt.Sprintf("aborted %s request",
+		handler.ContextNameMap[handler.ContextGraphql]), errToLog)
+
+	payloadJson, _ := json.Marshal(map[string]interface{}{
+		"data": nil,
+		"errors": []map[string]string{{
+			"message": errMsgUser,
+		}},
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpCode)
+	w.Write(payloadJson)
+}
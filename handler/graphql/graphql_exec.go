@@ -0,0 +1,1019 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"r3/data"
+	"r3/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// executes a single operation of a GraphQL document within a DB transaction
+type executor struct {
+	ctx       context.Context
+	tx        pgx.Tx
+	loginId   int64
+	doc       document
+	schema    *gqlSchema
+	variables map[string]interface{}
+	objects   int // number of record objects in response, limited by objectsMax
+}
+
+// errors of data requests are logged but not returned to the client, they can contain database details
+type dataError struct {
+	err error
+}
+
+func (e dataError) Error() string { return e.err.Error() }
+func (e dataError) Unwrap() error { return e.err }
+
+// selected field, fields with the same response key are merged
+type collectedField struct {
+	key string
+	sel selection
+}
+
+// selected fields of a relation index within a data GET request
+type planNode struct {
+	typ    *gqlType
+	index  int // relation index
+	fields []*planField
+}
+type planField struct {
+	key     string
+	field   *gqlField // nil for __typename
+	sel     selection
+	depth   int       // depth of selection, starting with 1 for root fields
+	exprPos int       // expression position for attribute values
+	node    *planNode // joined relation for nested record
+	pending []pendingRecord
+}
+
+// record, for which nested records of other relations are retrieved after the main data GET request
+type pendingRecord struct {
+	recordId int64
+	obj      *orderedMap
+}
+
+// JSON object that keeps the order of its keys (GraphQL responses follow the order of selections)
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{keys: make([]string, 0), values: make(map[string]interface{})}
+}
+func (m *orderedMap) set(key string, v interface{}) {
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i != 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// applies variable values and defaults of operation
+func (e *executor) setVariables(op operation, variables map[string]interface{}) error {
+	e.variables = make(map[string]interface{})
+	for _, def := range op.variables {
+		if v, exists := variables[def.name]; exists {
+			e.variables[def.name] = v
+			continue
+		}
+		if def.def != nil {
+			e.variables[def.name] = e.getValue(*def.def)
+			continue
+		}
+		if def.nonNull {
+			return fmt.Errorf("variable '$%s' is required", def.name)
+		}
+	}
+	return nil
+}
+
+func (e *executor) execute(op operation) (*orderedMap, error) {
+	root := e.schema.query
+	if op.kind == "mutation" {
+		root = e.schema.mutation
+		if root == nil {
+			return nil, errors.New("no mutations are available")
+		}
+	}
+	if op.kind == "subscription" {
+		return nil, errors.New("subscriptions are not supported")
+	}
+
+	fields, err := e.collectFields(root.name, op.selections)
+	if err != nil {
+		return nil, err
+	}
+
+	// mutations are executed in order, queries are executed in the same transaction
+	out := newOrderedMap()
+	for _, cf := range fields {
+		switch cf.sel.name {
+		case "__typename":
+			out.set(cf.key, root.name)
+			continue
+		case "__schema", "__type":
+			if op.kind != "query" {
+				return nil, fmt.Errorf("cannot query field '%s' on type '%s'", cf.sel.name, root.name)
+			}
+			v, err := e.getIntrospection(cf.sel)
+			if err != nil {
+				return nil, err
+			}
+			out.set(cf.key, v)
+			continue
+		}
+
+		f := root.getField(cf.sel.name)
+		if f == nil {
+			return nil, fmt.Errorf("cannot query field '%s' on type '%s'", cf.sel.name, root.name)
+		}
+		if err := checkSelection(f, cf.sel); err != nil {
+			return nil, err
+		}
+		args, err := e.getArguments(f, cf.sel)
+		if err != nil {
+			return nil, err
+		}
+		v, err := e.resolveRoot(f, cf.sel, args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cf.key, err)
+		}
+		out.set(cf.key, v)
+	}
+	return out, nil
+}
+
+func (e *executor) resolveRoot(f *gqlField, sel selection, args map[string]interface{}) (interface{}, error) {
+	typ := f.typ.getNamedType()
+
+	switch f.resolve {
+	case resolveRecords:
+		get := types.DataGet{Limit: limitDefault}
+		if v, exists := args["limit"]; exists && v != nil {
+			get.Limit = int(v.(int64))
+		}
+		if v, exists := args["offset"]; exists && v != nil {
+			get.Offset = int(v.(int64))
+		}
+		if get.Limit < 1 || get.Limit > limitMax || get.Offset < 0 {
+			return nil, fmt.Errorf("invalid limit or offset, max. limit is: %d", limitMax)
+		}
+		get.Filters = getFiltersFromArgs(getArgType(f, "where"), args)
+		get.Orders = getOrdersFromArgs(getArgType(f, "orderBy"), args)
+
+		objs, _, err := e.getRecords(typ, get, sel.selections, types.Attribute{}, 1)
+		if err != nil {
+			return nil, err
+		}
+		return objs, nil
+
+	case resolveCount:
+		get := types.DataGet{
+			RelationId: f.relationId,
+			Filters:    getFiltersFromArgs(getArgType(f, "where"), args),
+			Limit:      1,
+		}
+
+		var query string
+		_, count, err := data.Get_tx(e.ctx, e.tx, get, e.loginId, &query)
+		if err != nil {
+			return nil, dataError{err}
+		}
+		return count, nil
+
+	case resolveSet:
+		var recordId int64
+		if v, exists := args["id"]; exists && v != nil {
+			recordId = v.(int64)
+		}
+		input := f.args[1].typ.getNamedType()
+		values := args["values"].(map[string]interface{})
+
+		attributes := make([]types.DataSetAttribute, 0)
+		for _, iv := range input.inputFields {
+			if v, exists := values[iv.name]; exists {
+				attributes = append(attributes, types.DataSetAttribute{
+					AttributeId: iv.atr.Id,
+					Value:       v,
+				})
+			}
+		}
+
+		ids, err := data.Set_tx(e.ctx, e.tx, map[int]types.DataSet{
+			0: {
+				RelationId:  f.relationId,
+				AttributeId: uuid.Nil,
+				IndexFrom:   -1,
+				RecordId:    recordId,
+				Attributes:  attributes,
+			},
+		}, e.loginId)
+		if err != nil {
+			return nil, dataError{err}
+		}
+		return ids[0], nil
+
+	case resolveDel:
+		if err := data.Del_tx(e.ctx, e.tx, f.relationId, args["id"].(int64), e.loginId); err != nil {
+			return nil, dataError{err}
+		}
+		return true, nil
+	}
+	return nil, fmt.Errorf("cannot resolve field '%s'", f.name)
+}
+
+// returns records of relation type with selected fields
+// if group attribute is given, its value is returned for each record (to assign records to parent records)
+func (e *executor) getRecords(typ *gqlType, get types.DataGet, sels []selection,
+	atrGroup types.Attribute, depth int) ([]interface{}, []int64, error) {
+
+	get.RelationId = typ.relationId
+	get.IndexSource = 0
+
+	lists := make([]*planField, 0)
+	node, err := e.getPlanNode(&get, &lists, typ, 0, sels, depth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groupPos := -1
+	if atrGroup.Id != uuid.Nil {
+		groupPos = len(get.Expressions)
+		get.Expressions = append(get.Expressions, types.DataGetExpression{
+			AttributeId: pgtype.UUID{Bytes: atrGroup.Id, Valid: true},
+			Index:       0,
+		})
+	}
+
+	var query string
+	results, _, err := data.Get_tx(e.ctx, e.tx, get, e.loginId, &query)
+	if err != nil {
+		return nil, nil, dataError{err}
+	}
+
+	objs := make([]interface{}, 0)
+	groupIds := make([]int64, 0)
+	for _, result := range results {
+		obj, err := e.getObject(node, result)
+		if err != nil {
+			return nil, nil, err
+		}
+		objs = append(objs, obj)
+
+		if groupPos != -1 {
+			groupIds = append(groupIds, getInt64(result.Values[groupPos]))
+		}
+	}
+
+	// nested records of other relations, retrieved for all records at once
+	for _, pf := range lists {
+		if err := e.resolveNested(pf); err != nil {
+			return nil, nil, err
+		}
+	}
+	return objs, groupIds, nil
+}
+
+// adds expressions and joins for selected fields of relation type to data GET request
+func (e *executor) getPlanNode(get *types.DataGet, lists *[]*planField, typ *gqlType,
+	index int, sels []selection, depth int) (*planNode, error) {
+
+	if depth > depthMax {
+		return nil, fmt.Errorf("selections must not be nested deeper than %d levels", depthMax)
+	}
+
+	fields, err := e.collectFields(typ.name, sels)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &planNode{
+		typ:    typ,
+		index:  index,
+		fields: make([]*planField, 0),
+	}
+	for _, cf := range fields {
+		pf := &planField{key: cf.key, sel: cf.sel, depth: depth + 1}
+		node.fields = append(node.fields, pf)
+
+		if cf.sel.name == "__typename" {
+			continue
+		}
+
+		pf.field = typ.getField(cf.sel.name)
+		if pf.field == nil {
+			return nil, fmt.Errorf("cannot query field '%s' on type '%s'", cf.sel.name, typ.name)
+		}
+		if err := checkSelection(pf.field, cf.sel); err != nil {
+			return nil, err
+		}
+		if _, err := e.getArguments(pf.field, cf.sel); err != nil {
+			return nil, err
+		}
+
+		switch pf.field.resolve {
+		case resolveAttribute:
+			pf.exprPos = len(get.Expressions)
+			get.Expressions = append(get.Expressions, types.DataGetExpression{
+				AttributeId: pgtype.UUID{Bytes: pf.field.atr.Id, Valid: true},
+				Index:       index,
+			})
+		case resolveObject:
+			// records referred to via relationship attribute are joined
+			indexJoin := len(get.Joins) + 1
+			get.Joins = append(get.Joins, types.DataGetJoin{
+				AttributeId: pf.field.atr.Id,
+				Connector:   "LEFT",
+				Index:       indexJoin,
+				IndexFrom:   index,
+			})
+			pf.node, err = e.getPlanNode(get, lists, pf.field.typ.getNamedType(), indexJoin, cf.sel.selections, depth+1)
+			if err != nil {
+				return nil, err
+			}
+		case resolveList, resolveSingle:
+			*lists = append(*lists, pf)
+		}
+	}
+	return node, nil
+}
+
+// returns selected fields of record from data GET result, nil if record does not exist (empty join)
+func (e *executor) getObject(node *planNode, result types.DataGetResult) (interface{}, error) {
+	if result.IndexRecordIds[node.index] == nil {
+		return nil, nil
+	}
+	recordId := getInt64(result.IndexRecordIds[node.index])
+
+	e.objects++
+	if e.objects > objectsMax {
+		return nil, fmt.Errorf("response must not contain more than %d records", objectsMax)
+	}
+
+	obj := newOrderedMap()
+	for _, pf := range node.fields {
+		if pf.field == nil {
+			obj.set(pf.key, node.typ.name)
+			continue
+		}
+		switch pf.field.resolve {
+		case resolveId:
+			obj.set(pf.key, recordId)
+		case resolveAttribute:
+			obj.set(pf.key, getOutputValue(result.Values[pf.exprPos]))
+		case resolveObject:
+			v, err := e.getObject(pf.node, result)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(pf.key, v)
+		case resolveList, resolveSingle:
+			obj.set(pf.key, nil)
+			pf.pending = append(pf.pending, pendingRecord{recordId: recordId, obj: obj})
+		}
+	}
+	return obj, nil
+}
+
+// retrieves records of other relation that refer to pending records and assigns them
+func (e *executor) resolveNested(pf *planField) error {
+	if len(pf.pending) == 0 {
+		return nil
+	}
+
+	args, err := e.getArguments(pf.field, pf.sel)
+	if err != nil {
+		return err
+	}
+	typ := pf.field.typ.getNamedType()
+
+	recordIds := make([]int64, 0)
+	for _, p := range pf.pending {
+		if !slices.Contains(recordIds, p.recordId) {
+			recordIds = append(recordIds, p.recordId)
+		}
+	}
+
+	// limit & offset apply to records of each parent record
+	limit, offset := 0, 0
+	if v, exists := args["limit"]; exists && v != nil {
+		limit = int(v.(int64))
+	}
+	if v, exists := args["offset"]; exists && v != nil {
+		offset = int(v.(int64))
+	}
+	if limit < 0 || limit > limitMax || offset < 0 {
+		return fmt.Errorf("invalid limit or offset, max. limit is: %d", limitMax)
+	}
+
+	recordIdMapObjs := make(map[int64][]interface{})
+	if pf.field.resolve == resolveList && (limit != 0 || offset != 0) {
+		// paginated records are retrieved for each parent record, to apply limit & offset in the query
+		for _, recordId := range recordIds {
+			get := getNestedGet(pf, args, []int64{recordId})
+			get.Limit = limit
+			get.Offset = offset
+
+			objs, _, err := e.getRecords(typ, get, pf.sel.selections, types.Attribute{}, pf.depth)
+			if err != nil {
+				return err
+			}
+			recordIdMapObjs[recordId] = objs
+		}
+	} else {
+		// records are retrieved for all parent records at once
+		// result is limited to the remaining number of records allowed in the response (+1 to detect if it is exceeded)
+		get := getNestedGet(pf, args, recordIds)
+		get.Limit = objectsMax - e.objects + 1
+		if pf.field.resolve == resolveSingle {
+			get.Limit = min(get.Limit, len(recordIds))
+		}
+
+		objs, groupIds, err := e.getRecords(typ, get, pf.sel.selections, pf.field.atr, pf.depth)
+		if err != nil {
+			return err
+		}
+		for i, obj := range objs {
+			recordIdMapObjs[groupIds[i]] = append(recordIdMapObjs[groupIds[i]], obj)
+		}
+	}
+
+	for _, p := range pf.pending {
+		records, exists := recordIdMapObjs[p.recordId]
+		if !exists {
+			records = make([]interface{}, 0)
+		}
+
+		if pf.field.resolve == resolveSingle {
+			if len(records) != 0 {
+				p.obj.set(pf.key, records[0])
+			}
+			continue
+		}
+		p.obj.set(pf.key, records)
+	}
+	pf.pending = nil
+	return nil
+}
+
+// data GET request for records of nested field that refer to the given parent records
+func getNestedGet(pf *planField, args map[string]interface{}, recordIds []int64) types.DataGet {
+	get := types.DataGet{
+		Filters: getFiltersFromArgs(getArgType(pf.field, "where"), args),
+		Orders:  getOrdersFromArgs(getArgType(pf.field, "orderBy"), args),
+	}
+	get.Filters = append(get.Filters, types.DataGetFilter{
+		Connector: "AND",
+		Index:     0,
+		Operator:  "= ANY",
+		Side0: types.DataGetFilterSide{
+			AttributeId: pgtype.UUID{Bytes: pf.field.atr.Id, Valid: true},
+		},
+		Side1: types.DataGetFilterSide{Value: recordIds},
+	})
+	return get
+}
+
+// filters from 'id' and 'where' arguments
+func getFiltersFromArgs(whereType *gqlType, args map[string]interface{}) []types.DataGetFilter {
+	filters := make([]types.DataGetFilter, 0)
+
+	if v, exists := args["where"]; exists && v != nil {
+		if f := getFilters(whereType, v.(map[string]interface{})); len(f) != 0 {
+			filters = append(filters, encloseFilters(f)...)
+		}
+	}
+	if v, exists := args["id"]; exists && v != nil {
+		filters = append(filters, types.DataGetFilter{
+			Connector: "AND",
+			Index:     0,
+			Operator:  "=",
+			Side0: types.DataGetFilterSide{
+				AttributeId: pgtype.UUID{Bytes: whereType.inputFields[0].atr.Id, Valid: true},
+			},
+			Side1: types.DataGetFilterSide{Value: v},
+		})
+	}
+	return filters
+}
+
+// orders from 'orderBy' argument, records are always ordered by ID last to keep results stable
+func getOrdersFromArgs(orderType *gqlType, args map[string]interface{}) []types.DataGetOrder {
+	orders := make([]types.DataGetOrder, 0)
+
+	if v, exists := args["orderBy"]; exists && v != nil {
+		for _, item := range v.([]interface{}) {
+			m := item.(map[string]interface{})
+			for _, iv := range orderType.inputFields {
+				if direction, exists := m[iv.name]; exists && direction != nil {
+					orders = append(orders, types.DataGetOrder{
+						AttributeId: pgtype.UUID{Bytes: iv.atr.Id, Valid: true},
+						Index:       pgtype.Int4{Int32: 0, Valid: true},
+						Ascending:   direction == "ASC",
+					})
+				}
+			}
+		}
+	}
+	return append(orders, types.DataGetOrder{
+		AttributeId: pgtype.UUID{Bytes: orderType.inputFields[0].atr.Id, Valid: true},
+		Index:       pgtype.Int4{Int32: 0, Valid: true},
+		Ascending:   true,
+	})
+}
+
+// returns named type of field argument
+func getArgType(f *gqlField, name string) *gqlType {
+	for _, iv := range f.args {
+		if iv.name == name {
+			return iv.typ.getNamedType()
+		}
+	}
+	return nil
+}
+
+// converts 'where' input object into data GET filters, all given conditions are combined with AND
+func getFilters(whereType *gqlType, where map[string]interface{}) []types.DataGetFilter {
+	filters := make([]types.DataGetFilter, 0)
+
+	for _, iv := range whereType.inputFields {
+		v, exists := where[iv.name]
+		if !exists || v == nil {
+			continue
+		}
+
+		if iv.name == "and" || iv.name == "or" {
+			group := make([]types.DataGetFilter, 0)
+			for _, item := range v.([]interface{}) {
+				sub := getFilters(whereType, item.(map[string]interface{}))
+				if len(sub) == 0 {
+					continue
+				}
+				sub = encloseFilters(sub)
+				if len(group) != 0 {
+					sub[0].Connector = strings.ToUpper(iv.name)
+				}
+				group = append(group, sub...)
+			}
+			if len(group) != 0 {
+				filters = append(filters, encloseFilters(group)...)
+			}
+			continue
+		}
+
+		comparison := v.(map[string]interface{})
+		for _, op := range comparisonOperators {
+			cv, exists := comparison[op]
+			if !exists {
+				continue
+			}
+
+			filter := types.DataGetFilter{
+				Connector: "AND",
+				Index:     0,
+				Side0: types.DataGetFilterSide{
+					AttributeId: pgtype.UUID{Bytes: iv.atr.Id, Valid: true},
+				},
+			}
+
+			switch {
+			case op == "isNull":
+				if cv == nil {
+					continue
+				}
+				filter.Operator = "IS NOT NULL"
+				if cv.(bool) {
+					filter.Operator = "IS NULL"
+				}
+			case cv == nil:
+				// comparison with null is only meaningful for (in)equality
+				switch op {
+				case "eq":
+					filter.Operator = "IS NULL"
+				case "ne":
+					filter.Operator = "IS NOT NULL"
+				default:
+					continue
+				}
+			default:
+				filter.Operator = comparisonOperatorMap[op]
+				filter.Side1.Value = cv
+			}
+			filters = append(filters, filter)
+		}
+	}
+	return filters
+}
+
+// encloses filters in brackets, to combine them with other filters
+func encloseFilters(filters []types.DataGetFilter) []types.DataGetFilter {
+	filters[0].Connector = "AND"
+	filters[0].Side0.Brackets++
+	filters[len(filters)-1].Side1.Brackets++
+	return filters
+}
+
+// collects fields of selection set for given type, applying fragments and directives
+func (e *executor) collectFields(typeName string, sels []selection) ([]collectedField, error) {
+	fields := make([]collectedField, 0)
+	return fields, e.collectFieldsInto(typeName, sels, &fields, make(map[string]bool))
+}
+func (e *executor) collectFieldsInto(typeName string, sels []selection,
+	fields *[]collectedField, fragmentsVisited map[string]bool) error {
+
+	for _, s := range sels {
+		include, err := e.isIncluded(s)
+		if err != nil {
+			return err
+		}
+		if !include {
+			continue
+		}
+
+		switch s.kind {
+		case selectionField:
+			key := s.name
+			if s.alias != "" {
+				key = s.alias
+			}
+			merged := false
+			for i, f := range *fields {
+				if f.key != key {
+					continue
+				}
+				if f.sel.name != s.name {
+					return fmt.Errorf("fields '%s' and '%s' use the same response key '%s'", f.sel.name, s.name, key)
+				}
+				(*fields)[i].sel.selections = append(slices.Clone(f.sel.selections), s.selections...)
+				merged = true
+				break
+			}
+			if !merged {
+				*fields = append(*fields, collectedField{key: key, sel: s})
+			}
+
+		case selectionFragmentSpread:
+			if fragmentsVisited[s.name] {
+				continue
+			}
+			fragmentsVisited[s.name] = true
+
+			f, exists := e.doc.fragments[s.name]
+			if !exists {
+				return fmt.Errorf("unknown fragment '%s'", s.name)
+			}
+			if f.typeCondition != typeName {
+				continue
+			}
+			if err := e.collectFieldsInto(typeName, f.selections, fields, fragmentsVisited); err != nil {
+				return err
+			}
+
+		case selectionInlineFragment:
+			if s.typeCondition != "" && s.typeCondition != typeName {
+				continue
+			}
+			if err := e.collectFieldsInto(typeName, s.selections, fields, fragmentsVisited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applies @skip and @include directives
+func (e *executor) isIncluded(s selection) (bool, error) {
+	for _, d := range s.directives {
+		if d.name != "skip" && d.name != "include" {
+			return false, fmt.Errorf("unknown directive '@%s'", d.name)
+		}
+		if len(d.arguments) != 1 || d.arguments[0].name != "if" {
+			return false, fmt.Errorf("directive '@%s' requires argument 'if'", d.name)
+		}
+		v, ok := e.getValue(d.arguments[0].value).(bool)
+		if !ok {
+			return false, fmt.Errorf("argument 'if' of directive '@%s' must be Boolean", d.name)
+		}
+		if (d.name == "skip" && v) || (d.name == "include" && !v) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// returns coerced argument values of field, arguments that are not given are not included
+func (e *executor) getArguments(f *gqlField, s selection) (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+
+	for _, a := range s.arguments {
+		var def *gqlInputValue
+		for _, iv := range f.args {
+			if iv.name == a.name {
+				def = iv
+				break
+			}
+		}
+		if def == nil {
+			return nil, fmt.Errorf("unknown argument '%s' on field '%s'", a.name, f.name)
+		}
+
+		// variables without value are handled as if argument was not given
+		if a.value.kind == valueVariable {
+			if _, exists := e.variables[a.value.raw]; !exists {
+				continue
+			}
+		}
+
+		v, err := coerceValue(def.typ, e.getValue(a.value), a.name)
+		if err != nil {
+			return nil, err
+		}
+		args[a.name] = v
+	}
+
+	for _, iv := range f.args {
+		if _, exists := args[iv.name]; !exists && iv.typ.kind == kindNonNull {
+			return nil, fmt.Errorf("argument '%s' on field '%s' is required", iv.name, f.name)
+		}
+	}
+	return args, nil
+}
+
+// returns Go value of parsed input value, variables are replaced by their values
+func (e *executor) getValue(v value) interface{} {
+	switch v.kind {
+	case valueVariable:
+		return e.variables[v.raw]
+	case valueInt:
+		i, _ := strconv.ParseInt(v.raw, 10, 64)
+		return i
+	case valueFloat:
+		f, _ := strconv.ParseFloat(v.raw, 64)
+		return f
+	case valueString, valueEnum:
+		return v.raw
+	case valueBoolean:
+		return v.raw == "true"
+	case valueList:
+		list := make([]interface{}, 0)
+		for _, item := range v.list {
+			list = append(list, e.getValue(item))
+		}
+		return list
+	case valueObject:
+		m := make(map[string]interface{})
+		for _, field := range v.fields {
+			if field.value.kind == valueVariable {
+				if _, exists := e.variables[field.value.raw]; !exists {
+					continue
+				}
+			}
+			m[field.name] = e.getValue(field.value)
+		}
+		return m
+	}
+	return nil
+}
+
+// checks input value against input type, returns value with Go types used for data requests
+func coerceValue(t *gqlType, v interface{}, path string) (interface{}, error) {
+	if t.kind == kindNonNull {
+		if v == nil {
+			return nil, fmt.Errorf("value of '%s' must not be null", path)
+		}
+		return coerceValue(t.ofType, v, path)
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	switch t.kind {
+	case kindList:
+		items, ok := v.([]interface{})
+		if !ok {
+			// single value is accepted as list with one item
+			items = []interface{}{v}
+		}
+		list := make([]interface{}, 0)
+		for i, item := range items {
+			c, err := coerceValue(t.ofType, item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, c)
+		}
+		return list, nil
+
+	case kindInputObject:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("value of '%s' must be an input object of type '%s'", path, t.name)
+		}
+		out := make(map[string]interface{})
+		for name, fv := range m {
+			var def *gqlInputValue
+			for _, iv := range t.inputFields {
+				if iv.name == name {
+					def = iv
+					break
+				}
+			}
+			if def == nil {
+				return nil, fmt.Errorf("unknown field '%s.%s' of type '%s'", path, name, t.name)
+			}
+			c, err := coerceValue(def.typ, fv, fmt.Sprintf("%s.%s", path, name))
+			if err != nil {
+				return nil, err
+			}
+			out[name] = c
+		}
+		for _, iv := range t.inputFields {
+			if _, exists := out[iv.name]; !exists && iv.typ.kind == kindNonNull {
+				return nil, fmt.Errorf("field '%s.%s' is required", path, iv.name)
+			}
+		}
+		return out, nil
+
+	case kindEnum:
+		s, ok := v.(string)
+		if !ok || !slices.Contains(t.enumValues, s) {
+			return nil, fmt.Errorf("value of '%s' must be one of: %s", path, strings.Join(t.enumValues, ", "))
+		}
+		return s, nil
+	}
+
+	// scalars
+	var invalid = func() (interface{}, error) {
+		return nil, fmt.Errorf("value of '%s' is not a valid %s", path, t.name)
+	}
+	switch t.name {
+	case "Int", "BigInt":
+		var i int64
+		switch n := v.(type) {
+		case int64:
+			i = n
+		case float64:
+			if n != math.Trunc(n) || math.Abs(n) > math.MaxInt64 {
+				return invalid()
+			}
+			i = int64(n)
+		default:
+			return invalid()
+		}
+		if t.name == "Int" && (i > math.MaxInt32 || i < math.MinInt32) {
+			return invalid()
+		}
+		return i, nil
+	case "Float":
+		switch n := v.(type) {
+		case int64:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+		return invalid()
+	case "Boolean":
+		if _, ok := v.(bool); !ok {
+			return invalid()
+		}
+	case "String":
+		if _, ok := v.(string); !ok {
+			return invalid()
+		}
+	case "ID":
+		switch n := v.(type) {
+		case string:
+			return n, nil
+		case int64:
+			return strconv.FormatInt(n, 10), nil
+		}
+		return invalid()
+	}
+	return v, nil
+}
+
+// checks that object fields have sub selections and scalar fields have none
+func checkSelection(f *gqlField, s selection) error {
+	named := f.typ.getNamedType()
+	if named.kind == kindObject && len(s.selections) == 0 {
+		return fmt.Errorf("field '%s' of type '%s' must have a selection of subfields", f.name, named.name)
+	}
+	if named.kind != kindObject && len(s.selections) != 0 {
+		return fmt.Errorf("field '%s' must not have a selection since type '%s' has no subfields", f.name, named.name)
+	}
+	return nil
+}
+
+// introspection fields are resolved from generated introspection objects
+func (e *executor) getIntrospection(s selection) (interface{}, error) {
+	if s.name == "__schema" {
+		return e.project(e.schema.getIntrospectionSchema(), s.selections)
+	}
+
+	if len(s.arguments) != 1 || s.arguments[0].name != "name" {
+		return nil, errors.New("field '__type' requires argument 'name'")
+	}
+	name, ok := e.getValue(s.arguments[0].value).(string)
+	if !ok {
+		return nil, errors.New("argument 'name' of field '__type' must be String")
+	}
+	t, exists := e.schema.types[name]
+	if !exists {
+		return nil, nil
+	}
+	return e.project(e.schema.getIntrospectionType(t), s.selections)
+}
+
+func (e *executor) project(v interface{}, sels []selection) (interface{}, error) {
+	switch o := v.(type) {
+	case []interface{}:
+		list := make([]interface{}, 0)
+		for _, item := range o {
+			p, err := e.project(item, sels)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, p)
+		}
+		return list, nil
+
+	case map[string]interface{}:
+		if o == nil {
+			return nil, nil
+		}
+		typeName := o["__typename"].(string)
+		fields, err := e.collectFields(typeName, sels)
+		if err != nil {
+			return nil, err
+		}
+		out := newOrderedMap()
+		for _, cf := range fields {
+			fv, exists := o[cf.sel.name]
+			if !exists {
+				return nil, fmt.Errorf("cannot query field '%s' on type '%s'", cf.sel.name, typeName)
+			}
+			p, err := e.project(fv, cf.sel.selections)
+			if err != nil {
+				return nil, err
+			}
+			out.set(cf.key, p)
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+// converts data value to its GraphQL JSON representation
+func getOutputValue(v interface{}) interface{} {
+	if u, ok := v.([16]uint8); ok {
+		return uuid.UUID(u).String()
+	}
+	return v
+}
+
+func getInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int32:
+		return int64(n)
+	case int:
+		return int64(n)
+	}
+	return 0
+}
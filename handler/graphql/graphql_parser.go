@@ -0,0 +1,641 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// GraphQL document, parsed from request query
+type document struct {
+	operations []operation
+	fragments  map[string]fragment
+}
+type operation struct {
+	kind       string // query, mutation, subscription
+	name       string
+	variables  []variableDefinition
+	selections []selection
+}
+type variableDefinition struct {
+	name    string
+	nonNull bool   // variable type is non-null, value is required
+	def     *value // default value, optional
+}
+type fragment struct {
+	typeCondition string
+	selections    []selection
+}
+
+const (
+	selectionField = iota
+	selectionFragmentSpread
+	selectionInlineFragment
+)
+
+type selection struct {
+	kind          int
+	alias         string // field: response key, if different from field name
+	name          string // field: field name, fragment spread: fragment name
+	typeCondition string // inline fragment: type condition, optional
+	arguments     []argument
+	directives    []directive
+	selections    []selection
+}
+type argument struct {
+	name  string
+	value value
+}
+type directive struct {
+	name      string
+	arguments []argument
+}
+
+const (
+	valueVariable = iota
+	valueInt
+	valueFloat
+	valueString
+	valueBoolean
+	valueNull
+	valueEnum
+	valueList
+	valueObject
+)
+
+type value struct {
+	kind   int
+	raw    string     // variable name or literal value
+	list   []value    // list values
+	fields []argument // object fields
+}
+
+// lexical tokens
+const (
+	tokenEof = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  int
+	value string
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parses GraphQL document (executable definitions only)
+func parseDocument(input string) (document, error) {
+	doc := document{
+		operations: make([]operation, 0),
+		fragments:  make(map[string]fragment),
+	}
+
+	tokens, err := getTokens(input)
+	if err != nil {
+		return doc, err
+	}
+	p := parser{tokens: tokens}
+
+	for !p.peek(tokenEof, "") {
+		switch {
+		case p.peek(tokenPunctuator, "{"):
+			// query shorthand
+			selections, err := p.parseSelectionSet()
+			if err != nil {
+				return doc, err
+			}
+			doc.operations = append(doc.operations, operation{kind: "query", selections: selections})
+
+		case p.peek(tokenName, "fragment"):
+			p.pos++
+			name, err := p.expectName()
+			if err != nil {
+				return doc, err
+			}
+			if err := p.expectKeyword("on"); err != nil {
+				return doc, err
+			}
+			typeCondition, err := p.expectName()
+			if err != nil {
+				return doc, err
+			}
+			if _, err := p.parseDirectives(); err != nil {
+				return doc, err
+			}
+			selections, err := p.parseSelectionSet()
+			if err != nil {
+				return doc, err
+			}
+			if _, exists := doc.fragments[name]; exists {
+				return doc, fmt.Errorf("fragment '%s' is defined more than once", name)
+			}
+			doc.fragments[name] = fragment{typeCondition: typeCondition, selections: selections}
+
+		case p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			op := operation{kind: p.next().value}
+			if p.peek(tokenName, "") {
+				op.name = p.next().value
+			}
+			if p.peek(tokenPunctuator, "(") {
+				op.variables, err = p.parseVariableDefinitions()
+				if err != nil {
+					return doc, err
+				}
+			}
+			if _, err := p.parseDirectives(); err != nil {
+				return doc, err
+			}
+			op.selections, err = p.parseSelectionSet()
+			if err != nil {
+				return doc, err
+			}
+			doc.operations = append(doc.operations, op)
+
+		default:
+			return doc, p.unexpected()
+		}
+	}
+
+	if len(doc.operations) == 0 {
+		return doc, errors.New("document does not contain any operation")
+	}
+	return doc, nil
+}
+
+func (p *parser) parseVariableDefinitions() ([]variableDefinition, error) {
+	defs := make([]variableDefinition, 0)
+	if err := p.expect(tokenPunctuator, "("); err != nil {
+		return defs, err
+	}
+	for !p.peek(tokenPunctuator, ")") {
+		if err := p.expect(tokenPunctuator, "$"); err != nil {
+			return defs, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return defs, err
+		}
+		if err := p.expect(tokenPunctuator, ":"); err != nil {
+			return defs, err
+		}
+		nonNull, err := p.parseType()
+		if err != nil {
+			return defs, err
+		}
+		def := variableDefinition{name: name, nonNull: nonNull}
+
+		if p.peek(tokenPunctuator, "=") {
+			p.pos++
+			v, err := p.parseValue(true)
+			if err != nil {
+				return defs, err
+			}
+			def.def = &v
+		}
+		if _, err := p.parseDirectives(); err != nil {
+			return defs, err
+		}
+		defs = append(defs, def)
+	}
+	p.pos++
+	return defs, nil
+}
+
+// parses type reference, returns whether outer type is non-null
+func (p *parser) parseType() (bool, error) {
+	if p.peek(tokenPunctuator, "[") {
+		p.pos++
+		if _, err := p.parseType(); err != nil {
+			return false, err
+		}
+		if err := p.expect(tokenPunctuator, "]"); err != nil {
+			return false, err
+		}
+	} else if _, err := p.expectName(); err != nil {
+		return false, err
+	}
+
+	if p.peek(tokenPunctuator, "!") {
+		p.pos++
+		return true, nil
+	}
+	return false, nil
+}
+
+func (p *parser) parseSelectionSet() ([]selection, error) {
+	selections := make([]selection, 0)
+	if err := p.expect(tokenPunctuator, "{"); err != nil {
+		return selections, err
+	}
+	for !p.peek(tokenPunctuator, "}") {
+		s, err := p.parseSelection()
+		if err != nil {
+			return selections, err
+		}
+		selections = append(selections, s)
+	}
+	p.pos++
+
+	if len(selections) == 0 {
+		return selections, errors.New("selection set must not be empty")
+	}
+	return selections, nil
+}
+
+func (p *parser) parseSelection() (selection, error) {
+	var err error
+	var s selection
+
+	if p.peek(tokenPunctuator, "...") {
+		p.pos++
+
+		if p.peek(tokenName, "") && !p.peek(tokenName, "on") {
+			// fragment spread
+			s.kind = selectionFragmentSpread
+			s.name = p.next().value
+			s.directives, err = p.parseDirectives()
+			return s, err
+		}
+
+		// inline fragment
+		s.kind = selectionInlineFragment
+		if p.peek(tokenName, "on") {
+			p.pos++
+			if s.typeCondition, err = p.expectName(); err != nil {
+				return s, err
+			}
+		}
+		if s.directives, err = p.parseDirectives(); err != nil {
+			return s, err
+		}
+		s.selections, err = p.parseSelectionSet()
+		return s, err
+	}
+
+	// field
+	s.kind = selectionField
+	if s.name, err = p.expectName(); err != nil {
+		return s, err
+	}
+	if p.peek(tokenPunctuator, ":") {
+		p.pos++
+		s.alias = s.name
+		if s.name, err = p.expectName(); err != nil {
+			return s, err
+		}
+	}
+	if p.peek(tokenPunctuator, "(") {
+		if s.arguments, err = p.parseArguments(false); err != nil {
+			return s, err
+		}
+	}
+	if s.directives, err = p.parseDirectives(); err != nil {
+		return s, err
+	}
+	if p.peek(tokenPunctuator, "{") {
+		if s.selections, err = p.parseSelectionSet(); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) parseArguments(isConst bool) ([]argument, error) {
+	args := make([]argument, 0)
+	if err := p.expect(tokenPunctuator, "("); err != nil {
+		return args, err
+	}
+	for !p.peek(tokenPunctuator, ")") {
+		name, err := p.expectName()
+		if err != nil {
+			return args, err
+		}
+		if err := p.expect(tokenPunctuator, ":"); err != nil {
+			return args, err
+		}
+		v, err := p.parseValue(isConst)
+		if err != nil {
+			return args, err
+		}
+		args = append(args, argument{name: name, value: v})
+	}
+	p.pos++
+	return args, nil
+}
+
+func (p *parser) parseDirectives() ([]directive, error) {
+	directives := make([]directive, 0)
+	for p.peek(tokenPunctuator, "@") {
+		p.pos++
+		name, err := p.expectName()
+		if err != nil {
+			return directives, err
+		}
+		d := directive{name: name}
+		if p.peek(tokenPunctuator, "(") {
+			if d.arguments, err = p.parseArguments(false); err != nil {
+				return directives, err
+			}
+		}
+		directives = append(directives, d)
+	}
+	return directives, nil
+}
+
+// parses input value, variables are not allowed in constant values (variable defaults)
+func (p *parser) parseValue(isConst bool) (value, error) {
+	pos := p.pos
+	t := p.next()
+
+	switch t.kind {
+	case tokenPunctuator:
+		switch t.value {
+		case "$":
+			if isConst {
+				return value{}, errors.New("variables are not allowed in constant values")
+			}
+			name, err := p.expectName()
+			return value{kind: valueVariable, raw: name}, err
+
+		case "[":
+			v := value{kind: valueList, list: make([]value, 0)}
+			for !p.peek(tokenPunctuator, "]") {
+				item, err := p.parseValue(isConst)
+				if err != nil {
+					return v, err
+				}
+				v.list = append(v.list, item)
+			}
+			p.pos++
+			return v, nil
+
+		case "{":
+			v := value{kind: valueObject, fields: make([]argument, 0)}
+			for !p.peek(tokenPunctuator, "}") {
+				name, err := p.expectName()
+				if err != nil {
+					return v, err
+				}
+				if err := p.expect(tokenPunctuator, ":"); err != nil {
+					return v, err
+				}
+				field, err := p.parseValue(isConst)
+				if err != nil {
+					return v, err
+				}
+				v.fields = append(v.fields, argument{name: name, value: field})
+			}
+			p.pos++
+			return v, nil
+		}
+	case tokenInt:
+		return value{kind: valueInt, raw: t.value}, nil
+	case tokenFloat:
+		return value{kind: valueFloat, raw: t.value}, nil
+	case tokenString:
+		return value{kind: valueString, raw: t.value}, nil
+	case tokenName:
+		switch t.value {
+		case "true", "false":
+			return value{kind: valueBoolean, raw: t.value}, nil
+		case "null":
+			return value{kind: valueNull}, nil
+		}
+		return value{kind: valueEnum, raw: t.value}, nil
+	}
+	p.pos = pos
+	return value{}, p.unexpected()
+}
+
+// parser helpers
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEof {
+		p.pos++
+	}
+	return t
+}
+func (p *parser) peek(kind int, v string) bool {
+	t := p.tokens[p.pos]
+	return t.kind == kind && (v == "" || t.value == v)
+}
+func (p *parser) expect(kind int, v string) error {
+	if !p.peek(kind, v) {
+		return p.unexpected()
+	}
+	p.pos++
+	return nil
+}
+func (p *parser) expectKeyword(v string) error {
+	return p.expect(tokenName, v)
+}
+func (p *parser) expectName() (string, error) {
+	if !p.peek(tokenName, "") {
+		return "", p.unexpected()
+	}
+	return p.next().value, nil
+}
+func (p *parser) unexpected() error {
+	t := p.tokens[p.pos]
+	if t.kind == tokenEof {
+		return errors.New("syntax error: unexpected end of document")
+	}
+	return fmt.Errorf("syntax error: unexpected '%s'", t.value)
+}
+
+// splits GraphQL document into lexical tokens, ignoring whitespace, commas and comments
+func getTokens(input string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(input)
+
+	var isNameStart = func(c rune) bool {
+		return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+	}
+	var isDigit = func(c rune) bool {
+		return c >= '0' && c <= '9'
+	}
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' || c == '\uFEFF':
+			continue
+
+		case c == '#':
+			for i < len(runes) && runes[i] != '\n' && runes[i] != '\r' {
+				i++
+			}
+
+		case strings.ContainsRune("!$&():=@[]{}|", c):
+			tokens = append(tokens, token{kind: tokenPunctuator, value: string(c)})
+
+		case c == '.':
+			if i+2 >= len(runes) || runes[i+1] != '.' || runes[i+2] != '.' {
+				return nil, errors.New("syntax error: unexpected '.'")
+			}
+			tokens = append(tokens, token{kind: tokenPunctuator, value: "..."})
+			i += 2
+
+		case isNameStart(c):
+			start := i
+			for i < len(runes) && (isNameStart(runes[i]) || isDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenName, value: string(runes[start:i])})
+			i--
+
+		case c == '-' || isDigit(c):
+			start := i
+			kind := tokenInt
+			if c == '-' {
+				i++
+			}
+			for i < len(runes) && isDigit(runes[i]) {
+				i++
+			}
+			if i < len(runes) && runes[i] == '.' {
+				kind = tokenFloat
+				for i++; i < len(runes) && isDigit(runes[i]); i++ {
+				}
+			}
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				kind = tokenFloat
+				i++
+				if i < len(runes) && (runes[i] == '+' || runes[i] == '-') {
+					i++
+				}
+				for i < len(runes) && isDigit(runes[i]) {
+					i++
+				}
+			}
+			literal := string(runes[start:i])
+			if _, err := strconv.ParseFloat(literal, 64); err != nil {
+				return nil, fmt.Errorf("syntax error: invalid number '%s'", literal)
+			}
+			tokens = append(tokens, token{kind: kind, value: literal})
+			i--
+
+		case c == '"':
+			if i+2 < len(runes) && runes[i+1] == '"' && runes[i+2] == '"' {
+				// block string, common indentation is removed
+				end := -1
+				var b strings.Builder
+				for j := i + 3; j < len(runes); j++ {
+					if runes[j] == '\\' && j+3 < len(runes) && string(runes[j+1:j+4]) == `"""` {
+						b.WriteString(`"""`)
+						j += 3
+						continue
+					}
+					if j+2 < len(runes) && runes[j] == '"' && runes[j+1] == '"' && runes[j+2] == '"' {
+						end = j
+						break
+					}
+					b.WriteRune(runes[j])
+				}
+				if end == -1 {
+					return nil, errors.New("syntax error: unterminated block string")
+				}
+				tokens = append(tokens, token{kind: tokenString, value: getBlockStringValue(b.String())})
+				i = end + 2
+				continue
+			}
+
+			var b strings.Builder
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '"' {
+					closed = true
+					break
+				}
+				if runes[i] == '\n' || runes[i] == '\r' {
+					break
+				}
+				if runes[i] != '\\' {
+					b.WriteRune(runes[i])
+					continue
+				}
+				i++
+				if i >= len(runes) {
+					break
+				}
+				switch runes[i] {
+				case '"', '\\', '/':
+					b.WriteRune(runes[i])
+				case 'b':
+					b.WriteRune('\b')
+				case 'f':
+					b.WriteRune('\f')
+				case 'n':
+					b.WriteRune('\n')
+				case 'r':
+					b.WriteRune('\r')
+				case 't':
+					b.WriteRune('\t')
+				case 'u':
+					if i+4 >= len(runes) {
+						return nil, errors.New("syntax error: invalid unicode escape sequence")
+					}
+					code, err := strconv.ParseUint(string(runes[i+1:i+5]), 16, 32)
+					if err != nil || !utf8.ValidRune(rune(code)) {
+						return nil, errors.New("syntax error: invalid unicode escape sequence")
+					}
+					b.WriteRune(rune(code))
+					i += 4
+				default:
+					return nil, fmt.Errorf("syntax error: invalid escape sequence '\\%c'", runes[i])
+				}
+			}
+			if !closed {
+				return nil, errors.New("syntax error: unterminated string")
+			}
+			tokens = append(tokens, token{kind: tokenString, value: b.String()})
+
+		default:
+			return nil, fmt.Errorf("syntax error: unexpected character '%c'", c)
+		}
+	}
+	return append(tokens, token{kind: tokenEof}), nil
+}
+
+// removes common indentation and leading/trailing blank lines from block string
+func getBlockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+
+	indent := -1
+	for i, line := range lines {
+		if i == 0 {
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if l := len(line) - len(trimmed); indent == -1 || l < indent {
+			indent = l
+		}
+	}
+	if indent > 0 {
+		for i := range lines {
+			if i != 0 && len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			}
+		}
+	}
+	for len(lines) != 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) != 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
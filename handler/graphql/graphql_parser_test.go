@@ -0,0 +1,228 @@
+package graphql
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		// valid documents
+		{
+			name:  "query shorthand",
+			input: "{ invoice { id date } }",
+			want:  "query { invoice { id date } }",
+		},
+		{
+			name:  "named query with variables",
+			input: "query Open($state: String! = \"open\", $limit: Int) { invoice(where: {state: {eq: $state}}, limit: $limit) { id } }",
+			want:  "query Open($state! = \"open\", $limit) { invoice(where: {state: {eq: $state}}, limit: $limit) { id } }",
+		},
+		{
+			name:  "list types",
+			input: "query ($ids: [Int!]!) { invoice(ids: $ids) { id } }",
+			want:  "query($ids!) { invoice(ids: $ids) { id } }",
+		},
+		{
+			name:  "aliases, commas and comments",
+			input: "{\n  # open invoices\n  open: invoice, closed: invoice(limit: 5) { id, date } }",
+			want:  "query { open: invoice closed: invoice(limit: 5) { id date } }",
+		},
+		{
+			name:  "literal values",
+			input: `{ f(a: -12, b: 1.5e3, c: true, d: null, e: ASC, g: [1 [2]], h: {}) }`,
+			want:  `query { f(a: -12, b: 1.5e3, c: true, d: null, e: ASC, g: [1 [2]], h: {}) }`,
+		},
+		{
+			name:  "string escapes",
+			input: `{ f(a: "quote \" slash \\ \u00e4 \n") }`,
+			want:  "query { f(a: \"quote \\\" slash \\\\ ä \\n\") }",
+		},
+		{
+			name:  "block string",
+			input: "{ f(a: \"\"\"\n    first\n      second \\\"\"\"\n    \"\"\") }",
+			want:  "query { f(a: \"first\\n  second \\\"\\\"\\\"\") }",
+		},
+		{
+			name:  "directives",
+			input: "query ($all: Boolean!) { invoice @skip(if: $all) { id @include(if: true) } }",
+			want:  "query($all!) { invoice @skip(if: $all) { id @include(if: true) } }",
+		},
+		{
+			name:  "fragments",
+			input: "{ invoice { ...fields ... on invoice { date } ... { id } } } fragment fields on invoice { id }",
+			want:  "query { invoice { ...fields ... on invoice { date } ... { id } } } fragment fields on invoice { id }",
+		},
+		{
+			name:  "multiple operations",
+			input: "query A { a } mutation B { b(id: 1) } subscription C { c }",
+			want:  "query A { a } mutation B { b(id: 1) } subscription C { c }",
+		},
+
+		// invalid documents
+		{name: "empty", input: "", wantErr: true},
+		{name: "only fragment", input: "fragment f on invoice { id }", wantErr: true},
+		{name: "duplicate fragment", input: "{ a } fragment f on x { id } fragment f on x { id }", wantErr: true},
+		{name: "fragment without type condition", input: "{ a } fragment f { id }", wantErr: true},
+		{name: "unknown keyword", input: "select { a }", wantErr: true},
+		{name: "empty selection set", input: "{ }", wantErr: true},
+		{name: "unclosed selection set", input: "{ a { b }", wantErr: true},
+		{name: "missing argument value", input: "{ a(id:) }", wantErr: true},
+		{name: "variable in default value", input: "query ($a: Int = $b) { a }", wantErr: true},
+		{name: "variable without type", input: "query ($a) { a }", wantErr: true},
+		{name: "unterminated string", input: `{ a(b: "c) }`, wantErr: true},
+		{name: "line break in string", input: "{ a(b: \"c\nd\") }", wantErr: true},
+		{name: "unterminated block string", input: `{ a(b: """c) }`, wantErr: true},
+		{name: "invalid escape", input: `{ a(b: "\x") }`, wantErr: true},
+		{name: "invalid unicode escape", input: `{ a(b: "\u12") }`, wantErr: true},
+		{name: "invalid number", input: "{ a(b: 1.5e) }", wantErr: true},
+		{name: "invalid spread", input: "{ a { .. b } }", wantErr: true},
+		{name: "unexpected character", input: "{ a(b: 'c') }", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseDocument(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %s, want error", getDocumentString(doc))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := getDocumentString(doc); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBlockStringValue(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"", ""},
+		{"single line", "single line"},
+		{"\n  a\n    b\n  c\n", "a\n  b\nc"},
+		{"first\n    a\n    b", "first\na\nb"},
+		{"\r\n\ta\r\n\r\n\tb\r\n", "a\n\nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := getBlockStringValue(tt.raw); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// returns document in normalized GraphQL notation, operations first and then fragments in order of name
+func getDocumentString(doc document) string {
+	parts := make([]string, 0)
+	for _, op := range doc.operations {
+		var b strings.Builder
+		b.WriteString(op.kind)
+		if op.name != "" {
+			b.WriteString(" " + op.name)
+		}
+		if len(op.variables) != 0 {
+			vars := make([]string, 0)
+			for _, v := range op.variables {
+				s := "$" + v.name
+				if v.nonNull {
+					s += "!"
+				}
+				if v.def != nil {
+					s += " = " + getValueString(*v.def)
+				}
+				vars = append(vars, s)
+			}
+			b.WriteString(fmt.Sprintf("(%s)", strings.Join(vars, ", ")))
+		}
+		b.WriteString(" " + getSelectionsString(op.selections))
+		parts = append(parts, b.String())
+	}
+	for _, name := range slices.Sorted(maps.Keys(doc.fragments)) {
+		f := doc.fragments[name]
+		parts = append(parts, fmt.Sprintf("fragment %s on %s %s", name, f.typeCondition, getSelectionsString(f.selections)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func getSelectionsString(sels []selection) string {
+	parts := make([]string, 0)
+	for _, s := range sels {
+		var b strings.Builder
+		switch s.kind {
+		case selectionFragmentSpread:
+			b.WriteString("..." + s.name)
+		case selectionInlineFragment:
+			b.WriteString("...")
+			if s.typeCondition != "" {
+				b.WriteString(" on " + s.typeCondition)
+			}
+		case selectionField:
+			if s.alias != "" {
+				b.WriteString(s.alias + ": ")
+			}
+			b.WriteString(s.name)
+			if len(s.arguments) != 0 {
+				b.WriteString("(" + getArgumentsString(s.arguments) + ")")
+			}
+		}
+		for _, d := range s.directives {
+			b.WriteString(" @" + d.name)
+			if len(d.arguments) != 0 {
+				b.WriteString("(" + getArgumentsString(d.arguments) + ")")
+			}
+		}
+		if len(s.selections) != 0 {
+			b.WriteString(" " + getSelectionsString(s.selections))
+		}
+		parts = append(parts, b.String())
+	}
+	return fmt.Sprintf("{ %s }", strings.Join(parts, " "))
+}
+
+func getArgumentsString(args []argument) string {
+	parts := make([]string, 0)
+	for _, a := range args {
+		parts = append(parts, fmt.Sprintf("%s: %s", a.name, getValueString(a.value)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func getValueString(v value) string {
+	switch v.kind {
+	case valueVariable:
+		return "$" + v.raw
+	case valueString:
+		return fmt.Sprintf("%q", v.raw)
+	case valueNull:
+		return "null"
+	case valueList:
+		parts := make([]string, 0)
+		for _, item := range v.list {
+			parts = append(parts, getValueString(item))
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	case valueObject:
+		if len(v.fields) == 0 {
+			return "{}"
+		}
+		return "{" + getArgumentsString(v.fields) + "}"
+	}
+	return v.raw
+}
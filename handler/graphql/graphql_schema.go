@@ -0,0 +1,589 @@
+package graphql
+
+import (
+	"fmt"
+	"r3/cache"
+	"r3/schema"
+	"r3/types"
+	"slices"
+
+	"github.com/gofrs/uuid"
+)
+
+// type kinds, as used in GraphQL introspection
+const (
+	kindEnum        = "ENUM"
+	kindInputObject = "INPUT_OBJECT"
+	kindList        = "LIST"
+	kindNonNull     = "NON_NULL"
+	kindObject      = "OBJECT"
+	kindScalar      = "SCALAR"
+)
+
+// field resolvers
+const (
+	resolveAttribute = iota // attribute value of record
+	resolveCount            // root: number of records
+	resolveDel              // root: delete record
+	resolveId               // record ID
+	resolveList             // records of other relation, referring to this record via n:1 attribute
+	resolveObject           // record of other relation, referred to via 1:1/n:1 attribute
+	resolveRecords          // root: records of relation
+	resolveSet              // root: create/update record
+	resolveSingle           // record of other relation, referring to this record via 1:1 attribute
+)
+
+const (
+	depthMax     = 10    // max. depth of nested selections
+	limitDefault = 100   // default number of records for root queries
+	limitMax     = 1000  // max. number of records for root queries & for nested lists of each record
+	objectsMax   = 10000 // max. number of records in a response, including nested records
+)
+
+var (
+	comparisonOperators   = []string{"eq", "ne", "gt", "gte", "lt", "lte", "like", "ilike", "in", "isNull"}
+	comparisonOperatorMap = map[string]string{
+		"eq":    "=",
+		"ne":    "<>",
+		"gt":    ">",
+		"gte":   ">=",
+		"lt":    "<",
+		"lte":   "<=",
+		"like":  "LIKE",
+		"ilike": "ILIKE",
+		"in":    "= ANY",
+	}
+)
+
+// GraphQL schema, generated for a module and the access of a login
+type gqlSchema struct {
+	types     map[string]*gqlType
+	typeNames []string // type names in order of creation
+	query     *gqlType
+	mutation  *gqlType // nil if login cannot change any records
+
+	// introspection objects, created on request
+	intro map[string]map[string]interface{}
+}
+type gqlType struct {
+	kind        string
+	name        string
+	description string
+	fields      []*gqlField      // object fields
+	inputFields []*gqlInputValue // input object fields
+	enumValues  []string
+	ofType      *gqlType // wrapped type of list/non-null
+
+	relationId uuid.UUID // relation, for objects and their inputs
+}
+type gqlField struct {
+	name        string
+	description string
+	args        []*gqlInputValue
+	typ         *gqlType
+
+	resolve    int
+	atr        types.Attribute // attribute value, relationship attribute for nested records
+	relationId uuid.UUID       // relation for root fields
+}
+type gqlInputValue struct {
+	name        string
+	description string
+	typ         *gqlType
+	atr         types.Attribute // attribute to filter/order by or to set
+}
+
+// relation types of a schema
+type relationTypes struct {
+	object *gqlType
+	where  *gqlType
+	order  *gqlType
+	input  *gqlType // nil if no attributes can be set
+}
+
+// generates GraphQL schema from schema cache
+// relations of the module become object types and root query fields, if login has read access
+// relations of other modules are included if referred to via relationship attributes
+// schema cache must be read locked
+func getSchema(mod types.Module, access types.LoginAccess) *gqlSchema {
+
+	s := &gqlSchema{
+		types:     make(map[string]*gqlType),
+		typeNames: make([]string, 0),
+		intro:     make(map[string]map[string]interface{}),
+	}
+
+	var canAccessRelation = func(relationId uuid.UUID, accessRequested types.Access) bool {
+		a, exists := access.Relation[relationId]
+		return exists && a >= accessRequested
+	}
+	var canAccessAttribute = func(atr types.Attribute, accessRequested types.Access) bool {
+		// attribute access overwrites relation access
+		if a, exists := access.Attribute[atr.Id]; exists {
+			return a >= accessRequested
+		}
+		return canAccessRelation(atr.RelationId, accessRequested)
+	}
+
+	// built-in types
+	scalars := make(map[string]*gqlType)
+	for _, name := range []string{"Boolean", "Float", "ID", "Int", "String"} {
+		scalars[name] = s.addType(&gqlType{kind: kindScalar, name: name})
+	}
+	scalars["BigInt"] = s.addType(&gqlType{kind: kindScalar, name: "BigInt",
+		description: "64-bit integer, used for record IDs, relationships and date/time values (unix time)"})
+	scalars["JSON"] = s.addType(&gqlType{kind: kindScalar, name: "JSON",
+		description: "JSON value, used for file, JSON, range and geospatial (GeoJSON) attributes"})
+
+	orderDirection := s.addType(&gqlType{kind: kindEnum, name: "OrderDirection",
+		enumValues: []string{"ASC", "DESC"}})
+
+	comparisons := make(map[string]*gqlType)
+	for _, name := range []string{"BigInt", "Boolean", "Float", "Int", "String"} {
+		t := s.addType(&gqlType{kind: kindInputObject, name: fmt.Sprintf("%sComparison", name),
+			description: fmt.Sprintf("Filter conditions for %s values, multiple conditions must all apply", name)})
+
+		for _, op := range comparisonOperators {
+			iv := &gqlInputValue{name: op, typ: scalars[name]}
+			switch op {
+			case "like", "ilike":
+				if name != "String" {
+					continue
+				}
+				iv.description = "Wildcards (%) are added around the value, unless it includes them already"
+			case "in":
+				iv.typ = listOf(nonNull(scalars[name]))
+			case "isNull":
+				iv.typ = scalars["Boolean"]
+			}
+			t.inputFields = append(t.inputFields, iv)
+		}
+		comparisons[name] = t
+	}
+
+	// relations of module and relations referred to by them (in any module)
+	relIds := make([]uuid.UUID, 0)
+	for _, rel := range mod.Relations {
+		if canAccessRelation(rel.Id, types.AccessRead) {
+			relIds = append(relIds, rel.Id)
+		}
+	}
+	for i := 0; i < len(relIds); i++ {
+		for _, atr := range cache.RelationIdMap[relIds[i]].Attributes {
+			if !atr.RelationshipId.Valid || !canAccessAttribute(atr, types.AccessRead) {
+				continue
+			}
+			target := atr.RelationshipId.Bytes
+			if canAccessRelation(target, types.AccessRead) && !slices.Contains(relIds, target) {
+				relIds = append(relIds, target)
+			}
+		}
+	}
+
+	// create relation types before adding fields, as they can refer to each other
+	relIdMapTypes := make(map[uuid.UUID]relationTypes)
+	for _, relId := range relIds {
+		rel := cache.RelationIdMap[relId]
+		name := getTypeName(mod, rel)
+
+		var description string
+		if rel.Comment.Valid {
+			description = rel.Comment.String
+		}
+
+		relIdMapTypes[relId] = relationTypes{
+			object: s.addType(&gqlType{kind: kindObject, name: name, description: description, relationId: relId}),
+			where: s.addType(&gqlType{kind: kindInputObject, name: fmt.Sprintf("%s_where", name), relationId: relId,
+				description: fmt.Sprintf("Filter for %s records, all given conditions must apply", name)}),
+			order: s.addType(&gqlType{kind: kindInputObject, name: fmt.Sprintf("%s_order", name), relationId: relId,
+				description: fmt.Sprintf("Order for %s records, one attribute per entry", name)}),
+		}
+	}
+
+	for _, relId := range relIds {
+		rel := cache.RelationIdMap[relId]
+		t := relIdMapTypes[relId]
+		atrPk := cache.AttributeIdMap[rel.AttributeIdPk]
+
+		t.object.fields = append(t.object.fields, &gqlField{
+			name:    schema.PkName,
+			typ:     nonNull(scalars["BigInt"]),
+			resolve: resolveId,
+			atr:     atrPk,
+		})
+		t.where.inputFields = append(t.where.inputFields, &gqlInputValue{
+			name: schema.PkName,
+			typ:  comparisons["BigInt"],
+			atr:  atrPk,
+		})
+		t.order.inputFields = append(t.order.inputFields, &gqlInputValue{
+			name: schema.PkName,
+			typ:  orderDirection,
+			atr:  atrPk,
+		})
+
+		input := &gqlType{kind: kindInputObject, name: fmt.Sprintf("%s_input", t.object.name), relationId: relId,
+			description: fmt.Sprintf("Attribute values of %s record, only given attributes are updated", t.object.name)}
+
+		for _, atr := range rel.Attributes {
+			if atr.Id == rel.AttributeIdPk || !canAccessAttribute(atr, types.AccessRead) {
+				continue
+			}
+			scalar := getScalarName(atr)
+
+			// output field
+			f := &gqlField{
+				name:    atr.Name,
+				typ:     scalars[scalar],
+				resolve: resolveAttribute,
+				atr:     atr,
+			}
+			if target, exists := relIdMapTypes[atr.RelationshipId.Bytes]; exists && atr.RelationshipId.Valid {
+				f.typ = target.object
+				f.resolve = resolveObject
+			} else if !atr.Nullable {
+				f.typ = nonNull(f.typ)
+			}
+			t.object.fields = append(t.object.fields, f)
+
+			// encrypted values cannot be compared, files are not set via GraphQL
+			if atr.Encrypted || schema.IsContentFiles(atr.Content) {
+				continue
+			}
+			t.where.inputFields = append(t.where.inputFields, &gqlInputValue{
+				name: atr.Name,
+				typ:  comparisons[scalar],
+				atr:  atr,
+			})
+			t.order.inputFields = append(t.order.inputFields, &gqlInputValue{
+				name: atr.Name,
+				typ:  orderDirection,
+				atr:  atr,
+			})
+			// computed attribute values are generated by the database
+			if canAccessAttribute(atr, types.AccessWrite) && atr.Computed == "" {
+				input.inputFields = append(input.inputFields, &gqlInputValue{
+					name: atr.Name,
+					typ:  scalars[scalar],
+					atr:  atr,
+				})
+			}
+		}
+
+		if len(input.inputFields) != 0 {
+			t.input = s.addType(input)
+			relIdMapTypes[relId] = t
+		}
+
+		// logical combinations of filters
+		for _, name := range []string{"and", "or"} {
+			t.where.inputFields = append(t.where.inputFields, &gqlInputValue{
+				name:        name,
+				description: fmt.Sprintf("Filters, combined with %s", name),
+				typ:         listOf(nonNull(t.where)),
+			})
+		}
+	}
+
+	// records referring to other records via relationship attributes
+	for _, relId := range relIds {
+		t := relIdMapTypes[relId]
+		for _, relIdOther := range relIds {
+			other := relIdMapTypes[relIdOther]
+
+			for _, atr := range cache.RelationIdMap[relIdOther].Attributes {
+				if !atr.RelationshipId.Valid || atr.RelationshipId.Bytes != relId ||
+					!canAccessAttribute(atr, types.AccessRead) {
+
+					continue
+				}
+
+				f := &gqlField{
+					name: fmt.Sprintf("%s_%s", other.object.name, atr.Name),
+					description: fmt.Sprintf("%s records, referring to this record via attribute %s",
+						other.object.name, atr.Name),
+					atr: atr,
+				}
+				if t.object.getField(f.name) != nil {
+					continue
+				}
+				if schema.IsContentRelationship11(atr.Content) {
+					f.typ = other.object
+					f.resolve = resolveSingle
+				} else {
+					f.typ = nonNull(listOf(nonNull(other.object)))
+					f.resolve = resolveList
+					f.args = getListArgs(other, scalars, false)
+				}
+				t.object.fields = append(t.object.fields, f)
+			}
+		}
+	}
+
+	// root types
+	s.query = s.addType(&gqlType{kind: kindObject, name: "Query"})
+	mutation := &gqlType{kind: kindObject, name: "Mutation"}
+
+	for _, rel := range mod.Relations {
+		t, exists := relIdMapTypes[rel.Id]
+		if !exists {
+			continue
+		}
+
+		s.query.fields = append(s.query.fields, &gqlField{
+			name:        t.object.name,
+			description: fmt.Sprintf("%s records, limited to %d by default", t.object.name, limitDefault),
+			args:        getListArgs(t, scalars, true),
+			typ:         nonNull(listOf(nonNull(t.object))),
+			resolve:     resolveRecords,
+			relationId:  rel.Id,
+		})
+		s.query.fields = append(s.query.fields, &gqlField{
+			name:        fmt.Sprintf("%s_count", t.object.name),
+			description: fmt.Sprintf("Number of %s records", t.object.name),
+			args:        []*gqlInputValue{{name: "where", typ: t.where}},
+			typ:         nonNull(scalars["BigInt"]),
+			resolve:     resolveCount,
+			relationId:  rel.Id,
+		})
+
+		if t.input != nil {
+			mutation.fields = append(mutation.fields, &gqlField{
+				name:        fmt.Sprintf("%s_set", t.object.name),
+				description: fmt.Sprintf("Creates %s record (without ID) or updates it, returns record ID", t.object.name),
+				args: []*gqlInputValue{
+					{name: "id", typ: scalars["BigInt"]},
+					{name: "values", typ: nonNull(t.input)},
+				},
+				typ:        nonNull(scalars["BigInt"]),
+				resolve:    resolveSet,
+				relationId: rel.Id,
+			})
+		}
+		if canAccessRelation(rel.Id, types.AccessDelete) {
+			mutation.fields = append(mutation.fields, &gqlField{
+				name:        fmt.Sprintf("%s_del", t.object.name),
+				description: fmt.Sprintf("Deletes %s record", t.object.name),
+				args:        []*gqlInputValue{{name: "id", typ: nonNull(scalars["BigInt"])}},
+				typ:         nonNull(scalars["Boolean"]),
+				resolve:     resolveDel,
+				relationId:  rel.Id,
+			})
+		}
+	}
+	if len(mutation.fields) != 0 {
+		s.mutation = s.addType(mutation)
+	}
+	return s
+}
+
+// arguments for record lists, root lists can be paginated
+func getListArgs(t relationTypes, scalars map[string]*gqlType, isRoot bool) []*gqlInputValue {
+	args := make([]*gqlInputValue, 0)
+	if isRoot {
+		args = append(args, &gqlInputValue{name: "id", typ: scalars["BigInt"]})
+	}
+	args = append(args, []*gqlInputValue{
+		{name: "where", typ: t.where},
+		{name: "orderBy", typ: listOf(nonNull(t.order))},
+		{name: "limit", typ: scalars["Int"]},
+		{name: "offset", typ: scalars["Int"]},
+	}...)
+	if isRoot {
+		args[len(args)-2].description = fmt.Sprintf("Max. number of records, default: %d, max.: %d",
+			limitDefault, limitMax)
+	}
+	return args
+}
+
+// relations of other modules are prefixed with their module name
+func getTypeName(mod types.Module, rel types.Relation) string {
+	if rel.ModuleId == mod.Id {
+		return rel.Name
+	}
+	return fmt.Sprintf("%s_%s", cache.ModuleIdMap[rel.ModuleId].Name, rel.Name)
+}
+
+func getScalarName(atr types.Attribute) string {
+	if atr.Encrypted {
+		return "String"
+	}
+	switch atr.Content {
+	case "integer":
+		return "Int"
+	case "bigint", "1:1", "n:1":
+		return "BigInt"
+	case "numeric", "real", "double precision":
+		return "Float"
+	case "boolean":
+		return "Boolean"
+	case "daterange", "files", "geometry", "jsonb", "point", "tstzrange":
+		return "JSON"
+	}
+	return "String" // enum, varchar, text, regconfig, uuid
+}
+
+// type helpers
+func (s *gqlSchema) addType(t *gqlType) *gqlType {
+	s.types[t.name] = t
+	s.typeNames = append(s.typeNames, t.name)
+	return t
+}
+func (t *gqlType) getField(name string) *gqlField {
+	for _, f := range t.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+func (t *gqlType) getNamedType() *gqlType {
+	for t.ofType != nil {
+		t = t.ofType
+	}
+	return t
+}
+func listOf(t *gqlType) *gqlType {
+	return &gqlType{kind: kindList, ofType: t}
+}
+func nonNull(t *gqlType) *gqlType {
+	return &gqlType{kind: kindNonNull, ofType: t}
+}
+
+// introspection
+func (s *gqlSchema) getIntrospectionSchema() map[string]interface{} {
+	typesIntro := make([]interface{}, 0)
+	for _, name := range s.typeNames {
+		typesIntro = append(typesIntro, s.getIntrospectionType(s.types[name]))
+	}
+
+	var mutationType interface{}
+	if s.mutation != nil {
+		mutationType = s.getIntrospectionType(s.mutation)
+	}
+
+	directives := make([]interface{}, 0)
+	for _, name := range []string{"include", "skip"} {
+		directives = append(directives, map[string]interface{}{
+			"__typename":   "__Directive",
+			"name":         name,
+			"description":  nil,
+			"isRepeatable": false,
+			"locations":    []interface{}{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+			"args": []interface{}{s.getIntrospectionInputValue(&gqlInputValue{
+				name: "if",
+				typ:  nonNull(s.types["Boolean"]),
+			})},
+		})
+	}
+
+	return map[string]interface{}{
+		"__typename":       "__Schema",
+		"description":      nil,
+		"queryType":        s.getIntrospectionType(s.query),
+		"mutationType":     mutationType,
+		"subscriptionType": nil,
+		"types":            typesIntro,
+		"directives":       directives,
+	}
+}
+
+func (s *gqlSchema) getIntrospectionType(t *gqlType) map[string]interface{} {
+	if t == nil {
+		return nil
+	}
+
+	m := map[string]interface{}{
+		"__typename":     "__Type",
+		"kind":           t.kind,
+		"name":           nil,
+		"description":    nil,
+		"specifiedByURL": nil,
+		"fields":         nil,
+		"inputFields":    nil,
+		"interfaces":     nil,
+		"possibleTypes":  nil,
+		"enumValues":     nil,
+		"ofType":         nil,
+	}
+
+	// wrapping types are not named
+	if t.kind == kindList || t.kind == kindNonNull {
+		m["ofType"] = s.getIntrospectionType(t.ofType)
+		return m
+	}
+
+	// named types can refer to themselves, they are created once
+	if mIntro, exists := s.intro[t.name]; exists {
+		return mIntro
+	}
+	s.intro[t.name] = m
+
+	m["name"] = t.name
+	if t.description != "" {
+		m["description"] = t.description
+	}
+
+	switch t.kind {
+	case kindObject:
+		fields := make([]interface{}, 0)
+		for _, f := range t.fields {
+			args := make([]interface{}, 0)
+			for _, iv := range f.args {
+				args = append(args, s.getIntrospectionInputValue(iv))
+			}
+			var description interface{}
+			if f.description != "" {
+				description = f.description
+			}
+			fields = append(fields, map[string]interface{}{
+				"__typename":        "__Field",
+				"name":              f.name,
+				"description":       description,
+				"args":              args,
+				"type":              s.getIntrospectionType(f.typ),
+				"isDeprecated":      false,
+				"deprecationReason": nil,
+			})
+		}
+		m["fields"] = fields
+		m["interfaces"] = make([]interface{}, 0)
+
+	case kindInputObject:
+		inputFields := make([]interface{}, 0)
+		for _, iv := range t.inputFields {
+			inputFields = append(inputFields, s.getIntrospectionInputValue(iv))
+		}
+		m["inputFields"] = inputFields
+
+	case kindEnum:
+		enumValues := make([]interface{}, 0)
+		for _, v := range t.enumValues {
+			enumValues = append(enumValues, map[string]interface{}{
+				"__typename":        "__EnumValue",
+				"name":              v,
+				"description":       nil,
+				"isDeprecated":      false,
+				"deprecationReason": nil,
+			})
+		}
+		m["enumValues"] = enumValues
+	}
+	return m
+}
+
+func (s *gqlSchema) getIntrospectionInputValue(iv *gqlInputValue) map[string]interface{} {
+	var description interface{}
+	if iv.description != "" {
+		description = iv.description
+	}
+	return map[string]interface{}{
+		"__typename":        "__InputValue",
+		"name":              iv.name,
+		"description":       description,
+		"type":              s.getIntrospectionType(iv.typ),
+		"defaultValue":      nil,
+		"isDeprecated":      false,
+		"deprecationReason": nil,
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"mime/multipart"
@@ -31,6 +32,10 @@ const (
 	ContextLicenseUpload     handlerContext = 140
 	ContextManifestDownload  handlerContext = 150
 	ContextWebsocket         handlerContext = 160
+	ContextOdata             handlerContext = 170
+	ContextGraphql           handlerContext = 180
+	ContextGeojsonDownload   handlerContext = 190
+	ContextAcmeChallenge     handlerContext = 200
 )
 
 var (
@@ -51,10 +56,21 @@ var (
 		ContextLicenseUpload:     "license_upload",
 		ContextManifestDownload:  "manifest_download",
 		ContextWebsocket:         "websocket",
+		ContextOdata:             "odata",
+		ContextGraphql:           "graphql",
+		ContextGeojsonDownload:   "geojson_download",
+		ContextAcmeChallenge:     "acme_challenge",
 	}
 	NoImage []byte
 )
 
+// returns TLS client certificate of request, if verified by the web server
+func GetCertClient(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
 func GetStringFromPart(part *multipart.Part) string {
 	buf := new(bytes.Buffer)
 	buf.ReadFrom(part)
@@ -32,6 +32,7 @@ const (
 	ErrContextDbs = "DBS"
 	ErrContextLic = "LIC"
 	ErrContextSec = "SEC"
+	ErrContextVal = "VAL"
 
 	// error codes
 	ErrCodeAppUnknown               int = 1
@@ -43,12 +44,14 @@ const (
 	ErrCodeAppUnknownModule         int = 7
 	ErrCodeAppUnknownRelation       int = 8
 	ErrCodeAppUnknownAttribute      int = 9
+	ErrCodeAppRecordVersionConflict int = 10
 	ErrCodeCsvParseInt              int = 1
 	ErrCodeCsvParseFloat            int = 2
 	ErrCodeCsvParseDateTime         int = 3
 	ErrCodeCsvBadAttributeType      int = 4
 	ErrCodeCsvWrongFieldNumber      int = 5
 	ErrCodeCsvEncryptedAttribute    int = 6
+	ErrCodeCsvParseJson             int = 7
 	ErrCodeDbsFunctionMessage       int = 1
 	ErrCodeDbsConstraintUnique      int = 2
 	ErrCodeDbsConstraintUniqueLogin int = 3
@@ -62,13 +65,25 @@ const (
 	ErrCodeSecUnauthorized          int = 1
 	ErrCodeSecDataKeysNotAvailable  int = 5
 	ErrCodeSecNoPublicKeys          int = 6
+	ErrCodeSecPwReused              int = 8
+	ErrCodeSecPwBreached            int = 9
+	ErrCodeSecPwComplexity          int = 10
+	ErrCodeValRequired              int = 1
+	ErrCodeValMinValue              int = 2
+	ErrCodeValMaxValue              int = 3
+	ErrCodeValMinLength             int = 4
+	ErrCodeValMaxLength             int = 5
+	ErrCodeValRegex                 int = 6
+	ErrCodeValPgFunction            int = 7
 )
 
 var (
 	// errors
-	errContexts     = []string{ErrContextApp, ErrContextCsv, ErrContextDbs, ErrContextLic, ErrContextSec}
+	errContexts     = []string{ErrContextApp, ErrContextCsv, ErrContextDbs, ErrContextLic, ErrContextSec, ErrContextVal}
 	errCodeDbsCache = regexp.MustCompile(fmt.Sprintf("^{ERR_DBS_%03d}", ErrCodeDbsChangedCachePlan))
 	errCodeLicRx    = regexp.MustCompile(`^{ERR_LIC_(\d{3})}`)
+	errCodePwRx     = regexp.MustCompile(fmt.Sprintf("^{ERR_SEC_(%03d|%03d|%03d)}",
+		ErrCodeSecPwReused, ErrCodeSecPwBreached, ErrCodeSecPwComplexity))
 	errCodeRx       = regexp.MustCompile(`^{ERR_([A-Z]{3})_(\d{3})}`)
 	errExpectedList = []errExpected{
 
@@ -213,6 +228,9 @@ func ConvertToErrCode(err error, anonymizeIfUnexpected bool) (error, bool) {
 func CheckForLicenseErrCode(err error) bool {
 	return errCodeLicRx.MatchString(err.Error())
 }
+func CheckForPwErrCode(err error) bool {
+	return errCodePwRx.MatchString(err.Error())
+}
 func CheckForDbsCacheErrCode(err error) bool {
 	return errCodeDbsCache.MatchString(err.Error())
 }
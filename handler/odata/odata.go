@@ -0,0 +1,419 @@
+package odata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"r3/bruteforce"
+	"r3/cache"
+	"r3/config"
+	"r3/data"
+	"r3/data/data_query"
+	"r3/db"
+	"r3/handler"
+	"r3/log"
+	"r3/login/login_auth"
+	"r3/types"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	regexEntityKey = regexp.MustCompile(`^(.+)\((\d+)\)$`)
+)
+
+/*
+OData v4 read service for module APIs, such as:
+GET /odata/lsw_invoices/contracts/v1/                      service document
+GET /odata/lsw_invoices/contracts/v1/$metadata             CSDL schema
+GET /odata/lsw_invoices/contracts/v1/contracts             entity set, supports $filter, $select, $orderby, $top, $skip, $count
+GET /odata/lsw_invoices/contracts/v1/contracts/$count      number of entities
+GET /odata/lsw_invoices/contracts/v1/contracts(45)         single entity by record ID
+
+Authentication via API token (Bearer) or username/password (Basic, for BI tools)
+*/
+func Handler(w http.ResponseWriter, r *http.Request) {
+
+	if blocked := bruteforce.Check(r); blocked {
+		handler.AbortRequestNoLog(w, handler.ErrBruteforceBlock)
+		return
+	}
+
+	if r.Method != "GET" {
+		abort(w, http.StatusMethodNotAllowed, nil, "invalid HTTP method, allowed: GET")
+		return
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(),
+		time.Duration(int64(config.GetUint64("dbTimeoutDataRest")))*time.Second)
+
+	defer ctxCanc()
+
+	login, err := authenticate(ctx, r)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="REI3", charset="UTF-8"`)
+		abort(w, http.StatusUnauthorized, err, handler.ErrUnauthorized)
+		bruteforce.BadAttempt(r)
+		return
+	}
+
+	// process path elements
+	// 0 is empty, 1 = "odata", 2 = MODULE_NAME, 3 = API_NAME, 4 = API_VERSION, 5 = RESOURCE, 6 = $count (optional)
+	elements := strings.Split(r.URL.Path, "/")
+	if len(elements) < 5 || len(elements) > 7 || !strings.HasPrefix(elements[4], "v") {
+		abort(w, http.StatusBadRequest, nil, "invalid URL, expected: /odata/APP_NAME/API_NAME/VERSION/")
+		return
+	}
+	modName := elements[2]
+	apiName := elements[3]
+	version, err := strconv.Atoi(elements[4][1:])
+	if err != nil {
+		abort(w, http.StatusBadRequest, err, fmt.Sprintf("invalid API version format '%s', expected: 'v12'", elements[4]))
+		return
+	}
+	resource := ""
+	if len(elements) > 5 {
+		resource = elements[5]
+	}
+	isCount := len(elements) == 7 && elements[6] == "$count"
+	if len(elements) == 7 && !isCount {
+		abort(w, http.StatusNotFound, nil, fmt.Sprintf("unknown resource '%s'", elements[6]))
+		return
+	}
+
+	log.Info(log.ContextApi, fmt.Sprintf("'%s.%s' (v%d) is called via OData (resource: '%s')",
+		modName, apiName, version, resource))
+
+	cache.Schema_mx.RLock()
+	defer cache.Schema_mx.RUnlock()
+
+	apiId, exists := cache.ModuleApiNameMapId[modName][fmt.Sprintf("%s.v%d", apiName, version)]
+	if !exists {
+		abort(w, http.StatusNotFound, nil, fmt.Sprintf("API '%s.%s' (v%d) does not exist", modName, apiName, version))
+		return
+	}
+	api := cache.ApiIdMap[apiId]
+
+	if !api.HasGet {
+		abort(w, http.StatusBadRequest, nil, "HTTP method 'GET' is not supported by this API")
+		return
+	}
+	if !api.Query.RelationId.Valid {
+		abort(w, http.StatusServiceUnavailable, nil, "query has no base relation")
+		return
+	}
+
+	// check role access
+	access, err := cache.GetAccessById(login.Id)
+	if err != nil {
+		abort(w, http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+	if _, exists := access.Api[api.Id]; !exists {
+		abort(w, http.StatusForbidden, nil, handler.ErrUnauthorized)
+		return
+	}
+
+	props := getProperties(api)
+	serviceRoot := getServiceRoot(r, modName, api.Name, version)
+
+	w.Header().Set("OData-Version", "4.0")
+
+	// service document & metadata
+	if resource == "" {
+		writeJson(w, map[string]interface{}{
+			"@odata.context": serviceRoot + "$metadata",
+			"value": []map[string]string{{
+				"name": api.Name,
+				"kind": "EntitySet",
+				"url":  api.Name,
+			}},
+		})
+		return
+	}
+	if resource == "$metadata" {
+		out, err := getMetadata(modName, api, props)
+		if err != nil {
+			abort(w, http.StatusServiceUnavailable, err, handler.ErrGeneral)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write(out)
+		return
+	}
+
+	// entity set or single entity
+	var recordId int64
+	if m := regexEntityKey.FindStringSubmatch(resource); len(m) == 3 {
+		resource = m[1]
+		recordId, _ = strconv.ParseInt(m[2], 10, 64)
+	}
+	if resource != api.Name {
+		abort(w, http.StatusNotFound, nil, fmt.Sprintf("unknown resource '%s'", resource))
+		return
+	}
+
+	// parse query options
+	// custom query options (without $) are available as getters in API query filters, like in regular API calls
+	var options struct {
+		count   bool
+		filters []types.DataGetFilter
+		orders  []types.DataGetOrder
+		selects []property
+		skip    int
+		top     int
+		topSet  bool
+	}
+	options.selects = props
+	getters := make(map[string]string)
+
+	for name, values := range r.URL.Query() {
+		if len(values) != 1 {
+			continue
+		}
+		value := values[0]
+
+		if !strings.HasPrefix(name, "$") {
+			getters[name] = value
+			continue
+		}
+
+		var err error
+		switch name {
+		case "$count":
+			options.count, err = strconv.ParseBool(value)
+		case "$filter":
+			options.filters, err = getFilters(value, props)
+		case "$orderby":
+			options.orders, err = getOrders(value, props, 0)
+		case "$select":
+			options.selects, err = getSelect(value, props)
+		case "$skip":
+			options.skip, err = strconv.Atoi(value)
+		case "$top":
+			options.top, err = strconv.Atoi(value)
+			options.topSet = true
+		case "$format":
+			if value != "json" && !strings.HasPrefix(value, "application/json") {
+				err = errors.New("only JSON format is supported")
+			}
+		default:
+			err = errors.New("query option is not supported")
+		}
+		if err != nil {
+			abort(w, http.StatusBadRequest, err, fmt.Sprintf("invalid query option %s: %s", name, err))
+			return
+		}
+	}
+
+	dataGet := types.DataGet{
+		RelationId:  api.Query.RelationId.Bytes,
+		IndexSource: 0,
+		Limit:       api.LimitDef,
+		Offset:      options.skip,
+	}
+	if options.topSet {
+		dataGet.Limit = options.top
+	}
+	if isCount {
+		dataGet.Limit = 1
+		dataGet.Offset = 0
+	}
+	if api.Query.FixedLimit != 0 && api.Query.FixedLimit < dataGet.Limit {
+		dataGet.Limit = api.Query.FixedLimit
+	}
+	if api.LimitMax < dataGet.Limit {
+		abort(w, http.StatusBadRequest, nil, fmt.Sprintf("max. result limit is: %d", api.LimitMax))
+		return
+	}
+	if dataGet.Limit < 1 || dataGet.Offset < 0 {
+		abort(w, http.StatusBadRequest, nil, "invalid result limit or offset")
+		return
+	}
+
+	// resolve relation joins
+	for _, join := range api.Query.Joins {
+		if join.Index == 0 {
+			continue
+		}
+		dataGet.Joins = append(dataGet.Joins, types.DataGetJoin{
+			AttributeId: join.AttributeId.Bytes,
+			Index:       join.Index,
+			IndexFrom:   join.IndexFrom,
+			Connector:   join.Connector,
+		})
+	}
+
+	for _, column := range api.Columns {
+		dataGet.Expressions = append(dataGet.Expressions, data_query.ConvertColumnToExpression(
+			column, login.Id, login.LanguageCode, getters))
+	}
+
+	// API query filters apply first, OData filters are enclosed in brackets
+	dataGet.Filters = data_query.ConvertQueryToDataFilter(
+		api.Query.Filters, login.Id, login.LanguageCode, getters)
+
+	dataGet.Filters = append(dataGet.Filters, options.filters...)
+
+	if recordId != 0 {
+		dataGet.Filters = append(dataGet.Filters, types.DataGetFilter{
+			Connector: "AND",
+			Index:     0,
+			Operator:  "=",
+			Side0: types.DataGetFilterSide{
+				AttributeId: pgtype.UUID{
+					Bytes: cache.RelationIdMap[api.Query.RelationId.Bytes].AttributeIdPk,
+					Valid: true,
+				},
+			},
+			Side1: types.DataGetFilterSide{Value: recordId},
+		})
+	}
+
+	// requested order first, API query order keeps results stable
+	dataGet.Orders = append(options.orders, data_query.ConvertQueryToDataOrders(api.Query.Orders)...)
+
+	// execute request
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		abort(w, http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if err := db.SetSessionConfig_tx(ctx, tx, login.Id); err != nil {
+		abort(w, http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+
+	var query string
+	results, count, err := data.Get_tx(ctx, tx, dataGet, login.Id, &query)
+	if err != nil {
+		if err.Error() == handler.ErrUnauthorized {
+			abort(w, http.StatusUnauthorized, err, handler.ErrUnauthorized)
+			return
+		}
+		abort(w, http.StatusServiceUnavailable, err, err.Error())
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		abort(w, http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+
+	if isCount {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strconv.FormatInt(count, 10)))
+		return
+	}
+
+	// parse output
+	rows := make([]map[string]interface{}, 0)
+	for _, result := range results {
+		row := make(map[string]interface{})
+		for _, p := range options.selects {
+			if p.position == -1 {
+				row[p.name] = result.IndexRecordIds[0]
+				continue
+			}
+			row[p.name] = getPropertyValue(p, result.Values[p.position])
+		}
+		rows = append(rows, row)
+	}
+
+	if recordId != 0 {
+		if len(rows) == 0 {
+			abort(w, http.StatusNotFound, nil, fmt.Sprintf("entity %d does not exist", recordId))
+			return
+		}
+		rows[0]["@odata.context"] = fmt.Sprintf("%s$metadata#%s/$entity", serviceRoot, api.Name)
+		writeJson(w, rows[0])
+		return
+	}
+
+	res := map[string]interface{}{
+		"@odata.context": fmt.Sprintf("%s$metadata#%s", serviceRoot, api.Name),
+		"value":          rows,
+	}
+	if options.count {
+		res["@odata.count"] = count
+	}
+
+	// server-driven paging, if client did not request a specific number of results
+	if !options.topSet && len(rows) == dataGet.Limit {
+		next := r.URL.Query()
+		next.Set("$skip", strconv.Itoa(dataGet.Offset+dataGet.Limit))
+		res["@odata.nextLink"] = fmt.Sprintf("%s%s?%s", serviceRoot, api.Name, next.Encode())
+	}
+	writeJson(w, res)
+}
+
+// authenticates via API token (Bearer) or username/password (Basic)
+func authenticate(ctx context.Context, r *http.Request) (types.LoginAuthResult, error) {
+	if username, password, ok := r.BasicAuth(); ok {
+		// credentials authenticate each request, no token is issued
+		login, err := login_auth.User(ctx, username, password, types.LoginPasswordNew{}, pgtype.Int4{},
+			pgtype.Text{}, pgtype.Text{}, types.LoginWebAuthnAssertion{}, false)
+		if err != nil {
+			return login, err
+		}
+		if login.Passwordless {
+			return login, errors.New("failed to authenticate, password not given")
+		}
+		if login.PwExpired {
+			return login, errors.New("failed to authenticate, password has expired and must be changed")
+		}
+		if login.Id == 0 {
+			return login, errors.New("failed to authenticate, MFA is currently not supported")
+		}
+		return login, nil
+	}
+	return login_auth.TokenHttp(ctx, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), "odata", r)
+}
+
+func getServiceRoot(r *http.Request, modName string, apiName string, version int) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/odata/%s/%s/v%d/", scheme, r.Host,
+		url.PathEscape(modName), url.PathEscape(apiName), version)
+}
+
+func writeJson(w http.ResponseWriter, payload interface{}) {
+	payloadJson, err := json.Marshal(payload)
+	if err != nil {
+		abort(w, http.StatusServiceUnavailable, err, handler.ErrGeneral)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json;odata.metadata=minimal")
+	w.WriteHeader(http.StatusOK)
+	w.Write(payloadJson)
+}
+
+// aborts request with OData error response
+func abort(w http.ResponseWriter, httpCode int, errToLog error, errMsgUser string) {
+	if errToLog == nil {
+		errToLog = errors.New(errMsgUser)
+	}
+	log.Error(log.ContextServer, fmt.Sprintf("aborted %s request",
+		handler.ContextNameMap[handler.ContextOdata]), errToLog)
+
+	payloadJson, _ := json.Marshal(map[string]interface{}{
+		"error": map[string]string{
+			"code":    strconv.Itoa(httpCode),
+			"message": errMsgUser,
+		},
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(httpCode)
+	w.Write(payloadJson)
+}
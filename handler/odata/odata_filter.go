@@ -0,0 +1,428 @@
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"r3/types"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	operatorMap = map[string]string{
+		"eq": "=",
+		"ne": "<>",
+		"gt": ">",
+		"ge": ">=",
+		"lt": "<",
+		"le": "<=",
+	}
+	likeFunctions = []string{"contains", "endswith", "startswith"}
+)
+
+// filter expression token
+type token struct {
+	value    string
+	isString bool // quoted string literal
+}
+
+// splits filter expression into tokens: brackets, commas, string literals and words
+func getTokens(input string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == ' ' || c == '\t':
+			continue
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, token{value: string(c)})
+		case c == '\'':
+			// string literal, single quotes are escaped by doubling them
+			var b strings.Builder
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						b.WriteRune('\'')
+						i++
+						continue
+					}
+					closed = true
+					break
+				}
+				b.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, errors.New("unterminated string literal")
+			}
+			tokens = append(tokens, token{value: b.String(), isString: true})
+		default:
+			start := i
+			for i < len(runes) && !strings.ContainsRune(" \t(),'", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{value: string(runes[start:i])})
+			i--
+		}
+	}
+	return tokens, nil
+}
+
+// parses OData $filter expression into data GET filters
+// supported: comparisons (eq, ne, gt, ge, lt, le), 'in' lists, contains/startswith/endswith,
+// null comparisons, 'and'/'or' connectors and brackets
+// resulting filters are enclosed in brackets, to be combined with other filters
+func getFilters(input string, props []property) ([]types.DataGetFilter, error) {
+	tokens, err := getTokens(input)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make([]types.DataGetFilter, 0)
+	pos := 0
+	depth := 0
+	connector := "AND"
+
+	var next = func() (token, error) {
+		if pos >= len(tokens) {
+			return token{}, errors.New("unexpected end of filter expression")
+		}
+		pos++
+		return tokens[pos-1], nil
+	}
+	var expect = func(value string) error {
+		t, err := next()
+		if err != nil {
+			return err
+		}
+		if t.isString || t.value != value {
+			return fmt.Errorf("expected '%s', got '%s'", value, t.value)
+		}
+		return nil
+	}
+
+	for {
+		// opening brackets
+		bracketsOpen := 0
+		for pos < len(tokens) && !tokens[pos].isString && tokens[pos].value == "(" {
+			bracketsOpen++
+			pos++
+		}
+		depth += bracketsOpen
+
+		t, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if t.isString {
+			return nil, fmt.Errorf("expected property or function, got '%s'", t.value)
+		}
+
+		filter := types.DataGetFilter{
+			Connector: connector,
+			Index:     0,
+		}
+		name := strings.ToLower(t.value)
+
+		switch {
+		case name == "not":
+			return nil, errors.New("operator 'not' is not supported")
+
+		case slices.Contains(likeFunctions, name):
+			// function(property,'value')
+			if err := expect("("); err != nil {
+				return nil, err
+			}
+			tp, err := next()
+			if err != nil {
+				return nil, err
+			}
+			p, err := getFilterProperty(tp.value, props)
+			if err != nil {
+				return nil, err
+			}
+			if err := expect(","); err != nil {
+				return nil, err
+			}
+			tv, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if !tv.isString {
+				return nil, fmt.Errorf("function '%s' requires a string literal", name)
+			}
+			if err := expect(")"); err != nil {
+				return nil, err
+			}
+
+			// escape wildcard characters of input
+			v := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(tv.value)
+			switch name {
+			case "contains":
+				v = fmt.Sprintf("%%%s%%", v)
+			case "endswith":
+				v = fmt.Sprintf("%%%s", v)
+			case "startswith":
+				v = fmt.Sprintf("%s%%", v)
+			}
+			filter.Operator = "LIKE"
+			filter.Side0 = getFilterSideProperty(p)
+			filter.Side1 = types.DataGetFilterSide{Value: v}
+
+		default:
+			// property operator value
+			p, err := getFilterProperty(t.value, props)
+			if err != nil {
+				return nil, err
+			}
+			to, err := next()
+			if err != nil {
+				return nil, err
+			}
+			filter.Side0 = getFilterSideProperty(p)
+			op := strings.ToLower(to.value)
+
+			if op == "in" {
+				if err := expect("("); err != nil {
+					return nil, err
+				}
+				values := make([]interface{}, 0)
+				for {
+					tv, err := next()
+					if err != nil {
+						return nil, err
+					}
+					v, err := getLiteralValue(tv, p)
+					if err != nil {
+						return nil, err
+					}
+					values = append(values, v)
+
+					ts, err := next()
+					if err != nil {
+						return nil, err
+					}
+					if ts.value == ")" && !ts.isString {
+						break
+					}
+					if ts.value != "," || ts.isString {
+						return nil, fmt.Errorf("expected ',' or ')', got '%s'", ts.value)
+					}
+				}
+				filter.Operator = "= ANY"
+				filter.Side1 = types.DataGetFilterSide{Value: values}
+				break
+			}
+
+			operator, exists := operatorMap[op]
+			if !exists || to.isString {
+				return nil, fmt.Errorf("unsupported operator '%s'", to.value)
+			}
+			tv, err := next()
+			if err != nil {
+				return nil, err
+			}
+
+			if !tv.isString && tv.value == "null" {
+				switch op {
+				case "eq":
+					filter.Operator = "IS NULL"
+				case "ne":
+					filter.Operator = "IS NOT NULL"
+				default:
+					return nil, fmt.Errorf("operator '%s' cannot be used with null", op)
+				}
+				break
+			}
+
+			v, err := getLiteralValue(tv, p)
+			if err != nil {
+				return nil, err
+			}
+			filter.Operator = operator
+			filter.Side1 = types.DataGetFilterSide{Value: v}
+		}
+
+		// closing brackets
+		bracketsClose := 0
+		for pos < len(tokens) && !tokens[pos].isString && tokens[pos].value == ")" && depth > 0 {
+			bracketsClose++
+			depth--
+			pos++
+		}
+		filter.Side0.Brackets = bracketsOpen
+		filter.Side1.Brackets = bracketsClose
+		filters = append(filters, filter)
+
+		if pos >= len(tokens) {
+			break
+		}
+
+		// connector to next clause
+		tc, err := next()
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(tc.value) {
+		case "and":
+			connector = "AND"
+		case "or":
+			connector = "OR"
+		default:
+			return nil, fmt.Errorf("expected 'and' or 'or', got '%s'", tc.value)
+		}
+		if tc.isString {
+			return nil, fmt.Errorf("expected 'and' or 'or', got '%s'", tc.value)
+		}
+	}
+
+	if depth != 0 {
+		return nil, errors.New("unbalanced brackets in filter expression")
+	}
+
+	// enclose all filter clauses, to combine them with filters of API query
+	filters[0].Connector = "AND"
+	filters[0].Side0.Brackets++
+	filters[len(filters)-1].Side1.Brackets++
+	return filters, nil
+}
+
+// parses OData $orderby expression into data GET orders
+func getOrders(input string, props []property, apiRelationIndex int) ([]types.DataGetOrder, error) {
+	orders := make([]types.DataGetOrder, 0)
+
+	for _, item := range strings.Split(input, ",") {
+		words := strings.Fields(item)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, fmt.Errorf("invalid order expression '%s'", item)
+		}
+		p, err := getProperty(words[0], props)
+		if err != nil {
+			return nil, err
+		}
+
+		order := types.DataGetOrder{Ascending: true}
+		if len(words) == 2 {
+			switch strings.ToLower(words[1]) {
+			case "asc":
+			case "desc":
+				order.Ascending = false
+			default:
+				return nil, fmt.Errorf("invalid order direction '%s'", words[1])
+			}
+		}
+
+		if p.position == -1 {
+			// key without column
+			order.AttributeId = pgtype.UUID{Bytes: p.atr.Id, Valid: true}
+			order.Index = pgtype.Int4{Int32: int32(apiRelationIndex), Valid: true}
+		} else {
+			order.ExpressionPos = pgtype.Int4{Int32: int32(p.position), Valid: true}
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// parses OData $select expression, returns selected properties in request order
+func getSelect(input string, props []property) ([]property, error) {
+	if strings.TrimSpace(input) == "*" {
+		return props, nil
+	}
+	out := make([]property, 0)
+	for _, name := range strings.Split(input, ",") {
+		p, err := getProperty(strings.TrimSpace(name), props)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// helpers
+func getProperty(name string, props []property) (property, error) {
+	for _, p := range props {
+		if p.name == name {
+			return p, nil
+		}
+	}
+	return property{}, fmt.Errorf("unknown property '%s'", name)
+}
+func getFilterProperty(name string, props []property) (property, error) {
+	p, err := getProperty(name, props)
+	if err != nil {
+		return p, err
+	}
+	if !p.isKey && !p.filterable {
+		return p, fmt.Errorf("property '%s' cannot be filtered", name)
+	}
+	if p.atr.Encrypted {
+		return p, fmt.Errorf("property '%s' is encrypted and cannot be filtered", name)
+	}
+	return p, nil
+}
+func getFilterSideProperty(p property) types.DataGetFilterSide {
+	index := 0
+	if !p.isKey {
+		index = p.column.Index
+	}
+	return types.DataGetFilterSide{
+		AttributeId:    pgtype.UUID{Bytes: p.atr.Id, Valid: true},
+		AttributeIndex: index,
+	}
+}
+
+// converts literal from filter expression to value of property type
+func getLiteralValue(t token, p property) (interface{}, error) {
+	if !t.isString && t.value == "null" {
+		return nil, nil
+	}
+
+	var err error
+	var v interface{}
+	var parseTime = func(layout string) (int64, error) {
+		tm, err := time.Parse(layout, t.value)
+		if err != nil {
+			return 0, err
+		}
+		return tm.Unix(), nil
+	}
+
+	switch p.edmType {
+	case "Edm.Boolean":
+		v, err = strconv.ParseBool(t.value)
+	case "Edm.Int32", "Edm.Int64":
+		v, err = strconv.ParseInt(t.value, 10, 64)
+	case "Edm.Decimal", "Edm.Double", "Edm.Single":
+		v, err = strconv.ParseFloat(t.value, 64)
+	case "Edm.Date":
+		v, err = parseTime("2006-01-02")
+	case "Edm.DateTimeOffset":
+		v, err = parseTime(time.RFC3339)
+	case "Edm.TimeOfDay":
+		var tm time.Time
+		tm, err = time.Parse("15:04:05", t.value)
+		v = int64(tm.Hour()*3600 + tm.Minute()*60 + tm.Second())
+	case "Edm.Guid":
+		v = t.value
+	default:
+		if !t.isString {
+			return nil, fmt.Errorf("property '%s' requires a string literal", p.name)
+		}
+		v = t.value
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid value '%s' for property '%s'", t.value, p.name)
+	}
+	if t.isString && p.edmType != "Edm.String" && p.edmType != "Edm.Guid" {
+		return nil, fmt.Errorf("property '%s' does not accept string literals", p.name)
+	}
+	return v, nil
+}
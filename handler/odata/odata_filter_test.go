@@ -0,0 +1,162 @@
+package odata
+
+import (
+	"fmt"
+	"r3/types"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestGetFilters(t *testing.T) {
+	props := []property{
+		{name: "id", edmType: "Edm.Int64", isKey: true, position: -1},
+		{name: "name", edmType: "Edm.String", filterable: true, position: 0, column: types.Column{Index: 0}},
+		{name: "amount", edmType: "Edm.Decimal", filterable: true, position: 1, column: types.Column{Index: 1}},
+		{name: "date", edmType: "Edm.Date", filterable: true, position: 2, column: types.Column{Index: 1}},
+		{name: "active", edmType: "Edm.Boolean", filterable: true, position: 3},
+		{name: "secret", edmType: "Edm.String", filterable: true, position: 4, atr: types.Attribute{Encrypted: true}},
+		{name: "total", edmType: "Edm.Int64", filterable: false, position: 5},
+	}
+	atrIdMapName := make(map[uuid.UUID]string)
+	for i := range props {
+		props[i].atr.Id = uuid.Must(uuid.NewV4())
+		atrIdMapName[props[i].atr.Id] = props[i].name
+	}
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		// valid expressions
+		{input: "name eq 'Jane'", want: "(name@0 = Jane)"},
+		{input: "name eq 'O''Brien'", want: "(name@0 = O'Brien)"},
+		{input: "name EQ 'a b' ", want: "(name@0 = a b)"},
+		{input: "amount gt 10.5 and active eq true", want: "(amount@1 > 10.5 AND active@0 = true)"},
+		{input: "amount le -1", want: "(amount@1 <= -1)"},
+		{input: "date ge 2024-01-31", want: "(date@1 >= 1706659200)"},
+		{input: "id eq 5 or (name ne null and amount lt 3)", want: "(id@0 = 5 OR (name@0 IS NOT NULL AND amount@1 < 3))"},
+		{input: "((id eq 1)) and name eq null", want: "(((id@0 = 1)) AND name@0 IS NULL)"},
+		{input: "id in (1,2, 3)", want: "(id@0 = ANY [1 2 3])"},
+		{input: "name in ('a',null)", want: "(name@0 = ANY [a <nil>])"},
+		{input: "contains(name,'50%_off')", want: `(name@0 LIKE %50\%\_off%)`},
+		{input: "startswith(name,'J') or endswith(name,'e')", want: "(name@0 LIKE J% OR name@0 LIKE %e)"},
+
+		// invalid expressions
+		{input: "", wantErr: true},
+		{input: "name", wantErr: true},
+		{input: "name eq", wantErr: true},
+		{input: "name eq 'Jane", wantErr: true},
+		{input: "name eq Jane", wantErr: true},
+		{input: "Name eq 'Jane'", wantErr: true},
+		{input: "unknown eq 1", wantErr: true},
+		{input: "secret eq 'x'", wantErr: true},
+		{input: "total eq 1", wantErr: true},
+		{input: "amount eq 'x'", wantErr: true},
+		{input: "amount eq '5'", wantErr: true},
+		{input: "active eq yes", wantErr: true},
+		{input: "date eq 31.01.2024", wantErr: true},
+		{input: "amount gt null", wantErr: true},
+		{input: "name like 'x'", wantErr: true},
+		{input: "not name eq 'x'", wantErr: true},
+		{input: "(name eq 'x'", wantErr: true},
+		{input: "name eq 'x')", wantErr: true},
+		{input: "name eq 'x' xor id eq 1", wantErr: true},
+		{input: "name eq 'x' 'and' id eq 1", wantErr: true},
+		{input: "id in (1,2", wantErr: true},
+		{input: "id in (1;2)", wantErr: true},
+		{input: "contains(name,5)", wantErr: true},
+		{input: "contains(name 'x')", wantErr: true},
+		{input: "'name' eq 'x'", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			filters, err := getFilters(tt.input, props)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %s, want error", getFiltersString(filters, atrIdMapName))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := getFiltersString(filters, atrIdMapName); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetOrders(t *testing.T) {
+	props := []property{
+		{name: "id", isKey: true, position: -1},
+		{name: "name", position: 0},
+		{name: "amount", position: 1},
+	}
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "name", want: "pos0 ASC"},
+		{input: "amount desc, name asc", want: "pos1 DESC, pos0 ASC"},
+		{input: "id DESC", want: "index2 DESC"},
+		{input: "", wantErr: true},
+		{input: "name,", wantErr: true},
+		{input: "unknown", wantErr: true},
+		{input: "name down", wantErr: true},
+		{input: "name asc desc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			orders, err := getOrders(tt.input, props, 2)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			parts := make([]string, 0)
+			for _, o := range orders {
+				direction := "ASC"
+				if !o.Ascending {
+					direction = "DESC"
+				}
+				if o.ExpressionPos.Valid {
+					parts = append(parts, fmt.Sprintf("pos%d %s", o.ExpressionPos.Int32, direction))
+				} else {
+					parts = append(parts, fmt.Sprintf("index%d %s", o.Index.Int32, direction))
+				}
+			}
+			if got := strings.Join(parts, ", "); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// returns readable form of filters: brackets, connectors, property@index, operator & value
+func getFiltersString(filters []types.DataGetFilter, atrIdMapName map[uuid.UUID]string) string {
+	var b strings.Builder
+	for i, f := range filters {
+		if i != 0 {
+			b.WriteString(fmt.Sprintf(" %s ", f.Connector))
+		}
+		b.WriteString(strings.Repeat("(", f.Side0.Brackets))
+		b.WriteString(fmt.Sprintf("%s@%d %s", atrIdMapName[f.Side0.AttributeId.Bytes],
+			f.Side0.AttributeIndex, f.Operator))
+
+		if f.Operator != "IS NULL" && f.Operator != "IS NOT NULL" {
+			b.WriteString(fmt.Sprintf(" %v", f.Side1.Value))
+		}
+		b.WriteString(strings.Repeat(")", f.Side1.Brackets))
+	}
+	return b.String()
+}
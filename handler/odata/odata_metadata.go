@@ -0,0 +1,264 @@
+package odata
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"r3/cache"
+	"r3/schema"
+	"r3/types"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// entity property, generated from API column
+type property struct {
+	name       string
+	edmType    string
+	nullable   bool
+	filterable bool // attribute value can be filtered on, not possible for sub query columns
+	isKey      bool // record ID of base relation
+	position   int  // expression position in data GET request, -1 for key without column
+	column     types.Column
+	atr        types.Attribute
+}
+
+// CSDL (common schema definition language) document, XML representation
+type edmx struct {
+	XMLName      xml.Name         `xml:"edmx:Edmx"`
+	XmlnsEdmx    string           `xml:"xmlns:edmx,attr"`
+	Version      string           `xml:"Version,attr"`
+	DataServices edmxDataServices `xml:"edmx:DataServices"`
+}
+type edmxDataServices struct {
+	Schema edmSchema `xml:"Schema"`
+}
+type edmSchema struct {
+	Xmlns           string             `xml:"xmlns,attr"`
+	Namespace       string             `xml:"Namespace,attr"`
+	EntityType      edmEntityType      `xml:"EntityType"`
+	EntityContainer edmEntityContainer `xml:"EntityContainer"`
+}
+type edmEntityType struct {
+	Name       string        `xml:"Name,attr"`
+	Key        edmKey        `xml:"Key"`
+	Properties []edmProperty `xml:"Property"`
+}
+type edmKey struct {
+	PropertyRef edmPropertyRef `xml:"PropertyRef"`
+}
+type edmPropertyRef struct {
+	Name string `xml:"Name,attr"`
+}
+type edmProperty struct {
+	Name      string `xml:"Name,attr"`
+	Type      string `xml:"Type,attr"`
+	Nullable  bool   `xml:"Nullable,attr"`
+	Precision int    `xml:"Precision,attr,omitempty"`
+	Scale     int    `xml:"Scale,attr,omitempty"`
+	MaxLength int    `xml:"MaxLength,attr,omitempty"`
+}
+type edmEntityContainer struct {
+	Name      string       `xml:"Name,attr"`
+	EntitySet edmEntitySet `xml:"EntitySet"`
+}
+type edmEntitySet struct {
+	Name       string `xml:"Name,attr"`
+	EntityType string `xml:"EntityType,attr"`
+}
+
+// returns entity properties of API, key property is always first
+// property names are attribute names (base relation) or relation + attribute names (joined relations)
+// schema cache must be read locked
+func getProperties(api types.Api) []property {
+	rel := cache.RelationIdMap[api.Query.RelationId.Bytes]
+	props := []property{{
+		name:     schema.PkName,
+		edmType:  "Edm.Int64",
+		nullable: false,
+		isKey:    true,
+		position: -1,
+		atr:      cache.AttributeIdMap[rel.AttributeIdPk],
+	}}
+	names := map[string]bool{schema.PkName: true}
+
+	subQueryCtr := 0
+	for i, column := range api.Columns {
+		atr := cache.AttributeIdMap[column.AttributeId]
+
+		// primary key of base relation is used as key property
+		if !column.SubQuery && column.Index == 0 && atr.Id == rel.AttributeIdPk {
+			props[0].position = i
+			props[0].column = column
+			continue
+		}
+
+		p := property{
+			nullable:   true,
+			filterable: !column.SubQuery,
+			position:   i,
+			column:     column,
+			atr:        atr,
+		}
+
+		if column.SubQuery {
+			p.name = fmt.Sprintf("sub_query%d", subQueryCtr)
+			subQueryCtr++
+		} else if column.Index == 0 {
+			p.name = atr.Name
+		} else {
+			p.name = fmt.Sprintf("%s_%s", cache.RelationIdMap[atr.RelationId].Name, atr.Name)
+		}
+
+		// same attribute can be used multiple times (e. g. from multiple joins of the same relation)
+		if names[p.name] {
+			for n := 2; ; n++ {
+				if name := fmt.Sprintf("%s_%d", p.name, n); !names[name] {
+					p.name = name
+					break
+				}
+			}
+		}
+		names[p.name] = true
+
+		if column.SubQuery && column.Aggregator.Valid && column.Aggregator.String == "count" {
+			p.edmType = "Edm.Int64"
+		} else if column.SubQuery && column.Aggregator.Valid && column.Aggregator.String == "avg" {
+			p.edmType = "Edm.Decimal"
+		} else {
+			p.edmType = getEdmType(atr)
+		}
+		props = append(props, p)
+	}
+	return props
+}
+
+func getEdmType(atr types.Attribute) string {
+	if atr.Encrypted {
+		return "Edm.String"
+	}
+	switch atr.Content {
+	case "integer":
+		if atr.ContentUse == "time" {
+			return "Edm.TimeOfDay"
+		}
+		return "Edm.Int32"
+	case "bigint":
+		switch atr.ContentUse {
+		case "date":
+			return "Edm.Date"
+		case "datetime":
+			return "Edm.DateTimeOffset"
+		case "time":
+			return "Edm.TimeOfDay"
+		}
+		return "Edm.Int64"
+	case "numeric":
+		return "Edm.Decimal"
+	case "real":
+		return "Edm.Single"
+	case "double precision":
+		return "Edm.Double"
+	case "boolean":
+		return "Edm.Boolean"
+	case "uuid":
+		return "Edm.Guid"
+	case "1:1", "n:1":
+		return "Edm.Int64"
+	}
+	return "Edm.String" // enum, varchar, text, regconfig, files, jsonb, ranges, GeoJSON
+}
+
+func getMetadata(modName string, api types.Api, props []property) ([]byte, error) {
+	doc := edmx{
+		XmlnsEdmx: "http://docs.oasis-open.org/odata/ns/edmx",
+		Version:   "4.0",
+	}
+	doc.DataServices.Schema = edmSchema{
+		Xmlns:     "http://docs.oasis-open.org/odata/ns/edm",
+		Namespace: modName,
+		EntityType: edmEntityType{
+			Name: api.Name,
+			Key:  edmKey{PropertyRef: edmPropertyRef{Name: props[0].name}},
+		},
+		EntityContainer: edmEntityContainer{
+			Name: "Container",
+			EntitySet: edmEntitySet{
+				Name:       api.Name,
+				EntityType: fmt.Sprintf("%s.%s", modName, api.Name),
+			},
+		},
+	}
+
+	for _, p := range props {
+		ep := edmProperty{
+			Name:     p.name,
+			Type:     p.edmType,
+			Nullable: p.nullable,
+		}
+		switch p.edmType {
+		case "Edm.Decimal":
+			if p.atr.Content == "numeric" && p.atr.Length != 0 && !p.column.SubQuery {
+				ep.Precision = p.atr.Length
+				ep.Scale = p.atr.LengthFract
+			}
+		case "Edm.String":
+			if p.atr.Content == "varchar" && !p.atr.Encrypted && !p.column.SubQuery {
+				ep.MaxLength = p.atr.Length
+			}
+		}
+		doc.DataServices.Schema.EntityType.Properties = append(
+			doc.DataServices.Schema.EntityType.Properties, ep)
+	}
+
+	out, err := xml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// converts data value to its OData JSON representation
+func getPropertyValue(p property, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	switch p.edmType {
+	case "Edm.Date", "Edm.DateTimeOffset", "Edm.TimeOfDay":
+		var unix int64
+		switch v := value.(type) {
+		case int64:
+			unix = v
+		case int32:
+			unix = int64(v)
+		default:
+			return value
+		}
+		switch p.edmType {
+		case "Edm.Date":
+			return time.Unix(unix, 0).UTC().Format("2006-01-02")
+		case "Edm.DateTimeOffset":
+			return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+		}
+		return fmt.Sprintf("%02d:%02d:%02d", unix/3600, (unix%3600)/60, unix%60)
+
+	case "Edm.Guid":
+		if v, ok := value.([16]uint8); ok {
+			return uuid.UUID(v).String()
+		}
+	case "Edm.String":
+		switch value.(type) {
+		case string:
+			return value
+		default:
+			// structured values (e. g. files) are returned as JSON text
+			j, err := json.Marshal(value)
+			if err != nil {
+				return nil
+			}
+			return string(j)
+		}
+	}
+	return value
+}
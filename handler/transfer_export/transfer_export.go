@@ -29,7 +29,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	defer ctxCanc()
 
 	// authenticate via token
-	login, err := login_auth.Token(ctx, token)
+	login, err := login_auth.TokenHttp(ctx, token, "transfer_export", r)
 	if err != nil {
 		log.Error(log.ContextServer, genErr, err)
 		return
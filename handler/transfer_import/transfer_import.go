@@ -71,7 +71,7 @@ func Handler(res http.ResponseWriter, req *http.Request) {
 
 		// authenticate via token
 		// check token
-		login, err := login_auth.Token(ctx, token)
+		login, err := login_auth.TokenHttp(ctx, token, "transfer_import", req)
 		if err != nil {
 			finishRequest(err)
 			return
@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -26,19 +27,22 @@ import (
 
 // a websocket client
 type clientType struct {
-	id          uuid.UUID                   // unique ID for client (for registering/de-registering login sessions)
-	address     string                      // IP address, no port
-	admin       bool                        // belongs to admin login?
-	ctx         context.Context             // context for requests from this client
-	ctxCancel   context.CancelFunc          // to abort requests in case of disconnect
-	device      types.WebsocketClientDevice // client device type (browser, fatClient)
-	ioFailure   atomic.Bool                 // client failed to read/write
-	local       bool                        // client is local (::1, 127.0.0.1)
-	loginId     int64                       // client login ID, 0 = not logged in yet
-	noAuth      bool                        // logged in without authentication (public auth, username only)
-	pwaModuleId uuid.UUID                   // ID of module for direct app access via subdomain, nil UUID if not used
-	write_mx    sync.Mutex                  // to force sequential writes
-	ws          *websocket.Conn             // websocket connection
+	id             uuid.UUID                   // unique ID for client (for registering/de-registering login sessions)
+	address        string                      // IP address, no port
+	admin          bool                        // belongs to admin login?
+	certClient     *x509.Certificate           // verified TLS client certificate, nil if not given
+	ctx            context.Context             // context for requests from this client
+	ctxCancel      context.CancelFunc          // to abort requests in case of disconnect
+	device         types.WebsocketClientDevice // client device type (browser, fatClient)
+	impersonatorId int64                       // admin login ID acting as client login, 0 = not impersonated
+	ioFailure      atomic.Bool                 // client failed to read/write
+	local          bool                        // client is local (::1, 127.0.0.1)
+	loginId        int64                       // client login ID, 0 = not logged in yet
+	noAuth         bool                        // logged in without authentication (public auth, username only)
+	pwaModuleId    uuid.UUID                   // ID of module for direct app access via subdomain, nil UUID if not used
+	tokenId        uuid.UUID                   // ID of login token used by client, nil UUID if not known
+	write_mx       sync.Mutex                  // to force sequential writes
+	ws             *websocket.Conn             // websocket connection
 }
 
 // a hub for all active websocket clients
@@ -109,6 +113,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		id:          clientId,
 		address:     host,
 		admin:       false,
+		certClient:  handler.GetCertClient(r),
 		ctx:         ctx,
 		ctxCancel:   ctxCancel,
 		device:      types.WebsocketClientDeviceBrowser,
@@ -223,7 +228,8 @@ func (hub *hubType) start() {
 				// skip if strict target filter does not apply to client
 				if (event.Target.Address != "" && event.Target.Address != client.address && !bothLocal) ||
 					(event.Target.Device != 0 && event.Target.Device != client.device) ||
-					(event.Target.LoginId != 0 && event.Target.LoginId != client.loginId) {
+					(event.Target.LoginId != 0 && event.Target.LoginId != client.loginId) ||
+					(event.Target.TokenId != uuid.Nil && event.Target.TokenId != client.tokenId) {
 					continue
 				}
 
@@ -320,7 +326,7 @@ func (client *clientType) handleTransaction(reqTransJson json.RawMessage) json.R
 	if !authRequest {
 		// execute non-authentication transaction
 		resTrans.Responses, err = request.ExecTransaction(ctx, client.address, client.loginId,
-			client.admin, client.device, client.noAuth, reqTrans, false)
+			client.admin, client.device, client.noAuth, client.impersonatorId, client.tokenId, reqTrans, false)
 
 		if err != nil {
 			returnErr := processReturnErr(err, client.admin, client.loginId, reqTrans.TransactionNr)
@@ -328,7 +334,7 @@ func (client *clientType) handleTransaction(reqTransJson json.RawMessage) json.R
 			if handler.CheckForDbsCacheErrCode(returnErr) {
 				// known PGX cache error, repeat with cleared DB statement/description cache
 				resTrans.Responses, err = request.ExecTransaction(ctx, client.address, client.loginId,
-					client.admin, client.device, client.noAuth, reqTrans, true)
+					client.admin, client.device, client.noAuth, client.impersonatorId, client.tokenId, reqTrans, true)
 
 				if err != nil {
 					resTrans.Responses = make([]types.Response, 0)
@@ -350,9 +356,16 @@ func (client *clientType) handleTransaction(reqTransJson json.RawMessage) json.R
 		var err error
 		var login types.LoginAuthResult
 		var req = reqTrans.Requests[0]
+		var badAttempt = true
 		resTrans.Responses = make([]types.Response, 0)
 
 		switch req.Action {
+		case "cert": // authentication via TLS client certificate
+			login, err = request.LoginAuthCert(ctx, client.certClient)
+
+			// clients attempt certificate authentication if enabled, not having a certificate is not a bad attempt
+			badAttempt = client.certClient != nil
+
 		case "openId": // authentication via Open ID Connect
 			login, err = request.LoginAuthOpenId(ctx, req.Payload)
 
@@ -365,14 +378,25 @@ func (client *clientType) handleTransaction(reqTransJson json.RawMessage) json.R
 
 		case "user": // authentication via username + password (+ MFA if used)
 			login, err = request.LoginAuthUser(ctx, req.Payload)
+
+			// passwordless challenges reveal usernames with passkeys, limit probing
+			if err == nil && login.Passwordless {
+				bruteforce.BadAttemptByHost(client.address)
+			}
 		}
 
 		if err != nil {
-			log.Warning(log.ContextWebsocket, "failed to authenticate user", err)
-			bruteforce.BadAttemptByHost(client.address)
+			// password policy errors occur after the current password was verified
+			if handler.CheckForPwErrCode(err) {
+				badAttempt = false
+			}
+			if badAttempt {
+				log.Warning(log.ContextWebsocket, "failed to authenticate user", err)
+				bruteforce.BadAttemptByHost(client.address)
+			}
 
-			if handler.CheckForLicenseErrCode(err) {
-				// license errors are relevant to the client
+			if handler.CheckForLicenseErrCode(err) || handler.CheckForPwErrCode(err) {
+				// license & password policy errors are relevant to the client
 				resTrans.Error = err.Error()
 			} else {
 				// any other error is not relevant to the client and could reveal internals
@@ -388,6 +412,8 @@ func (client *clientType) handleTransaction(reqTransJson json.RawMessage) json.R
 				client.loginId = login.Id
 				client.admin = login.Admin
 				client.noAuth = login.NoAuth
+				client.tokenId = login.TokenId
+				client.impersonatorId = login.ImpersonatorId
 
 				resTrans.Responses = append(resTrans.Responses, res)
 			}
@@ -398,7 +424,7 @@ func (client *clientType) handleTransaction(reqTransJson json.RawMessage) json.R
 		if resTrans.Error == "" && client.loginId != 0 {
 			log.Info(log.ContextWebsocket, fmt.Sprintf("authenticated client (login ID %d, admin: %v)", client.loginId, client.admin))
 
-			if err := login_session.Log(client.id, client.loginId, client.address, client.device); err != nil {
+			if err := login_session.Log(client.id, client.loginId, client.tokenId, client.address, client.device); err != nil {
 				log.Error(log.ContextWebsocket, "failed to create login session log", err)
 			}
 		}
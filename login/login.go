@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"math/rand"
 	"r3/cache"
+	"r3/config"
 	"r3/db"
 	"r3/handler"
 	"r3/log"
+	"r3/login/login_check"
 	"r3/login/login_external"
 	"r3/login/login_meta"
 	"r3/login/login_role"
@@ -276,12 +278,13 @@ func Set_tx(ctx context.Context, tx pgx.Tx, id int64, loginTemplateId pgtype.Int
 		if err := tx.QueryRow(ctx, `
 			INSERT INTO instance.login (
 				ldap_id, ldap_key, oauth_client_id, oauth_iss, oauth_sub, name, salt, hash,
-				salt_kdf, admin, no_auth, limited, active, token_expiry_hours, date_favorites
+				salt_kdf, admin, no_auth, limited, active, token_expiry_hours, date_favorites,
+				date_password
 			)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,0)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,0,$15)
 			RETURNING id
 		`, ldapId, ldapKey, oauthClientId, oauthIss, oauthSub, name, &salt, &hash,
-			saltKdf, admin, noAuth, isLimited, active, tokenExpiryHours).Scan(&id); err != nil {
+			saltKdf, admin, noAuth, isLimited, active, tokenExpiryHours, tools.GetTimeUnix()).Scan(&id); err != nil {
 
 			return 0, err
 		}
@@ -363,11 +366,39 @@ func Set_tx(ctx context.Context, tx pgx.Tx, id int64, loginTemplateId pgtype.Int
 }
 
 func SetSaltHash_tx(ctx context.Context, tx pgx.Tx, salt pgtype.Text, hash pgtype.Text, id int64) error {
+
+	// keep replaced password in history, current password counts towards history count
+	if count := config.GetUint64("pwHistoryCount"); count > 1 {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.login_password_history (login_id, salt, hash, date_change)
+				SELECT id, salt, hash, $1
+				FROM instance.login
+				WHERE id = $2
+				AND   salt IS NOT NULL
+				AND   hash IS NOT NULL
+		`, tools.GetTimeUnix(), id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM instance.login_password_history
+			WHERE login_id = $1
+			AND   ctid NOT IN (
+				SELECT ctid
+				FROM instance.login_password_history
+				WHERE login_id = $1
+				ORDER BY date_change DESC
+				LIMIT $2
+			)
+		`, id, count-1); err != nil {
+			return err
+		}
+	}
+
 	_, err := tx.Exec(ctx, `
 		UPDATE instance.login
-		SET salt = $1, hash = $2
-		WHERE id = $3
-	`, &salt, &hash, id)
+		SET salt = $1, hash = $2, date_password = $3
+		WHERE id = $4
+	`, &salt, &hash, tools.GetTimeUnix(), id)
 
 	return err
 }
@@ -511,10 +542,23 @@ func SetTokenFixed_tx(ctx context.Context, tx pgx.Tx, loginId int64, name string
 }
 
 // create new admin user
-func CreateAdmin(username string, password string) error {
+// password policy is checked, unless the initial admin user is created during instance setup
+func CreateAdmin(username string, password string, checkPolicy bool) error {
 	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
 	defer ctxCanc()
 
+	if checkPolicy {
+		if err := login_check.PasswordComplexity(password); err != nil {
+			return err
+		}
+		if err := login_check.PasswordBreached(password); err != nil {
+			if handler.CheckForPwErrCode(err) {
+				return errors.New("password was found in list of breached passwords")
+			}
+			return err
+		}
+	}
+
 	tx, err := db.Pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -1,12 +1,15 @@
 package login_auth
 
 import (
+	"context"
 	"errors"
 	"r3/config"
 	"r3/login/login_session"
+	"r3/login/login_token"
 	"time"
 
 	"github.com/gbrlsnchs/jwt/v3"
+	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -17,17 +20,23 @@ type tokenPayload struct {
 	LoginId int64     `json:"loginId"` // login ID
 	Type    loginType `json:"type"`    // login type
 	NoAuth  bool      `json:"noAuth"`  // login without authentication (name only)
+
+	ImpersonatorId int64 `json:"impersonatorId,omitempty"` // admin login acting as login, only set for impersonation
 }
 
 const (
-	loginTypeFixed  loginType = "fixed"  // auth via fixed token, used for ICS & fat client
-	loginTypeLdap   loginType = "ldap"   // auth via credentials, credentials managed in ext. directory
-	loginTypeLocal  loginType = "local"  // auth via credentials, credentials managed in internal login backend
-	loginTypeNoAuth loginType = "noAuth" // auth via login name (public user)
-	loginTypeOauth  loginType = "oauth"  // auth via ext. provider (Open ID connect)
+	loginTypeCert          loginType = "cert"          // auth via TLS client certificate
+	loginTypeFixed         loginType = "fixed"         // auth via fixed token, used for ICS & fat client
+	loginTypeImpersonation loginType = "impersonation" // auth via admin acting as login
+	loginTypeLdap          loginType = "ldap"          // auth via credentials, credentials managed in ext. directory
+	loginTypeLocal         loginType = "local"         // auth via credentials, credentials managed in internal login backend
+	loginTypeNoAuth        loginType = "noAuth"        // auth via login name (public user)
+	loginTypeOauth         loginType = "oauth"         // auth via ext. provider (Open ID connect)
 )
 
-func createToken(loginId int64, name string, admin bool, loginType loginType, tokenExpiryHours pgtype.Int4) (string, error) {
+// creates signed token with unique ID (jti), token is registered to be revocable
+func createToken(ctx context.Context, loginId int64, name string, admin bool, loginType loginType,
+	tokenExpiryHours pgtype.Int4, impersonatorId int64) (string, uuid.UUID, error) {
 
 	// token is valid for multiple days, if user decides to stay logged in
 	now := time.Now()
@@ -38,18 +47,29 @@ func createToken(loginId int64, name string, admin bool, loginType loginType, to
 		expiryHoursTime = time.Duration(int64(config.GetUint64("tokenExpiryHours")))
 	}
 
+	id, err := uuid.NewV4()
+	if err != nil {
+		return "", id, err
+	}
+	expiry := now.Add(expiryHoursTime * time.Hour)
+
 	token, err := jwt.Sign(tokenPayload{
 		Payload: jwt.Payload{
 			Issuer:         "r3 application",
 			Subject:        name,
-			ExpirationTime: jwt.NumericDate(now.Add(expiryHoursTime * time.Hour)),
+			ExpirationTime: jwt.NumericDate(expiry),
 			IssuedAt:       jwt.NumericDate(now),
+			JWTID:          id.String(),
 		},
-		Admin:   admin,
-		LoginId: loginId,
-		Type:    loginType,
+		Admin:          admin,
+		LoginId:        loginId,
+		Type:           loginType,
+		ImpersonatorId: impersonatorId,
 	}, config.GetTokenSecret())
-	return string(token), err
+	if err != nil {
+		return "", id, err
+	}
+	return string(token), id, login_token.Add(ctx, loginId, id, expiry.Unix())
 }
 
 func preAuthChecks(loginId int64, admin bool, limited bool, checkConcurrent bool) error {
@@ -0,0 +1,118 @@
+package login_auth
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"r3/cache"
+	"r3/db"
+	"r3/handler"
+	"r3/types"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// performs authentication attempt via TLS client certificate, verified by the web server against the configured client CA
+// certificate subject or subject alternative names (SAN) must be mapped to a login
+// without issueToken, no token is created (stateless authentication of single requests)
+func Cert(ctx context.Context, cert *x509.Certificate, issueToken bool) (types.LoginAuthResult, error) {
+
+	if cert == nil {
+		return types.LoginAuthResult{}, errors.New("no verified client certificate given")
+	}
+
+	// collect SANs of client certificate
+	sans := make([]string, 0)
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	// subject and SANs must map to a single login
+	loginIds := make([]int64, 0)
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT ARRAY(
+			SELECT DISTINCT login_id
+			FROM instance.login_client_cert
+			WHERE (type = 'subject' AND value = $1)
+			OR    (type = 'san'     AND value = ANY($2))
+		)
+	`, cert.Subject.String(), sans).Scan(&loginIds); err != nil {
+		return types.LoginAuthResult{}, err
+	}
+	if len(loginIds) > 1 {
+		return types.LoginAuthResult{}, fmt.Errorf("%s, client certificate '%s' is mapped to multiple logins",
+			handler.ErrAuthFailed, cert.Subject.String())
+	}
+
+	var err error
+	var l = types.LoginAuthResult{
+		MfaTokens: make([]types.LoginMfaToken, 0),
+	}
+	var limited bool
+	var nameDisplay pgtype.Text
+	var tokenExpiryHours pgtype.Int4
+
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT l.id, l.name, l.salt_kdf, l.admin, l.limited,
+			l.token_expiry_hours, lm.name_display, s.language_code
+		FROM      instance.login         AS l
+		JOIN      instance.login_setting AS s  ON s.login_id  = l.id
+		LEFT JOIN instance.login_meta    AS lm ON lm.login_id = l.id
+		WHERE l.active
+		AND   l.id = ANY($1)
+	`, loginIds).Scan(&l.Id, &l.Name, &l.SaltKdf, &l.Admin, &limited,
+		&tokenExpiryHours, &nameDisplay, &l.LanguageCode); err != nil {
+
+		if err == pgx.ErrNoRows {
+			return types.LoginAuthResult{}, fmt.Errorf("%s, no active login is mapped to client certificate '%s'",
+				handler.ErrAuthFailed, cert.Subject.String())
+		}
+		return types.LoginAuthResult{}, err
+	}
+
+	if err := preAuthChecks(l.Id, l.Admin, limited, true); err != nil {
+		return types.LoginAuthResult{}, err
+	}
+
+	// certificates cannot be combined with a second factor
+	// logins with MFA set up must not be able to bypass it with a certificate
+	var hasMfa bool
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT login_id
+			FROM instance.login_token_fixed
+			WHERE login_id = $1
+			AND   context  = 'totp'
+		) OR EXISTS(
+			SELECT login_id
+			FROM instance.login_webauthn
+			WHERE login_id = $1
+		)
+	`, l.Id).Scan(&hasMfa); err != nil {
+		return types.LoginAuthResult{}, err
+	}
+	if hasMfa {
+		return types.LoginAuthResult{}, fmt.Errorf("%s, client certificate authentication is not allowed for login '%s' with MFA set up",
+			handler.ErrAuthFailed, l.Name)
+	}
+
+	// everything in order, auth successful
+	if issueToken {
+		l.Token, l.TokenId, err = createToken(ctx, l.Id, l.Name, l.Admin, loginTypeCert, tokenExpiryHours, 0)
+		if err != nil {
+			return types.LoginAuthResult{}, err
+		}
+	}
+	if err := cache.LoadAccessIfUnknown(l.Id); err != nil {
+		return types.LoginAuthResult{}, err
+	}
+
+	if nameDisplay.Valid && nameDisplay.String != "" {
+		l.Name = nameDisplay.String
+	}
+	return l, nil
+}
@@ -0,0 +1,46 @@
+package login_auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"r3/log"
+	"r3/login/login_impersonation"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var impersonationExpiryHours int32 = 1 // impersonation tokens are short-lived
+
+// creates token for admin to act as another login
+// token carries both identities, impersonated sessions never receive admin permissions
+func Impersonate_tx(ctx context.Context, tx pgx.Tx, impersonatorId int64, loginId int64) (string, error) {
+
+	if impersonatorId == loginId {
+		return "", errors.New("cannot impersonate own login")
+	}
+
+	var active bool
+	var name string
+	if err := tx.QueryRow(ctx, `
+		SELECT name, active
+		FROM instance.login
+		WHERE id = $1
+	`, loginId).Scan(&name, &active); err != nil {
+		return "", err
+	}
+	if !active {
+		return "", errors.New("cannot impersonate inactive login")
+	}
+
+	token, _, err := createToken(ctx, loginId, name, false, loginTypeImpersonation,
+		pgtype.Int4{Int32: impersonationExpiryHours, Valid: true}, impersonatorId)
+
+	if err != nil {
+		return "", err
+	}
+
+	log.Info(log.ContextServer, fmt.Sprintf("admin login (ID %d) started impersonation of login '%s'", impersonatorId, name))
+	return token, login_impersonation.Log(ctx, impersonatorId, loginId, "auth", "impersonate", nil, false)
+}
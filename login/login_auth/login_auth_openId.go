@@ -213,7 +213,7 @@ func OpenId(ctx context.Context, oauthClientId int32, code string, codeVerifier
 	}
 
 	// everything in order, auth successful
-	l.Token, err = createToken(l.Id, l.Name, l.Admin, loginTypeOauth, tokenExpiryHours)
+	l.Token, l.TokenId, err = createToken(ctx, l.Id, l.Name, l.Admin, loginTypeOauth, tokenExpiryHours, 0)
 	if err != nil {
 		return types.LoginAuthResult{}, err
 	}
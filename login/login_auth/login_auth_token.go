@@ -3,16 +3,35 @@ package login_auth
 import (
 	"context"
 	"errors"
+	"net/http"
 	"r3/cache"
 	"r3/config"
 	"r3/db"
+	"r3/login/login_impersonation"
+	"r3/login/login_token"
 	"r3/tools"
 	"r3/types"
 
 	"github.com/gbrlsnchs/jwt/v3"
+	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// performs authentication attempt for HTTP handler by using existing JWT token
+// impersonated requests are logged, requests affecting credentials are blocked
+func TokenHttp(ctx context.Context, token string, ressource string, r *http.Request) (types.LoginAuthResult, error) {
+	l, err := Token(ctx, token)
+	if err != nil {
+		return types.LoginAuthResult{}, err
+	}
+	if l.ImpersonatorId != 0 {
+		if err := login_impersonation.CheckHttp(ctx, l.ImpersonatorId, l.Id, ressource, r); err != nil {
+			return types.LoginAuthResult{}, err
+		}
+	}
+	return l, nil
+}
+
 // performs authentication attempt for user by using existing JWT token, signed by server
 // returns login name and language code
 func Token(ctx context.Context, token string) (types.LoginAuthResult, error) {
@@ -31,13 +50,43 @@ func Token(ctx context.Context, token string) (types.LoginAuthResult, error) {
 		return types.LoginAuthResult{}, errors.New("token expired")
 	}
 
+	// check revocation list
+	// tokens issued before token IDs were introduced have no ID and cannot be revoked, they require a new login
+	if tp.JWTID == "" {
+		return types.LoginAuthResult{}, errors.New("token has no ID")
+	}
+	tokenId, err := uuid.FromString(tp.JWTID)
+	if err != nil {
+		return types.LoginAuthResult{}, err
+	}
+	if err := login_token.Use(ctx, tp.LoginId, tokenId); err != nil {
+		return types.LoginAuthResult{}, err
+	}
+
+	// impersonation is only valid while impersonator is an active admin
+	if tp.ImpersonatorId != 0 {
+		var impersonatorValid bool
+		if err := db.Pool.QueryRow(ctx, `
+			SELECT active AND admin
+			FROM instance.login
+			WHERE id = $1
+		`, tp.ImpersonatorId).Scan(&impersonatorValid); err != nil {
+			return types.LoginAuthResult{}, err
+		}
+		if !impersonatorValid {
+			return types.LoginAuthResult{}, errors.New("impersonating login is not an active admin")
+		}
+	}
+
 	// get known login details
 	var l = types.LoginAuthResult{
-		Admin:     tp.Admin,
-		Id:        tp.LoginId,
-		MfaTokens: make([]types.LoginMfaToken, 0),
-		NoAuth:    tp.NoAuth,
-		Token:     token,
+		Admin:          tp.Admin,
+		Id:             tp.LoginId,
+		ImpersonatorId: tp.ImpersonatorId,
+		MfaTokens:      make([]types.LoginMfaToken, 0),
+		NoAuth:         tp.NoAuth,
+		Token:          token,
+		TokenId:        tokenId,
 	}
 	var active bool
 	var limited bool
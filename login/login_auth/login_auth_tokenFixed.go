@@ -55,7 +55,7 @@ func TokenFixed(ctx context.Context, loginId int64, context string, tokenFixed s
 	if err := cache.LoadAccessIfUnknown(loginId); err != nil {
 		return types.LoginAuthResult{}, err
 	}
-	l.Token, err = createToken(l.Id, l.Name, false, loginTypeFixed, pgtype.Int4{})
+	l.Token, l.TokenId, err = createToken(ctx, l.Id, l.Name, false, loginTypeFixed, pgtype.Int4{}, 0)
 	if err != nil {
 		return types.LoginAuthResult{}, nil
 	}
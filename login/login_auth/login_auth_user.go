@@ -5,10 +5,17 @@ import (
 	"database/sql"
 	"encoding/base32"
 	"errors"
+	"fmt"
 	"r3/cache"
+	"r3/config"
 	"r3/db"
 	"r3/handler"
 	"r3/ldap/ldap_auth"
+	"r3/login"
+	"r3/login/login_check"
+	"r3/login/login_keys"
+	"r3/login/login_recoveryCode"
+	"r3/login/login_webAuthn"
 	"r3/tools"
 	"r3/types"
 	"strings"
@@ -18,9 +25,15 @@ import (
 	"github.com/xlzd/gotp"
 )
 
-// performs authentication attempt for known login via username + password + MFA PINs (if used)
-// if MFA is enabled but MFA PIN not given, returns list of available MFAs
-func User(ctx context.Context, username string, password string, mfaTokenId pgtype.Int4, mfaTokenPin pgtype.Text) (types.LoginAuthResult, error) {
+// performs authentication attempt for known login via username + password + MFA (if used)
+// if MFA is enabled but not given, returns list of available MFAs & WebAuthn challenge (if passkeys are registered)
+// if password is empty and passkeys are registered, returns WebAuthn challenge for passwordless authentication
+// if password has expired but no new password is given, returns with expired state
+// if password has expired and private key is not yet re-encrypted, returns encrypted private key after all factors are validated
+// without issueToken, no token is created (stateless authentication of single requests)
+func User(ctx context.Context, username string, password string, pwNew types.LoginPasswordNew, mfaTokenId pgtype.Int4,
+	mfaTokenPin pgtype.Text, mfaRecoveryCode pgtype.Text, webAuthn types.LoginWebAuthnAssertion,
+	issueToken bool) (types.LoginAuthResult, error) {
 
 	if username == "" {
 		return types.LoginAuthResult{}, errors.New("username not given")
@@ -32,6 +45,8 @@ func User(ctx context.Context, username string, password string, mfaTokenId pgty
 		MfaTokens: make([]types.LoginMfaToken, 0),
 		Name:      strings.ToLower(username), // usernames are case insensitive
 	}
+	var datePassword pgtype.Int8
+	var keyPrivateEnc pgtype.Text
 	var ldapId pgtype.Int4
 	var salt sql.NullString
 	var hash sql.NullString
@@ -40,14 +55,15 @@ func User(ctx context.Context, username string, password string, mfaTokenId pgty
 	var tokenExpiryHours pgtype.Int4
 
 	if err := db.Pool.QueryRow(ctx, `
-		SELECT l.id, l.ldap_id, l.salt, l.hash, l.salt_kdf, l.admin,
-			l.no_auth, l.limited, l.token_expiry_hours, lm.name_display
+		SELECT l.id, l.ldap_id, l.salt, l.hash, l.salt_kdf, l.admin, l.no_auth, l.limited,
+			l.token_expiry_hours, l.date_password, l.key_private_enc, lm.name_display
 		FROM      instance.login      AS l
 		LEFT JOIN instance.login_meta AS lm ON lm.login_id = l.id
 		WHERE l.active
 		AND   l.name            = $1
 		AND   l.oauth_client_id IS NULL
-	`, l.Name).Scan(&l.Id, &ldapId, &salt, &hash, &l.SaltKdf, &l.Admin, &l.NoAuth, &limited, &tokenExpiryHours, &nameDisplay); err != nil {
+	`, l.Name).Scan(&l.Id, &ldapId, &salt, &hash, &l.SaltKdf, &l.Admin, &l.NoAuth, &limited,
+		&tokenExpiryHours, &datePassword, &keyPrivateEnc, &nameDisplay); err != nil {
 
 		if err == pgx.ErrNoRows {
 			// name not found / login inactive must result in same response as authentication failed
@@ -58,7 +74,23 @@ func User(ctx context.Context, username string, password string, mfaTokenId pgty
 		}
 	}
 
-	if !l.NoAuth && password == "" {
+	// WebAuthn ceremonies & recovery codes change state, handle authentication in transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return types.LoginAuthResult{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	hasWebAuthn, err := login_webAuthn.Has_tx(ctx, tx, l.Id)
+	if err != nil {
+		return types.LoginAuthResult{}, err
+	}
+
+	// passkeys can replace password & second factor
+	passwordless := !l.NoAuth && password == "" && hasWebAuthn
+	pwExpired := false
+
+	if !l.NoAuth && password == "" && !passwordless {
 		return types.LoginAuthResult{}, errors.New("password not given")
 	}
 
@@ -66,68 +98,169 @@ func User(ctx context.Context, username string, password string, mfaTokenId pgty
 		return types.LoginAuthResult{}, err
 	}
 
-	if !l.NoAuth {
-		if ldapId.Valid {
-			// authentication against LDAP
-			if err := ldap_auth.Check(ldapId.Int32, l.Name, password); err != nil {
+	if passwordless {
+		if !webAuthn.SessionId.Valid {
+			// user verification (PIN, biometrics) is required if passkey is the only factor
+			c, err := login_webAuthn.LoginBegin_tx(ctx, tx, l.Id, true)
+			if err != nil {
+				return types.LoginAuthResult{}, err
+			}
+			// challenge is issued for any username with passkeys, callers must count it as bad attempt to limit probing
+			return types.LoginAuthResult{
+				MfaTokens:    make([]types.LoginMfaToken, 0),
+				Passwordless: true,
+				WebAuthn:     c,
+			}, tx.Commit(ctx)
+		}
+		if err := login_webAuthn.LoginFinish_tx(ctx, tx, l.Id, webAuthn); err != nil {
+			return types.LoginAuthResult{}, fmt.Errorf("%s, %v", handler.ErrAuthFailed, err)
+		}
+	} else {
+		if !l.NoAuth {
+			if ldapId.Valid {
+				// authentication against LDAP
+				if err := ldap_auth.Check(ldapId.Int32, l.Name, password); err != nil {
+					return types.LoginAuthResult{}, errors.New(handler.ErrAuthFailed)
+				}
+			} else {
+				// authentication against stored hash
+				if !hash.Valid || !salt.Valid || hash.String != tools.Hash(salt.String+password) {
+					return types.LoginAuthResult{}, errors.New(handler.ErrAuthFailed)
+				}
+
+				// check for expired password, new password is set after all factors are validated
+				pwMaxAgeDays := int64(config.GetUint64("pwMaxAgeDays"))
+				pwExpired = pwMaxAgeDays != 0 && datePassword.Int64+(pwMaxAgeDays*86400) < tools.GetTimeUnix()
+
+				if pwExpired {
+					// only expired state is returned, encrypted private key is not sent before all factors are validated
+					if pwNew.Pw == "" {
+						return types.LoginAuthResult{
+							MfaTokens: make([]types.LoginMfaToken, 0),
+							PwExpired: true,
+						}, nil
+					}
+					if pwNew.Pw == password {
+						return types.LoginAuthResult{}, handler.CreateErrCode(handler.ErrContextSec, handler.ErrCodeSecPwReused)
+					}
+					if err := login_check.PasswordComplexity(pwNew.Pw); err != nil {
+						return types.LoginAuthResult{}, handler.CreateErrCode(handler.ErrContextSec, handler.ErrCodeSecPwComplexity)
+					}
+					if err := login_check.PasswordHistory_tx(ctx, tx, l.Id, pwNew.Pw); err != nil {
+						return types.LoginAuthResult{}, err
+					}
+					if err := login_check.PasswordBreached(pwNew.Pw); err != nil {
+						return types.LoginAuthResult{}, err
+					}
+				}
+			}
+		}
+
+		// authentication ok so far, check MFA
+		if mfaTokenId.Valid && mfaTokenPin.Valid {
+
+			// validate provided MFA token
+			var mfaToken []byte
+			if err := tx.QueryRow(ctx, `
+				SELECT token
+				FROM instance.login_token_fixed
+				WHERE login_id = $1
+				AND   id       = $2
+				AND   context  = 'totp'
+			`, l.Id, mfaTokenId.Int32).Scan(&mfaToken); err != nil {
+				return types.LoginAuthResult{}, err
+			}
+
+			if mfaTokenPin.String != gotp.NewDefaultTOTP(base32.StdEncoding.WithPadding(
+				base32.NoPadding).EncodeToString(mfaToken)).Now() {
+
 				return types.LoginAuthResult{}, errors.New(handler.ErrAuthFailed)
 			}
+
+		} else if mfaRecoveryCode.Valid {
+
+			// validate & consume provided recovery code
+			if err := login_recoveryCode.Use_tx(ctx, tx, l.Id, mfaRecoveryCode.String); err != nil {
+				return types.LoginAuthResult{}, fmt.Errorf("%s, %v", handler.ErrAuthFailed, err)
+			}
+
+		} else if webAuthn.SessionId.Valid && hasWebAuthn {
+
+			// validate provided WebAuthn assertion
+			if err := login_webAuthn.LoginFinish_tx(ctx, tx, l.Id, webAuthn); err != nil {
+				return types.LoginAuthResult{}, fmt.Errorf("%s, %v", handler.ErrAuthFailed, err)
+			}
+
 		} else {
-			// authentication against stored hash
-			if !hash.Valid || !salt.Valid || hash.String != tools.Hash(salt.String+password) {
-				return types.LoginAuthResult{}, errors.New(handler.ErrAuthFailed)
+			// check for active MFAs, ignore if not used
+			rows, err := tx.Query(ctx, `
+				SELECT id, name
+				FROM instance.login_token_fixed
+				WHERE login_id = $1
+				AND   context  = 'totp'
+			`, l.Id)
+			if err != nil {
+				return types.LoginAuthResult{}, err
 			}
-		}
-	}
 
-	// authentication ok so far, check MFA
-	if mfaTokenId.Valid && mfaTokenPin.Valid {
-
-		// validate provided MFA token
-		var mfaToken []byte
-		if err := db.Pool.QueryRow(ctx, `
-			SELECT token
-			FROM instance.login_token_fixed
-			WHERE login_id = $1
-			AND   id       = $2
-			AND   context  = 'totp'
-		`, l.Id, mfaTokenId.Int32).Scan(&mfaToken); err != nil {
-			return types.LoginAuthResult{}, err
-		}
+			mfaTokens := make([]types.LoginMfaToken, 0)
+			for rows.Next() {
+				var m types.LoginMfaToken
+				if err := rows.Scan(&m.Id, &m.Name); err != nil {
+					return types.LoginAuthResult{}, err
+				}
+				mfaTokens = append(mfaTokens, m)
+			}
+			rows.Close()
 
-		if mfaTokenPin.String != gotp.NewDefaultTOTP(base32.StdEncoding.WithPadding(
-			base32.NoPadding).EncodeToString(mfaToken)).Now() {
+			// if MFAs available, return with list & WebAuthn challenge, continue otherwise
+			if len(mfaTokens) != 0 || hasWebAuthn {
+				recoveryCodes, err := login_recoveryCode.Count_tx(ctx, tx, l.Id)
+				if err != nil {
+					return types.LoginAuthResult{}, err
+				}
 
-			return types.LoginAuthResult{}, errors.New(handler.ErrAuthFailed)
+				res := types.LoginAuthResult{
+					MfaRecovery: recoveryCodes != 0,
+					MfaTokens:   mfaTokens,
+				}
+				if hasWebAuthn {
+					res.WebAuthn, err = login_webAuthn.LoginBegin_tx(ctx, tx, l.Id, false)
+					if err != nil {
+						return types.LoginAuthResult{}, err
+					}
+				}
+				return res, tx.Commit(ctx)
+			}
 		}
+	}
 
-	} else {
-		// check for active MFAs, ignore if not used
-		rows, err := db.Pool.Query(ctx, `
-			SELECT id, name
-			FROM instance.login_token_fixed
-			WHERE login_id = $1
-			AND   context  = 'totp'
-		`, l.Id)
-		if err != nil {
-			return types.LoginAuthResult{}, err
+	// all factors validated, replace expired password
+	if pwExpired {
+		// encrypted private key is only returned after all factors are validated, to be re-encrypted with the new password
+		// used MFA (recovery code, WebAuthn session) is consumed, all factors must be given again with the re-encrypted key
+		if keyPrivateEnc.Valid && (!pwNew.KeyPrivateEnc.Valid || pwNew.KeyPrivateEnc.String == "") {
+			return types.LoginAuthResult{
+				KeyPrivateEnc: keyPrivateEnc,
+				MfaTokens:     make([]types.LoginMfaToken, 0),
+				PwExpired:     true,
+				SaltKdf:       l.SaltKdf,
+			}, tx.Commit(ctx)
 		}
 
-		mfaTokens := make([]types.LoginMfaToken, 0)
-		for rows.Next() {
-			var m types.LoginMfaToken
-			if err := rows.Scan(&m.Id, &m.Name); err != nil {
+		salt, hash := login.GenerateSaltHash(pwNew.Pw)
+		if err := login.SetSaltHash_tx(ctx, tx, salt, hash, l.Id); err != nil {
+			return types.LoginAuthResult{}, err
+		}
+		if keyPrivateEnc.Valid {
+			if err := login_keys.StorePrivate_tx(ctx, tx, l.Id, pwNew.KeyPrivateEnc.String); err != nil {
 				return types.LoginAuthResult{}, err
 			}
-			mfaTokens = append(mfaTokens, m)
-		}
-		rows.Close()
-
-		// if MFA tokens available, return with list, continue otherwise
-		if len(mfaTokens) != 0 {
-			return types.LoginAuthResult{MfaTokens: mfaTokens}, nil
 		}
 	}
+	if err := tx.Commit(ctx); err != nil {
+		return types.LoginAuthResult{}, err
+	}
 
 	// everything in order, auth successful
 	loginType := loginTypeLocal
@@ -137,9 +270,11 @@ func User(ctx context.Context, username string, password string, mfaTokenId pgty
 		loginType = loginTypeLdap
 	}
 
-	l.Token, err = createToken(l.Id, l.Name, l.Admin, loginType, tokenExpiryHours)
-	if err != nil {
-		return types.LoginAuthResult{}, err
+	if issueToken {
+		l.Token, l.TokenId, err = createToken(ctx, l.Id, l.Name, l.Admin, loginType, tokenExpiryHours, 0)
+		if err != nil {
+			return types.LoginAuthResult{}, err
+		}
 	}
 	if err := cache.LoadAccessIfUnknown(l.Id); err != nil {
 		return types.LoginAuthResult{}, err
@@ -1,11 +1,17 @@
 package login_check
 
 import (
+	"bufio"
 	"context"
+	"crypto/sha1"
 	"fmt"
+	"os"
+	"path/filepath"
 	"r3/config"
+	"r3/handler"
 	"r3/tools"
 	"regexp"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -82,3 +88,91 @@ func PasswordComplexity(pw string) error {
 	}
 	return nil
 }
+
+// checks whether password is found in local list of breached passwords, stored as SHA-1 hashes (hex)
+// path can be a directory of hash prefix files (k-anonymity format, as in 'Have I Been Pwned' range downloads),
+// each named after the first 5 hash characters (optionally with '.txt') & containing lines 'SUFFIX:COUNT',
+// or a single file with full hashes, one per line 'HASH[:COUNT]'
+func PasswordBreached(pw string) error {
+	path := config.GetString("pwBreachedPath")
+	if path == "" {
+		return nil
+	}
+
+	sum := fmt.Sprintf("%X", sha1.Sum([]byte(pw)))
+	prefix := ""
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to access breached password list, %v", err)
+	}
+	if info.IsDir() {
+		prefix = sum[:5]
+		path = filepath.Join(path, prefix)
+
+		exists, err := tools.Exists(path)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			path = fmt.Sprintf("%s.txt", path)
+			if exists, err = tools.Exists(path); err != nil || !exists {
+				// no prefix file, no known breached password with this prefix
+				return err
+			}
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(prefix+hash, sum) {
+			return handler.CreateErrCode(handler.ErrContextSec, handler.ErrCodeSecPwBreached)
+		}
+	}
+	return scanner.Err()
+}
+
+// checks whether password was used by login before, current password included
+// number of passwords to check is defined by password history count, 0 disables the check
+func PasswordHistory_tx(ctx context.Context, tx pgx.Tx, loginId int64, pw string) error {
+	count := config.GetUint64("pwHistoryCount")
+	if count == 0 {
+		return nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT salt, hash
+		FROM instance.login
+		WHERE id = $1
+		AND   hash IS NOT NULL
+		UNION ALL (
+			SELECT salt, hash
+			FROM instance.login_password_history
+			WHERE login_id = $1
+			ORDER BY date_change DESC
+			LIMIT $2
+		)
+	`, loginId, count-1)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var salt, hash string
+		if err := rows.Scan(&salt, &hash); err != nil {
+			return err
+		}
+		if hash == tools.Hash(salt+pw) {
+			return handler.CreateErrCode(handler.ErrContextSec, handler.ErrCodeSecPwReused)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,83 @@
+package login_clientCert
+
+import (
+	"context"
+	"fmt"
+	"r3/types"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var mappingTypes = []string{"san", "subject"}
+
+func Del_tx(ctx context.Context, tx pgx.Tx, loginId int64, c types.LoginClientCert) error {
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.login_client_cert
+		WHERE login_id = $1
+		AND   type     = $2
+		AND   value    = $3
+	`, loginId, c.Type, c.Value)
+	return err
+}
+
+func Get_tx(ctx context.Context, tx pgx.Tx, loginId int64) ([]types.LoginClientCert, error) {
+	certs := make([]types.LoginClientCert, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT type, value
+		FROM instance.login_client_cert
+		WHERE login_id = $1
+		ORDER BY type ASC, value ASC
+	`, loginId)
+	if err != nil {
+		return certs, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c types.LoginClientCert
+		if err := rows.Scan(&c.Type, &c.Value); err != nil {
+			return certs, err
+		}
+		certs = append(certs, c)
+	}
+	return certs, nil
+}
+
+func Set_tx(ctx context.Context, tx pgx.Tx, loginId int64, c types.LoginClientCert) error {
+
+	c.Value = strings.TrimSpace(c.Value)
+	if !slices.Contains(mappingTypes, c.Type) {
+		return fmt.Errorf("invalid client certificate mapping type '%s'", c.Type)
+	}
+	if c.Value == "" {
+		return fmt.Errorf("client certificate mapping value must not be empty")
+	}
+
+	// a certificate must not authenticate more than one login
+	var loginIdEx int64
+	err := tx.QueryRow(ctx, `
+		SELECT login_id
+		FROM instance.login_client_cert
+		WHERE type  = $1
+		AND   value = $2
+	`, c.Type, c.Value).Scan(&loginIdEx)
+
+	if err == nil {
+		if loginIdEx != loginId {
+			return fmt.Errorf("client certificate mapping '%s' is already used by another login", c.Value)
+		}
+		return nil
+	}
+	if err != pgx.ErrNoRows {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO instance.login_client_cert (login_id, type, value)
+		VALUES ($1,$2,$3)
+	`, loginId, c.Type, c.Value)
+	return err
+}
@@ -0,0 +1,119 @@
+// audit log for admins acting as other logins (impersonation)
+// every request executed in an impersonated session is logged, including blocked ones
+
+package login_impersonation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"r3/db"
+	"r3/handler"
+	"r3/tools"
+	"r3/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func Get_tx(ctx context.Context, tx pgx.Tx, loginId int64, limit int, offset int) ([]types.LoginImpersonationLog, int64, error) {
+	logs := make([]types.LoginImpersonationLog, 0)
+
+	var total int64
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM instance.login_impersonation_log
+		WHERE login_id = $1
+	`, loginId).Scan(&total); err != nil {
+		return logs, total, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT ll.login_id_impersonator, l.name, ll.date,
+			ll.ressource, ll.action, ll.payload, ll.blocked
+		FROM      instance.login_impersonation_log AS ll
+		LEFT JOIN instance.login                   AS l ON l.id = ll.login_id_impersonator
+		WHERE ll.login_id = $1
+		ORDER BY ll.date DESC
+		LIMIT  $2
+		OFFSET $3
+	`, loginId, limit, offset)
+	if err != nil {
+		return logs, total, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l types.LoginImpersonationLog
+		if err := rows.Scan(&l.LoginIdImpersonator, &l.LoginNameImpersonator, &l.Date,
+			&l.Ressource, &l.Action, &l.Payload, &l.Blocked); err != nil {
+
+			return logs, total, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, total, nil
+}
+
+// logs request executed by impersonator as login
+// written outside of the request transaction, so that failed requests are logged as well
+func Log(ctx context.Context, loginIdImpersonator int64, loginId int64, ressource string,
+	action string, payload json.RawMessage, blocked bool) error {
+
+	ctx, ctxCanc := context.WithTimeout(ctx, db.CtxDefTimeoutLogWrite)
+	defer ctxCanc()
+
+	if len(payload) == 0 {
+		payload = nil
+	}
+
+	_, err := db.Pool.Exec(ctx, `
+		INSERT INTO instance.login_impersonation_log (login_id, login_id_impersonator,
+			date, ressource, action, payload, blocked)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+	`, loginId, loginIdImpersonator, tools.GetTimeUnix(), ressource, action, payload, blocked)
+	return err
+}
+
+// logs request executed by impersonator as login
+// returns error if request is not allowed for impersonated sessions
+func Check(ctx context.Context, loginIdImpersonator int64, loginId int64, ressource string,
+	action string, payload json.RawMessage) error {
+
+	blocked := IsBlocked(ressource, action)
+	if err := Log(ctx, loginIdImpersonator, loginId, ressource, action, payload, blocked); err != nil {
+		return err
+	}
+	if blocked {
+		return errors.New(handler.ErrUnauthorized)
+	}
+	return nil
+}
+
+// logs HTTP request executed by impersonator as login, ressource is the name of the HTTP handler
+// only method & path are logged, query parameters can include authentication tokens
+func CheckHttp(ctx context.Context, loginIdImpersonator int64, loginId int64, ressource string, r *http.Request) error {
+	payload, err := json.Marshal(map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	})
+	if err != nil {
+		return err
+	}
+	return Check(ctx, loginIdImpersonator, loginId, ressource, r.Method, payload)
+}
+
+// requests not allowed for impersonated sessions, as they affect credentials or keys of the impersonated login
+func IsBlocked(ressource string, action string) bool {
+	switch ressource {
+	case "data":
+		return action == "getKeys" || action == "setKeys"
+	case "login":
+		return action == "delTokenFixed" || action == "setTokenFixed"
+	case "loginKeys":
+		return action != "getPublic"
+	case "client_download_config", "loginPassword", "loginRecoveryCode", "loginToken", "loginWebAuthn":
+		return true
+	}
+	return false
+}
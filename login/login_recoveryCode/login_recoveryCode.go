@@ -0,0 +1,93 @@
+// one-time recovery codes, usable instead of any second authentication factor (TOTP, WebAuthn)
+// only hashes are stored, codes are shown once when generated
+
+package login_recoveryCode
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"r3/tools"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	codeCount = 10 // number of codes generated at once
+	codeBytes = 10 // random bytes per code, shown as hex characters
+)
+
+func Count_tx(ctx context.Context, tx pgx.Tx, loginId int64) (int, error) {
+	var count int
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM instance.login_recovery_code
+		WHERE login_id = $1
+	`, loginId).Scan(&count)
+	return count, err
+}
+
+func Del_tx(ctx context.Context, tx pgx.Tx, loginId int64) error {
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.login_recovery_code
+		WHERE login_id = $1
+	`, loginId)
+	return err
+}
+
+// replaces all recovery codes of login with newly generated ones, returns new codes
+func Set_tx(ctx context.Context, tx pgx.Tx, loginId int64) ([]string, error) {
+	codes := make([]string, 0)
+
+	if err := Del_tx(ctx, tx, loginId); err != nil {
+		return codes, err
+	}
+
+	now := tools.GetTimeUnix()
+	for i := 0; i < codeCount; i++ {
+		b := make([]byte, codeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return codes, err
+		}
+
+		// split code in groups of 5 characters for readability
+		h := hex.EncodeToString(b)
+		parts := make([]string, 0)
+		for j := 0; j < len(h); j += 5 {
+			parts = append(parts, h[j:min(j+5, len(h))])
+		}
+		code := strings.Join(parts, "-")
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.login_recovery_code (login_id, code_hash, date_create)
+			VALUES ($1,$2,$3)
+		`, loginId, tools.Hash(normalize(code)), now); err != nil {
+			return codes, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// validates & consumes recovery code
+func Use_tx(ctx context.Context, tx pgx.Tx, loginId int64, code string) error {
+	res, err := tx.Exec(ctx, `
+		DELETE FROM instance.login_recovery_code
+		WHERE login_id  = $1
+		AND   code_hash = $2
+	`, loginId, tools.Hash(normalize(code)))
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return errors.New("invalid recovery code")
+	}
+	return nil
+}
+
+// codes are case insensitive, separators are optional
+func normalize(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
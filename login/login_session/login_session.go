@@ -15,7 +15,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-func Log(id uuid.UUID, loginId int64, address string, device types.WebsocketClientDevice) error {
+func Log(id uuid.UUID, loginId int64, tokenId uuid.UUID, address string, device types.WebsocketClientDevice) error {
 	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutLogWrite)
 	defer ctxCanc()
 
@@ -26,16 +26,19 @@ func Log(id uuid.UUID, loginId int64, address string, device types.WebsocketClie
 	defer tx.Rollback(ctx)
 
 	// on conflict constraint requires full name for ID column in WHERE definition
+	// tokens issued before token IDs were introduced are not linked
 	now := tools.GetTimeUnix()
+	tokenIdNull := pgtype.UUID{Bytes: tokenId, Valid: tokenId != uuid.Nil}
 	if _, err := tx.Exec(ctx, `
-		INSERT INTO instance.login_session(id, login_id, node_id, address, device, date)
-		VALUES ($1,$2,$3,$4,$5,$6)
+		INSERT INTO instance.login_session(id, login_id, node_id, address, device, date, token_id)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
 		ON CONFLICT
 			ON CONSTRAINT login_session_pkey
 			DO UPDATE
-				SET date = $7
-				WHERE instance.login_session.id = $8
-	`, id, loginId, cache.GetNodeId(), address, types.WebsocketClientDeviceNames[device], now, now, id); err != nil {
+				SET date = $8, token_id = $9
+				WHERE instance.login_session.id = $10
+	`, id, loginId, cache.GetNodeId(), address, types.WebsocketClientDeviceNames[device],
+		now, tokenIdNull, now, tokenIdNull, id); err != nil {
 		return err
 	}
 	return tx.Commit(ctx)
@@ -62,17 +65,18 @@ func LogRemove(id uuid.UUID) error {
 
 func LogsGet_tx(ctx context.Context, tx pgx.Tx, byString pgtype.Text, limit int, offset int, orderBy string, orderAsc bool) (interface{}, error) {
 	type session struct {
-		LoginId         int64  `json:"loginId"`
-		LoginName       string `json:"loginName"`
-		LoginDepartment string `json:"loginDepartment"`
-		LoginDisplay    string `json:"loginDisplay"`
-		Address         string `json:"address"`
-		Admin           bool   `json:"admin"`
-		Limited         bool   `json:"limited"`
-		NoAuth          bool   `json:"noAuth"`
-		NodeName        string `json:"nodeName"`
-		Date            int64  `json:"date"`
-		Device          string `json:"device"`
+		LoginId         int64       `json:"loginId"`
+		LoginName       string      `json:"loginName"`
+		LoginDepartment string      `json:"loginDepartment"`
+		LoginDisplay    string      `json:"loginDisplay"`
+		Address         string      `json:"address"`
+		Admin           bool        `json:"admin"`
+		Limited         bool        `json:"limited"`
+		NoAuth          bool        `json:"noAuth"`
+		NodeName        string      `json:"nodeName"`
+		Date            int64       `json:"date"`
+		Device          string      `json:"device"`
+		TokenId         pgtype.UUID `json:"tokenId"` // login token used by session, NULL if not known
 	}
 
 	var total int64
@@ -134,8 +138,8 @@ func LogsGet_tx(ctx context.Context, tx pgx.Tx, byString pgtype.Text, limit int,
 
 	// get session logs
 	rows, err := tx.Query(ctx, fmt.Sprintf(`
-		SELECT ls.login_id, ls.address, ls.device, ls.date, l.admin, l.limited, l.no_auth,
-			l.name, COALESCE(m.name_display, ''), COALESCE(m.department, ''), n.name
+		SELECT ls.login_id, ls.address, ls.device, ls.date, ls.token_id, l.admin, l.limited,
+			l.no_auth, l.name, COALESCE(m.name_display, ''), COALESCE(m.department, ''), n.name
 		FROM      instance.login_session AS ls
 		JOIN      instance.login         AS l ON l.id = ls.login_id
 		LEFT JOIN instance.login_meta    AS m ON l.id = m.login_id
@@ -158,8 +162,8 @@ func LogsGet_tx(ctx context.Context, tx pgx.Tx, byString pgtype.Text, limit int,
 
 	for rows.Next() {
 		var s session
-		if err := rows.Scan(&s.LoginId, &s.Address, &s.Device, &s.Date, &s.Admin, &s.Limited, &s.NoAuth,
-			&s.LoginName, &s.LoginDisplay, &s.LoginDepartment, &s.NodeName); err != nil {
+		if err := rows.Scan(&s.LoginId, &s.Address, &s.Device, &s.Date, &s.TokenId, &s.Admin, &s.Limited,
+			&s.NoAuth, &s.LoginName, &s.LoginDisplay, &s.LoginDepartment, &s.NodeName); err != nil {
 
 			return nil, err
 		}
@@ -0,0 +1,158 @@
+// issued login tokens (JWTs), identified by their token ID (jti)
+// tokens are valid until expiry, unless revoked - revoked tokens are kept until they expire
+
+package login_token
+
+import (
+	"context"
+	"errors"
+	"r3/cluster"
+	"r3/db"
+	"r3/tools"
+	"r3/types"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var dateUsedUpdateSec int64 = 300 // usage date of tokens is updated at most every x seconds
+
+// registers newly issued token, expired tokens are removed by system task
+func Add(ctx context.Context, loginId int64, id uuid.UUID, dateExpiry int64) error {
+	_, err := db.Pool.Exec(ctx, `
+		INSERT INTO instance.login_token (id, login_id, date_create, date_expiry)
+		VALUES ($1,$2,$3,$4)
+	`, id, loginId, tools.GetTimeUnix(), dateExpiry)
+	return err
+}
+
+// removes expired tokens, including revoked ones
+func DelExpired(ctx context.Context) error {
+	_, err := db.Pool.Exec(ctx, `
+		DELETE FROM instance.login_token
+		WHERE date_expiry < $1
+	`, tools.GetTimeUnix())
+	return err
+}
+
+// returns active (not expired, not revoked) tokens of login
+// device, address & activity are taken from websocket sessions using the token
+func Get_tx(ctx context.Context, tx pgx.Tx, loginId int64) ([]types.LoginToken, error) {
+	tokens := make([]types.LoginToken, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT t.id, t.date_create, t.date_expiry, ls.address, ls.device::TEXT,
+			COALESCE(ls.connected, 0), GREATEST(t.date_create, t.date_used, ls.date)
+		FROM instance.login_token AS t
+		LEFT JOIN LATERAL (
+			SELECT address, device, date, COUNT(*) OVER() AS connected
+			FROM instance.login_session
+			WHERE token_id = t.id
+			ORDER BY date DESC
+			LIMIT 1
+		) AS ls ON TRUE
+		WHERE t.login_id     = $1
+		AND   t.date_expiry  > $2
+		AND   t.date_revoked IS NULL
+		ORDER BY 7 DESC
+	`, loginId, tools.GetTimeUnix())
+	if err != nil {
+		return tokens, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t types.LoginToken
+		if err := rows.Scan(&t.Id, &t.DateCreate, &t.DateExpiry, &t.Address,
+			&t.Device, &t.Connected, &t.DateActivity); err != nil {
+
+			return tokens, err
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, nil
+}
+
+// revokes token of login & disconnects active sessions using it
+func Revoke_tx(ctx context.Context, tx pgx.Tx, loginId int64, id uuid.UUID) error {
+	res, err := tx.Exec(ctx, `
+		UPDATE instance.login_token
+		SET date_revoked = $1
+		WHERE login_id     = $2
+		AND   id           = $3
+		AND   date_revoked IS NULL
+	`, tools.GetTimeUnix(), loginId, id)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return errors.New("token does not exist or has already been revoked")
+	}
+	return cluster.LoginTokenRevoked_tx(ctx, tx, true, loginId, id)
+}
+
+// revokes all tokens of login except the one given (usually the token of the requesting session)
+func RevokeOthers_tx(ctx context.Context, tx pgx.Tx, loginId int64, idKeep uuid.UUID) error {
+	rows, err := tx.Query(ctx, `
+		UPDATE instance.login_token
+		SET date_revoked = $1
+		WHERE login_id     = $2
+		AND   id           <> $3
+		AND   date_revoked IS NULL
+		AND   date_expiry  > $1
+		RETURNING id
+	`, tools.GetTimeUnix(), loginId, idKeep)
+	if err != nil {
+		return err
+	}
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if err := cluster.LoginTokenRevoked_tx(ctx, tx, true, loginId, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validates that token has not been revoked & updates its usage date
+// usage date is only updated if older than x seconds, to avoid writing on every token authentication
+func Use(ctx context.Context, loginId int64, id uuid.UUID) error {
+	now := tools.GetTimeUnix()
+
+	var exists bool
+	if err := db.Pool.QueryRow(ctx, `
+		WITH t AS (
+			SELECT id, date_used
+			FROM instance.login_token
+			WHERE login_id     = $1
+			AND   id           = $2
+			AND   date_revoked IS NULL
+		), u AS (
+			UPDATE instance.login_token
+			SET date_used = $3
+			WHERE id IN (
+				SELECT id
+				FROM t
+				WHERE date_used IS NULL
+				OR    date_used < $4
+			)
+		)
+		SELECT EXISTS(SELECT id FROM t)
+	`, loginId, id, now, now-dateUsedUpdateSec).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("token has been revoked")
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+// WebAuthn (passkey) credentials, usable as passwordless first or as second authentication factor
+// ceremony states are stored in the database, so that begin & finish steps can be handled by any cluster node
+
+package login_webAuthn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"r3/cache"
+	"r3/config"
+	"r3/tools"
+	"r3/types"
+	"strconv"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var sessionTimeoutSec int64 = 300 // WebAuthn ceremonies must be completed within x seconds
+
+type user struct {
+	id          int64
+	name        string
+	credentials []webauthn.Credential
+}
+
+func (u user) WebAuthnID() []byte                         { return []byte(strconv.FormatInt(u.id, 10)) }
+func (u user) WebAuthnName() string                       { return u.name }
+func (u user) WebAuthnDisplayName() string                { return u.name }
+func (u user) WebAuthnCredentials() []webauthn.Credential { return u.credentials }
+
+func Del_tx(ctx context.Context, tx pgx.Tx, loginId int64, id int64) error {
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.login_webauthn
+		WHERE login_id = $1
+		AND   id       = $2
+	`, loginId, id)
+	return err
+}
+
+func Get_tx(ctx context.Context, tx pgx.Tx, loginId int64) ([]types.LoginWebAuthn, error) {
+	credentials := make([]types.LoginWebAuthn, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, name, date_create, date_used
+		FROM instance.login_webauthn
+		WHERE login_id = $1
+		ORDER BY date_create ASC
+	`, loginId)
+	if err != nil {
+		return credentials, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c types.LoginWebAuthn
+		if err := rows.Scan(&c.Id, &c.Name, &c.DateCreate, &c.DateUsed); err != nil {
+			return credentials, err
+		}
+		credentials = append(credentials, c)
+	}
+	return credentials, nil
+}
+
+// returns whether login has any WebAuthn credentials
+func Has_tx(ctx context.Context, tx pgx.Tx, loginId int64) (bool, error) {
+	var exists bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT id
+			FROM instance.login_webauthn
+			WHERE login_id = $1
+		)
+	`, loginId).Scan(&exists)
+	return exists, err
+}
+
+// removes all WebAuthn credentials of login, used by admins if login lost access to its authenticators
+func Reset_tx(ctx context.Context, tx pgx.Tx, loginId int64) error {
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.login_webauthn
+		WHERE login_id = $1
+	`, loginId)
+	return err
+}
+
+// assertion ceremony
+// starts authentication with one of the registered credentials of the login
+// user verification (PIN, biometrics) can be enforced, if credential is used as only authentication factor
+func LoginBegin_tx(ctx context.Context, tx pgx.Tx, loginId int64, userVerification bool) (types.LoginWebAuthnChallenge, error) {
+	var c types.LoginWebAuthnChallenge
+
+	w, err := getWebAuthn()
+	if err != nil {
+		return c, err
+	}
+	u, err := getUser_tx(ctx, tx, loginId)
+	if err != nil {
+		return c, err
+	}
+	if len(u.credentials) == 0 {
+		return c, errors.New("login has no WebAuthn credentials")
+	}
+
+	opts := make([]webauthn.LoginOption, 0)
+	if userVerification {
+		opts = append(opts, webauthn.WithUserVerification(protocol.VerificationRequired))
+	}
+
+	assertion, session, err := w.BeginLogin(u, opts...)
+	if err != nil {
+		return c, err
+	}
+	if c.SessionId, err = setSession_tx(ctx, tx, loginId, session); err != nil {
+		return c, err
+	}
+	c.Options, err = json.Marshal(assertion)
+	return c, err
+}
+
+// validates authenticator response to assertion challenge, session can only be used once
+func LoginFinish_tx(ctx context.Context, tx pgx.Tx, loginId int64, assertion types.LoginWebAuthnAssertion) error {
+
+	w, err := getWebAuthn()
+	if err != nil {
+		return err
+	}
+	session, err := getSession_tx(ctx, tx, loginId, assertion.SessionId)
+	if err != nil {
+		return err
+	}
+	u, err := getUser_tx(ctx, tx, loginId)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(assertion.Response)
+	if err != nil {
+		return err
+	}
+	credential, err := w.ValidateLogin(u, session, parsed)
+	if err != nil {
+		return err
+	}
+	if credential.Authenticator.CloneWarning {
+		return fmt.Errorf("WebAuthn credential signature counter is invalid, authenticator might be cloned")
+	}
+
+	// store updated signature counter
+	credentialJson, err := json.Marshal(credential)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE instance.login_webauthn
+		SET credential = $1, date_used = $2
+		WHERE login_id      = $3
+		AND   credential_id = $4
+	`, credentialJson, tools.GetTimeUnix(), loginId, credential.ID)
+	return err
+}
+
+// registration ceremony
+// starts registration of a new, discoverable credential (passkey)
+func RegisterBegin_tx(ctx context.Context, tx pgx.Tx, loginId int64) (types.LoginWebAuthnChallenge, error) {
+	var c types.LoginWebAuthnChallenge
+
+	w, err := getWebAuthn()
+	if err != nil {
+		return c, err
+	}
+	u, err := getUser_tx(ctx, tx, loginId)
+	if err != nil {
+		return c, err
+	}
+
+	// exclude existing credentials, so that authenticators are not registered twice
+	exclusions := make([]protocol.CredentialDescriptor, 0)
+	for _, credential := range u.credentials {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	creation, session, err := w.BeginRegistration(u,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred))
+
+	if err != nil {
+		return c, err
+	}
+	if c.SessionId, err = setSession_tx(ctx, tx, loginId, session); err != nil {
+		return c, err
+	}
+	c.Options, err = json.Marshal(creation)
+	return c, err
+}
+
+// validates authenticator response to registration challenge & stores the new credential
+func RegisterFinish_tx(ctx context.Context, tx pgx.Tx, loginId int64, name string, assertion types.LoginWebAuthnAssertion) error {
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("WebAuthn credential name must not be empty")
+	}
+
+	w, err := getWebAuthn()
+	if err != nil {
+		return err
+	}
+	session, err := getSession_tx(ctx, tx, loginId, assertion.SessionId)
+	if err != nil {
+		return err
+	}
+	u, err := getUser_tx(ctx, tx, loginId)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(assertion.Response)
+	if err != nil {
+		return err
+	}
+	credential, err := w.CreateCredential(u, session, parsed)
+	if err != nil {
+		return err
+	}
+	credentialJson, err := json.Marshal(credential)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO instance.login_webauthn (login_id, name,
+			credential_id, credential, date_create)
+		VALUES ($1,$2,$3,$4,$5)
+	`, loginId, name, credential.ID, credentialJson, tools.GetTimeUnix())
+	return err
+}
+
+// relying party is the public host name, PWA sub domains are valid origins
+func getWebAuthn() (*webauthn.WebAuthn, error) {
+	domain := cache.GetPublicHostDomain()
+	host := strings.ToLower(config.GetString("publicHostName"))
+	if domain == "" {
+		return nil, errors.New("public host name is not set, required for WebAuthn")
+	}
+
+	origins := []string{fmt.Sprintf("https://%s", host)}
+	for subdomain := range cache.GetPwaDomainMap() {
+		origins = append(origins, strings.ToLower(fmt.Sprintf("https://%s.%s", subdomain, host)))
+	}
+
+	// browsers consider localhost a secure context, even without TLS
+	if domain == "localhost" {
+		origins = append(origins, fmt.Sprintf("http://%s", host))
+	}
+
+	return webauthn.New(&webauthn.Config{
+		RPID:          domain,
+		RPDisplayName: config.GetString("appName"),
+		RPOrigins:     origins,
+	})
+}
+
+// returns and removes ceremony session, expired sessions are removed as well
+func getSession_tx(ctx context.Context, tx pgx.Tx, loginId int64, sessionId pgtype.UUID) (webauthn.SessionData, error) {
+	var session webauthn.SessionData
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.login_webauthn_session
+		WHERE date_expiry < $1
+	`, tools.GetTimeUnix()); err != nil {
+		return session, err
+	}
+
+	var sessionJson []byte
+	if err := tx.QueryRow(ctx, `
+		DELETE FROM instance.login_webauthn_session
+		WHERE id       = $1
+		AND   login_id = $2
+		RETURNING session
+	`, sessionId, loginId).Scan(&sessionJson); err != nil {
+		if err == pgx.ErrNoRows {
+			return session, errors.New("WebAuthn session does not exist or has expired")
+		}
+		return session, err
+	}
+	return session, json.Unmarshal(sessionJson, &session)
+}
+
+func getUser_tx(ctx context.Context, tx pgx.Tx, loginId int64) (user, error) {
+	u := user{
+		id:          loginId,
+		credentials: make([]webauthn.Credential, 0),
+	}
+
+	if err := tx.QueryRow(ctx, `
+		SELECT name
+		FROM instance.login
+		WHERE id = $1
+	`, loginId).Scan(&u.name); err != nil {
+		return u, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT credential
+		FROM instance.login_webauthn
+		WHERE login_id = $1
+	`, loginId)
+	if err != nil {
+		return u, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var credentialJson []byte
+		var credential webauthn.Credential
+		if err := rows.Scan(&credentialJson); err != nil {
+			return u, err
+		}
+		if err := json.Unmarshal(credentialJson, &credential); err != nil {
+			return u, err
+		}
+		u.credentials = append(u.credentials, credential)
+	}
+	return u, nil
+}
+
+func setSession_tx(ctx context.Context, tx pgx.Tx, loginId int64, session *webauthn.SessionData) (pgtype.UUID, error) {
+	var sessionId pgtype.UUID
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return sessionId, err
+	}
+	sessionJson, err := json.Marshal(session)
+	if err != nil {
+		return sessionId, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO instance.login_webauthn_session (id, login_id, session, date_expiry)
+		VALUES ($1,$2,$3,$4)
+	`, id, loginId, sessionJson, tools.GetTimeUnix()+sessionTimeoutSec); err != nil {
+		return sessionId, err
+	}
+	return pgtype.UUID{Bytes: id, Valid: true}, nil
+}
@@ -23,6 +23,7 @@ import (
 	"r3/db/initialize"
 	"r3/db/upgrade"
 	"r3/handler"
+	"r3/handler/acme_challenge"
 	"r3/handler/api"
 	"r3/handler/api_auth"
 	"r3/handler/cache_download"
@@ -34,10 +35,13 @@ import (
 	"r3/handler/data_download"
 	"r3/handler/data_download_thumb"
 	"r3/handler/data_upload"
+	"r3/handler/geojson_download"
+	"r3/handler/graphql"
 	"r3/handler/icon_upload"
 	"r3/handler/ics_download"
 	"r3/handler/license_upload"
 	"r3/handler/manifest_download"
+	"r3/handler/odata"
 	"r3/handler/transfer_export"
 	"r3/handler/transfer_import"
 	"r3/handler/websocket"
@@ -55,6 +59,7 @@ import (
 	_ "time/tzdata" // to embed timezone DB
 
 	"github.com/kardianos/service"
+	"golang.org/x/crypto/acme"
 )
 
 var (
@@ -96,6 +101,7 @@ type program struct {
 	logger          service.Logger // logs to the operating system if called as service, otherwise to stdOut
 	stopping        atomic.Bool
 	webServer       *http.Server
+	webServerAcme   *http.Server // plain HTTP server for ACME HTTP-01 challenges & redirects to HTTPS
 }
 
 func main() {
@@ -359,7 +365,7 @@ func (prg *program) execute(svc service.Service) {
 		if len(adminInputs) != 2 {
 			prg.executeAborted(svc, fmt.Errorf("invalid syntax for admin creation, required is username:password"))
 		} else {
-			if err := login.CreateAdmin(adminInputs[0], adminInputs[1]); err != nil {
+			if err := login.CreateAdmin(adminInputs[0], adminInputs[1], true); err != nil {
 				prg.executeAborted(svc, fmt.Errorf("failed to create admin user, %v", err))
 			} else {
 				prg.logger.Info("successfully created new admin user")
@@ -418,6 +424,7 @@ func (prg *program) execute(svc service.Service) {
 
 	handler.SetNoImage(fsStaticNoPic)
 
+	mux.HandleFunc("/.well-known/acme-challenge/", acme_challenge.Handler)
 	mux.HandleFunc("/api/", api.Handler)
 	mux.HandleFunc("/api/auth", api_auth.Handler)
 	mux.HandleFunc("/cache/download/", cache_download.Handler)
@@ -428,10 +435,13 @@ func (prg *program) execute(svc service.Service) {
 	mux.HandleFunc("/data/download/", data_download.Handler)
 	mux.HandleFunc("/data/download/thumb/", data_download_thumb.Handler)
 	mux.HandleFunc("/data/upload", data_upload.Handler)
+	mux.HandleFunc("/geojson/download/", geojson_download.Handler)
+	mux.HandleFunc("/graphql/", graphql.Handler)
 	mux.HandleFunc("/icon/upload", icon_upload.Handler)
 	mux.HandleFunc("/ics/download/", ics_download.Handler)
 	mux.HandleFunc("/license/upload", license_upload.Handler)
 	mux.HandleFunc("/manifests/", manifest_download.Handler)
+	mux.HandleFunc("/odata/", odata.Handler)
 	mux.HandleFunc("/websocket", websocket.Handler)
 	mux.HandleFunc("/export/", transfer_export.Handler)
 	mux.HandleFunc("/import", transfer_import.Handler)
@@ -489,7 +499,27 @@ func (prg *program) execute(svc service.Service) {
 		// https://github.com/golang/go/issues/45430
 		prg.webServer.TLSConfig = &tls.Config{
 			GetCertificate: cache.GetCert,
+			NextProtos:     []string{"h2", "http/1.1", acme.ALPNProto},
 		}
+
+		// optional TLS client certificate authentication, clients without certificate can still use other authentication methods
+		if config.File.Web.ClientCa != "" {
+			crlPaths := make([]string, 0)
+			for _, crl := range config.File.Web.ClientCrls {
+				crlPaths = append(crlPaths, filepath.Join(config.File.Paths.Certificates, crl))
+			}
+			cache.SetCertClientPaths(filepath.Join(config.File.Paths.Certificates, config.File.Web.ClientCa), crlPaths)
+
+			caPool, err := cache.GetCertClientCaPool()
+			if err != nil {
+				prg.executeAborted(svc, fmt.Errorf("failed to load client CA file, %v", err))
+				return
+			}
+			prg.webServer.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+			prg.webServer.TLSConfig.ClientCAs = caPool
+			prg.webServer.TLSConfig.VerifyConnection = cache.CheckCertClientRevoked
+		}
+
 		switch config.File.Web.TlsMinVersion {
 		case "": // prior to 3.8.4, defaults to not apply min. TLS version
 		case "1.1":
@@ -502,12 +532,47 @@ func (prg *program) execute(svc service.Service) {
 			log.Warning(log.ContextServer, "failed to apply min. TLS version",
 				fmt.Errorf("version '%s' is not supported (valid: 1.1, 1.2 or 1.3)", config.File.Web.TlsMinVersion))
 		}
+
+		// plain HTTP server for ACME HTTP-01 challenges, if enabled
+		if config.File.Web.AcmeHttpPort != 0 {
+			go prg.serveAcmeHttp(svc)
+		}
+
 		if err := prg.webServer.ServeTLS(webListener, "", ""); err != nil && err != http.ErrServerClosed {
 			prg.executeAborted(svc, err)
 		}
 	}
 }
 
+// serves ACME HTTP-01 challenges via plain HTTP, redirects all other requests to HTTPS
+func (prg *program) serveAcmeHttp(svc service.Service) {
+	muxAcme := http.NewServeMux()
+	muxAcme.HandleFunc("/.well-known/acme-challenge/", acme_challenge.Handler)
+	muxAcme.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if config.File.Web.Port != 443 {
+			host = net.JoinHostPort(host, fmt.Sprintf("%d", config.File.Web.Port))
+		}
+		http.Redirect(w, r, fmt.Sprintf("https://%s%s", host, r.URL.RequestURI()), http.StatusMovedPermanently)
+	})
+
+	acmeServerString := fmt.Sprintf("%s:%d", config.File.Web.Listen, config.File.Web.AcmeHttpPort)
+	prg.webServerAcme = &http.Server{
+		Addr:              acmeServerString,
+		Handler:           muxAcme,
+		IdleTimeout:       120 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	log.Info(log.ContextServer, fmt.Sprintf("starting ACME challenge handler for '%s'", acmeServerString))
+
+	if err := prg.webServerAcme.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		prg.executeAborted(svc, fmt.Errorf("failed to start ACME challenge handler, %v", err))
+	}
+}
+
 // init system with connected database
 func initSystem(ctx context.Context) error {
 	tx, err := db.Pool.Begin(ctx)
@@ -638,6 +703,11 @@ func (prg *program) Stop(svc service.Service) error {
 		}
 		log.Info(log.ContextServer, "stopped web handlers")
 	}
+	if prg.webServerAcme != nil {
+		if err := prg.webServerAcme.Shutdown(ctx); err != nil {
+			prg.logger.Error(err)
+		}
+	}
 
 	// close database connection and deregister cluster node if DB is open
 	if db.Pool != nil {
@@ -12,14 +12,16 @@ import (
 	"r3/handler"
 	"r3/ldap"
 	"r3/log"
+	"r3/login/login_impersonation"
 	"r3/types"
 
+	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
 // executes a websocket transaction with multiple requests within a single DB transaction
 func ExecTransaction(ctx context.Context, address string, loginId int64, isAdmin bool, device types.WebsocketClientDevice,
-	isNoAuth bool, reqTrans types.RequestTransaction, clearDbCache bool) ([]types.Response, error) {
+	isNoAuth bool, impersonatorId int64, tokenId uuid.UUID, reqTrans types.RequestTransaction, clearDbCache bool) ([]types.Response, error) {
 
 	var tx pgx.Tx
 	var err error
@@ -47,7 +49,8 @@ func ExecTransaction(ctx context.Context, address string, loginId int64, isAdmin
 	for _, req := range reqTrans.Requests {
 		log.Info(log.ContextWebsocket, fmt.Sprintf("TRANSACTION %d, %s %s, payload: %s", reqTrans.TransactionNr, req.Action, req.Ressource, req.Payload))
 
-		payload, err := Exec_tx(ctx, tx, address, loginId, isAdmin, device, isNoAuth, req.Ressource, req.Action, req.Payload)
+		payload, err := Exec_tx(ctx, tx, address, loginId, isAdmin, device, isNoAuth,
+			impersonatorId, tokenId, req.Ressource, req.Action, req.Payload)
 		if err != nil {
 			return nil, err
 		}
@@ -67,8 +70,15 @@ func ExecTransaction(ctx context.Context, address string, loginId int64, isAdmin
 }
 
 func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAdmin bool,
-	device types.WebsocketClientDevice, isNoAuth bool, ressource string, action string,
-	reqJson json.RawMessage) (interface{}, error) {
+	device types.WebsocketClientDevice, isNoAuth bool, impersonatorId int64, tokenId uuid.UUID,
+	ressource string, action string, reqJson json.RawMessage) (interface{}, error) {
+
+	// impersonated requests: logged, requests affecting credentials or keys are blocked
+	if impersonatorId != 0 {
+		if err := login_impersonation.Check(ctx, impersonatorId, loginId, ressource, action, reqJson); err != nil {
+			return nil, err
+		}
+	}
 
 	// public requests: accessible to all
 	switch ressource {
@@ -114,6 +124,14 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 			return DataGetKeys_tx(ctx, tx, reqJson, loginId)
 		case "getLog":
 			return DataLogGet_tx(ctx, tx, reqJson, loginId)
+		case "getTrash":
+			return DataTrashGet_tx(ctx, tx, reqJson, loginId)
+		case "restoreLog":
+			return DataLogRestore_tx(ctx, tx, reqJson, loginId)
+		case "revertLog":
+			return DataLogRevert_tx(ctx, tx, reqJson, loginId)
+		case "restoreTrash":
+			return DataTrashRestore_tx(ctx, tx, reqJson, loginId)
 		case "set":
 			return DataSet_tx(ctx, tx, reqJson, loginId)
 		case "setKeys":
@@ -209,6 +227,16 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 			}
 			return loginPasswortSet_tx(ctx, tx, reqJson, loginId)
 		}
+	case "loginRecoveryCode":
+		if isNoAuth {
+			return nil, errors.New(handler.ErrUnauthorized)
+		}
+		switch action {
+		case "get":
+			return LoginRecoveryCodeGet_tx(ctx, tx, loginId)
+		case "set":
+			return LoginRecoveryCodeSet_tx(ctx, tx, loginId)
+		}
 	case "loginSetting":
 		switch action {
 		case "get":
@@ -219,6 +247,32 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 			}
 			return LoginSettingsSet_tx(ctx, tx, reqJson, loginId)
 		}
+	case "loginToken":
+		if isNoAuth {
+			return nil, errors.New(handler.ErrUnauthorized)
+		}
+		switch action {
+		case "get":
+			return LoginTokenGet_tx(ctx, tx, loginId)
+		case "revoke":
+			return LoginTokenRevoke_tx(ctx, tx, reqJson, loginId)
+		case "revokeOthers":
+			return LoginTokenRevokeOthers_tx(ctx, tx, loginId, tokenId)
+		}
+	case "loginWebAuthn":
+		if isNoAuth {
+			return nil, errors.New(handler.ErrUnauthorized)
+		}
+		switch action {
+		case "del":
+			return LoginWebAuthnDel_tx(ctx, tx, reqJson, loginId)
+		case "get":
+			return LoginWebAuthnGet_tx(ctx, tx, loginId)
+		case "registerBegin":
+			return LoginWebAuthnRegisterBegin_tx(ctx, tx, loginId)
+		case "registerFinish":
+			return LoginWebAuthnRegisterFinish_tx(ctx, tx, reqJson, loginId)
+		}
 	case "loginWidgetGroups":
 		switch action {
 		case "get":
@@ -229,7 +283,7 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 	case "lookup":
 		switch action {
 		case "get":
-			return lookupGet_tx(ctx, tx, reqJson, loginId)
+			return lookupGet_tx(ctx, tx, reqJson, loginId, impersonatorId != 0)
 		}
 	case "pgFunction":
 		switch action {
@@ -243,6 +297,27 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		return nil, errors.New(handler.ErrUnauthorized)
 	}
 
+	// admin requests changing the system are written to the audit log
+	if !isAuditedAction(ressource, action) {
+		return execAdmin_tx(ctx, tx, loginId, ressource, action, reqJson)
+	}
+
+	entityId := auditEntityId(reqJson, nil)
+	stateBefore, err := auditStateGet_tx(ctx, tx, ressource, action, entityId, reqJson)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := execAdmin_tx(ctx, tx, loginId, ressource, action, reqJson)
+	if err != nil {
+		return nil, err
+	}
+	return res, auditWrite_tx(ctx, tx, loginId, ressource, action, reqJson, res, stateBefore)
+}
+
+func execAdmin_tx(ctx context.Context, tx pgx.Tx, loginId int64, ressource string,
+	action string, reqJson json.RawMessage) (interface{}, error) {
+
 	switch ressource {
 	case "api":
 		switch action {
@@ -262,6 +337,13 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "set":
 			return ArticleSet_tx(ctx, tx, reqJson)
 		}
+	case "auditLog":
+		switch action {
+		case "get":
+			return AuditLogGet_tx(ctx, tx, reqJson)
+		case "verify":
+			return AuditLogVerify_tx(ctx, tx)
+		}
 	case "attribute":
 		switch action {
 		case "del":
@@ -320,6 +402,11 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "shutdownNode":
 			return ClusterNodeShutdown_tx(ctx, tx, reqJson)
 		}
+	case "dataLog":
+		switch action {
+		case "revertLogin":
+			return DataLogRevertLogin_tx(ctx, tx, reqJson, loginId)
+		}
 	case "dataSql":
 		switch action {
 		case "get":
@@ -353,6 +440,24 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "setName":
 			return IconSetName_tx(ctx, tx, reqJson)
 		}
+	case "job":
+		switch action {
+		case "del":
+			return JobDel_tx(ctx, tx, reqJson)
+		case "get":
+			return JobGet_tx(ctx, tx, reqJson)
+		case "reset":
+			return JobReset_tx(ctx, tx, reqJson)
+		}
+	case "jobQueue":
+		switch action {
+		case "del":
+			return JobQueueDel_tx(ctx, tx, reqJson)
+		case "get":
+			return JobQueueGet_tx(ctx, tx)
+		case "set":
+			return JobQueueSet_tx(ctx, tx, reqJson)
+		}
 	case "jsFunction":
 		switch action {
 		case "del":
@@ -400,8 +505,12 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 			return LoginGetIsNotUnique_tx(ctx, tx, reqJson)
 		case "getMembers":
 			return LoginGetMembers_tx(ctx, tx, reqJson)
+		case "getImpersonationLog":
+			return LoginGetImpersonationLog_tx(ctx, tx, reqJson)
 		case "getRecords":
 			return LoginGetRecords_tx(ctx, tx, reqJson)
+		case "impersonate":
+			return LoginImpersonate_tx(ctx, tx, reqJson, loginId)
 		case "kick":
 			return LoginKick(ctx, tx, reqJson)
 		case "reauth":
@@ -410,11 +519,22 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 			return LoginReauthAll_tx(ctx, tx)
 		case "resetTotp":
 			return LoginResetTotp_tx(ctx, tx, reqJson)
+		case "resetWebAuthn":
+			return LoginResetWebAuthn_tx(ctx, tx, reqJson)
 		case "set":
 			return LoginSet_tx(ctx, tx, reqJson)
 		case "setMembers":
 			return LoginSetMembers_tx(ctx, tx, reqJson)
 		}
+	case "loginClientCert":
+		switch action {
+		case "del":
+			return LoginClientCertDel_tx(ctx, tx, reqJson)
+		case "get":
+			return LoginClientCertGet_tx(ctx, tx, reqJson)
+		case "set":
+			return LoginClientCertSet_tx(ctx, tx, reqJson)
+		}
 	case "loginForm":
 		switch action {
 		case "del":
@@ -428,6 +548,8 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 			return LoginSessionsGet_tx(ctx, tx, reqJson)
 		case "getConcurrent":
 			return LoginSessionConcurrentGet_tx(ctx, tx)
+		case "revoke":
+			return LoginSessionRevoke_tx(ctx, tx, reqJson)
 		}
 	case "loginTemplate":
 		switch action {
@@ -460,6 +582,15 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		case "reset":
 			return MailSpoolerReset_tx(ctx, tx, reqJson)
 		}
+	case "mailTemplate":
+		switch action {
+		case "del":
+			return MailTemplateDel_tx(ctx, tx, reqJson)
+		case "get":
+			return MailTemplateGet_tx(ctx, tx)
+		case "set":
+			return MailTemplateSet_tx(ctx, tx, reqJson)
+		}
 	case "mailTraffic":
 		switch action {
 		case "get":
@@ -572,6 +703,8 @@ func Exec_tx(ctx context.Context, tx pgx.Tx, address string, loginId int64, isAd
 		switch action {
 		case "get":
 			return schedulersGet_tx(ctx, tx)
+		case "getRuns":
+			return schedulerRunsGet_tx(ctx, tx, reqJson)
 		}
 	case "schema":
 		switch action {
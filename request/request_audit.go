@@ -0,0 +1,334 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"r3/audit"
+	"r3/db"
+	"r3/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// larger payloads (like full form definitions) are replaced by their size & hash
+const auditPayloadSizeMax = 65536
+
+// values of these keys are not written to the audit log
+var auditRedactKeys = []string{"bindUserPw", "clientSecret", "dkimPrivateKey", "exportKey", "exportPrivateKey",
+	"pass", "password", "privateKeyEnc", "privateKeyEncBackup", "repoPass", "tokenFixed", "tokenSecret"}
+
+// values of keys with these suffixes are not written to the audit log either, to cover newly added secrets
+var auditRedactKeySuffixes = []string{"Key", "Secret", "Pw", "Pass"}
+
+func AuditLogGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+
+	var (
+		err error
+		req struct {
+			ByString  string      `json:"byString"`
+			DateFrom  pgtype.Int8 `json:"dateFrom"`
+			DateTo    pgtype.Int8 `json:"dateTo"`
+			LoginId   pgtype.Int8 `json:"loginId"`
+			Ressource string      `json:"ressource"`
+			Limit     int         `json:"limit"`
+			Offset    int         `json:"offset"`
+		}
+		res struct {
+			Logs  []types.AuditLog `json:"logs"`
+			Total int              `json:"total"`
+		}
+	)
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	res.Logs, res.Total, err = audit.Get_tx(ctx, tx, req.DateFrom, req.DateTo,
+		req.LoginId, req.Ressource, req.ByString, req.Limit, req.Offset)
+
+	return res, err
+}
+func AuditLogVerify_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
+	return audit.Verify_tx(ctx, tx)
+}
+
+// admin actions that only read or check are not written to the audit log
+func isAuditedAction(ressource string, action string) bool {
+	if strings.HasPrefix(action, "get") {
+		return false
+	}
+	switch action {
+	case "check", "checkChange", "delCheck", "preview", "reload", "test":
+		return false
+	}
+	return ressource != "auditLog" && ressource != "key"
+}
+
+// returns ID of entity affected by admin request
+// taken from request payload or, for new entities, from the response
+func auditEntityId(reqJson json.RawMessage, res interface{}) pgtype.Text {
+	var req map[string]json.RawMessage
+	if err := json.Unmarshal(reqJson, &req); err == nil {
+		for _, key := range []string{"id", "moduleId", "roleId", "loginId"} {
+			raw, exists := req[key]
+			if !exists {
+				continue
+			}
+
+			var v interface{}
+			if err := json.Unmarshal(raw, &v); err == nil {
+				switch id := v.(type) {
+				case float64:
+					if id != 0 {
+						return pgtype.Text{String: strconv.FormatInt(int64(id), 10), Valid: true}
+					}
+				case string:
+					if id != "" && id != uuid.Nil.String() {
+						return pgtype.Text{String: id, Valid: true}
+					}
+				}
+			}
+			break
+		}
+	}
+
+	switch id := res.(type) {
+	case int64:
+		return pgtype.Text{String: strconv.FormatInt(id, 10), Valid: true}
+	case uuid.UUID:
+		return pgtype.Text{String: id.String(), Valid: true}
+	}
+	return pgtype.Text{}
+}
+
+// returns state of entity affected by admin request, used for before/after diffs
+// only available for entities that can be retrieved cheaply, nil otherwise
+func auditStateGet_tx(ctx context.Context, tx pgx.Tx, ressource string, action string,
+	entityId pgtype.Text, reqJson json.RawMessage) (map[string]interface{}, error) {
+
+	if tx == nil {
+		return nil, nil
+	}
+
+	switch ressource + "/" + action {
+	case "config/set":
+		var req map[string]string
+		if err := json.Unmarshal(reqJson, &req); err != nil {
+			return nil, err
+		}
+		cfg, err := configGetMap()
+		if err != nil {
+			return nil, err
+		}
+		state := make(map[string]interface{})
+		for name := range req {
+			if value, exists := cfg[name]; exists {
+				state[name] = value
+			}
+		}
+		return state, nil
+
+	case "login/set":
+		id, err := strconv.ParseInt(entityId.String, 10, 64)
+		if !entityId.Valid || err != nil {
+			return nil, nil
+		}
+		var name string
+		var active, admin, noAuth bool
+		var tokenExpiryHours pgtype.Int4
+		var roleIds []uuid.UUID
+		if err := tx.QueryRow(ctx, `
+			SELECT name, active, admin, no_auth, token_expiry_hours, ARRAY(
+				SELECT role_id
+				FROM instance.login_role
+				WHERE login_id = l.id
+				ORDER BY role_id ASC
+			)
+			FROM instance.login AS l
+			WHERE id = $1
+		`, id).Scan(&name, &active, &admin, &noAuth, &tokenExpiryHours, &roleIds); err != nil {
+			if err == pgx.ErrNoRows {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return map[string]interface{}{
+			"name":             name,
+			"active":           active,
+			"admin":            admin,
+			"noAuth":           noAuth,
+			"tokenExpiryHours": tokenExpiryHours,
+			"roleIds":          roleIds,
+		}, nil
+
+	case "login/setMembers":
+		roleId, err := uuid.FromString(entityId.String)
+		if !entityId.Valid || err != nil {
+			return nil, nil
+		}
+		loginIds := make([]int64, 0)
+		if err := tx.QueryRow(ctx, `
+			SELECT ARRAY(
+				SELECT login_id
+				FROM instance.login_role
+				WHERE role_id = $1
+				ORDER BY login_id ASC
+			)
+		`, roleId).Scan(&loginIds); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"loginIds": loginIds}, nil
+	}
+	return nil, nil
+}
+
+// writes executed admin request to the audit log
+func auditWrite_tx(ctx context.Context, tx pgx.Tx, loginId int64, ressource string, action string,
+	reqJson json.RawMessage, res interface{}, stateBefore map[string]interface{}) error {
+
+	entityId := auditEntityId(reqJson, res)
+
+	payload, err := auditPayloadGet(reqJson)
+	if err != nil {
+		return err
+	}
+
+	var diff []byte
+	if stateBefore != nil || entityId.Valid {
+		stateAfter, err := auditStateGet_tx(ctx, tx, ressource, action, entityId, reqJson)
+		if err != nil {
+			return err
+		}
+		diff, err = auditDiffGet(stateBefore, stateAfter)
+		if err != nil {
+			return err
+		}
+	}
+
+	// requests without DB transaction (like package installs) are logged in their own transaction
+	if tx != nil {
+		return audit.Write_tx(ctx, tx, loginId, ressource, action, entityId, payload, diff)
+	}
+
+	tx, err = db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.Write_tx(ctx, tx, loginId, ressource, action, entityId, payload, diff); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// returns changed values between both states, nil if nothing changed or states are unavailable
+func auditDiffGet(before map[string]interface{}, after map[string]interface{}) ([]byte, error) {
+	if before == nil && after == nil {
+		return nil, nil
+	}
+
+	keys := make([]string, 0)
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, exists := before[k]; !exists {
+			keys = append(keys, k)
+		}
+	}
+
+	diff := make(map[string]interface{})
+	for _, k := range keys {
+		b, err := json.Marshal(before[k])
+		if err != nil {
+			return nil, err
+		}
+		a, err := json.Marshal(after[k])
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(a, b) {
+			continue
+		}
+
+		if isAuditRedactKey(k) {
+			diff[k] = map[string]interface{}{"before": "***", "after": "***"}
+		} else {
+			diff[k] = map[string]interface{}{"before": auditRedact(before[k]), "after": auditRedact(after[k])}
+		}
+	}
+
+	if len(diff) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(diff)
+}
+
+// returns request payload with secrets redacted
+func auditPayloadGet(reqJson json.RawMessage) ([]byte, error) {
+	if len(reqJson) == 0 {
+		return nil, nil
+	}
+
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(reqJson))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	payload, err := json.Marshal(auditRedact(v))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) > auditPayloadSizeMax {
+		hash := sha256.Sum256(payload)
+		return json.Marshal(map[string]interface{}{
+			"size":   len(payload),
+			"sha256": hex.EncodeToString(hash[:]),
+		})
+	}
+	return payload, nil
+}
+
+func auditRedact(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, value := range t {
+			if s, isString := value.(string); isString && s != "" && isAuditRedactKey(k) {
+				t[k] = "***"
+				continue
+			}
+			t[k] = auditRedact(value)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = auditRedact(t[i])
+		}
+	}
+	return v
+}
+
+func isAuditRedactKey(k string) bool {
+	if slices.Contains(auditRedactKeys, k) {
+		return true
+	}
+	for _, suffix := range auditRedactKeySuffixes {
+		if strings.HasSuffix(k, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -13,6 +13,10 @@ import (
 )
 
 func ConfigGet() (interface{}, error) {
+	return configGetMap()
+}
+
+func configGetMap() (map[string]string, error) {
 
 	// not directly changeable configuration options
 	ignore := []string{"dbVersionCut", "tokenSecret"}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func DataGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
@@ -88,6 +89,78 @@ func DataLogGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 	}
 	return data.GetLogs_tx(ctx, tx, req.RecordId, req.AttributeIds, loginId)
 }
+func DataLogRestore_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64) (interface{}, error) {
+
+	var req struct {
+		RelationId uuid.UUID `json:"relationId"`
+		RecordId   int64     `json:"recordId"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return data.RestoreRecord_tx(ctx, tx, req.RelationId, req.RecordId, loginId)
+}
+func DataLogRevert_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64) (interface{}, error) {
+
+	// revert record to log entry or point in time
+	var req struct {
+		LogId      pgtype.UUID `json:"logId"`
+		RelationId uuid.UUID   `json:"relationId"`
+		RecordId   int64       `json:"recordId"`
+		Date       int64       `json:"date"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	if req.LogId.Valid {
+		return nil, data.RevertToLog_tx(ctx, tx, req.LogId.Bytes, loginId)
+	}
+	return nil, data.RevertToDate_tx(ctx, tx, req.RelationId, req.RecordId, req.Date, loginId)
+}
+func DataLogRevertLogin_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64) (interface{}, error) {
+
+	var req struct {
+		LoginId  int64 `json:"loginId"`
+		DateFrom int64 `json:"dateFrom"`
+		DateTo   int64 `json:"dateTo"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return data.RevertLoginChanges_tx(ctx, tx, req.LoginId, req.DateFrom, req.DateTo, loginId)
+}
+
+// data trash
+func DataTrashGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64) (interface{}, error) {
+
+	var req struct {
+		RelationId uuid.UUID `json:"relationId"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return data.GetTrash_tx(ctx, tx, req.RelationId, loginId)
+}
+func DataTrashRestore_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
+	loginId int64) (interface{}, error) {
+
+	var req struct {
+		Id uuid.UUID `json:"id"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, data.RestoreTrash_tx(ctx, tx, req.Id, loginId)
+}
 
 // data SQL
 func DataSqlGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
@@ -0,0 +1,173 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"r3/types"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func JobDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Ids []uuid.UUID `json:"ids"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.job
+		WHERE id = ANY($1)
+	`, req.Ids)
+
+	return nil, err
+}
+
+func JobGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+
+	var (
+		req struct {
+			Limit  int    `json:"limit"`
+			Offset int    `json:"offset"`
+			State  string `json:"state"` // pending, failed, all if empty
+		}
+		res struct {
+			Jobs  []types.Job `json:"jobs"`
+			Total int64       `json:"total"`
+		}
+	)
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, pg_function_id, queue, args, unique_key, priority, state,
+			attempt_count, date_added, date_run_at, date_attempt, error_text
+		FROM instance.job
+		WHERE ($1 = '' OR state::TEXT = $1)
+		ORDER BY date_run_at ASC, priority DESC
+		LIMIT  $2
+		OFFSET $3
+	`, req.State, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res.Jobs = make([]types.Job, 0)
+	for rows.Next() {
+		var j types.Job
+		if err := rows.Scan(&j.Id, &j.PgFunctionId, &j.Queue, &j.Args, &j.UniqueKey,
+			&j.Priority, &j.State, &j.AttemptCount, &j.DateAdded, &j.DateRunAt,
+			&j.DateAttempt, &j.ErrorText); err != nil {
+
+			return nil, err
+		}
+		res.Jobs = append(res.Jobs, j)
+	}
+	rows.Close()
+
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM instance.job
+		WHERE ($1 = '' OR state::TEXT = $1)
+	`, req.State).Scan(&res.Total); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// resets jobs to be executed again as soon as possible
+// jobs are skipped if another pending job with the same unique key exists
+func JobReset_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Ids []uuid.UUID `json:"ids"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE instance.job AS j
+		SET state = 'pending', attempt_count = 0, date_attempt = NULL,
+			date_run_at = EXTRACT(EPOCH FROM NOW()), error_text = NULL
+		WHERE j.id = ANY($1)
+		AND (
+			j.unique_key IS NULL
+			OR j.state = 'pending'
+			OR NOT EXISTS (
+				SELECT id
+				FROM instance.job
+				WHERE unique_key = j.unique_key
+				AND   state      = 'pending'
+			)
+		)
+	`, req.Ids)
+
+	return nil, err
+}
+
+func JobQueueDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.job_queue
+		WHERE name = $1
+	`, req.Name)
+
+	return nil, err
+}
+
+func JobQueueGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
+
+	queues := make([]types.JobQueue, 0)
+	rows, err := tx.Query(ctx, `
+		SELECT name, concurrency
+		FROM instance.job_queue
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var q types.JobQueue
+		if err := rows.Scan(&q.Name, &q.Concurrency); err != nil {
+			return nil, err
+		}
+		queues = append(queues, q)
+	}
+	return queues, nil
+}
+
+func JobQueueSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req types.JobQueue
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		return nil, errors.New("job queue name must not be empty")
+	}
+	if req.Concurrency < 0 {
+		return nil, errors.New("job queue concurrency must not be negative")
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO instance.job_queue (name, concurrency)
+		VALUES ($1,$2)
+		ON CONFLICT (name) DO UPDATE
+			SET concurrency = $2
+	`, req.Name, req.Concurrency)
+
+	return nil, err
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"r3/cluster"
 	"r3/login"
+	"r3/login/login_auth"
+	"r3/login/login_impersonation"
 	"r3/login/login_meta"
 	"r3/login/login_role"
 	"r3/types"
@@ -222,3 +224,35 @@ func LoginResetTotp_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage)
 	}
 	return nil, login.ResetTotp_tx(ctx, tx, req.Id)
 }
+func LoginGetImpersonationLog_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+
+	var (
+		err error
+		req struct {
+			Id     int64 `json:"id"`
+			Limit  int   `json:"limit"`
+			Offset int   `json:"offset"`
+		}
+		res struct {
+			Logs  []types.LoginImpersonationLog `json:"logs"`
+			Total int64                         `json:"total"`
+		}
+	)
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	res.Logs, res.Total, err = login_impersonation.Get_tx(ctx, tx, req.Id, req.Limit, req.Offset)
+	return res, err
+}
+func LoginImpersonate_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, impersonatorId int64) (interface{}, error) {
+
+	var req struct {
+		Id int64 `json:"id"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return login_auth.Impersonate_tx(ctx, tx, impersonatorId, req.Id)
+}
@@ -2,6 +2,7 @@ package request
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
 	"r3/login/login_auth"
 	"r3/types"
@@ -17,14 +18,22 @@ func LoginAuthUser(ctx context.Context, reqJson json.RawMessage) (types.LoginAut
 		Username string `json:"username"`
 		Password string `json:"password"`
 
+		// new password, sent if current password has expired
+		PwNew types.LoginPasswordNew `json:"pwNew"`
+
 		// MFA details, sent together with credentials (usually on second auth attempt)
-		MfaTokenId  pgtype.Int4 `json:"mfaTokenId"`
-		MfaTokenPin pgtype.Text `json:"mfaTokenPin"`
+		MfaTokenId      pgtype.Int4 `json:"mfaTokenId"`
+		MfaTokenPin     pgtype.Text `json:"mfaTokenPin"`
+		MfaRecoveryCode pgtype.Text `json:"mfaRecoveryCode"`
+
+		// WebAuthn assertion, sent as second factor or instead of password (passwordless)
+		WebAuthn types.LoginWebAuthnAssertion `json:"webAuthn"`
 	}
 	if err := json.Unmarshal(reqJson, &req); err != nil {
 		return types.LoginAuthResult{}, err
 	}
-	return login_auth.User(ctx, req.Username, req.Password, req.MfaTokenId, req.MfaTokenPin)
+	return login_auth.User(ctx, req.Username, req.Password, req.PwNew, req.MfaTokenId,
+		req.MfaTokenPin, req.MfaRecoveryCode, req.WebAuthn, true)
 }
 
 // attempt login via Open ID Connect
@@ -65,3 +74,9 @@ func LoginAuthTokenFixed(ctx context.Context, reqJson json.RawMessage) (types.Lo
 	}
 	return login_auth.TokenFixed(ctx, req.LoginId, "client", req.TokenFixed)
 }
+
+// attempt login via verified TLS client certificate
+// applies login ID & admin to provided parameters if successful
+func LoginAuthCert(ctx context.Context, cert *x509.Certificate) (types.LoginAuthResult, error) {
+	return login_auth.Cert(ctx, cert, true)
+}
@@ -0,0 +1,42 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"r3/login/login_clientCert"
+	"r3/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func LoginClientCertDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		LoginId         int64                 `json:"loginId"`
+		LoginClientCert types.LoginClientCert `json:"loginClientCert"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, login_clientCert.Del_tx(ctx, tx, req.LoginId, req.LoginClientCert)
+}
+
+func LoginClientCertGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		LoginId int64 `json:"loginId"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return login_clientCert.Get_tx(ctx, tx, req.LoginId)
+}
+
+func LoginClientCertSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		LoginId         int64                 `json:"loginId"`
+		LoginClientCert types.LoginClientCert `json:"loginClientCert"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, login_clientCert.Set_tx(ctx, tx, req.LoginId, req.LoginClientCert)
+}
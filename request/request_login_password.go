@@ -31,6 +31,12 @@ func loginPasswortSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage
 	if err := login_check.PasswordComplexity(req.PwNew0); err != nil {
 		return nil, err
 	}
+	if err := login_check.PasswordHistory_tx(ctx, tx, loginId, req.PwNew0); err != nil {
+		return nil, err
+	}
+	if err := login_check.PasswordBreached(req.PwNew0); err != nil {
+		return nil, err
+	}
 
 	salt, hash := login.GenerateSaltHash(req.PwNew0)
 	return nil, login.SetSaltHash_tx(ctx, tx, salt, hash, loginId)
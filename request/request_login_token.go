@@ -0,0 +1,40 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"r3/login/login_token"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// user requests
+func LoginTokenGet_tx(ctx context.Context, tx pgx.Tx, loginId int64) (interface{}, error) {
+	return login_token.Get_tx(ctx, tx, loginId)
+}
+func LoginTokenRevoke_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId int64) (interface{}, error) {
+	var req struct {
+		Id uuid.UUID `json:"id"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, login_token.Revoke_tx(ctx, tx, loginId, req.Id)
+}
+func LoginTokenRevokeOthers_tx(ctx context.Context, tx pgx.Tx, loginId int64, tokenId uuid.UUID) (interface{}, error) {
+	// token of requesting session is kept, sessions using it stay connected
+	return nil, login_token.RevokeOthers_tx(ctx, tx, loginId, tokenId)
+}
+
+// admin requests
+func LoginSessionRevoke_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		LoginId int64     `json:"loginId"`
+		TokenId uuid.UUID `json:"tokenId"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, login_token.Revoke_tx(ctx, tx, req.LoginId, req.TokenId)
+}
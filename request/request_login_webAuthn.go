@@ -0,0 +1,56 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"r3/login/login_recoveryCode"
+	"r3/login/login_webAuthn"
+	"r3/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// user requests
+func LoginRecoveryCodeGet_tx(ctx context.Context, tx pgx.Tx, loginId int64) (interface{}, error) {
+	return login_recoveryCode.Count_tx(ctx, tx, loginId)
+}
+func LoginRecoveryCodeSet_tx(ctx context.Context, tx pgx.Tx, loginId int64) (interface{}, error) {
+	return login_recoveryCode.Set_tx(ctx, tx, loginId)
+}
+
+func LoginWebAuthnDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId int64) (interface{}, error) {
+	var req struct {
+		Id int64 `json:"id"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, login_webAuthn.Del_tx(ctx, tx, loginId, req.Id)
+}
+func LoginWebAuthnGet_tx(ctx context.Context, tx pgx.Tx, loginId int64) (interface{}, error) {
+	return login_webAuthn.Get_tx(ctx, tx, loginId)
+}
+func LoginWebAuthnRegisterBegin_tx(ctx context.Context, tx pgx.Tx, loginId int64) (interface{}, error) {
+	return login_webAuthn.RegisterBegin_tx(ctx, tx, loginId)
+}
+func LoginWebAuthnRegisterFinish_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId int64) (interface{}, error) {
+	var req struct {
+		Name     string                       `json:"name"`
+		WebAuthn types.LoginWebAuthnAssertion `json:"webAuthn"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, login_webAuthn.RegisterFinish_tx(ctx, tx, loginId, req.Name, req.WebAuthn)
+}
+
+// admin requests
+func LoginResetWebAuthn_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Id int64 `json:"id"`
+	}
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+	return nil, login_webAuthn.Reset_tx(ctx, tx, req.Id)
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-func lookupGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId int64) (interface{}, error) {
+func lookupGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, loginId int64, isImpersonated bool) (interface{}, error) {
 
 	var req struct {
 		Name string `json:"name"`
@@ -54,6 +54,11 @@ func lookupGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage, login
 			Public           pgtype.Text `json:"public"`
 		}
 
+		// keys of impersonated login are not available, as if encryption was not set up
+		if isImpersonated {
+			return res, nil
+		}
+
 		err := tx.QueryRow(ctx, `
 			SELECT key_private_enc, key_private_enc_backup, key_public
 			FROM instance.login
@@ -2,9 +2,13 @@ package request
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"r3/cache"
+	"r3/tools"
 	"r3/types"
 
 	"github.com/jackc/pgx/v5"
@@ -39,6 +43,30 @@ func MailAccountSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage)
 	var err error
 	newRecord := req.Id == 0
 
+	// IMAP & DKIM options missing from request keep their existing values (older clients)
+	// existing values are loaded first and then overwritten by options given in request
+	if !newRecord {
+		if err := tx.QueryRow(ctx, `
+			SELECT dkim_domain, dkim_selector, dkim_private_key,
+				imap_folder, imap_folder_archive, imap_action, imap_idle, imap_store_raw
+			FROM instance.mail_account
+			WHERE id = $1
+		`, req.Id).Scan(&req.DkimDomain, &req.DkimSelector, &req.DkimPrivateKey,
+			&req.ImapFolder, &req.ImapFolderArchive, &req.ImapAction, &req.ImapIdle,
+			&req.ImapStoreRaw); err != nil {
+
+			return nil, err
+		}
+		if err := json.Unmarshal(reqJson, &req); err != nil {
+			return nil, err
+		}
+	}
+
+	// processed messages are deleted, if no action is given (older clients)
+	if req.ImapAction == "" {
+		req.ImapAction = "delete"
+	}
+
 	if req.AuthMethod == "xoauth2" {
 		if !req.OauthClientId.Valid {
 			return nil, errors.New("cannot set email account with OAuth authentication but no OAuth client")
@@ -47,25 +75,64 @@ func MailAccountSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage)
 		req.OauthClientId.Valid = false
 	}
 
+	// IMAP options are only relevant for incoming mails
+	if req.Mode != "imap" {
+		req.ImapFolder.Valid = false
+		req.ImapFolderArchive.Valid = false
+		req.ImapAction = "delete"
+		req.ImapIdle = false
+		req.ImapStoreRaw = false
+	} else if req.ImapAction == "move" && (!req.ImapFolderArchive.Valid || req.ImapFolderArchive.String == "") {
+		return nil, errors.New("cannot set email account to move processed messages without archive folder")
+	}
+
+	// DKIM signing is only relevant for outgoing mails
+	if req.Mode != "smtp" || !req.DkimPrivateKey.Valid || req.DkimPrivateKey.String == "" {
+		req.DkimDomain.Valid = false
+		req.DkimSelector.Valid = false
+		req.DkimPrivateKey.Valid = false
+	} else {
+		if !req.DkimDomain.Valid || !req.DkimSelector.Valid {
+			return nil, errors.New("cannot set email account with DKIM key but no DKIM domain or selector")
+		}
+		key, err := tools.ParsePrivKeyPem(req.DkimPrivateKey.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DKIM private key, %s", err)
+		}
+		switch key.(type) {
+		case *rsa.PrivateKey, ed25519.PrivateKey:
+		default:
+			return nil, errors.New("invalid DKIM private key, RSA or Ed25519 key required")
+		}
+	}
+
 	if newRecord {
 		_, err = tx.Exec(ctx, `
 			INSERT INTO instance.mail_account (oauth_client_id, name, mode,
 				auth_method, send_as, username, password, start_tls, host_name,
-				host_port, comment)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+				host_port, comment, dkim_domain, dkim_selector, dkim_private_key,
+				imap_folder, imap_folder_archive, imap_action, imap_idle, imap_store_raw)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19)
 		`, req.OauthClientId, req.Name, req.Mode, req.AuthMethod, req.SendAs,
 			req.Username, req.Password, req.StartTls, req.HostName, req.HostPort,
-			req.Comment)
+			req.Comment, req.DkimDomain, req.DkimSelector, req.DkimPrivateKey,
+			req.ImapFolder, req.ImapFolderArchive, req.ImapAction, req.ImapIdle,
+			req.ImapStoreRaw)
 	} else {
 		_, err = tx.Exec(ctx, `
 			UPDATE instance.mail_account
 			SET oauth_client_id = $1, name = $2, mode = $3, auth_method = $4,
 				send_as = $5, username = $6, password = $7, start_tls = $8,
-				host_name = $9, host_port = $10, comment = $11
-			WHERE id = $12
+				host_name = $9, host_port = $10, comment = $11, dkim_domain = $12,
+				dkim_selector = $13, dkim_private_key = $14, imap_folder = $15,
+				imap_folder_archive = $16, imap_action = $17, imap_idle = $18,
+				imap_store_raw = $19
+			WHERE id = $20
 		`, req.OauthClientId, req.Name, req.Mode, req.AuthMethod, req.SendAs,
 			req.Username, req.Password, req.StartTls, req.HostName, req.HostPort,
-			req.Comment, req.Id)
+			req.Comment, req.DkimDomain, req.DkimSelector, req.DkimPrivateKey,
+			req.ImapFolder, req.ImapFolderArchive, req.ImapAction, req.ImapIdle,
+			req.ImapStoreRaw, req.Id)
 	}
 	return nil, err
 }
@@ -0,0 +1,126 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"r3/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func MailTemplateDel_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req struct {
+		Id int32 `json:"id"`
+	}
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM instance.mail_template
+		WHERE id = $1
+	`, req.Id)
+	return nil, err
+}
+
+func MailTemplateGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
+	templates := make([]types.MailTemplate, 0)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, name, comment
+		FROM instance.mail_template
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var t types.MailTemplate
+		if err := rows.Scan(&t.Id, &t.Name, &t.Comment); err != nil {
+			return nil, err
+		}
+		t.Captions = types.CaptionMap{
+			"subject":  make(map[string]string),
+			"bodyHtml": make(map[string]string),
+			"bodyText": make(map[string]string),
+		}
+		templates = append(templates, t)
+	}
+	rows.Close()
+
+	for i, t := range templates {
+		rows, err := tx.Query(ctx, `
+			SELECT language_code, subject, body_html, body_text
+			FROM instance.mail_template_caption
+			WHERE mail_template_id = $1
+		`, t.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var code, subject, bodyHtml, bodyText string
+			if err := rows.Scan(&code, &subject, &bodyHtml, &bodyText); err != nil {
+				return nil, err
+			}
+			templates[i].Captions["subject"][code] = subject
+			templates[i].Captions["bodyHtml"][code] = bodyHtml
+			templates[i].Captions["bodyText"][code] = bodyText
+		}
+		rows.Close()
+	}
+	return templates, nil
+}
+
+func MailTemplateSet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+	var req types.MailTemplate
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	if req.Name == "" {
+		return nil, errors.New("cannot set mail template without name")
+	}
+
+	if req.Id == 0 {
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO instance.mail_template (name, comment)
+			VALUES ($1,$2)
+			RETURNING id
+		`, req.Name, req.Comment).Scan(&req.Id); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := tx.Exec(ctx, `
+			UPDATE instance.mail_template
+			SET name = $1, comment = $2
+			WHERE id = $3
+		`, req.Name, req.Comment, req.Id); err != nil {
+			return nil, err
+		}
+	}
+
+	// replace captions, a language is defined by its subject
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM instance.mail_template_caption
+		WHERE mail_template_id = $1
+	`, req.Id); err != nil {
+		return nil, err
+	}
+
+	for code, subject := range req.Captions["subject"] {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.mail_template_caption (mail_template_id,
+				language_code, subject, body_html, body_text)
+			VALUES ($1,$2,$3,$4,$5)
+		`, req.Id, code, subject, req.Captions["bodyHtml"][code],
+			req.Captions["bodyText"][code]); err != nil {
+
+			return nil, err
+		}
+	}
+	return req.Id, nil
+}
@@ -47,7 +47,8 @@ func MailTrafficGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage)
 
 	rows, err := tx.Query(ctx, fmt.Sprintf(`
 		SELECT from_list, to_list, cc_list, bcc_list,
-			subject, outgoing, date, files, mail_account_id
+			subject, outgoing, date, files, mail_account_id, message_id,
+			delivery_action, delivery_date
 		FROM instance.mail_traffic
 		%s
 		ORDER BY date DESC
@@ -63,7 +64,8 @@ func MailTrafficGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage)
 	for rows.Next() {
 		var m types.MailTraffic
 		if err := rows.Scan(&m.FromList, &m.ToList, &m.CcList, &m.BccList,
-			&m.Subject, &m.Outgoing, &m.Date, &m.Files, &m.AccountId); err != nil {
+			&m.Subject, &m.Outgoing, &m.Date, &m.Files, &m.AccountId, &m.MessageId,
+			&m.DeliveryAction, &m.DeliveryDate); err != nil {
 
 			return nil, err
 		}
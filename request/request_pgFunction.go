@@ -3,11 +3,13 @@ package request
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"r3/cache"
 	"r3/handler"
 	"r3/schema/pgFunction"
 	"r3/types"
+	"slices"
 	"strings"
 
 	"github.com/gofrs/uuid"
@@ -31,8 +33,9 @@ func PgFunctionExec_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 	defer cache.Schema_mx.RUnlock()
 
 	var req struct {
-		Id   uuid.UUID     `json:"id"`
-		Args []interface{} `json:"args"`
+		Id       uuid.UUID     `json:"id"`
+		Args     []interface{} `json:"args"`
+		ArgNames []string      `json:"argNames"` // optional, arguments in named notation
 	}
 
 	if err := json.Unmarshal(reqJson, &req); err != nil {
@@ -53,8 +56,21 @@ func PgFunctionExec_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage,
 	mod := cache.ModuleIdMap[fnc.ModuleId]
 
 	placeholders := make([]string, 0)
-	for i, _ := range req.Args {
-		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+	if req.ArgNames == nil {
+		for i := range req.Args {
+			placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+		}
+	} else {
+		if len(req.ArgNames) != len(req.Args) {
+			return nil, errors.New("number of argument names does not match number of arguments")
+		}
+		argNames := pgFunction.GetArgNames(fnc.CodeArgs)
+		for i, name := range req.ArgNames {
+			if !slices.Contains(argNames, name) {
+				return nil, fmt.Errorf("unknown function argument '%s'", name)
+			}
+			placeholders = append(placeholders, fmt.Sprintf(`"%s" => $%d`, name, i+1))
+		}
 	}
 
 	var returnIf interface{}
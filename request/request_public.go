@@ -28,6 +28,7 @@ func PublicGet() (interface{}, error) {
 		AppNameShort           string                            `json:"appNameShort"`
 		AppVersion             string                            `json:"appVersion"`
 		CaptionMapCustom       types.CaptionMapsAll              `json:"captionMapCustom"`
+		ClientCertAuth         bool                              `json:"clientCertAuth"`
 		ClusterNodeName        string                            `json:"clusterNodeName"`
 		CompanyColorHeader     string                            `json:"companyColorHeader"`
 		CompanyColorLogin      string                            `json:"companyColorLogin"`
@@ -54,6 +55,7 @@ func PublicGet() (interface{}, error) {
 		AppNameShort:           config.GetString("appNameShort"),
 		AppVersion:             config.GetAppVersion().Full,
 		CaptionMapCustom:       cache.GetCaptionMapCustom(),
+		ClientCertAuth:         config.File.Web.ClientCa != "",
 		ClusterNodeName:        cache.GetNodeName(),
 		CompanyColorHeader:     config.GetString("companyColorHeader"),
 		CompanyColorLogin:      config.GetString("companyColorLogin"),
@@ -2,9 +2,12 @@ package request
 
 import (
 	"context"
+	"encoding/json"
+	"r3/types"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
@@ -15,6 +18,7 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
 		DateSuccess int64  `json:"dateSuccess"`
 	}
 	type task struct {
+		Id                   int64         `json:"id"`
 		Active               bool          `json:"active"`
 		ActiveOnly           bool          `json:"activeOnly"`
 		ClusterMasterOnly    bool          `json:"clusterMasterOnly"`
@@ -30,7 +34,8 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
 	tasks := make([]task, 0)
 
 	rows, err := tx.Query(ctx, `
-		SELECT fs.pg_function_id,
+		SELECT s.id,
+			fs.pg_function_id,
 			s.pg_function_schedule_id,
 			s.date_attempt,
 			s.date_success,
@@ -70,7 +75,7 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
 	for rows.Next() {
 		var t task
 
-		if err := rows.Scan(&t.PgFunctionId, &t.PgFunctionScheduleId,
+		if err := rows.Scan(&t.Id, &t.PgFunctionId, &t.PgFunctionScheduleId,
 			&t.DateAttempt, &t.DateSuccess, &t.TaskName, &t.IntervalType,
 			&t.IntervalValue, &t.ClusterMasterOnly, &t.ActiveOnly,
 			&t.Active, &t.NodeMeta); err != nil {
@@ -81,3 +86,61 @@ func schedulersGet_tx(ctx context.Context, tx pgx.Tx) (interface{}, error) {
 	}
 	return tasks, nil
 }
+
+func schedulerRunsGet_tx(ctx context.Context, tx pgx.Tx, reqJson json.RawMessage) (interface{}, error) {
+
+	var (
+		req struct {
+			Limit      int         `json:"limit"`
+			Offset     int         `json:"offset"`
+			OnlyFailed bool        `json:"onlyFailed"`
+			ScheduleId pgtype.Int8 `json:"scheduleId"` // runs of specific schedule, all if NULL
+		}
+		res struct {
+			Runs  []types.SchedulerRun `json:"runs"`
+			Total int64                `json:"total"`
+		}
+	)
+
+	if err := json.Unmarshal(reqJson, &req); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT r.schedule_id, n.name, r.date_start, r.date_end,
+			r.duration_ms, r.success, r.error_text, r.rows_affected
+		FROM instance.schedule_run AS r
+		LEFT JOIN instance_cluster.node AS n ON n.id = r.node_id
+		WHERE ($1::INTEGER IS NULL OR r.schedule_id = $1)
+		AND   (NOT $2 OR NOT r.success)
+		ORDER BY r.date_start DESC
+		LIMIT  $3
+		OFFSET $4
+	`, req.ScheduleId, req.OnlyFailed, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res.Runs = make([]types.SchedulerRun, 0)
+	for rows.Next() {
+		var r types.SchedulerRun
+		if err := rows.Scan(&r.ScheduleId, &r.NodeName, &r.DateStart, &r.DateEnd,
+			&r.DurationMs, &r.Success, &r.ErrorText, &r.RowsAffected); err != nil {
+
+			return nil, err
+		}
+		res.Runs = append(res.Runs, r)
+	}
+	rows.Close()
+
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM instance.schedule_run
+		WHERE ($1::INTEGER IS NULL OR schedule_id = $1)
+		AND   (NOT $2 OR NOT success)
+	`, req.ScheduleId, req.OnlyFailed).Scan(&res.Total); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
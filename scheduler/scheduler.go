@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"r3/acme"
 	"r3/backup"
 	"r3/bruteforce"
 	"r3/cache"
@@ -17,6 +18,7 @@ import (
 	"r3/repo"
 	"r3/schema"
 	"r3/spooler/file_process"
+	"r3/spooler/job_process"
 	"r3/spooler/mail_attach"
 	"r3/spooler/mail_receive"
 	"r3/spooler/mail_send"
@@ -24,6 +26,7 @@ import (
 	"r3/tools"
 	"r3/transfer"
 	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -53,8 +56,15 @@ type taskSchedule struct {
 	id                int64  // schedule ID
 	clusterMasterOnly bool   // schedule only to be executed by cluster master (instead of by all nodes)
 	interval          int64  // execution interval
-	intervalType      string // type of interval (seconds, minutes, hours, days, weeks, months, years, once)
+	intervalType      string // type of interval (seconds, minutes, hours, days, weeks, months, years, once, cron)
 	runLastUnix       int64  // unix time of last execution time of this schedule
+	catchUp           string // policy for runs missed during downtime (skip, once, all)
+
+	// cron expression for interval type cron
+	cron tools.Cron
+
+	// time zone that target days/times refer to, server time zone if nil
+	location *time.Location
 
 	// target day for interval types weeks/months
 	atDay int
@@ -71,6 +81,7 @@ var (
 	loadCounter             int            = 0    // number of times tasks were loaded - used to check whether tasks were reloaded during execution
 	nextExecutionUnix       int64          = 0    // unix time of next (earliest) task to run
 	oneDayInSeconds         int64          = 60 * 60 * 24
+	pgFunctionIntegerTypes  []string       = []string{"BIGINT", "INT", "INT2", "INT4", "INT8", "INTEGER", "SMALLINT"}
 	tasks                   []task         // all tasks
 	tasksDisabledMirrorMode []string       = []string{"adminMails", "backupRun", "mailAttach", "mailRetrieve", "mailSend", "restExecute"}
 	OsExit                  chan os.Signal = make(chan os.Signal)
@@ -238,10 +249,17 @@ func runTaskByIndex(taskIndex int) {
 			t.nameLog), err)
 	}
 
+	var rowsAffected pgtype.Int8
+	dateStart := time.Now()
+
 	if t.isSystemTask {
 		err = t.fn()
 	} else {
-		err = runPgFunction(t.pgFunctionId)
+		rowsAffected, err = runPgFunction(t.pgFunctionId)
+	}
+
+	if err := storeTaskRun(t, dateStart, time.Now(), rowsAffected, err); err != nil {
+		log.Error(log.ContextScheduler, fmt.Sprintf("task '%s' failed to store its run history", t.nameLog), err)
 	}
 
 	if err == nil {
@@ -261,6 +279,11 @@ func runTaskByIndex(taskIndex int) {
 	} else {
 		s := t.pgFunctionScheduleIdMap[t.pgFunctionScheduleIdNext]
 		s.runLastUnix = tools.GetTimeUnix()
+
+		// to catch up on all missed runs, continue from the planned instead of the actual run time
+		if s.catchUp == "all" && t.runNextUnix < s.runLastUnix {
+			s.runLastUnix = t.runNextUnix
+		}
 		t.pgFunctionScheduleIdMap[t.pgFunctionScheduleIdNext] = s
 		t.runNextUnix, t.pgFunctionScheduleIdNext = getNextRunScheduleFromTask(t)
 	}
@@ -327,6 +350,7 @@ func load() error {
 
 		// system tasks currently have a single schedule, every x seconds
 		s.intervalType = "seconds"
+		s.catchUp = "once"
 
 		// system task schedule never ran, use now as starting point
 		// update check should however run immediately (in case of important security update)
@@ -339,6 +363,9 @@ func load() error {
 		t.runNextUnix = getNextRunFromSchedule(s)
 
 		switch t.name {
+		case "acmeRenew":
+			t.nameLog = "Renewal of ACME certificate"
+			t.fn = acme.Renew
 		case "adminMails":
 			t.nameLog = "Admin notification mails"
 			t.fn = adminMails
@@ -354,6 +381,12 @@ func load() error {
 		case "cleanupDataLogs":
 			t.nameLog = "Cleanup of data change logs"
 			t.fn = data.DelLogsBackground
+		case "cleanupDataTrash":
+			t.nameLog = "Cleanup of expired recycle bin entries"
+			t.fn = data.DelTrashBackground
+		case "cleanupLoginTokens":
+			t.nameLog = "Cleanup of expired login tokens"
+			t.fn = cleanupLoginTokens
 		case "cleanupLogs":
 			t.nameLog = "Cleanup of system logs"
 			t.fn = cleanupLogs
@@ -363,6 +396,9 @@ func load() error {
 		case "cleanupMailTraffic":
 			t.nameLog = "Cleanup of mail traffic entries"
 			t.fn = cleanupMailTraffic
+		case "cleanupSchedulerRuns":
+			t.nameLog = "Cleanup of scheduler run history"
+			t.fn = cleanupSchedulerRuns
 		case "clusterCheckIn":
 			t.nameLog = "Cluster node check-in to database"
 			t.fn = cluster.CheckInNode
@@ -381,6 +417,9 @@ func load() error {
 		case "importLdapLogins":
 			t.nameLog = "Import from LDAP connections"
 			t.fn = ldap_import.RunAll
+		case "jobsProcess":
+			t.nameLog = "Background job processing"
+			t.fn = job_process.DoAll
 		case "mailAttach":
 			t.nameLog = "Email attachment transfer"
 			t.fn = mail_attach.DoAll
@@ -416,7 +455,7 @@ func load() error {
 		rows, err = db.Pool.Query(context.Background(), `
 			SELECT f.name, fs.pg_function_id, fs.id, fs.at_hour, fs.at_minute,
 				fs.at_second, fs.at_day, fs.interval_type, fs.interval_value,
-				s.id, s.date_attempt
+				fs.cron, fs.timezone, fs.catch_up, s.id, s.date_attempt
 			FROM app.pg_function AS f
 			INNER JOIN app.pg_function_schedule AS fs ON fs.pg_function_id = f.id
 			INNER JOIN instance.schedule AS s
@@ -434,17 +473,41 @@ func load() error {
 			var t task
 			var s taskSchedule
 			var pgFunctionScheduleId uuid.UUID
+			var cron, timezone string
 
 			t.pgFunctionScheduleIdMap = make(map[uuid.UUID]taskSchedule)
 
 			if err := rows.Scan(&t.name, &t.pgFunctionId, &pgFunctionScheduleId,
 				&s.atHour, &s.atMinute, &s.atSecond, &s.atDay, &s.intervalType,
-				&s.interval, &s.id, &s.runLastUnix); err != nil {
+				&s.interval, &cron, &timezone, &s.catchUp, &s.id, &s.runLastUnix); err != nil {
 
 				return err
 			}
 			t.nameLog = t.name
 
+			if timezone != "" {
+				s.location, err = time.LoadLocation(timezone)
+				if err != nil {
+					log.Error(log.ContextScheduler, fmt.Sprintf("schedule of task '%s' has unknown time zone '%s', using server time zone",
+						t.nameLog, timezone), err)
+
+					s.location = nil
+				}
+			}
+
+			if s.intervalType == "cron" {
+				s.cron, err = tools.ParseCron(cron)
+				if err != nil {
+					log.Error(log.ContextScheduler, fmt.Sprintf("schedule of task '%s' is ignored", t.nameLog), err)
+					continue
+				}
+
+				// cron schedule never ran, start from now instead of running immediately
+				if s.runLastUnix == 0 {
+					s.runLastUnix = tools.GetTimeUnix()
+				}
+			}
+
 			if _, exists := pgFunctionIdMapTasks[t.pgFunctionId]; exists {
 				t = pgFunctionIdMapTasks[t.pgFunctionId]
 			}
@@ -465,25 +528,38 @@ func load() error {
 }
 
 // helpers
-func runPgFunction(pgFunctionId uuid.UUID) error {
+// executes PG function, returns its result if it returns an integer (as in number of affected rows)
+func runPgFunction(pgFunctionId uuid.UUID) (pgtype.Int8, error) {
+	var rowsAffected pgtype.Int8
+
 	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutPgFunc)
 	defer ctxCanc()
 
 	tx, err := db.Pool.Begin(ctx)
 	if err != nil {
-		return err
+		return rowsAffected, err
 	}
 	defer tx.Rollback(ctx)
 
 	modName, fncName, _, _, err := schema.GetPgFunctionDetailsById_tx(ctx, tx, pgFunctionId)
 	if err != nil {
-		return err
+		return rowsAffected, err
 	}
 
-	if _, err := tx.Exec(ctx, fmt.Sprintf(`SELECT "%s"."%s"()`, modName, fncName)); err != nil {
-		return err
+	cache.Schema_mx.RLock()
+	returnsInteger := slices.Contains(pgFunctionIntegerTypes,
+		strings.ToUpper(strings.TrimSpace(cache.PgFunctionIdMap[pgFunctionId].CodeReturns)))
+	cache.Schema_mx.RUnlock()
+
+	if returnsInteger {
+		err = tx.QueryRow(ctx, fmt.Sprintf(`SELECT "%s"."%s"()`, modName, fncName)).Scan(&rowsAffected)
+	} else {
+		_, err = tx.Exec(ctx, fmt.Sprintf(`SELECT "%s"."%s"()`, modName, fncName))
 	}
-	return tx.Commit(ctx)
+	if err != nil {
+		return rowsAffected, err
+	}
+	return rowsAffected, tx.Commit(ctx)
 }
 
 // get unix time and index of task schedule to run next
@@ -493,7 +569,7 @@ func getNextRunScheduleFromTask(t task) (int64, uuid.UUID) {
 	var nextRunId uuid.UUID = uuid.Nil
 
 	for id, s := range t.pgFunctionScheduleIdMap {
-		nextRunSchedule := getNextRunFromSchedule(s)
+		nextRunSchedule := getNextRunWithCatchUp(s)
 
 		// apply schedule if
 		// * next planned run is stopped (-1)
@@ -506,6 +582,37 @@ func getNextRunScheduleFromTask(t task) (int64, uuid.UUID) {
 	return nextRun, nextRunId
 }
 
+// applies catch-up policy if schedule missed runs (as in during downtime)
+// skip: ignore missed runs, continue with next regular run
+// once: run once immediately, then continue with regular runs (default)
+// all:  run immediately until all missed runs are caught up (s. runTaskByIndex)
+func getNextRunWithCatchUp(s taskSchedule) int64 {
+	nextRun := getNextRunFromSchedule(s)
+	now := tools.GetTimeUnix()
+
+	if s.catchUp != "skip" || s.intervalType == "once" || nextRun == -1 || nextRun >= now {
+		return nextRun
+	}
+
+	switch s.intervalType {
+	case "cron":
+		s.runLastUnix = now - 1
+		return getNextRunFromSchedule(s)
+	case "seconds", "minutes", "hours":
+		// fixed intervals, skip all missed runs at once
+		if step := nextRun - s.runLastUnix; step > 0 {
+			return nextRun + ((now-nextRun)/step+1)*step
+		}
+	}
+
+	// advance through missed runs, as long as schedule progresses
+	for nextRun > s.runLastUnix && nextRun < now {
+		s.runLastUnix = nextRun
+		nextRun = getNextRunFromSchedule(s)
+	}
+	return nextRun
+}
+
 func getNextRunFromSchedule(s taskSchedule) int64 {
 
 	// run without schedule, just once
@@ -526,9 +633,24 @@ func getNextRunFromSchedule(s taskSchedule) int64 {
 		return s.runLastUnix + (s.interval * 60 * 60)
 	}
 
+	// target days/times are applied in schedule time zone (local time if not defined)
+	// date operations in this time zone keep target times stable over daylight saving changes
+	loc := time.Local
+	if s.location != nil {
+		loc = s.location
+	}
+
+	// cron expressions, next matching time after last run
+	if s.intervalType == "cron" {
+		tm := s.cron.Next(time.Unix(s.runLastUnix, 0).In(loc))
+		if tm.IsZero() {
+			return -1
+		}
+		return tm.Unix()
+	}
+
 	// more complex intervals, add dates and set to target day/time
-	// as no timezone is defined, tm will be in local time, which will affect all date operations
-	tm := time.Unix(s.runLastUnix, 0)
+	tm := time.Unix(s.runLastUnix, 0).In(loc)
 
 	switch s.intervalType {
 	case "days":
@@ -563,7 +685,7 @@ func getNextRunFromSchedule(s taskSchedule) int64 {
 		targetMonth = 1
 	}
 
-	// apply target month/day and time at local time
+	// apply target month/day and time in schedule time zone
 	tm = time.Date(tm.Year(), targetMonth, targetDay, s.atHour, s.atMinute,
 		s.atSecond, 0, tm.Location())
 
@@ -607,6 +729,6 @@ func storeTaskDate(t task, dateContent string) error {
 		UPDATE instance.schedule
 		SET date_%s = $1
 		WHERE id = $2
-	`, dateContent), tools.GetTimeUnix(), t.pgFunctionScheduleIdMap[t.pgFunctionScheduleIdNext].id)
+	`, dateContent), tools.GetTimeUnix(), getTaskScheduleId(t))
 	return err
 }
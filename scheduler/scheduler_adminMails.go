@@ -15,17 +15,17 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+var adminMailIntro = `<p>You are receiving this message, because your email address has been added to the REI3 admin notification list.</p>
+<p>To change this setting, please visit your REI3 instance: {URL}</p>`
+
 func adminMails() error {
 
 	var templates = struct {
-		intro                        string
 		licenseExpirationBody        string
 		licenseExpirationSubject     string
 		oauthClientExpirationBody    string
 		oauthClientExpirationSubject string
 	}{
-		intro: `<p>You are receiving this message, because your email address has been added to the REI3 admin notification list.</p>
-		<p>To change this setting, please visit your REI3 instance: {URL}</p>`,
 		licenseExpirationBody:        `<p>Your license expires on: {DATE}</p>`,
 		licenseExpirationSubject:     `Your REI3 Professional license is about to expire`,
 		oauthClientExpirationBody:    `<p>Your OAuth client expires on: {DATE}</p>`,
@@ -36,38 +36,16 @@ func adminMails() error {
 	defer ctxCanc()
 
 	var sendMail = func(subject string, body string, dateExpiration int64, reason string) error {
-		// get mail receivers
-		if config.GetString("adminMails") == "" {
-			log.Warning(log.ContextServer, "cannot send admin notification mails", fmt.Errorf("no mail receivers defined"))
-			return nil
-		}
-
-		var toList []string
-		if err := json.Unmarshal([]byte(config.GetString("adminMails")), &toList); err != nil {
-			return fmt.Errorf("cannot read admin mail receivers, %s", err.Error())
-		}
-
-		if len(toList) == 0 {
-			log.Warning(log.ContextServer, "cannot send admin notification mails", fmt.Errorf("no mail receivers defined"))
-			return nil
-		}
-
-		// apply intro
-		body = fmt.Sprintf("%s%s", templates.intro, body)
-
-		// replace known placeholders
-		body = strings.Replace(body, "{URL}", config.GetString("publicHostName"), -1)
-		body = strings.Replace(body, "{DATE}", time.Unix(dateExpiration, 0).String(), -1)
-
 		tx, err := db.Pool.Begin(ctx)
 		if err != nil {
 			return err
 		}
 		defer tx.Rollback(ctx)
 
-		if _, err := tx.Exec(ctx, `
-			SELECT instance.mail_send($1,$2,$3)
-		`, subject, body, strings.Join(toList, ",")); err != nil {
+		body = strings.Replace(body, "{DATE}", time.Unix(dateExpiration, 0).String(), -1)
+
+		sent, err := adminMailSend_tx(ctx, tx, subject, body)
+		if err != nil || !sent {
 			return err
 		}
 
@@ -162,3 +140,37 @@ func adminMails() error {
 	}
 	return nil
 }
+
+// sends admin notification mail to all defined receivers
+// returns false if no receivers are defined
+func adminMailSend_tx(ctx context.Context, tx pgx.Tx, subject string, body string) (bool, error) {
+
+	// get mail receivers
+	if config.GetString("adminMails") == "" {
+		log.Warning(log.ContextServer, "cannot send admin notification mails", fmt.Errorf("no mail receivers defined"))
+		return false, nil
+	}
+
+	var toList []string
+	if err := json.Unmarshal([]byte(config.GetString("adminMails")), &toList); err != nil {
+		return false, fmt.Errorf("cannot read admin mail receivers, %s", err.Error())
+	}
+
+	if len(toList) == 0 {
+		log.Warning(log.ContextServer, "cannot send admin notification mails", fmt.Errorf("no mail receivers defined"))
+		return false, nil
+	}
+
+	// apply intro
+	body = fmt.Sprintf("%s%s", adminMailIntro, body)
+
+	// replace known placeholders
+	body = strings.Replace(body, "{URL}", config.GetString("publicHostName"), -1)
+
+	if _, err := tx.Exec(ctx, `
+		SELECT instance.mail_send($1,$2,$3)
+	`, subject, body, strings.Join(toList, ",")); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -9,6 +9,7 @@ import (
 	"r3/data"
 	"r3/db"
 	"r3/log"
+	"r3/login/login_token"
 	"r3/schema"
 	"r3/tools"
 
@@ -50,6 +51,14 @@ func cleanupTemp() error {
 	return nil
 }
 
+// deletes expired login tokens
+func cleanupLoginTokens() error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	return login_token.DelExpired(ctx)
+}
+
 // deletes expired logs
 func cleanupLogs() error {
 	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
@@ -292,3 +301,20 @@ func cleanUpFiles() error {
 	}
 	return nil
 }
+
+// deletes expired scheduler run history entries
+func cleanupSchedulerRuns() error {
+	keepForDays := config.GetUint64("schedulerRunsKeepDays")
+	if keepForDays == 0 {
+		return nil
+	}
+
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutDbTask)
+	defer ctxCanc()
+
+	_, err := db.Pool.Exec(ctx, `
+		DELETE FROM instance.schedule_run
+		WHERE date_start < $1
+	`, (tools.GetTimeUnix()-(oneDayInSeconds*int64(keepForDays)))*1000)
+	return err
+}
@@ -90,6 +90,8 @@ func clusterProcessEvent(ctx context.Context, tx pgx.Tx, e types.ClusterEvent, c
 	}
 
 	switch e.Content {
+	case "certRenewed":
+		err = cluster.CertRenewed_tx(ctx, tx, false)
 	case "clientEventsChanged":
 		err = cluster.ClientEventsChanged_tx(ctx, tx, false, e.Target.Address, e.Target.LoginId)
 	case "collectionUpdated":
@@ -134,6 +136,12 @@ func clusterProcessEvent(ctx context.Context, tx pgx.Tx, e types.ClusterEvent, c
 		err = cluster.KeystrokesRequested_tx(ctx, tx, false, e.Target.Address, e.Target.LoginId, keystrokes)
 	case "loginDisabled":
 		err = cluster.LoginDisabled_tx(ctx, tx, false, e.Target.LoginId)
+	case "loginTokenRevoked":
+		var tokenId uuid.UUID
+		if err := json.Unmarshal(jsonPayload, &tokenId); err != nil {
+			return err
+		}
+		err = cluster.LoginTokenRevoked_tx(ctx, tx, false, e.Target.LoginId, tokenId)
 	case "loginReauthorized":
 		err = cluster.LoginReauthorized_tx(ctx, tx, false, e.Target.LoginId)
 	case "loginReauthorizedAll":
@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"r3/cache"
+	"r3/config"
+	"r3/db"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// stores run of task in schedule run history and sends admin alerts if required
+func storeTaskRun(t task, dateStart time.Time, dateEnd time.Time, rowsAffected pgtype.Int8, errRun error) error {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	scheduleId := getTaskScheduleId(t)
+	durationMs := dateEnd.Sub(dateStart).Milliseconds()
+
+	var errorText pgtype.Text
+	if errRun != nil {
+		errorText.String = errRun.Error()
+		errorText.Valid = true
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO instance.schedule_run (schedule_id, node_id, date_start,
+			date_end, duration_ms, success, error_text, rows_affected)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+	`, scheduleId, cache.GetNodeId(), dateStart.UnixMilli(), dateEnd.UnixMilli(),
+		durationMs, errRun == nil, errorText, rowsAffected); err != nil {
+
+		return err
+	}
+
+	// admin alerts are disabled in mirror mode, same as regular admin notification mails
+	if !config.File.Mirror {
+		// node specific tasks are evaluated per node
+		nodeId := pgtype.UUID{}
+		if t.isSystemTask && !t.taskSchedule.clusterMasterOnly {
+			nodeId.Bytes = cache.GetNodeId()
+			nodeId.Valid = true
+		}
+
+		if errRun != nil {
+			if err := alertOnFailures_tx(ctx, tx, t, scheduleId, nodeId, errRun); err != nil {
+				return err
+			}
+		}
+		if err := alertOnDuration_tx(ctx, tx, t, scheduleId, nodeId, durationMs); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// sends admin alert once a task fails the defined number of times in a row
+func alertOnFailures_tx(ctx context.Context, tx pgx.Tx, t task, scheduleId int64, nodeId pgtype.UUID, errRun error) error {
+
+	failuresMax := int64(config.GetUint64("schedulerAlertFailures"))
+	if failuresMax == 0 {
+		return nil
+	}
+
+	// count failed runs since last successful run
+	var failures int64
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM instance.schedule_run
+		WHERE schedule_id = $1
+		AND   ($2::UUID IS NULL OR node_id = $2)
+		AND   NOT success
+		AND   date_start > COALESCE((
+			SELECT MAX(date_start)
+			FROM instance.schedule_run
+			WHERE schedule_id = $1
+			AND   ($2::UUID IS NULL OR node_id = $2)
+			AND   success
+		),0)
+	`, scheduleId, nodeId).Scan(&failures); err != nil {
+		return err
+	}
+
+	// alert only when threshold is reached, not for every following failure
+	if failures != failuresMax {
+		return nil
+	}
+
+	_, err := adminMailSend_tx(ctx, tx,
+		fmt.Sprintf("REI3 task '%s' failed %d times in a row", t.nameLog, failures),
+		fmt.Sprintf("<p>Task '%s' failed %d times in a row on node '%s'.</p><p>Last error: %s</p>",
+			html.EscapeString(t.nameLog), failures, html.EscapeString(cache.GetNodeName()),
+			html.EscapeString(errRun.Error())))
+
+	return err
+}
+
+// sends admin alert once a task exceeds the defined duration threshold
+func alertOnDuration_tx(ctx context.Context, tx pgx.Tx, t task, scheduleId int64, nodeId pgtype.UUID, durationMs int64) error {
+
+	thresholdMs := int64(config.GetUint64("schedulerAlertDurationSec")) * 1000
+	if thresholdMs == 0 || durationMs <= thresholdMs {
+		return nil
+	}
+
+	// alert only if previous run did not exceed the threshold already
+	var durationMsPrev pgtype.Int8
+	if err := tx.QueryRow(ctx, `
+		SELECT duration_ms
+		FROM instance.schedule_run
+		WHERE schedule_id = $1
+		AND   ($2::UUID IS NULL OR node_id = $2)
+		ORDER BY date_start DESC
+		LIMIT 1
+		OFFSET 1
+	`, scheduleId, nodeId).Scan(&durationMsPrev); err != nil && err != pgx.ErrNoRows {
+		return err
+	}
+
+	if durationMsPrev.Valid && durationMsPrev.Int64 > thresholdMs {
+		return nil
+	}
+
+	_, err := adminMailSend_tx(ctx, tx,
+		fmt.Sprintf("REI3 task '%s' exceeded its duration threshold", t.nameLog),
+		fmt.Sprintf("<p>Task '%s' took %s on node '%s', exceeding the threshold of %s.</p>",
+			html.EscapeString(t.nameLog), time.Duration(durationMs)*time.Millisecond,
+			html.EscapeString(cache.GetNodeName()), time.Duration(thresholdMs)*time.Millisecond))
+
+	return err
+}
+
+// returns ID of instance schedule that task is being executed for
+func getTaskScheduleId(t task) int64 {
+	if t.isSystemTask {
+		return t.taskSchedule.id
+	}
+	return t.pgFunctionScheduleIdMap[t.pgFunctionScheduleIdNext].id
+}
@@ -12,6 +12,7 @@ import (
 	"r3/schema/pgIndex"
 	"r3/types"
 	"slices"
+	"strings"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
@@ -20,7 +21,7 @@ import (
 
 var contentTypes = []string{"integer", "bigint", "numeric", "real",
 	"double precision", "varchar", "text", "boolean", "regconfig", "uuid",
-	"1:1", "n:1", "files"}
+	"1:1", "n:1", "files", "jsonb", "daterange", "tstzrange", "enum", "geometry", "point"}
 
 var contentUseTypes = []string{"default", "textarea", "richtext",
 	"date", "datetime", "time", "color", "iframe", "drawing", "barcode"}
@@ -70,7 +71,9 @@ func Get_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID) ([]types.Attri
 	attributes := make([]types.Attribute, 0)
 	rows, err := tx.Query(ctx, `
 		SELECT id, relationship_id, icon_id, name, content, content_use,
-			length, length_fract, nullable, encrypted, def, on_update, on_delete
+			length, length_fract, nullable, encrypted, def, computed, on_update, on_delete,
+			validation_required, validation_min, validation_max, validation_regex,
+			validation_pg_function_id
 		FROM app.attribute
 		WHERE relation_id = $1
 		ORDER BY CASE WHEN name = 'id' THEN 0 END, name ASC
@@ -84,7 +87,9 @@ func Get_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID) ([]types.Attri
 		var atr types.Attribute
 		if err := rows.Scan(&atr.Id, &atr.RelationshipId, &atr.IconId, &atr.Name,
 			&atr.Content, &atr.ContentUse, &atr.Length, &atr.LengthFract, &atr.Nullable,
-			&atr.Encrypted, &atr.Def, &onUpdateNull, &onDeleteNull); err != nil {
+			&atr.Encrypted, &atr.Def, &atr.Computed, &onUpdateNull, &onDeleteNull,
+			&atr.Validation.Required, &atr.Validation.Min, &atr.Validation.Max,
+			&atr.Validation.Regex, &atr.Validation.PgFunctionId); err != nil {
 
 			return attributes, err
 		}
@@ -99,6 +104,14 @@ func Get_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID) ([]types.Attri
 		if err != nil {
 			return attributes, err
 		}
+
+		attributes[i].EnumValues = make([]types.AttributeEnumValue, 0)
+		if schema.IsContentEnum(atr.Content) {
+			attributes[i].EnumValues, err = getEnumValues_tx(ctx, tx, atr.Id)
+			if err != nil {
+				return attributes, err
+			}
+		}
 	}
 	return attributes, nil
 }
@@ -121,6 +134,12 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 	if !slices.Contains(contentUseTypes, atr.ContentUse) {
 		return fmt.Errorf("invalid attribute content use type '%s'", atr.ContentUse)
 	}
+	if err := checkComputed(atr); err != nil {
+		return err
+	}
+	if err := checkValidation_tx(ctx, tx, atr); err != nil {
+		return err
+	}
 
 	_, moduleName, err := schema.GetModuleDetailsByRelationId_tx(ctx, tx, atr.RelationId)
 	if err != nil {
@@ -131,6 +150,17 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 		return err
 	}
 
+	// geometry attributes require PostGIS, plain points are used as fallback
+	if atr.Content == "geometry" {
+		postgis, err := schema.GetPostgisAvailable_tx(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if !postgis {
+			atr.Content = "point"
+		}
+	}
+
 	isNew := atr.Id == uuid.Nil
 	isRel := schema.IsContentRelationship(atr.Content)
 	isFiles := schema.IsContentFiles(atr.Content)
@@ -161,16 +191,17 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 		var lengthFractEx int
 		var nullableEx bool
 		var defEx string
+		var computedEx string
 		var onUpdateEx pgtype.Text
 		var onDeleteEx pgtype.Text
 		var relationshipIdEx pgtype.UUID
 		if err := tx.QueryRow(ctx, `
 			SELECT name, content, length, length_fract, nullable,
-				def, on_update, on_delete, relationship_id
+				def, computed, on_update, on_delete, relationship_id
 			FROM app.attribute
 			WHERE id = $1
 		`, atr.Id).Scan(&nameEx, &contentEx, &lengthEx, &lengthFractEx, &nullableEx,
-			&defEx, &onUpdateEx, &onDeleteEx, &relationshipIdEx); err != nil {
+			&defEx, &computedEx, &onUpdateEx, &onDeleteEx, &relationshipIdEx); err != nil {
 
 			return err
 		}
@@ -200,10 +231,12 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 		case "double precision": // keep double or downgrade to real
 			contentUpdateOk = slices.Contains([]string{"real", "double precision"}, atr.Content)
 
-		case "varchar": // keep varchar or upgrade to text
+		case "varchar": // keep varchar or upgrade to text, switch to enum
+			fallthrough
+		case "text": // keep text or downgrade to varchar, switch to enum
 			fallthrough
-		case "text": // keep text or downgrade to varchar
-			contentUpdateOk = slices.Contains([]string{"varchar", "text"}, atr.Content)
+		case "enum": // keep enum or switch to varchar/text
+			contentUpdateOk = slices.Contains([]string{"varchar", "text", "enum"}, atr.Content)
 
 		case "boolean": // keep boolean
 			contentUpdateOk = atr.Content == "boolean"
@@ -221,6 +254,21 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 
 		case "files": // keep files
 			contentUpdateOk = atr.Content == "files"
+
+		case "jsonb": // keep JSON
+			contentUpdateOk = atr.Content == "jsonb"
+
+		case "daterange": // keep date range
+			contentUpdateOk = atr.Content == "daterange"
+
+		case "tstzrange": // keep timestamp range
+			contentUpdateOk = atr.Content == "tstzrange"
+
+		case "point": // keep point or upgrade to geometry (PostGIS)
+			contentUpdateOk = slices.Contains([]string{"point", "geometry"}, atr.Content)
+
+		case "geometry": // keep geometry
+			contentUpdateOk = atr.Content == "geometry"
 		}
 
 		if !contentUpdateOk {
@@ -246,7 +294,7 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 		}
 
 		// update attribute column definition (not for files attributes: no column)
-		if !isFiles && (contentEx != atr.Content || nullableEx != atr.Nullable || defEx != atr.Def ||
+		if !isFiles && (contentEx != atr.Content || nullableEx != atr.Nullable || defEx != atr.Def || computedEx != atr.Computed ||
 			(atr.Content == "varchar" && lengthEx != atr.Length) ||
 			(atr.Content == "numeric" && (lengthEx != atr.Length || lengthFractEx != atr.LengthFract))) {
 
@@ -286,7 +334,7 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 			// default definition
 			defaultDef := "DROP DEFAULT"
 			if atr.Def != "" {
-				if schema.IsContentText(atr.Content) {
+				if schema.IsContentText(atr.Content) || schema.IsContentEnum(atr.Content) {
 					// add quotes around default value for text
 					defaultDef = fmt.Sprintf("SET DEFAULT '%s'", atr.Def)
 				} else {
@@ -294,15 +342,50 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 				}
 			}
 
-			if _, err := tx.Exec(ctx, fmt.Sprintf(`
-				ALTER TABLE "%s"."%s"
-					ALTER COLUMN "%s" TYPE %s,
-					ALTER COLUMN "%s" %s,
-					ALTER COLUMN "%s" %s
-			`, moduleName, relationName,
-				atr.Name, columnDef,
-				atr.Name, nullableDef,
-				atr.Name, defaultDef)); err != nil {
+			// plain points are converted to PostGIS geometries (WGS 84)
+			usingDef := ""
+			if contentEx == "point" && atr.Content == "geometry" {
+				usingDef = fmt.Sprintf(`USING ST_SetSRID(ST_MakePoint("%s"[0], "%s"[1]), 4326)`, atr.Name, atr.Name)
+			}
+
+			if atr.Computed != "" && atr.Computed != computedEx {
+				// regular columns cannot become generated ones and generation expressions cannot be replaced
+				// computed values only depend on other attributes, column is therefore recreated
+				if err := recreateComputedColumn_tx(ctx, tx, moduleName, relationName, atr, columnDef); err != nil {
+					return err
+				}
+			} else {
+				// computed attribute becomes regular one, keeps its current values
+				if computedEx != "" && atr.Computed == "" {
+					if _, err := tx.Exec(ctx, fmt.Sprintf(`
+						ALTER TABLE "%s"."%s"
+						ALTER COLUMN "%s" DROP EXPRESSION
+					`, moduleName, relationName, atr.Name)); err != nil {
+						return err
+					}
+				}
+
+				alterDefs := []string{
+					fmt.Sprintf(`ALTER COLUMN "%s" TYPE %s %s`, atr.Name, columnDef, usingDef),
+					fmt.Sprintf(`ALTER COLUMN "%s" %s`, atr.Name, nullableDef),
+				}
+
+				// generated columns cannot have defaults
+				if atr.Computed == "" {
+					alterDefs = append(alterDefs, fmt.Sprintf(`ALTER COLUMN "%s" %s`, atr.Name, defaultDef))
+				}
+
+				if _, err := tx.Exec(ctx, fmt.Sprintf(`
+					ALTER TABLE "%s"."%s" %s
+				`, moduleName, relationName, strings.Join(alterDefs, ",\n"))); err != nil {
+					return err
+				}
+			}
+		}
+
+		// update enum values, removes them if attribute is no longer an enum
+		if !isFiles && !isRel && (schema.IsContentEnum(atr.Content) || schema.IsContentEnum(contentEx)) {
+			if err := setEnumValues_tx(ctx, tx, moduleName, relationName, atr); err != nil {
 				return err
 			}
 		}
@@ -325,10 +408,14 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 		if _, err := tx.Exec(ctx, `
 			UPDATE app.attribute
 			SET icon_id = $1, content = $2, content_use = $3, length = $4, length_fract = $5,
-				nullable = $6, def = $7, on_update = $8, on_delete = $9
-			WHERE id = $10
+				nullable = $6, def = $7, computed = $8, on_update = $9, on_delete = $10,
+				validation_required = $11, validation_min = $12, validation_max = $13,
+				validation_regex = $14, validation_pg_function_id = $15
+			WHERE id = $16
 		`, atr.IconId, atr.Content, atr.ContentUse, atr.Length, atr.LengthFract, atr.Nullable,
-			atr.Def, onUpdateNull, onDeleteNull, atr.Id); err != nil {
+			atr.Def, atr.Computed, onUpdateNull, onDeleteNull, atr.Validation.Required,
+			atr.Validation.Min, atr.Validation.Max, atr.Validation.Regex,
+			atr.Validation.PgFunctionId, atr.Id); err != nil {
 
 			return err
 		}
@@ -380,7 +467,7 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 			// default definition
 			defaultDef := ""
 			if atr.Def != "" {
-				if schema.IsContentText(atr.Content) {
+				if schema.IsContentText(atr.Content) || schema.IsContentEnum(atr.Content) {
 					// add quotes around default value for text
 					defaultDef = fmt.Sprintf("DEFAULT '%s'", atr.Def)
 				} else {
@@ -391,8 +478,9 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 			// add attribute to relation
 			if _, err := tx.Exec(ctx, fmt.Sprintf(`
 				ALTER TABLE "%s"."%s" 
-				ADD COLUMN "%s" %s %s %s
-			`, moduleName, relationName, atr.Name, columnDef, nullableDef, defaultDef)); err != nil {
+				ADD COLUMN "%s" %s %s %s %s
+			`, moduleName, relationName, atr.Name, columnDef, getComputedDefinition(atr.Computed),
+				nullableDef, defaultDef)); err != nil {
 				return err
 			}
 		}
@@ -401,11 +489,15 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 		if _, err := tx.Exec(ctx, `
 			INSERT INTO app.attribute (id, relation_id, relationship_id,
 				icon_id, name, content, content_use, length, length_fract,
-				nullable, encrypted, def, on_update, on_delete)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+				nullable, encrypted, def, computed, on_update, on_delete,
+				validation_required, validation_min, validation_max,
+				validation_regex, validation_pg_function_id)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20)
 		`, atr.Id, atr.RelationId, atr.RelationshipId, atr.IconId, atr.Name,
 			atr.Content, atr.ContentUse, atr.Length, atr.LengthFract, atr.Nullable,
-			atr.Encrypted, atr.Def, onUpdateNull, onDeleteNull); err != nil {
+			atr.Encrypted, atr.Def, atr.Computed, onUpdateNull, onDeleteNull,
+			atr.Validation.Required, atr.Validation.Min, atr.Validation.Max,
+			atr.Validation.Regex, atr.Validation.PgFunctionId); err != nil {
 
 			return err
 		}
@@ -457,6 +549,12 @@ func Set_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
 			}
 		}
 
+		if schema.IsContentEnum(atr.Content) {
+			if err := setEnumValues_tx(ctx, tx, moduleName, relationName, atr); err != nil {
+				return err
+			}
+		}
+
 		if isRel {
 			// add FK constraint
 			if err := createFK_tx(ctx, tx, moduleName, relationName, atr.Id, atr.Name,
@@ -519,9 +617,95 @@ func setName_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID, name string, ignor
 		if err := pgFunction.RecreateAffectedBy_tx(ctx, tx, schema.DbAttribute, id); err != nil {
 			return err
 		}
+
+		// Postgres updates column references of generated columns itself
+		// stored expressions of computed attributes are refreshed to match
+		if _, err := tx.Exec(ctx, `
+			UPDATE app.attribute AS a
+			SET computed = PG_GET_EXPR(d.adbin, d.adrelid)
+			FROM pg_catalog.pg_attrdef AS d
+			JOIN pg_catalog.pg_attribute AS c
+				ON  c.attrelid = d.adrelid
+				AND c.attnum   = d.adnum
+			WHERE a.relation_id = (
+				SELECT relation_id
+				FROM app.attribute
+				WHERE id = $1
+			)
+			AND a.computed     <> ''
+			AND c.attname      =  a.name
+			AND c.attgenerated =  's'
+			AND d.adrelid      =  FORMAT('%I.%I', $2::TEXT, $3::TEXT)::REGCLASS
+		`, id, moduleName, relationName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// computed attributes
+func checkComputed(atr types.Attribute) error {
+	if atr.Computed == "" {
+		return nil
+	}
+	if atr.Name == schema.PkName || schema.IsContentFiles(atr.Content) || schema.IsContentRelationship(atr.Content) {
+		return errors.New("primary key, files and relationship attributes cannot be computed")
+	}
+	if atr.Encrypted {
+		return errors.New("computed attributes cannot be encrypted")
+	}
+	if atr.Def != "" {
+		return errors.New("computed attributes cannot have default values")
+	}
+
+	// expression is embedded in column definition, only single expressions are allowed
+	if strings.Contains(atr.Computed, ";") {
+		return fmt.Errorf("invalid expression for computed attribute '%s'", atr.Name)
 	}
 	return nil
 }
+func getComputedDefinition(computed string) string {
+	if computed == "" {
+		return ""
+	}
+	return fmt.Sprintf("GENERATED ALWAYS AS (%s) STORED", computed)
+}
+func recreateComputedColumn_tx(ctx context.Context, tx pgx.Tx, moduleName string,
+	relationName string, atr types.Attribute, columnDef string) error {
+
+	// dropping the column would also remove indexes using it
+	var indexCount int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM app.pg_index_attribute
+		WHERE attribute_id = $1
+	`, atr.Id).Scan(&indexCount); err != nil {
+		return err
+	}
+	if indexCount != 0 {
+		return fmt.Errorf("cannot change expression of computed attribute '%s' while it is used by indexes", atr.Name)
+	}
+
+	nullableDef := ""
+	if !atr.Nullable {
+		nullableDef = "NOT NULL"
+	}
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`
+		ALTER TABLE "%s"."%s"
+		DROP COLUMN "%s"
+	`, moduleName, relationName, atr.Name)); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, fmt.Sprintf(`
+		ALTER TABLE "%s"."%s"
+		ADD COLUMN "%s" %s %s %s
+	`, moduleName, relationName, atr.Name, columnDef,
+		getComputedDefinition(atr.Computed), nullableDef))
+
+	return err
+}
 
 func getContentColumnDefinition(content string, length int, lengthFract int, contentRel string) (string, error) {
 
@@ -543,6 +727,12 @@ func getContentColumnDefinition(content string, length int, lengthFract int, con
 			return "", fmt.Errorf("varchar requires defined length")
 		}
 		columnDef = fmt.Sprintf("character varying(%d)", length)
+	case "enum":
+		// enum values are checked by constraint, allowing values to change without type changes
+		columnDef = "text"
+	case "geometry":
+		// PostGIS geometry with WGS 84 coordinates (longitude/latitude), as used by GeoJSON
+		columnDef = "geometry(Geometry,4326)"
 	}
 
 	// overwrite relationship column
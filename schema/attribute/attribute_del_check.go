@@ -96,6 +96,7 @@ func DelCheck_tx(ctx context.Context, tx pgx.Tx, attributeId uuid.UUID) (interfa
 				WHERE api_id IS NOT NULL
 				AND (
 					attribute_id = $1
+					OR window_partition_attribute_id = $1
 					OR id = ANY($3)
 				)
 			) AS apis,
@@ -114,6 +115,7 @@ func DelCheck_tx(ctx context.Context, tx pgx.Tx, attributeId uuid.UUID) (interfa
 				WHERE collection_id IS NOT NULL
 				AND (
 					attribute_id = $1
+					OR window_partition_attribute_id = $1
 					OR id = ANY($3)
 				)
 			) AS collections,
@@ -190,6 +192,7 @@ func DelCheck_tx(ctx context.Context, tx pgx.Tx, attributeId uuid.UUID) (interfa
 			WHERE field_id IS NOT NULL
 			AND (
 				attribute_id = $1
+				OR window_partition_attribute_id = $1
 				OR id = ANY($3)
 			)
 			
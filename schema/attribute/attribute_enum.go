@@ -0,0 +1,133 @@
+package attribute
+
+import (
+	"context"
+	"fmt"
+	"r3/schema"
+	"r3/schema/caption"
+	"r3/types"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func getEnumValues_tx(ctx context.Context, tx pgx.Tx, attributeId uuid.UUID) ([]types.AttributeEnumValue, error) {
+
+	values := make([]types.AttributeEnumValue, 0)
+	rows, err := tx.Query(ctx, `
+		SELECT id, value
+		FROM app.attribute_enum_value
+		WHERE attribute_id = $1
+		ORDER BY position ASC
+	`, attributeId)
+	if err != nil {
+		return values, err
+	}
+
+	for rows.Next() {
+		var v types.AttributeEnumValue
+		if err := rows.Scan(&v.Id, &v.Value); err != nil {
+			rows.Close()
+			return values, err
+		}
+		values = append(values, v)
+	}
+	rows.Close()
+
+	for i, v := range values {
+		values[i].Captions, err = caption.Get_tx(ctx, tx, schema.DbAttributeEnumValue, v.Id, []string{"attributeEnumValueTitle"})
+		if err != nil {
+			return values, err
+		}
+	}
+	return values, nil
+}
+
+// sets allowed values of enum attribute and applies them as check constraint
+// removes values and check constraint if attribute is not (or no longer) an enum
+func setEnumValues_tx(ctx context.Context, tx pgx.Tx, moduleName string,
+	relationName string, atr types.Attribute) error {
+
+	isEnum := schema.IsContentEnum(atr.Content)
+	if !isEnum {
+		atr.EnumValues = make([]types.AttributeEnumValue, 0)
+	}
+	if isEnum && len(atr.EnumValues) == 0 {
+		return fmt.Errorf("enum attribute requires at least one value")
+	}
+
+	// delete removed values
+	idsKeep := make([]uuid.UUID, 0)
+	for _, v := range atr.EnumValues {
+		idsKeep = append(idsKeep, v.Id)
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM app.attribute_enum_value
+		WHERE attribute_id = $1
+		AND id <> ALL($2)
+	`, atr.Id, idsKeep); err != nil {
+		return err
+	}
+
+	// insert new/update existing values
+	valuesSql := make([]string, 0)
+	valuesSeen := make(map[string]bool)
+	for position, v := range atr.EnumValues {
+
+		if v.Value == "" {
+			return fmt.Errorf("enum attribute values must not be empty")
+		}
+		if _, exists := valuesSeen[v.Value]; exists {
+			return fmt.Errorf("enum attribute value '%s' is not unique", v.Value)
+		}
+		valuesSeen[v.Value] = true
+
+		known, err := schema.CheckCreateId_tx(ctx, tx, &v.Id, schema.DbAttributeEnumValue, "id")
+		if err != nil {
+			return err
+		}
+
+		if known {
+			if _, err := tx.Exec(ctx, `
+				UPDATE app.attribute_enum_value
+				SET position = $1, value = $2
+				WHERE id = $3
+			`, position, v.Value, v.Id); err != nil {
+				return err
+			}
+		} else {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO app.attribute_enum_value (id, attribute_id, position, value)
+				VALUES ($1,$2,$3,$4)
+			`, v.Id, atr.Id, position, v.Value); err != nil {
+				return err
+			}
+		}
+
+		if err := caption.Set_tx(ctx, tx, v.Id, v.Captions); err != nil {
+			return err
+		}
+		valuesSql = append(valuesSql, fmt.Sprintf("'%s'", strings.ReplaceAll(v.Value, "'", "''")))
+	}
+
+	// recreate check constraint with current values
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`
+		ALTER TABLE "%s"."%s"
+		DROP CONSTRAINT IF EXISTS "%s"
+	`, moduleName, relationName, schema.GetEnumConstraintName(atr.Id))); err != nil {
+		return err
+	}
+
+	if !isEnum {
+		return nil
+	}
+	_, err := tx.Exec(ctx, fmt.Sprintf(`
+		ALTER TABLE "%s"."%s"
+		ADD CONSTRAINT "%s"
+		CHECK ("%s" = ANY(ARRAY[%s]::TEXT[]))
+	`, moduleName, relationName, schema.GetEnumConstraintName(atr.Id),
+		atr.Name, strings.Join(valuesSql, ",")))
+
+	return err
+}
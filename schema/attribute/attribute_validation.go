@@ -0,0 +1,58 @@
+package attribute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"r3/schema"
+	"r3/schema/pgFunction"
+	"r3/types"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// checks validation rules of attribute before they are stored
+// rules are enforced when attribute values are set (see data.Set_tx)
+func checkValidation_tx(ctx context.Context, tx pgx.Tx, atr types.Attribute) error {
+	v := atr.Validation
+
+	if schema.IsContentFiles(atr.Content) && (v.Min.Valid || v.Max.Valid || v.Regex.Valid || v.PgFunctionId.Valid) {
+		return errors.New("files attributes only support the required validation rule")
+	}
+	if atr.Computed != "" && (v.Required || v.Min.Valid || v.Max.Valid || v.Regex.Valid || v.PgFunctionId.Valid) {
+		return errors.New("computed attributes cannot have validation rules")
+	}
+	if v.Min.Valid && v.Max.Valid && v.Min.Float64 > v.Max.Float64 {
+		return fmt.Errorf("validation minimum of attribute '%s' exceeds its maximum", atr.Name)
+	}
+	if v.Regex.Valid {
+		if _, err := regexp.Compile(v.Regex.String); err != nil {
+			return fmt.Errorf("invalid validation regex of attribute '%s': %w", atr.Name, err)
+		}
+	}
+
+	// validator function receives the value and returns whether it is valid
+	if v.PgFunctionId.Valid {
+		var isTrigger bool
+		var args string
+		var returns string
+		if err := tx.QueryRow(ctx, `
+			SELECT is_trigger, code_args, code_returns
+			FROM app.pg_function
+			WHERE id = $1
+		`, v.PgFunctionId).Scan(&isTrigger, &args, &returns); err != nil {
+			return err
+		}
+		returns = strings.ToLower(strings.TrimSpace(returns))
+
+		if isTrigger || (returns != "boolean" && returns != "bool") {
+			return fmt.Errorf("validation function of attribute '%s' must return a BOOLEAN and cannot be a trigger function", atr.Name)
+		}
+		if !pgFunction.IsArgSingle(args, "JSONB") {
+			return fmt.Errorf("validation function of attribute '%s' must have exactly one JSONB argument", atr.Name)
+		}
+	}
+	return nil
+}
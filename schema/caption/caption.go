@@ -92,6 +92,10 @@ func GetDefaultContent(entity string) types.CaptionMap {
 		return types.CaptionMap{
 			"attributeTitle": make(map[string]string),
 		}
+	case "attributeEnumValue":
+		return types.CaptionMap{
+			"attributeEnumValueTitle": make(map[string]string),
+		}
 	case "clientEvent":
 		return types.CaptionMap{
 			"clientEventTitle": make(map[string]string),
@@ -173,6 +177,9 @@ func GetEntityName(content string) (schema.DbEntity, error) {
 	case "attributeTitle":
 		return schema.DbAttribute, nil
 
+	case "attributeEnumValueTitle":
+		return schema.DbAttributeEnumValue, nil
+
 	case "clientEventTitle":
 		return schema.DbClientEvent, nil
 
@@ -30,7 +30,9 @@ func Get_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 
 	rows, err := tx.Query(ctx, fmt.Sprintf(`
 		SELECT id, attribute_id, index, batch, basis, length, display, group_by,
-			aggregator, distincted, hidden, on_mobile, sub_query, styles
+			aggregator, distincted, hidden, on_mobile, sub_query, styles, percentile,
+			window_function, window_offset, window_partition_attribute_id,
+			window_partition_index
 		FROM app.column
 		WHERE %s_id = $1
 		ORDER BY position ASC
@@ -44,7 +46,9 @@ func Get_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 		var c types.Column
 		if err := rows.Scan(&c.Id, &c.AttributeId, &c.Index, &c.Batch, &c.Basis,
 			&c.Length, &c.Display, &c.GroupBy, &c.Aggregator, &c.Distincted,
-			&c.Hidden, &c.OnMobile, &c.SubQuery, &c.Styles); err != nil {
+			&c.Hidden, &c.OnMobile, &c.SubQuery, &c.Styles, &c.Percentile,
+			&c.WindowFunction, &c.WindowOffset, &c.WindowPartitionAttributeId,
+			&c.WindowPartitionIndex); err != nil {
 
 			return columns, err
 		}
@@ -107,16 +111,26 @@ func Set_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 		// fix imports < 3.8: Convert to new styles
 		c = compatible.FixColumnStyles(c)
 
+		if c.WindowFunction.Valid && !slices.Contains(types.QueryWindowFunctions, c.WindowFunction.String) {
+			return errors.New("invalid window function")
+		}
+		if c.WindowOffset < 1 {
+			c.WindowOffset = 1
+		}
+
 		if known {
 			if _, err := tx.Exec(ctx, `
 				UPDATE app.column
 				SET attribute_id = $1, index = $2, position = $3, batch = $4, basis = $5,
 					length = $6, display = $7, group_by = $8, aggregator = $9, distincted = $10,
-					hidden = $11, on_mobile = $12, sub_query = $13, styles = $14
-				WHERE id = $15
+					hidden = $11, on_mobile = $12, sub_query = $13, styles = $14,
+					percentile = $15, window_function = $16, window_offset = $17,
+					window_partition_attribute_id = $18, window_partition_index = $19
+				WHERE id = $20
 			`, c.AttributeId, c.Index, position, c.Batch, c.Basis, c.Length, c.Display,
 				c.GroupBy, c.Aggregator, c.Distincted, c.Hidden, c.OnMobile, c.SubQuery,
-				c.Styles, c.Id); err != nil {
+				c.Styles, c.Percentile, c.WindowFunction, c.WindowOffset,
+				c.WindowPartitionAttributeId, c.WindowPartitionIndex, c.Id); err != nil {
 
 				return err
 			}
@@ -125,12 +139,16 @@ func Set_tx(ctx context.Context, tx pgx.Tx, entity schema.DbEntity, entityId uui
 				INSERT INTO app.column (
 					id, %s_id, attribute_id, index, position, batch, basis, length,
 					display, group_by, aggregator, distincted, hidden, on_mobile,
-					sub_query, styles
+					sub_query, styles, percentile, window_function, window_offset,
+					window_partition_attribute_id, window_partition_index
 				)
-				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,
+					$18,$19,$20,$21)
 			`, entity), c.Id, entityId, c.AttributeId, c.Index, position, c.Batch,
 				c.Basis, c.Length, c.Display, c.GroupBy, c.Aggregator, c.Distincted,
-				c.Hidden, c.OnMobile, c.SubQuery, c.Styles); err != nil {
+				c.Hidden, c.OnMobile, c.SubQuery, c.Styles, c.Percentile,
+				c.WindowFunction, c.WindowOffset, c.WindowPartitionAttributeId,
+				c.WindowPartitionIndex); err != nil {
 
 				return err
 			}
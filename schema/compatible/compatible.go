@@ -14,6 +14,17 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// < 3.12
+// fix missing catch-up policy of PG function schedules, previous behavior was to run missed schedules once
+func FixMissingScheduleCatchUp(fnc types.PgFunction) types.PgFunction {
+	for i, s := range fnc.Schedules {
+		if s.CatchUp == "" {
+			fnc.Schedules[i].CatchUp = "once"
+		}
+	}
+	return fnc
+}
+
 // < 3.11
 // fix missing cost setting
 func FixMissingCost(fnc types.PgFunction) types.PgFunction {
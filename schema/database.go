@@ -8,6 +8,7 @@ import (
 
 // constants
 var PkName = "id"
+var RecordVersionName = "_version" // record version column, only on relations with record versions
 
 // database entity names
 func GetPkConstraintName(relationId uuid.UUID) string {
@@ -16,6 +17,9 @@ func GetPkConstraintName(relationId uuid.UUID) string {
 func GetFkConstraintName(attributeId uuid.UUID) string {
 	return fmt.Sprintf("fk_%s", attributeId.String())
 }
+func GetEnumConstraintName(attributeId uuid.UUID) string {
+	return fmt.Sprintf("enum_%s", attributeId.String())
+}
 func GetSequenceName(relationId uuid.UUID) string {
 	return fmt.Sprintf("sq_%s", relationId.String())
 }
@@ -31,3 +35,6 @@ func GetFilesTableName(attributeId uuid.UUID) string {
 func GetFilesTriggerName(attributeId uuid.UUID) string {
 	return fmt.Sprintf("trg_%s_record", attributeId.String())
 }
+func GetRecordVersionTriggerName(relationId uuid.UUID) string {
+	return fmt.Sprintf("trg_%s_version", relationId.String())
+}
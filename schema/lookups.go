@@ -187,3 +187,17 @@ func GetIsFormBound_tx(ctx context.Context, tx pgx.Tx, entity DbEntity, id uuid.
 
 	return isFormBound, err
 }
+
+// returns whether the PostGIS extension is installed in the database
+func GetPostgisAvailable_tx(ctx context.Context, tx pgx.Tx) (bool, error) {
+	var available bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT extname
+			FROM pg_catalog.pg_extension
+			WHERE extname = 'postgis'
+		)
+	`).Scan(&available)
+
+	return available, err
+}
@@ -8,15 +8,25 @@ import (
 	"r3/schema"
 	"r3/schema/caption"
 	"r3/schema/compatible"
+	"r3/tools"
 	"r3/types"
 	"regexp"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
+var (
+	scheduleCatchUpPolicies = []string{"skip", "once", "all"}
+
+	// argument modes, OUT arguments do not take input values
+	argModesOut = []string{"OUT"}
+	argModes    = []string{"IN", "INOUT", "OUT", "VARIADIC"}
+)
+
 func Del_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
 
 	nameMod, nameEx, _, _, err := schema.GetPgFunctionDetailsById_tx(ctx, tx, id)
@@ -85,7 +95,8 @@ func getSchedules_tx(ctx context.Context, tx pgx.Tx, pgFunctionId uuid.UUID) ([]
 	schedules := make([]types.PgFunctionSchedule, 0)
 
 	rows, err := tx.Query(ctx, `
-		SELECT id, at_second, at_minute, at_hour, at_day, interval_type, interval_value
+		SELECT id, at_second, at_minute, at_hour, at_day, interval_type,
+			interval_value, cron, timezone, catch_up
 		FROM app.pg_function_schedule
 		WHERE pg_function_id = $1
 		ORDER BY id ASC
@@ -99,7 +110,8 @@ func getSchedules_tx(ctx context.Context, tx pgx.Tx, pgFunctionId uuid.UUID) ([]
 		var s types.PgFunctionSchedule
 
 		if err := rows.Scan(&s.Id, &s.AtSecond, &s.AtMinute, &s.AtHour,
-			&s.AtDay, &s.IntervalType, &s.IntervalValue); err != nil {
+			&s.AtDay, &s.IntervalType, &s.IntervalValue, &s.Cron, &s.Timezone,
+			&s.CatchUp); err != nil {
 
 			return schedules, err
 		}
@@ -125,6 +137,9 @@ func Set_tx(ctx context.Context, tx pgx.Tx, fnc types.PgFunction) error {
 	// fix imports < 3.11: Missing cost setting
 	fnc = compatible.FixMissingCost(fnc)
 
+	// fix imports < 3.12: Missing catch-up policy for schedules
+	fnc = compatible.FixMissingScheduleCatchUp(fnc)
+
 	// enforce valid function configuration
 	if fnc.IsLoginSync {
 		fnc.CodeReturns = "INTEGER"
@@ -214,14 +229,31 @@ func Set_tx(ctx context.Context, tx pgx.Tx, fnc types.PgFunction) error {
 		// overwrite invalid inputs
 		s.AtDay = schema.GetValidAtDay(s.IntervalType, s.AtDay)
 
+		if s.IntervalType == "cron" {
+			if _, err := tools.ParseCron(s.Cron); err != nil {
+				return err
+			}
+		} else {
+			s.Cron = ""
+		}
+		if !slices.Contains(scheduleCatchUpPolicies, s.CatchUp) {
+			return fmt.Errorf("invalid schedule catch-up policy '%s'", s.CatchUp)
+		}
+		if s.Timezone != "" {
+			if _, err := time.LoadLocation(s.Timezone); err != nil {
+				return fmt.Errorf("invalid schedule time zone '%s'", s.Timezone)
+			}
+		}
+
 		if known {
 			if _, err := tx.Exec(ctx, `
 				UPDATE app.pg_function_schedule
 				SET at_second = $1, at_minute = $2, at_hour = $3, at_day = $4,
-					interval_type = $5, interval_value = $6
-				WHERE id = $7
-			`, s.AtSecond, s.AtMinute, s.AtHour, s.AtDay,
-				s.IntervalType, s.IntervalValue, s.Id); err != nil {
+					interval_type = $5, interval_value = $6, cron = $7,
+					timezone = $8, catch_up = $9
+				WHERE id = $10
+			`, s.AtSecond, s.AtMinute, s.AtHour, s.AtDay, s.IntervalType,
+				s.IntervalValue, s.Cron, s.Timezone, s.CatchUp, s.Id); err != nil {
 
 				return err
 			}
@@ -229,11 +261,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, fnc types.PgFunction) error {
 			if _, err := tx.Exec(ctx, `
 				INSERT INTO app.pg_function_schedule (
 					id, pg_function_id, at_second, at_minute, at_hour, at_day,
-					interval_type, interval_value
+					interval_type, interval_value, cron, timezone, catch_up
 				)
-				VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
 			`, s.Id, fnc.Id, s.AtSecond, s.AtMinute, s.AtHour, s.AtDay,
-				s.IntervalType, s.IntervalValue); err != nil {
+				s.IntervalType, s.IntervalValue, s.Cron, s.Timezone, s.CatchUp); err != nil {
 
 				return err
 			}
@@ -484,3 +516,77 @@ func processDependentIds_tx(ctx context.Context, tx pgx.Tx, id uuid.UUID, body s
 	}
 	return body, nil
 }
+
+// returns names of input arguments from function argument definition
+// example: 'invoice_id INTEGER, comment TEXT DEFAULT NULL, amount NUMERIC(10,2)'
+func GetArgNames(codeArgs string) []string {
+	names := make([]string, 0)
+	for _, words := range getArgsInput(codeArgs) {
+		if len(words) < 2 {
+			continue // unnamed argument
+		}
+		names = append(names, strings.Trim(words[0], `"`))
+	}
+	return names
+}
+
+// returns whether function argument definition consists of exactly one input argument of given type
+// example: 'value JSONB' or 'JSONB'
+func IsArgSingle(codeArgs string, argType string) bool {
+	args := getArgsInput(codeArgs)
+	if len(args) != 1 {
+		return false
+	}
+
+	// remove default value
+	words := args[0]
+	for i, word := range words {
+		if strings.EqualFold(word, "DEFAULT") || word == "=" {
+			words = words[:i]
+			break
+		}
+	}
+	return len(words) != 0 && len(words) <= 2 && strings.EqualFold(words[len(words)-1], argType)
+}
+
+// returns input arguments from function argument definition, each as list of words without argument mode
+func getArgsInput(codeArgs string) [][]string {
+	out := make([][]string, 0)
+
+	// split by commas outside of type modifiers, such as NUMERIC(10,2)
+	args := make([]string, 0)
+	depth, start := 0, 0
+	for i, c := range codeArgs {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, codeArgs[start:i])
+				start = i + 1
+			}
+		}
+	}
+	args = append(args, codeArgs[start:])
+
+	for _, arg := range args {
+		words := strings.Fields(arg)
+		if len(words) == 0 {
+			continue
+		}
+
+		if len(words) > 1 {
+			mode := strings.ToUpper(words[0])
+			if slices.Contains(argModesOut, mode) {
+				continue
+			}
+			if slices.Contains(argModes, mode) {
+				words = words[1:]
+			}
+		}
+		out = append(out, words)
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package pgFunction
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetArgNames(t *testing.T) {
+	tests := []struct {
+		codeArgs string
+		want     []string
+	}{
+		{"", []string{}},
+		{"invoice_id INTEGER", []string{"invoice_id"}},
+		{"invoice_id INTEGER, comment TEXT DEFAULT NULL, amount NUMERIC(10,2)", []string{"invoice_id", "comment", "amount"}},
+		{`IN "Name" TEXT, OUT total INTEGER, INOUT state TEXT`, []string{"Name", "state"}},
+		{"INTEGER, TEXT", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.codeArgs, func(t *testing.T) {
+			if got := GetArgNames(tt.codeArgs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsArgSingle(t *testing.T) {
+	tests := []struct {
+		codeArgs string
+		want     bool
+	}{
+		{"value JSONB", true},
+		{"jsonb", true},
+		{"IN value JSONB", true},
+		{"value JSONB DEFAULT NULL", true},
+		{"value JSONB = '{}'", true},
+		{"value JSONB, OUT valid BOOLEAN", true},
+		{"", false},
+		{"value TEXT", false},
+		{"value JSONB, other JSONB", false},
+		{"value JSONB[]", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.codeArgs, func(t *testing.T) {
+			if got := IsArgSingle(tt.codeArgs, "JSONB"); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -222,17 +222,21 @@ func setValues_tx(ctx context.Context, tx pgx.Tx, relationId uuid.UUID, presetId
 
 		// make sure that preset values belong to the correct relation
 		var relationIdAtr uuid.UUID
+		var computed string
 		if err := tx.QueryRow(ctx, `
-			SELECT relation_id
+			SELECT relation_id, computed
 			FROM app.attribute
 			WHERE id = $1
-		`, value.AttributeId).Scan(&relationIdAtr); err != nil {
+		`, value.AttributeId).Scan(&relationIdAtr, &computed); err != nil {
 			return err
 		}
 
 		if relationIdAtr.String() != relationId.String() {
 			return fmt.Errorf("cannot save preset values, at least 1 attribute value is from a different relation")
 		}
+		if computed != "" {
+			return fmt.Errorf("cannot save preset values, at least 1 attribute is computed")
+		}
 
 		if _, err := tx.Exec(ctx, `
 			INSERT INTO app.preset_value (id, preset_id,
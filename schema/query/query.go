@@ -436,7 +436,7 @@ func getFilterSide_tx(ctx context.Context, tx pgx.Tx, queryId uuid.UUID, filterI
 	if err := tx.QueryRow(ctx, `
 		SELECT attribute_id, attribute_index, attribute_nested, brackets,
 			collection_id, column_id, content, field_id, now_offset, preset_id,
-			role_id, variable_id, query_aggregator, value
+			role_id, variable_id, query_aggregator, aggregator, value
 		FROM app.query_filter_side
 		WHERE query_id              = $1
 		AND   query_filter_index    = $2
@@ -445,7 +445,7 @@ func getFilterSide_tx(ctx context.Context, tx pgx.Tx, queryId uuid.UUID, filterI
 	`, queryId, filterIndex, filterPosition, side).Scan(&s.AttributeId, &s.AttributeIndex,
 		&s.AttributeNested, &s.Brackets, &s.CollectionId, &s.ColumnId, &s.Content,
 		&s.FieldId, &s.NowOffset, &s.PresetId, &s.RoleId, &s.VariableId,
-		&s.QueryAggregator, &s.Value); err != nil {
+		&s.QueryAggregator, &s.Aggregator, &s.Value); err != nil {
 
 		return s, err
 	}
@@ -501,13 +501,13 @@ func SetFilterSide_tx(ctx context.Context, tx pgx.Tx, queryId uuid.UUID, filterI
 			query_id, query_filter_index, query_filter_position, side, attribute_id,
 			attribute_index, attribute_nested, brackets, collection_id, column_id,
 			content, field_id, now_offset, preset_id, role_id, variable_id,
-			query_aggregator, value
+			query_aggregator, aggregator, value
 		)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19)
 	`, queryId, filterIndex, filterPosition, side, s.AttributeId, s.AttributeIndex,
 		s.AttributeNested, s.Brackets, s.CollectionId, s.ColumnId, s.Content,
 		s.FieldId, s.NowOffset, s.PresetId, s.RoleId, s.VariableId,
-		s.QueryAggregator, s.Value); err != nil {
+		s.QueryAggregator, s.Aggregator, s.Value); err != nil {
 
 		return err
 	}
@@ -77,7 +77,8 @@ func Get_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.Relatio
 
 	relations := make([]types.Relation, 0)
 	rows, err := tx.Query(ctx, `
-		SELECT id, name, comment, encryption, retention_count, retention_days, (
+		SELECT id, name, comment, encryption, record_version, retention_count,
+			retention_days, trash_days, (
 			SELECT id
 			FROM app.attribute
 			WHERE relation_id = app.relation.id
@@ -94,8 +95,8 @@ func Get_tx(ctx context.Context, tx pgx.Tx, moduleId uuid.UUID) ([]types.Relatio
 
 	for rows.Next() {
 		var r types.Relation
-		if err := rows.Scan(&r.Id, &r.Name, &r.Comment, &r.Encryption,
-			&r.RetentionCount, &r.RetentionDays, &r.AttributeIdPk); err != nil {
+		if err := rows.Scan(&r.Id, &r.Name, &r.Comment, &r.Encryption, &r.RecordVersion,
+			&r.RetentionCount, &r.RetentionDays, &r.TrashDays, &r.AttributeIdPk); err != nil {
 
 			return relations, err
 		}
@@ -120,6 +121,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, rel types.Relation) error {
 		return err
 	}
 
+	// data keys of encrypted records are deleted with their records, they cannot be restored
+	if rel.Encryption && rel.TrashDays.Valid {
+		return fmt.Errorf("recycle bin is not supported for relations with encryption")
+	}
+
 	moduleName, err := schema.GetModuleNameById_tx(ctx, tx, rel.ModuleId)
 	if err != nil {
 		return err
@@ -140,9 +146,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, rel types.Relation) error {
 		// update relation reference
 		if _, err := tx.Exec(ctx, `
 			UPDATE app.relation
-			SET name = $1, comment = $2, retention_count = $3, retention_days = $4
-			WHERE id = $5
-		`, rel.Name, rel.Comment, rel.RetentionCount, rel.RetentionDays, rel.Id); err != nil {
+			SET name = $1, comment = $2, record_version = $3,
+				retention_count = $4, retention_days = $5, trash_days = $6
+			WHERE id = $7
+		`, rel.Name, rel.Comment, rel.RecordVersion, rel.RetentionCount,
+			rel.RetentionDays, rel.TrashDays, rel.Id); err != nil {
 			return err
 		}
 
@@ -169,10 +177,10 @@ func Set_tx(ctx context.Context, tx pgx.Tx, rel types.Relation) error {
 		// insert relation reference
 		if _, err := tx.Exec(ctx, `
 			INSERT INTO app.relation (id, module_id, name, comment,
-				encryption, retention_count, retention_days)
-			VALUES ($1,$2,$3,$4,$5,$6,$7)
+				encryption, record_version, retention_count, retention_days, trash_days)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
 		`, rel.Id, rel.ModuleId, rel.Name, rel.Comment, rel.Encryption,
-			rel.RetentionCount, rel.RetentionDays); err != nil {
+			rel.RecordVersion, rel.RetentionCount, rel.RetentionDays, rel.TrashDays); err != nil {
 
 			return err
 		}
@@ -200,6 +208,34 @@ func Set_tx(ctx context.Context, tx pgx.Tx, rel types.Relation) error {
 		}
 	}
 
+	// set record version column
+	if err := setRecordVersion_tx(ctx, tx, moduleName, rel); err != nil {
+		return err
+	}
+
 	// set policies
 	return setPolicies_tx(ctx, tx, rel.Id, rel.Policies)
 }
+
+// adds or removes record version column with its update trigger
+// version is increased on every update, regardless of whether the update comes from the frontend, API or backend functions
+func setRecordVersion_tx(ctx context.Context, tx pgx.Tx, moduleName string, rel types.Relation) error {
+	trgName := schema.GetRecordVersionTriggerName(rel.Id)
+
+	if !rel.RecordVersion {
+		_, err := tx.Exec(ctx, fmt.Sprintf(`
+			DROP TRIGGER IF EXISTS "%s" ON "%s"."%s";
+			ALTER TABLE "%s"."%s" DROP COLUMN IF EXISTS "%s";
+		`, trgName, moduleName, rel.Name, moduleName, rel.Name, schema.RecordVersionName))
+		return err
+	}
+
+	_, err := tx.Exec(ctx, fmt.Sprintf(`
+		ALTER TABLE "%s"."%s" ADD COLUMN IF NOT EXISTS "%s" BIGINT NOT NULL DEFAULT 1;
+		DROP TRIGGER IF EXISTS "%s" ON "%s"."%s";
+		CREATE TRIGGER "%s" BEFORE UPDATE ON "%s"."%s"
+			FOR EACH ROW EXECUTE FUNCTION instance.trg_record_version_update();
+	`, moduleName, rel.Name, schema.RecordVersionName, trgName, moduleName, rel.Name,
+		trgName, moduleName, rel.Name))
+	return err
+}
@@ -75,12 +75,13 @@ func getAccess_tx(ctx context.Context, tx pgx.Tx, role types.Role) (types.Role,
 	role.AccessCollections = make(map[uuid.UUID]types.Access)
 	role.AccessRelations = make(map[uuid.UUID]types.Access)
 	role.AccessMenus = make(map[uuid.UUID]types.Access)
+	role.AccessPgFunctions = make(map[uuid.UUID]types.Access)
 	role.AccessSearchBars = make(map[uuid.UUID]types.Access)
 	role.AccessWidgets = make(map[uuid.UUID]types.Access)
 
 	rows, err := tx.Query(ctx, `
 		SELECT api_id, attribute_id, client_event_id, collection_id,
-			menu_id, relation_id, search_bar_id, widget_id, access
+			menu_id, pg_function_id, relation_id, search_bar_id, widget_id, access
 		FROM app.role_access
 		WHERE role_id = $1
 	`, role.Id)
@@ -90,11 +91,11 @@ func getAccess_tx(ctx context.Context, tx pgx.Tx, role types.Role) (types.Role,
 	defer rows.Close()
 
 	for rows.Next() {
-		var apiId, attributeId, clientEventId, collectionId, menuId, relationId, searchBarId, widgetId pgtype.UUID
+		var apiId, attributeId, clientEventId, collectionId, menuId, pgFunctionId, relationId, searchBarId, widgetId pgtype.UUID
 		var access types.Access
 
 		if err := rows.Scan(&apiId, &attributeId, &clientEventId, &collectionId,
-			&menuId, &relationId, &searchBarId, &widgetId, &access); err != nil {
+			&menuId, &pgFunctionId, &relationId, &searchBarId, &widgetId, &access); err != nil {
 
 			return role, err
 		}
@@ -113,6 +114,9 @@ func getAccess_tx(ctx context.Context, tx pgx.Tx, role types.Role) (types.Role,
 		if menuId.Valid {
 			role.AccessMenus[menuId.Bytes] = access
 		}
+		if pgFunctionId.Valid {
+			role.AccessPgFunctions[pgFunctionId.Bytes] = access
+		}
 		if relationId.Valid {
 			role.AccessRelations[relationId.Bytes] = access
 		}
@@ -207,6 +211,11 @@ func Set_tx(ctx context.Context, tx pgx.Tx, role types.Role) error {
 			return err
 		}
 	}
+	for trgId, access := range role.AccessPgFunctions {
+		if err := setAccess_tx(ctx, tx, role.Id, trgId, schema.DbPgFunction, access); err != nil {
+			return err
+		}
+	}
 	for trgId, access := range role.AccessRelations {
 		if err := setAccess_tx(ctx, tx, role.Id, trgId, schema.DbRelation, access); err != nil {
 			return err
@@ -251,6 +260,10 @@ func setAccess_tx(ctx context.Context, tx pgx.Tx, roleId uuid.UUID, id uuid.UUID
 		if access < -1 || access > 1 {
 			return errors.New("invalid access level")
 		}
+	case schema.DbPgFunction: // 1 call backend function via REST RPC
+		if access < -1 || access > 1 {
+			return errors.New("invalid access level")
+		}
 	case schema.DbRelation: // 1 read, 2 write, 3 delete relation record
 		if access < -1 || access > 3 {
 			return errors.New("invalid access level")
@@ -18,6 +18,7 @@ const (
 	DbApi                   DbEntity = "api"
 	DbArticle               DbEntity = "article"
 	DbAttribute             DbEntity = "attribute"
+	DbAttributeEnumValue    DbEntity = "attribute_enum_value"
 	DbClientEvent           DbEntity = "client_event"
 	DbCollection            DbEntity = "collection"
 	DbCollectionConsumer    DbEntity = "collection_consumer"
@@ -168,18 +169,34 @@ func CheckCreateId_tx(ctx context.Context, tx pgx.Tx, id *uuid.UUID, entity DbEn
 }
 
 // attribute checks
+func IsContentEnum(content string) bool {
+	return content == "enum"
+}
 func IsContentFiles(content string) bool {
 	return content == "files"
 }
+func IsContentGeo(content string) bool {
+	return content == "geometry" || content == "point"
+}
+func IsContentJson(content string) bool {
+	return content == "jsonb"
+}
 func IsContentNumeric(content string) bool {
 	return content == "numeric"
 }
+func IsContentNumber(content string) bool {
+	return content == "integer" || content == "bigint" || content == "numeric" ||
+		content == "real" || content == "double precision"
+}
 func IsContentRelationship(content string) bool {
 	return content == "1:1" || content == "n:1"
 }
 func IsContentRelationship11(content string) bool {
 	return content == "1:1"
 }
+func IsContentRange(content string) bool {
+	return content == "daterange" || content == "tstzrange"
+}
 func IsContentText(content string) bool {
 	return content == "varchar" || content == "text"
 }
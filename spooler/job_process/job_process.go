@@ -0,0 +1,227 @@
+// for executing background jobs, enqueued via instance.job_enqueue()
+
+package job_process
+
+import (
+	"context"
+	"fmt"
+	"r3/config"
+	"r3/db"
+	"r3/log"
+	"r3/schema"
+	"r3/tools"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	attemptsAllow = 5                // how many attempts for each job before it is marked as failed
+	backoffBase   = 30 * time.Second // delay before first retry, doubles with each further attempt
+	backoffMax    = 6 * time.Hour    // maximum delay between retries
+)
+
+type job struct {
+	id           uuid.UUID
+	pgFunctionId uuid.UUID
+	queue        string
+	args         []byte
+	attemptCount int
+}
+
+// executes due jobs with the configured number of workers
+// workers run on all cluster nodes, jobs are distributed via row locks
+func DoAll() error {
+	workers := int(config.GetUint64("jobWorkers"))
+	if workers == 0 {
+		return nil
+	}
+
+	queueLimits, err := getQueueLimits()
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := work(queueLimits); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// executes jobs until no due job is left
+func work(queueLimits map[string]int) error {
+	queuesFull := make([]string, 0) // queues that reached their concurrency limit
+
+	for {
+		found, queueFull, err := runNext(queueLimits, queuesFull)
+		if err != nil {
+			return err
+		}
+		if queueFull != "" {
+			queuesFull = append(queuesFull, queueFull)
+			continue
+		}
+		if !found {
+			return nil
+		}
+	}
+}
+
+// executes next due job
+// returns whether a job was found and the job queue if it reached its concurrency limit
+func runNext(queueLimits map[string]int, queuesFull []string) (bool, string, error) {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutPgFunc+db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return false, "", err
+	}
+	defer tx.Rollback(ctx)
+
+	// job row stays locked until it is executed, other workers skip it
+	var j job
+	if err := tx.QueryRow(ctx, `
+		SELECT id, pg_function_id, queue, args, attempt_count
+		FROM instance.job
+		WHERE state = 'pending'
+		AND   date_run_at <= $1
+		AND   queue <> ALL($2)
+		ORDER BY priority DESC, date_run_at ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`, tools.GetTimeUnix(), queuesFull).Scan(&j.id, &j.pgFunctionId,
+		&j.queue, &j.args, &j.attemptCount); err != nil {
+
+		if err == pgx.ErrNoRows {
+			return false, "", nil
+		}
+		return false, "", err
+	}
+
+	// concurrency limits apply to the entire cluster
+	// each job being executed holds one transaction-bound advisory lock (slot) of its queue
+	if limit, exists := queueLimits[j.queue]; exists {
+		slotFound := false
+		for slot := 0; slot < limit && !slotFound; slot++ {
+			if err := tx.QueryRow(ctx, `
+				SELECT PG_TRY_ADVISORY_XACT_LOCK(HASHTEXT($1),$2)
+			`, fmt.Sprintf("r3_job_queue_%s", j.queue), int32(slot)).Scan(&slotFound); err != nil {
+				return false, "", err
+			}
+		}
+		if !slotFound {
+			return false, j.queue, nil
+		}
+	}
+
+	errRun := execute_tx(ctx, tx, j)
+
+	if errRun == nil {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM instance.job
+			WHERE id = $1
+		`, j.id); err != nil {
+			return false, "", err
+		}
+		return true, "", tx.Commit(ctx)
+	}
+
+	// job failed, retry with exponential backoff until attempts are exhausted
+	j.attemptCount++
+	state := "pending"
+	if j.attemptCount >= attemptsAllow {
+		state = "failed"
+	}
+
+	backoff := backoffBase << (j.attemptCount - 1)
+	if backoff > backoffMax || backoff <= 0 {
+		backoff = backoffMax
+	}
+
+	log.Error(log.ContextScheduler, fmt.Sprintf("failed to execute job %s (attempt %d of %d)",
+		j.id, j.attemptCount, attemptsAllow), errRun)
+
+	now := tools.GetTimeUnix()
+	if _, err := tx.Exec(ctx, `
+		UPDATE instance.job
+		SET state = $1, attempt_count = $2, date_attempt = $3,
+			date_run_at = $4, error_text = $5
+		WHERE id = $6
+	`, state, j.attemptCount, now, now+int64(backoff.Seconds()), errRun.Error(), j.id); err != nil {
+		return false, "", err
+	}
+	return true, "", tx.Commit(ctx)
+}
+
+// executes job function in a sub transaction, so that failures can be recorded in the parent transaction
+func execute_tx(ctx context.Context, tx pgx.Tx, j job) error {
+
+	modName, fncName, fncArgs, _, err := schema.GetPgFunctionDetailsById_tx(ctx, tx, j.pgFunctionId)
+	if err != nil {
+		return err
+	}
+
+	txSub, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer txSub.Rollback(ctx)
+
+	// statement timeout instead of context timeout, to keep the parent transaction usable
+	if _, err := txSub.Exec(ctx, fmt.Sprintf(`SET LOCAL statement_timeout = %d`,
+		db.CtxDefTimeoutPgFunc.Milliseconds())); err != nil {
+
+		return err
+	}
+
+	// job arguments are handed over as single JSONB argument, if the function accepts arguments
+	if fncArgs == "" {
+		_, err = txSub.Exec(ctx, fmt.Sprintf(`SELECT "%s"."%s"()`, modName, fncName))
+	} else {
+		_, err = txSub.Exec(ctx, fmt.Sprintf(`SELECT "%s"."%s"($1::JSONB)`, modName, fncName), j.args)
+	}
+	if err != nil {
+		return err
+	}
+	return txSub.Commit(ctx)
+}
+
+func getQueueLimits() (map[string]int, error) {
+	ctx, ctxCanc := context.WithTimeout(context.Background(), db.CtxDefTimeoutSysTask)
+	defer ctxCanc()
+
+	queueLimits := make(map[string]int)
+	rows, err := db.Pool.Query(ctx, `
+		SELECT name, concurrency
+		FROM instance.job_queue
+	`)
+	if err != nil {
+		return queueLimits, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var concurrency int
+		if err := rows.Scan(&name, &concurrency); err != nil {
+			return queueLimits, err
+		}
+		queueLimits[name] = concurrency
+	}
+	return queueLimits, nil
+}
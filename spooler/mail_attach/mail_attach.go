@@ -13,16 +13,20 @@ import (
 	"r3/schema"
 	"r3/tools"
 	"r3/types"
+	"regexp"
+	"strings"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
+var regexFileNameInvalid = regexp.MustCompile(`[\\/:*?"<>|\x00-\x1f]`)
+
 func DoAll() error {
 	mails := make([]types.Mail, 0)
 
 	rows, err := db.Pool.Query(context.Background(), `
-		SELECT id, record_id_wofk, attribute_id
+		SELECT id, subject, record_id_wofk, attribute_id, attach_raw
 		FROM instance.mail_spool
 		WHERE outgoing = FALSE
 		AND record_id_wofk IS NOT NULL
@@ -36,7 +40,7 @@ func DoAll() error {
 	for rows.Next() {
 		var m types.Mail
 
-		if err := rows.Scan(&m.Id, &m.RecordId, &m.AttributeId); err != nil {
+		if err := rows.Scan(&m.Id, &m.Subject, &m.RecordId, &m.AttributeId, &m.AttachRaw); err != nil {
 			return err
 		}
 		mails = append(mails, m)
@@ -99,6 +103,32 @@ func do(mail types.Mail) error {
 	}
 	rows.Close()
 
+	// add original message if requested
+	if mail.AttachRaw {
+		var raw []byte
+		if err := db.Pool.QueryRow(ctx, `
+			SELECT raw
+			FROM instance.mail_spool
+			WHERE id = $1
+			AND raw IS NOT NULL
+		`, mail.Id).Scan(&raw); err != nil && err != pgx.ErrNoRows {
+			return err
+		}
+
+		if len(raw) != 0 {
+			f := types.MailFile{
+				File: raw,
+				Name: getRawFileName(mail.Subject),
+				Size: int64(len(raw) / 1024),
+			}
+			f.Id, err = uuid.NewV4()
+			if err != nil {
+				return err
+			}
+			filesMail = append(filesMail, f)
+		}
+	}
+
 	if len(filesMail) == 0 {
 		// no attachments to process, delete mail
 		return deleteMail(ctx, mail.Id)
@@ -172,6 +202,13 @@ func do(mail types.Mail) error {
 }
 
 // helpers
+func getRawFileName(subject string) string {
+	name := strings.TrimSpace(regexFileNameInvalid.ReplaceAllString(subject, "_"))
+	if name == "" {
+		name = "message"
+	}
+	return fmt.Sprintf("%s.eml", tools.Substring(name, 0, 100))
+}
 func deleteMail(ctx context.Context, id int64) error {
 	tx, err := db.Pool.Begin(ctx)
 	if err != nil {
@@ -1,12 +1,15 @@
 package mail_receive
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"html"
 	"io"
+	"net/textproto"
 	"r3/cache"
 	"r3/config"
 	"r3/db"
@@ -21,25 +24,29 @@ import (
 	"github.com/emersion/go-imap/client"
 	_ "github.com/emersion/go-message/charset"
 	"github.com/emersion/go-message/mail"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 var (
-	accountMode   = "imap"
-	collectPerRun = uint32(50)
-	imapFolder    = "INBOX"
-	regexCid      = regexp.MustCompile(`<img[^>]*cid\:([^\"]*)`)
+	accountMode       = "imap"
+	collectPerRun     = uint32(50)
+	imapFolderDefault = "INBOX"
+	regexCid          = regexp.MustCompile(`<img[^>]*cid\:([^\"]*)`)
 )
 
 func DoAll() error {
+	accountMap := cache.GetMailAccountMap()
+
+	// accounts with IDLE are retrieved by their listeners, start/stop them as required
+	updateListeners(accountMap)
+
 	if !cache.GetMailAccountsExist() {
 		log.Info(log.ContextMail, "cannot start retrieval, no accounts defined")
 		return nil
 	}
 
-	accountMap := cache.GetMailAccountMap()
-
 	for _, ma := range accountMap {
-		if ma.Mode != accountMode {
+		if ma.Mode != accountMode || ma.ImapIdle {
 			continue
 		}
 
@@ -54,23 +61,34 @@ func DoAll() error {
 }
 
 func do(ma types.MailAccount) error {
+	c, err := connect(ma)
+	if err != nil {
+		return err
+	}
+	defer c.Logout()
+
+	return processMailbox(c, ma)
+}
+
+// connects and authenticates to IMAP server, selects folder to retrieve from
+func connect(ma types.MailAccount) (*client.Client, error) {
 
 	// get OAuth client token if used
 	usesXoauth2 := ma.OauthClientId.Valid
 	if usesXoauth2 {
 		if !config.GetLicenseActive() {
-			return errors.New("no valid license (required for OAuth clients)")
+			return nil, errors.New("no valid license (required for OAuth clients)")
 		}
 		c, err := cache.GetOauthClient(ma.OauthClientId.Int32)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if !c.ClientSecret.Valid || !c.TokenUrl.Valid {
-			return errors.New("missing client secret or token URL in OAUTH client")
+			return nil, errors.New("missing client secret or token URL in OAUTH client")
 		}
 		ma.Password, err = tools.GetOAuthToken(c.ClientId, c.ClientSecret.String, c.TokenUrl.String, c.Scopes)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -86,70 +104,86 @@ func do(ma types.MailAccount) error {
 		c, err = client.DialTLS(fmt.Sprintf("%s:%d", ma.HostName, ma.HostPort), nil)
 	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer c.Logout()
 
 	// STARTTLS upgrade to encrypted connection
 	if ma.StartTls {
 		if err := c.StartTLS(&tls.Config{ServerName: ma.HostName}); err != nil {
-			return err
+			c.Logout()
+			return nil, err
 		}
 	}
 
 	if usesXoauth2 {
-		if err := c.Authenticate(newXoauth2Client(ma.Username, ma.Password)); err != nil {
-			return err
-		}
+		err = c.Authenticate(newXoauth2Client(ma.Username, ma.Password))
 	} else {
-		if err := c.Login(ma.Username, ma.Password); err != nil {
-			return err
-		}
+		err = c.Login(ma.Username, ma.Password)
+	}
+	if err != nil {
+		c.Logout()
+		return nil, err
+	}
+
+	if _, err := c.Select(getFolder(ma), false); err != nil {
+		c.Logout()
+		return nil, err
 	}
+	return c, nil
+}
+
+// retrieves messages from selected folder, applies configured action to processed messages
+func processMailbox(c *client.Client, ma types.MailAccount) error {
+	folder := getFolder(ma)
 
-	mbox, err := c.Select(imapFolder, false)
+	// processed messages, that are only marked as seen, stay in the folder
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{imap.DeletedFlag}
+	if ma.ImapAction == "seen" {
+		criteria.WithoutFlags = append(criteria.WithoutFlags, imap.SeenFlag)
+	}
+
+	uids, err := c.UidSearch(criteria)
 	if err != nil {
 		return err
 	}
 
 	log.Info(log.ContextMail, fmt.Sprintf("found %d messages inside %s for account '%s'",
-		mbox.Messages, imapFolder, ma.Name))
+		len(uids), folder, ma.Name))
 
-	if mbox.Messages == 0 {
+	if len(uids) == 0 {
 		return nil
 	}
 
 	log.Info(log.ContextMail, fmt.Sprintf("is now fetching messages (at most %d per run)", collectPerRun))
 
-	// fetch mails from mailbox
-	seqDel := new(imap.SeqSet) // messages to delete
-	seqGet := new(imap.SeqSet) // messages to fetch
-
 	// fetch X last messages
-	offsetFrom := uint32(1)
-	offsetTo := mbox.Messages
-	if mbox.Messages > collectPerRun-1 {
-		offsetFrom = mbox.Messages - (collectPerRun - 1)
+	if uint32(len(uids)) > collectPerRun {
+		uids = uids[uint32(len(uids))-collectPerRun:]
 	}
-	seqGet.AddRange(offsetFrom, offsetTo)
 
-	section := imap.BodySectionName{}
+	seqDone := new(imap.SeqSet) // messages that were processed
+	seqGet := new(imap.SeqSet)  // messages to fetch
+	seqGet.AddNum(uids...)
+
+	// peek to keep messages unseen, failed messages stay in the mailbox unchanged
+	section := imap.BodySectionName{Peek: true}
 	messages := make(chan *imap.Message, 10)
 	doneErr := make(chan error, 1)
 
 	go func() {
-		doneErr <- c.Fetch(seqGet, []imap.FetchItem{section.FetchItem()}, messages)
+		doneErr <- c.UidFetch(seqGet, []imap.FetchItem{section.FetchItem(), imap.FetchUid}, messages)
 	}()
 
 	// process and then store messages to mail spooler
 	for msg := range messages {
-		if err := processMessage(ma.Id, msg, &section); err != nil {
+		if err := processMessage(ma.Id, ma.ImapStoreRaw, msg, &section); err != nil {
 			// mail processing can fail because of many reasons, warn and move on
-			log.Warning(log.ContextMail, "failed to process message - its not being deleted from the mailbox", err)
+			log.Warning(log.ContextMail, "failed to process message - it stays in the mailbox", err)
 
 		} else {
-			// add to deletion sequence if processed successfully
-			seqDel.AddNum(msg.SeqNum)
+			// add to done sequence if processed successfully
+			seqDone.AddNum(msg.Uid)
 		}
 	}
 
@@ -158,24 +192,32 @@ func do(ma types.MailAccount) error {
 		return err
 	}
 
-	log.Info(log.ContextMail, fmt.Sprintf("processed %d messages successfully, marking them for deletion",
-		len(seqDel.Set)))
+	if seqDone.Empty() {
+		return nil
+	}
+
+	// if database update was successful, execute action for processed messages
+	log.Info(log.ContextMail, fmt.Sprintf("processed %d messages successfully, applying action '%s'",
+		len(seqDone.Set), ma.ImapAction))
 
-	// if database update was successful, execute mail deletion
-	if len(seqDel.Set) != 0 {
+	switch ma.ImapAction {
+	case "move":
+		return c.UidMove(seqDone, ma.ImapFolderArchive.String)
+	case "seen":
 		item := imap.FormatFlagsOp(imap.AddFlags, true)
-		flags := []interface{}{imap.DeletedFlag}
-		if err := c.Store(seqDel, item, flags, nil); err != nil {
-			return err
-		}
-		if err := c.Expunge(nil); err != nil {
-			return err
-		}
+		flags := []interface{}{imap.SeenFlag}
+		return c.UidStore(seqDone, item, flags, nil)
 	}
-	return nil
+
+	item := imap.FormatFlagsOp(imap.AddFlags, true)
+	flags := []interface{}{imap.DeletedFlag}
+	if err := c.UidStore(seqDone, item, flags, nil); err != nil {
+		return err
+	}
+	return c.Expunge(nil)
 }
 
-func processMessage(mailAccountId int32, msg *imap.Message, section *imap.BodySectionName) error {
+func processMessage(mailAccountId int32, storeRaw bool, msg *imap.Message, section *imap.BodySectionName) error {
 
 	if msg == nil {
 		return errors.New("server did not return message")
@@ -186,7 +228,13 @@ func processMessage(mailAccountId int32, msg *imap.Message, section *imap.BodySe
 		return errors.New("message body was empty")
 	}
 
-	mr, err := mail.CreateReader(msgBody)
+	// keep original message for parsing, it is only stored if enabled for mail account
+	raw, err := io.ReadAll(msgBody)
+	if err != nil {
+		return err
+	}
+
+	mr, err := mail.CreateReader(bytes.NewReader(raw))
 	if err != nil {
 		return err
 	}
@@ -194,7 +242,8 @@ func processMessage(mailAccountId int32, msg *imap.Message, section *imap.BodySe
 	// parse header
 	var date time.Time
 	var subject string
-	var cc, from, to []*mail.Address
+	var cc, from, replyTo, to []*mail.Address
+	var inReplyTo, references []string
 
 	header := mr.Header
 	date, err = header.Date()
@@ -217,6 +266,54 @@ func processMessage(mailAccountId int32, msg *imap.Message, section *imap.BodySe
 	if err != nil {
 		return err
 	}
+	replyTo, err = header.AddressList("Reply-To")
+	if err != nil {
+		return err
+	}
+
+	// thread headers, message IDs are stored in header form (<id>)
+	messageId, err := header.MessageID()
+	if err != nil || messageId == "" {
+		// message ID is optional, generate one to keep message referable
+		messageId = fmt.Sprintf("%s.%d.%d@r3.local", tools.Hash(string(raw))[:32], mailAccountId, date.Unix())
+	}
+	messageId = fmt.Sprintf("<%s>", messageId)
+
+	if inReplyTo, err = header.MsgIDList("In-Reply-To"); err != nil {
+		// broken thread headers are not critical, continue without thread
+		log.Warning(log.ContextMail, "failed to parse In-Reply-To header", err)
+		inReplyTo = []string{}
+	}
+	if references, err = header.MsgIDList("References"); err != nil {
+		log.Warning(log.ContextMail, "failed to parse References header", err)
+		references = []string{}
+	}
+	for i := range inReplyTo {
+		inReplyTo[i] = fmt.Sprintf("<%s>", inReplyTo[i])
+	}
+	for i := range references {
+		references[i] = fmt.Sprintf("<%s>", references[i])
+	}
+
+	// thread is taken from known referenced mails, otherwise first reference or own message ID starts a new one
+	threadIdFallback := messageId
+	if len(references) != 0 {
+		threadIdFallback = references[0]
+	} else if len(inReplyTo) != 0 {
+		threadIdFallback = inReplyTo[0]
+	}
+
+	// all headers, values decoded if possible
+	headers := make(map[string][]string)
+	fields := header.Fields()
+	for fields.Next() {
+		value, err := fields.Text()
+		if err != nil {
+			value = fields.Value()
+		}
+		key := textproto.CanonicalMIMEHeaderKey(fields.Key())
+		headers[key] = append(headers[key], value)
+	}
 
 	// parse body
 	type cid struct {
@@ -229,6 +326,15 @@ func processMessage(mailAccountId int32, msg *imap.Message, section *imap.BodySe
 	var files []types.MailFile
 	var gotHtmlText bool = false
 
+	// delivery status reports
+	var dsnText string     // text content, to recognize non-standard bounces
+	var dsnStatus []byte   // delivery-status part
+	var dsnOriginal []byte // returned original message or its headers
+
+	contentType, contentTypeParams, _ := header.ContentType()
+	isReport := contentType == "multipart/report" &&
+		strings.ToLower(contentTypeParams["report-type"]) == "delivery-status"
+
 	for {
 		p, err := mr.NextPart()
 		if err == io.EOF {
@@ -245,6 +351,19 @@ func processMessage(mailAccountId int32, msg *imap.Message, section *imap.BodySe
 				return err
 			}
 
+			switch headerType {
+			case "message/delivery-status", "message/global-delivery-status":
+				if dsnStatus, err = io.ReadAll(p.Body); err != nil {
+					return err
+				}
+				continue
+			case "message/rfc822", "message/global", "text/rfc822-headers", "message/global-headers":
+				if dsnOriginal, err = io.ReadAll(p.Body); err != nil {
+					return err
+				}
+				continue
+			}
+
 			if strings.Contains(headerType, "text") {
 
 				// some senders include both HTML and plain text - in these cases, we only want the HTML version
@@ -257,6 +376,12 @@ func processMessage(mailAccountId int32, msg *imap.Message, section *imap.BodySe
 					return err
 				}
 
+				if headerType == "text/html" {
+					dsnText = html.UnescapeString(string(b))
+				} else {
+					dsnText = string(b)
+				}
+
 				if headerType == "text/plain" {
 					// replace 2 new lines with a paragraph, 1 new line with a line break
 					body = regexp.MustCompile(`(.*)(\r\n){2,}`).ReplaceAllString(string(b), "<p>$1</p>")
@@ -308,6 +433,13 @@ func processMessage(mailAccountId int32, msg *imap.Message, section *imap.BodySe
 				}
 			}
 
+			// some servers return original message as attachment
+			if contentType, _, err := h.ContentType(); err == nil && dsnOriginal == nil &&
+				(contentType == "message/rfc822" || contentType == "text/rfc822-headers") {
+
+				dsnOriginal = b
+			}
+
 			files = append(files, types.MailFile{
 				File: b,
 				Name: name,
@@ -349,25 +481,57 @@ func processMessage(mailAccountId int32, msg *imap.Message, section *imap.BodySe
 	for _, file := range files {
 		fileList = append(fileList, fmt.Sprintf("%s (%dkb)", file.Name, file.Size))
 	}
-	if _, err := tx.Exec(ctx, `
+	referencesList := pgtype.Text{String: strings.Join(references, " "), Valid: len(references) != 0}
+	inReplyToList := pgtype.Text{String: strings.Join(inReplyTo, " "), Valid: len(inReplyTo) != 0}
+
+	var threadId string
+	if err := tx.QueryRow(ctx, `
 		INSERT INTO instance.mail_traffic (from_list, to_list, cc_list,
-			subject, date, files, mail_account_id, outgoing)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,FALSE)
+			subject, date, files, mail_account_id, outgoing, message_id,
+			references_list, thread_id)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,FALSE,$8,$9,COALESCE((
+			SELECT thread_id
+			FROM instance.mail_traffic
+			WHERE message_id = ANY($10)
+			AND thread_id IS NOT NULL
+			ORDER BY date ASC
+			LIMIT 1
+		),$11))
+		RETURNING thread_id
 	`, getStringListFromAddress(from), getStringListFromAddress(to), getStringListFromAddress(cc),
-		subject, date.Unix(), fileList, mailAccountId); err != nil {
+		subject, date.Unix(), fileList, mailAccountId, messageId, referencesList,
+		append(inReplyTo, references...), threadIdFallback).Scan(&threadId); err != nil {
 
 		return fmt.Errorf("%w, %s", errors.New("failed to store message in traffic log"), err)
 	}
 
+	// delivery status reports of known outgoing messages are not stored as regular messages
+	if report := parseDeliveryReport(isReport, from, subject, dsnText, dsnStatus, dsnOriginal); report != nil {
+		known, err := applyDeliveryReport_tx(ctx, tx, report, date.Unix())
+		if err != nil {
+			return fmt.Errorf("%w, %s", errors.New("failed to store delivery status"), err)
+		}
+		if known {
+			return tx.Commit(ctx)
+		}
+	}
+
 	// store message in spooler
+	var rawStored []byte
+	if storeRaw {
+		rawStored = raw
+	}
+
 	var mailId int64
 	if err := tx.QueryRow(ctx, `
 		INSERT INTO instance.mail_spool (from_list, to_list, cc_list,
-			subject, body, date, mail_account_id, outgoing)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,FALSE)
+			reply_to_list, subject, body, date, mail_account_id, outgoing,
+			message_id, in_reply_to, references_list, thread_id, headers, raw)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,FALSE,$9,$10,$11,$12,$13,$14)
 		RETURNING id
 	`, getStringListFromAddress(from), getStringListFromAddress(to), getStringListFromAddress(cc),
-		subject, body, date.Unix(), mailAccountId).Scan(&mailId); err != nil {
+		getStringListFromAddress(replyTo), subject, body, date.Unix(), mailAccountId,
+		messageId, inReplyToList, referencesList, threadId, headers, rawStored).Scan(&mailId); err != nil {
 
 		return fmt.Errorf("%w, %s", errors.New("failed to store message in spooler"), err)
 	}
@@ -385,6 +549,12 @@ func processMessage(mailAccountId int32, msg *imap.Message, section *imap.BodySe
 }
 
 // helpers
+func getFolder(ma types.MailAccount) string {
+	if ma.ImapFolder.Valid && ma.ImapFolder.String != "" {
+		return ma.ImapFolder.String
+	}
+	return imapFolderDefault
+}
 func getStringListFromAddress(list []*mail.Address) string {
 	out := make([]string, 0)
 	for _, a := range list {
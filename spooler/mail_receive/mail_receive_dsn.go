@@ -0,0 +1,303 @@
+package mail_receive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/textproto"
+	"r3/cache"
+	"r3/log"
+	"r3/tools"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/emersion/go-message/mail"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// delivery status report for an outgoing message
+// either a standard DSN (RFC 3464, multipart/report) or a non-standard bounce, recognized by sender/subject
+type deliveryReport struct {
+	messageId  string // Message-ID of original message, in header form (<id>)
+	action     string // overall action, most severe of all recipients
+	heuristic  bool   // non-standard bounce, recipients are guessed from message text
+	recipients []deliveryRecipient
+}
+type deliveryRecipient struct {
+	address    string
+	action     string // delivered/delayed/expanded/failed/relayed
+	status     string // enhanced status code (RFC 3463), e.g. 5.1.1
+	diagnostic string // diagnostic text of reporting server
+}
+
+var (
+	deliveryActions = []string{"delivered", "delayed", "expanded", "failed", "relayed"}
+
+	regexBounceSender    = regexp.MustCompile(`(?i)^(mailer-daemon|postmaster)@`)
+	regexBounceSubject   = regexp.MustCompile(`(?i)(undeliver|returned mail|delivery (status notification|failure|has failed|problem)|mail delivery (failed|failure|subsystem)|failure notice|non[- ]?delivery)`)
+	regexBounceDelayed   = regexp.MustCompile(`(?i)(delay|will retry|still trying)`)
+	regexBounceMessageId = regexp.MustCompile(`(?im)^[ \t]*Message-ID:[ \t]*(<[^<>\s]+>)`)
+	regexBounceRecipient = regexp.MustCompile(`<([^<>@\s]+@[^<>\s]+)>`)
+	regexBounceStatus    = regexp.MustCompile(`\b([245]\.\d{1,3}\.\d{1,3})\b`)
+)
+
+// returns delivery status report if message is one, nil otherwise
+// isReport: message is multipart/report with report-type delivery-status
+// text: text content of message, status: delivery-status part, original: returned message or its headers
+func parseDeliveryReport(isReport bool, from []*mail.Address, subject string,
+	text string, status []byte, original []byte) *deliveryReport {
+
+	var r deliveryReport
+
+	if isReport && len(status) != 0 {
+		r.recipients = parseDeliveryStatus(status)
+	} else {
+		isBounce := regexBounceSubject.MatchString(subject)
+		for _, a := range from {
+			if regexBounceSender.MatchString(a.Address) {
+				isBounce = true
+			}
+		}
+		if !isBounce {
+			return nil
+		}
+		r.heuristic = true
+	}
+
+	// original message ID, from returned headers or from message text
+	if len(original) != 0 {
+		h, _ := textproto.NewReader(bufio.NewReader(bytes.NewReader(original))).ReadMIMEHeader()
+		if id := strings.TrimSpace(h.Get("Message-Id")); strings.HasPrefix(id, "<") {
+			r.messageId = id
+		}
+	}
+	if r.messageId == "" {
+		if m := regexBounceMessageId.FindStringSubmatch(string(original) + "\n" + text); len(m) == 2 {
+			r.messageId = m[1]
+		}
+	}
+	if r.messageId == "" {
+		return nil
+	}
+
+	if r.heuristic {
+		action := "failed"
+		if regexBounceDelayed.MatchString(subject) {
+			action = "delayed"
+		}
+
+		var status, diagnostic string
+		if m := regexBounceStatus.FindStringSubmatch(text); len(m) == 2 {
+			status = m[1]
+			for _, line := range strings.Split(text, "\n") {
+				if strings.Contains(line, status) {
+					diagnostic = tools.Substring(strings.TrimSpace(line), 0, 500)
+					break
+				}
+			}
+		}
+
+		// candidates are checked against recipients of original message once it is known
+		for _, m := range regexBounceRecipient.FindAllStringSubmatch(text, -1) {
+			address := strings.ToLower(m[1])
+			if fmt.Sprintf("<%s>", address) == strings.ToLower(r.messageId) ||
+				slices.ContainsFunc(r.recipients, func(dr deliveryRecipient) bool { return dr.address == address }) {
+				continue
+			}
+			r.recipients = append(r.recipients, deliveryRecipient{
+				address:    address,
+				action:     action,
+				status:     status,
+				diagnostic: diagnostic,
+			})
+		}
+		r.action = action
+		return &r
+	}
+
+	if len(r.recipients) == 0 {
+		return nil
+	}
+
+	// most severe action applies to the whole message
+	for _, dr := range r.recipients {
+		if r.action == "" || dr.action == "failed" || (dr.action == "delayed" && r.action != "failed") {
+			r.action = dr.action
+		}
+	}
+	return &r
+}
+
+// parses per-recipient fields of message/delivery-status part (RFC 3464)
+// first block contains per-message fields, following blocks per-recipient fields
+func parseDeliveryStatus(status []byte) []deliveryRecipient {
+	recipients := make([]deliveryRecipient, 0)
+	reader := textproto.NewReader(bufio.NewReader(bytes.NewReader(status)))
+
+	for {
+		h, err := reader.ReadMIMEHeader()
+
+		recipient := h.Get("Final-Recipient")
+		if recipient == "" {
+			recipient = h.Get("Original-Recipient")
+		}
+		action := strings.ToLower(strings.TrimSpace(h.Get("Action")))
+
+		if recipient != "" && slices.Contains(deliveryActions, action) {
+			// address type is prefixed (rfc822; user@example.com)
+			if _, address, found := strings.Cut(recipient, ";"); found {
+				recipient = address
+			}
+			diagnostic := h.Get("Diagnostic-Code")
+			if _, text, found := strings.Cut(diagnostic, ";"); found {
+				diagnostic = text
+			}
+			recipients = append(recipients, deliveryRecipient{
+				address:    strings.ToLower(strings.Trim(strings.TrimSpace(recipient), "<>")),
+				action:     action,
+				status:     strings.TrimSpace(strings.Split(h.Get("Status"), " ")[0]),
+				diagnostic: tools.Substring(strings.TrimSpace(diagnostic), 0, 500),
+			})
+		}
+		if err != nil {
+			break
+		}
+	}
+	return recipients
+}
+
+// returns lower case addresses of comma separated address list
+// example: 'Jane Doe <jane.doe@example.com>, john.doe@example.com'
+func getAddressesFromList(list string) []string {
+	addresses := make([]string, 0)
+	for _, s := range strings.Split(list, ",") {
+		s = strings.TrimSpace(s)
+		if a, err := mail.ParseAddress(s); err == nil {
+			s = a.Address
+		}
+		if s != "" {
+			addresses = append(addresses, strings.ToLower(s))
+		}
+	}
+	return addresses
+}
+
+// stores delivery status of known outgoing message and executes its callback function
+// returns false if original message is unknown, report is then handled as regular message
+// returns an error if the transaction cannot be used anymore, the report message then stays in the mailbox
+// it is processed again by the next retrieval, including callbacks (their DB changes are rolled back with the transaction)
+func applyDeliveryReport_tx(ctx context.Context, tx pgx.Tx, r *deliveryReport, date int64) (bool, error) {
+
+	var fncId pgtype.UUID
+	var callbackValue pgtype.Text
+	var recipientsKnown string
+
+	err := tx.QueryRow(ctx, `
+		SELECT pg_function_id_callback, callback_value,
+			CONCAT_WS(',', to_list, cc_list, bcc_list)
+		FROM instance.mail_traffic
+		WHERE message_id = $1
+		AND outgoing
+		ORDER BY date DESC
+		LIMIT 1
+	`, r.messageId).Scan(&fncId, &callbackValue, &recipientsKnown)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// guessed recipients must be recipients of original message
+	if r.heuristic {
+		addresses := getAddressesFromList(recipientsKnown)
+		r.recipients = slices.DeleteFunc(r.recipients, func(dr deliveryRecipient) bool {
+			return !slices.Contains(addresses, dr.address)
+		})
+	}
+
+	log.Info(log.ContextMail, fmt.Sprintf("received delivery status '%s' for message %s",
+		r.action, r.messageId))
+
+	for _, dr := range r.recipients {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO instance.mail_delivery (message_id, recipient,
+				action, status, diagnostic, date)
+			VALUES ($1,$2,$3,$4,$5,$6)
+			ON CONFLICT (message_id, recipient) DO UPDATE
+			SET action = EXCLUDED.action, status = EXCLUDED.status,
+				diagnostic = EXCLUDED.diagnostic, date = EXCLUDED.date
+		`, r.messageId, dr.address, dr.action, dr.status, dr.diagnostic, date); err != nil {
+			return false, err
+		}
+	}
+
+	// failed delivery is not overwritten by later reports (e.g. delivered to other recipients)
+	if _, err := tx.Exec(ctx, `
+		UPDATE instance.mail_traffic
+		SET delivery_action = $1, delivery_date = $2
+		WHERE message_id = $3
+		AND outgoing
+		AND (delivery_action IS DISTINCT FROM 'failed' OR $4)
+	`, r.action, date, r.messageId, r.action == "failed"); err != nil {
+		return false, err
+	}
+
+	if !fncId.Valid {
+		return true, nil
+	}
+
+	cache.Schema_mx.RLock()
+	fnc, exists := cache.PgFunctionIdMap[fncId.Bytes]
+	if !exists {
+		cache.Schema_mx.RUnlock()
+		log.Warning(log.ContextMail, "failed to execute delivery status callback",
+			fmt.Errorf("unknown function '%s'", fncId.String()))
+
+		return true, nil
+	}
+	mod, exists := cache.ModuleIdMap[fnc.ModuleId]
+	cache.Schema_mx.RUnlock()
+	if !exists {
+		log.Warning(log.ContextMail, "failed to execute delivery status callback",
+			fmt.Errorf("unknown module '%s'", fnc.ModuleId))
+
+		return true, nil
+	}
+
+	// callback is executed once per recipient, once without recipient if none are known
+	recipients := r.recipients
+	if len(recipients) == 0 {
+		recipients = []deliveryRecipient{{action: r.action}}
+	}
+	for _, dr := range recipients {
+
+		// failing callback must not block mail retrieval, it is executed inside a savepoint
+		txCallback, err := tx.Begin(ctx)
+		if err != nil {
+			return false, err
+		}
+		if _, err := txCallback.Exec(ctx, fmt.Sprintf(`SELECT "%s"."%s"($1,$2,$3,$4,$5,$6)`, mod.Name, fnc.Name),
+			r.messageId, pgtype.Text{String: dr.address, Valid: dr.address != ""}, dr.action,
+			pgtype.Text{String: dr.status, Valid: dr.status != ""},
+			pgtype.Text{String: dr.diagnostic, Valid: dr.diagnostic != ""}, callbackValue); err != nil {
+
+			log.Warning(log.ContextMail, fmt.Sprintf("failed to execute delivery status callback '%s.%s'",
+				mod.Name, fnc.Name), err)
+
+			// savepoint rollback only fails if the transaction itself is broken (e.g. lost connection)
+			// the report cannot be stored then, it is retried with the message
+			if err := txCallback.Rollback(ctx); err != nil {
+				return false, fmt.Errorf("failed to roll back delivery status callback, %w", err)
+			}
+			continue
+		}
+		if err := txCallback.Commit(ctx); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,240 @@
+package mail_receive
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/emersion/go-message/mail"
+)
+
+func TestParseDeliveryStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		want   []deliveryRecipient
+	}{
+		{
+			name:   "empty",
+			status: "",
+			want:   []deliveryRecipient{},
+		},
+		{
+			name:   "per-message fields only",
+			status: "Reporting-MTA: dns; mx.example.com\r\nArrival-Date: Mon, 1 Jan 2024 10:00:00 +0000\r\n",
+			want:   []deliveryRecipient{},
+		},
+		{
+			name: "single failed recipient",
+			status: "Reporting-MTA: dns; mx.example.com\r\n\r\n" +
+				"Final-Recipient: rfc822; Jane.Doe@Example.com\r\n" +
+				"Action: failed\r\n" +
+				"Status: 5.1.1\r\n" +
+				"Diagnostic-Code: smtp; 550 5.1.1 user unknown\r\n",
+			want: []deliveryRecipient{{
+				address:    "jane.doe@example.com",
+				action:     "failed",
+				status:     "5.1.1",
+				diagnostic: "550 5.1.1 user unknown",
+			}},
+		},
+		{
+			name: "multiple recipients",
+			status: "Reporting-MTA: dns; mx.example.com\r\n\r\n" +
+				"Final-Recipient: rfc822; <jane@example.com>\r\n" +
+				"Action: Delayed\r\n" +
+				"Status: 4.4.7 (delivery time expired)\r\n\r\n" +
+				"Final-Recipient: rfc822; john@example.com\r\n" +
+				"Action: delivered\r\n" +
+				"Status: 2.0.0\r\n",
+			want: []deliveryRecipient{
+				{address: "jane@example.com", action: "delayed", status: "4.4.7"},
+				{address: "john@example.com", action: "delivered", status: "2.0.0"},
+			},
+		},
+		{
+			name: "original recipient as fallback",
+			status: "Reporting-MTA: dns; mx.example.com\r\n\r\n" +
+				"Original-Recipient: rfc822; jane@example.com\r\n" +
+				"Action: relayed\r\n",
+			want: []deliveryRecipient{{address: "jane@example.com", action: "relayed"}},
+		},
+		{
+			name: "unknown action is ignored",
+			status: "Reporting-MTA: dns; mx.example.com\r\n\r\n" +
+				"Final-Recipient: rfc822; jane@example.com\r\n" +
+				"Action: bounced\r\n",
+			want: []deliveryRecipient{},
+		},
+		{
+			name: "recipient without action is ignored",
+			status: "Reporting-MTA: dns; mx.example.com\r\n\r\n" +
+				"Final-Recipient: rfc822; jane@example.com\r\n",
+			want: []deliveryRecipient{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseDeliveryStatus([]byte(tt.status))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDeliveryReport(t *testing.T) {
+	daemon := []*mail.Address{{Address: "MAILER-DAEMON@mx.example.com"}}
+	person := []*mail.Address{{Name: "Jane", Address: "jane@example.com"}}
+
+	statusFailed := []byte("Reporting-MTA: dns; mx.example.com\r\n\r\n" +
+		"Final-Recipient: rfc822; jane@example.com\r\n" +
+		"Action: failed\r\n" +
+		"Status: 5.1.1\r\n\r\n" +
+		"Final-Recipient: rfc822; john@example.com\r\n" +
+		"Action: delivered\r\n" +
+		"Status: 2.0.0\r\n")
+	statusDelayed := []byte("Reporting-MTA: dns; mx.example.com\r\n\r\n" +
+		"Final-Recipient: rfc822; john@example.com\r\n" +
+		"Action: delivered\r\n\r\n" +
+		"Final-Recipient: rfc822; jane@example.com\r\n" +
+		"Action: delayed\r\n")
+	original := []byte("Message-ID: <abc.123@example.com>\r\nSubject: Invoice\r\n\r\n")
+
+	tests := []struct {
+		name     string
+		isReport bool
+		from     []*mail.Address
+		subject  string
+		text     string
+		status   []byte
+		original []byte
+		want     *deliveryReport
+	}{
+		{
+			name:    "regular message",
+			from:    person,
+			subject: "Re: Invoice",
+			text:    "Thanks, see Message-ID: <abc.123@example.com>",
+			want:    nil,
+		},
+		{
+			name:     "DSN with most severe action",
+			isReport: true,
+			from:     daemon,
+			subject:  "Delivery Status Notification",
+			status:   statusFailed,
+			original: original,
+			want: &deliveryReport{
+				messageId: "<abc.123@example.com>",
+				action:    "failed",
+				recipients: []deliveryRecipient{
+					{address: "jane@example.com", action: "failed", status: "5.1.1"},
+					{address: "john@example.com", action: "delivered", status: "2.0.0"},
+				},
+			},
+		},
+		{
+			name:     "DSN with delayed recipient",
+			isReport: true,
+			from:     daemon,
+			status:   statusDelayed,
+			original: original,
+			want: &deliveryReport{
+				messageId: "<abc.123@example.com>",
+				action:    "delayed",
+				recipients: []deliveryRecipient{
+					{address: "john@example.com", action: "delivered"},
+					{address: "jane@example.com", action: "delayed"},
+				},
+			},
+		},
+		{
+			name:     "DSN without original message ID",
+			isReport: true,
+			from:     daemon,
+			status:   statusFailed,
+			want:     nil,
+		},
+		{
+			name:     "DSN without recipients",
+			isReport: true,
+			from:     daemon,
+			status:   []byte("Reporting-MTA: dns; mx.example.com\r\n"),
+			original: original,
+			want:     nil,
+		},
+		{
+			name:    "bounce by sender",
+			from:    daemon,
+			subject: "Returned mail: see transcript for details",
+			text: "The following address failed: <Jane@Example.com>\n" +
+				"550 5.1.1 <jane@example.com>: user unknown\n" +
+				"Message-ID: <abc.123@example.com>\n",
+			want: &deliveryReport{
+				messageId: "<abc.123@example.com>",
+				action:    "failed",
+				heuristic: true,
+				recipients: []deliveryRecipient{{
+					address:    "jane@example.com",
+					action:     "failed",
+					status:     "5.1.1",
+					diagnostic: "550 5.1.1 <jane@example.com>: user unknown",
+				}},
+			},
+		},
+		{
+			name:    "delayed bounce by subject",
+			from:    person,
+			subject: "Delivery Status Notification (Delay)",
+			text:    "Delivery delayed for <john@example.com>\n",
+			original: []byte("Message-ID: <abc.123@example.com>\r\n" +
+				"To: john@example.com\r\n\r\n"),
+			want: &deliveryReport{
+				messageId: "<abc.123@example.com>",
+				action:    "delayed",
+				heuristic: true,
+				recipients: []deliveryRecipient{
+					{address: "john@example.com", action: "delayed"},
+				},
+			},
+		},
+		{
+			name:    "bounce without original message ID",
+			from:    daemon,
+			subject: "Undelivered Mail Returned to Sender",
+			text:    "<jane@example.com>: user unknown",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseDeliveryReport(tt.isReport, tt.from, tt.subject, tt.text, tt.status, tt.original)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAddressesFromList(t *testing.T) {
+	tests := []struct {
+		list string
+		want []string
+	}{
+		{"", []string{}},
+		{"jane@example.com", []string{"jane@example.com"}},
+		{"Jane Doe <Jane.Doe@Example.com>, john@example.com", []string{"jane.doe@example.com", "john@example.com"}},
+		{"jane@example.com,,joann@example.com", []string{"jane@example.com", "joann@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.list, func(t *testing.T) {
+			got := getAddressesFromList(tt.list)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package mail_receive
+
+import (
+	"fmt"
+	"r3/cache"
+	"r3/log"
+	"r3/types"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-imap/client"
+)
+
+// IMAP IDLE listeners, one per IMAP account with IDLE enabled
+// listeners are started/stopped by the regular retrieval task, which only runs on the cluster master
+type listener struct {
+	account types.MailAccount // account state at listener start, listener is restarted if it changes
+	stop    chan struct{}
+}
+
+var (
+	listener_mx       sync.Mutex
+	listenerIdMap     = make(map[int32]listener)
+	listenerRefresh   = time.Minute * 5  // IDLE is renewed regularly, to check cluster master state and to keep connection alive
+	listenerRetryWait = time.Second * 30 // wait time before reconnecting after connection errors
+)
+
+func updateListeners(accountMap map[int32]types.MailAccount) {
+	listener_mx.Lock()
+	defer listener_mx.Unlock()
+
+	// stop listeners of deleted or changed accounts
+	for id, l := range listenerIdMap {
+		ma, exists := accountMap[id]
+		if !exists || ma != l.account {
+			close(l.stop)
+			delete(listenerIdMap, id)
+		}
+	}
+
+	// start missing listeners
+	for id, ma := range accountMap {
+		if ma.Mode != accountMode || !ma.ImapIdle {
+			continue
+		}
+		if _, exists := listenerIdMap[id]; exists {
+			continue
+		}
+		l := listener{
+			account: ma,
+			stop:    make(chan struct{}),
+		}
+		listenerIdMap[id] = l
+		go listen(l)
+	}
+}
+
+func listen(l listener) {
+	log.Info(log.ContextMail, fmt.Sprintf("started IDLE listener for '%s'", l.account.Name))
+
+	for {
+		if err := listenConnection(l); err != nil {
+			log.Error(log.ContextMail, fmt.Sprintf("IDLE listener for '%s' failed, reconnecting in %s",
+				l.account.Name, listenerRetryWait), err)
+		}
+
+		if !cache.GetIsClusterMaster() {
+			// retrieval is done by cluster master only, remove own listener
+			listener_mx.Lock()
+			if current, exists := listenerIdMap[l.account.Id]; exists && current.stop == l.stop {
+				delete(listenerIdMap, l.account.Id)
+			}
+			listener_mx.Unlock()
+
+			log.Info(log.ContextMail, fmt.Sprintf("stopped IDLE listener for '%s', node is not cluster master", l.account.Name))
+			return
+		}
+
+		select {
+		case <-l.stop:
+			log.Info(log.ContextMail, fmt.Sprintf("stopped IDLE listener for '%s'", l.account.Name))
+			return
+		case <-time.After(listenerRetryWait):
+		}
+	}
+}
+
+// processes available messages, then waits for new messages via IDLE until stopped or on error
+// returns without error if listener was stopped or node is not cluster master anymore
+func listenConnection(l listener) error {
+	c, err := connect(l.account)
+	if err != nil {
+		return err
+	}
+	defer c.Logout()
+
+	// updates must be drained continuously, otherwise the IMAP client blocks
+	// new mailbox states are forwarded as signal for new messages
+	updates := make(chan client.Update, 10)
+	newMessages := make(chan struct{}, 1)
+	exit := make(chan struct{})
+	defer close(exit)
+
+	go func() {
+		for {
+			select {
+			case u := <-updates:
+				if _, ok := u.(*client.MailboxUpdate); ok {
+					select {
+					case newMessages <- struct{}{}:
+					default:
+					}
+				}
+			case <-exit:
+				return
+			}
+		}
+	}()
+	c.Updates = updates
+
+	for {
+		if !cache.GetIsClusterMaster() {
+			return nil
+		}
+
+		if err := processMailbox(c, l.account); err != nil {
+			return err
+		}
+
+		stopIdle := make(chan struct{})
+		idleErr := make(chan error, 1)
+		go func() {
+			idleErr <- c.Idle(stopIdle, nil)
+		}()
+
+		select {
+		case err := <-idleErr:
+			return err
+		case <-newMessages:
+			log.Info(log.ContextMail, fmt.Sprintf("IDLE listener for '%s' was notified about new messages", l.account.Name))
+		case <-time.After(listenerRefresh):
+		case <-l.stop:
+			close(stopIdle)
+			return <-idleErr
+		}
+
+		close(stopIdle)
+		if err := <-idleErr; err != nil {
+			return err
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	netMail "net/mail"
 	"os"
 	"r3/cache"
 	"r3/config"
@@ -16,6 +17,9 @@ import (
 	"r3/types"
 	"strings"
 
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/wneessen/go-mail"
 )
 
@@ -35,8 +39,11 @@ func DoAll() error {
 	mails := make([]types.Mail, 0)
 
 	rows, err := db.Pool.Query(context.Background(), `
-		SELECT id, to_list, cc_list, bcc_list, subject, body, attempt_count,
-			mail_account_id, record_id_wofk, attribute_id
+		SELECT id, to_list, cc_list, bcc_list, subject, body, body_text,
+			attempt_count, mail_account_id, record_id_wofk, attribute_id,
+			message_id, in_reply_to, references_list, mail_template_id,
+			template_language, template_values, pg_function_id_callback,
+			callback_value
 		FROM instance.mail_spool
 		WHERE outgoing
 		AND attempt_count < $1
@@ -51,8 +58,10 @@ func DoAll() error {
 		var m types.Mail
 
 		if err := rows.Scan(&m.Id, &m.ToList, &m.CcList, &m.BccList,
-			&m.Subject, &m.Body, &m.AttemptCount, &m.AccountId,
-			&m.RecordId, &m.AttributeId); err != nil {
+			&m.Subject, &m.Body, &m.BodyText, &m.AttemptCount, &m.AccountId,
+			&m.RecordId, &m.AttributeId, &m.MessageId, &m.InReplyTo,
+			&m.References, &m.TemplateId, &m.TemplateLanguage,
+			&m.TemplateValues, &m.CallbackFncId, &m.CallbackValue); err != nil {
 
 			return err
 		}
@@ -127,9 +136,64 @@ func do(m types.Mail) error {
 		}
 	}
 
+	// render subject & body from mail template if used
+	if m.TemplateId.Valid {
+		m.Subject, m.Body, m.BodyText.String, err = renderTemplate(m.TemplateId.Int32,
+			m.TemplateLanguage.String, m.TemplateValues)
+
+		if err != nil {
+			return err
+		}
+		m.BodyText.Valid = m.BodyText.String != ""
+	}
+
+	// generate message ID once, it must stay stable for repeated send attempts
+	if !m.MessageId.Valid {
+		m.MessageId.String, err = getMessageId(ma.SendAs)
+		if err != nil {
+			return err
+		}
+		m.MessageId.Valid = true
+
+		if _, err := db.Pool.Exec(context.Background(), `
+			UPDATE instance.mail_spool
+			SET message_id = $1
+			WHERE id = $2
+		`, m.MessageId, m.Id); err != nil {
+			return err
+		}
+	}
+
 	// build mail
 	msg := mail.NewMsg()
 	msg.Subject(m.Subject)
+	msg.SetDate()
+	msg.SetGenHeader(mail.HeaderMessageID, m.MessageId.String)
+
+	// thread headers, references default to the references of the mail being replied to + its message ID
+	if m.InReplyTo.Valid && m.InReplyTo.String != "" {
+		msg.SetGenHeader(mail.HeaderInReplyTo, m.InReplyTo.String)
+
+		if !m.References.Valid || m.References.String == "" {
+			var referencesParent pgtype.Text
+			if err := db.Pool.QueryRow(context.Background(), `
+				SELECT references_list
+				FROM instance.mail_traffic
+				WHERE message_id = $1
+				LIMIT 1
+			`, m.InReplyTo.String).Scan(&referencesParent); err != nil && err != pgx.ErrNoRows {
+				return err
+			}
+
+			m.References = m.InReplyTo
+			if referencesParent.Valid && referencesParent.String != "" {
+				m.References.String = fmt.Sprintf("%s %s", referencesParent.String, m.InReplyTo.String)
+			}
+		}
+	}
+	if m.References.Valid && m.References.String != "" {
+		msg.SetGenHeader(mail.HeaderReferences, m.References.String)
+	}
 
 	if err := msg.From(ma.SendAs); err != nil {
 		return err
@@ -150,9 +214,14 @@ func do(m types.Mail) error {
 		}
 	}
 
-	// dirty trick to assume body content by looking for beginning of HTML tag
-	// we should find a way to store our preference when sending mails
-	if strings.Contains(m.Body, "<") {
+	// if plain text is given, body is sent as HTML alongside it (if not empty)
+	// otherwise: dirty trick to assume body content by looking for beginning of HTML tag
+	if m.BodyText.Valid && m.BodyText.String != "" {
+		msg.SetBodyString(mail.TypeTextPlain, m.BodyText.String)
+		if m.Body != "" {
+			msg.AddAlternativeString(mail.TypeTextHTML, m.Body)
+		}
+	} else if strings.Contains(m.Body, "<") {
 		msg.SetBodyString(mail.TypeTextHTML, m.Body)
 	} else {
 		msg.SetBodyString(mail.TypeTextPlain, m.Body)
@@ -215,6 +284,13 @@ func do(m types.Mail) error {
 		}
 	}
 
+	// sign mail if DKIM key is set
+	if ma.DkimPrivateKey.Valid {
+		if err := signDkim(msg, ma.DkimDomain.String, ma.DkimSelector.String, ma.DkimPrivateKey.String); err != nil {
+			return fmt.Errorf("failed to apply DKIM signature, %s", err)
+		}
+	}
+
 	// send mail
 	log.Info(log.ContextMail, fmt.Sprintf("sending message (%d attachments)",
 		len(msg.GetAttachments())))
@@ -257,12 +333,29 @@ func do(m types.Mail) error {
 	}
 
 	// add to mail traffic log
+	// thread is taken from known referenced mails, otherwise first reference or own message ID starts a new one
+	references := strings.Fields(m.References.String)
+	threadIdFallback := m.MessageId.String
+	if len(references) != 0 {
+		threadIdFallback = references[0]
+	}
+
 	_, err = db.Pool.Exec(context.Background(), `
 		INSERT INTO instance.mail_traffic (from_list, to_list, cc_list,
-			subject, date, files, mail_account_id, outgoing)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,TRUE)
+			subject, date, files, mail_account_id, outgoing, message_id,
+			references_list, thread_id, pg_function_id_callback, callback_value)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,TRUE,$8,$9,COALESCE((
+			SELECT thread_id
+			FROM instance.mail_traffic
+			WHERE message_id = ANY($10)
+			AND thread_id IS NOT NULL
+			ORDER BY date ASC
+			LIMIT 1
+		),$11),$12,$13)
 	`, m.FromList, m.ToList, m.CcList, m.Subject,
-		tools.GetTimeUnix(), fileList, m.AccountId)
+		tools.GetTimeUnix(), fileList, m.AccountId, m.MessageId,
+		m.References, references, threadIdFallback, m.CallbackFncId,
+		m.CallbackValue)
 
 	return err
 }
@@ -273,3 +366,25 @@ func getAttachedFileWithName(n string) mail.FileOption {
 		f.Name = n
 	}
 }
+
+// returns new message ID in the domain of the sender address
+func getMessageId(sendAs string) (string, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+
+	domain := ""
+	if addr, err := netMail.ParseAddress(sendAs); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i != -1 {
+			domain = addr.Address[i+1:]
+		}
+	}
+	if domain == "" {
+		domain, err = os.Hostname()
+		if err != nil {
+			domain = "localhost.localdomain"
+		}
+	}
+	return fmt.Sprintf("<%s@%s>", id.String(), domain), nil
+}
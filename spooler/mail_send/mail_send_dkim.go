@@ -0,0 +1,140 @@
+package mail_send
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"r3/tools"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wneessen/go-mail"
+)
+
+var (
+	// headers to sign, if present in message
+	dkimHeaders = []string{"From", "Reply-To", "Subject", "Date", "To", "Cc",
+		"Message-ID", "In-Reply-To", "References", "MIME-Version", "Content-Type"}
+
+	regexWsp = regexp.MustCompile(`[ \t]+`)
+)
+
+// DKIM signature (RFC 6376) with relaxed/relaxed canonicalization
+// supports RSA (rsa-sha256) and Ed25519 (ed25519-sha256, RFC 8463) keys
+// message is rendered once to sign it, go-mail keeps generated boundaries, dates and IDs for the actual send
+func signDkim(msg *mail.Msg, domain string, selector string, keyPem string) error {
+
+	key, err := tools.ParsePrivKeyPem(keyPem)
+	if err != nil {
+		return err
+	}
+
+	var algorithm string
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		algorithm = "rsa-sha256"
+	case ed25519.PrivateKey:
+		algorithm = "ed25519-sha256"
+	default:
+		return errors.New("unsupported key type, RSA or Ed25519 key required")
+	}
+
+	var buf bytes.Buffer
+	if _, err := msg.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	raw := buf.String()
+	headerBlock, body, found := strings.Cut(raw, "\r\n\r\n")
+	if !found {
+		return errors.New("message has no body")
+	}
+
+	// body hash
+	bodyHash := sha256.Sum256([]byte(canonicalizeBodyRelaxed(body)))
+
+	// collect headers to sign, last occurrence is used
+	headerMap := make(map[string]string)
+	for _, h := range unfoldHeaders(headerBlock) {
+		name, _, found := strings.Cut(h, ":")
+		if found {
+			headerMap[strings.ToLower(strings.TrimSpace(name))] = h
+		}
+	}
+
+	signedNames := make([]string, 0)
+	signedHeaders := make([]string, 0)
+	for _, name := range dkimHeaders {
+		if h, exists := headerMap[strings.ToLower(name)]; exists {
+			signedNames = append(signedNames, name)
+			signedHeaders = append(signedHeaders, canonicalizeHeaderRelaxed(h))
+		}
+	}
+
+	value := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s; t=%d; h=%s; bh=%s; b=",
+		algorithm, domain, selector, time.Now().Unix(), strings.Join(signedNames, ":"),
+		base64.StdEncoding.EncodeToString(bodyHash[:]))
+
+	// signature is calculated over signed headers + DKIM header itself (with empty signature, no trailing CRLF)
+	data := strings.Join(signedHeaders, "") +
+		strings.TrimSuffix(canonicalizeHeaderRelaxed("DKIM-Signature: "+value), "\r\n")
+
+	dataHash := sha256.Sum256([]byte(data))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, dataHash[:])
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, dataHash[:])
+	}
+	if err != nil {
+		return err
+	}
+
+	msg.SetGenHeaderPreformatted("DKIM-Signature", value+base64.StdEncoding.EncodeToString(signature))
+	return nil
+}
+
+// returns header fields with continuation lines merged
+func unfoldHeaders(headerBlock string) []string {
+	headers := make([]string, 0)
+	for _, line := range strings.Split(headerBlock, "\r\n") {
+		if len(headers) != 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			headers[len(headers)-1] += "\r\n" + line
+			continue
+		}
+		headers = append(headers, line)
+	}
+	return headers
+}
+
+func canonicalizeHeaderRelaxed(header string) string {
+	name, value, _ := strings.Cut(header, ":")
+	value = strings.ReplaceAll(value, "\r\n", "")
+	value = regexWsp.ReplaceAllString(value, " ")
+	return fmt.Sprintf("%s:%s\r\n", strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value))
+}
+
+func canonicalizeBodyRelaxed(body string) string {
+	lines := strings.Split(body, "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(regexWsp.ReplaceAllString(line, " "), " ")
+	}
+
+	// remove empty lines at end of body
+	for len(lines) != 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
@@ -0,0 +1,115 @@
+package mail_send
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"r3/db"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+var (
+	templateLanguageFallback = "en_us"
+	regexPlaceholder         = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_\.\-]+)\s*\}\}`)
+)
+
+// renders subject, HTML and plain text body from mail template
+// placeholders are written as {{name}}, their values can either be plain or captions (language code -> value)
+// the requested language is used if available, otherwise the fallback language or any other one
+func renderTemplate(templateId int32, languageCode string,
+	values map[string]interface{}) (string, string, string, error) {
+
+	type content struct {
+		subject  string
+		bodyHtml string
+		bodyText string
+	}
+	codeMap := make(map[string]content)
+	codes := make([]string, 0)
+
+	rows, err := db.Pool.Query(context.Background(), `
+		SELECT language_code, subject, body_html, body_text
+		FROM instance.mail_template_caption
+		WHERE mail_template_id = $1
+	`, templateId)
+	if err != nil {
+		return "", "", "", err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code string
+		var c content
+		if err := rows.Scan(&code, &c.subject, &c.bodyHtml, &c.bodyText); err != nil {
+			return "", "", "", err
+		}
+		codeMap[code] = c
+		codes = append(codes, code)
+	}
+
+	if len(codes) == 0 {
+		return "", "", "", fmt.Errorf("mail template %d has no content", templateId)
+	}
+
+	code := getTemplateLanguage(languageCode, codes)
+	c := codeMap[code]
+
+	subject := renderPlaceholders(c.subject, code, values, false)
+	bodyHtml := renderPlaceholders(c.bodyHtml, code, values, true)
+	bodyText := renderPlaceholders(c.bodyText, code, values, false)
+
+	// HTML body can be empty, template is then sent as plain text only
+	return subject, bodyHtml, bodyText, nil
+}
+
+func renderPlaceholders(input string, languageCode string,
+	values map[string]interface{}, escapeHtml bool) string {
+
+	return regexPlaceholder.ReplaceAllStringFunc(input, func(match string) string {
+		name := regexPlaceholder.FindStringSubmatch(match)[1]
+
+		value, exists := values[name]
+		if !exists || value == nil {
+			return ""
+		}
+
+		var out string
+		switch v := value.(type) {
+		case string:
+			out = v
+		case map[string]interface{}:
+			// caption, value per language code
+			codes := make([]string, 0)
+			for code := range v {
+				codes = append(codes, code)
+			}
+			if caption, exists := v[getTemplateLanguage(languageCode, codes)]; exists && caption != nil {
+				out = fmt.Sprintf("%v", caption)
+			}
+		default:
+			out = fmt.Sprintf("%v", v)
+		}
+
+		if escapeHtml {
+			return html.EscapeString(out)
+		}
+		return out
+	})
+}
+
+// returns requested language code if available, otherwise fallback or first available language code
+func getTemplateLanguage(languageCode string, codes []string) string {
+	if slices.Contains(codes, languageCode) {
+		return languageCode
+	}
+	if slices.Contains(codes, templateLanguageFallback) {
+		return templateLanguageFallback
+	}
+	if len(codes) == 0 {
+		return ""
+	}
+	sort.Strings(codes)
+	return codes[0]
+}
@@ -0,0 +1,183 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cron expression with 5 fields: minute, hour, day of month, month, day of week
+// supports lists (1,15), ranges (1-5), steps (*/15, 8-18/2), names (JAN, MON) and macros (@daily)
+// day of month and day of week follow cron rules: if both are restricted, either one must match
+type Cron struct {
+	minutes     uint64
+	hours       uint64
+	days        uint64
+	months      uint64
+	weekdays    uint64
+	daysAny     bool // day of month is not restricted (*)
+	weekdaysAny bool // day of week is not restricted (*)
+}
+
+type cronField struct {
+	min   int
+	max   int
+	names []string // names for values, starting at min
+}
+
+var (
+	cronFields = []cronField{
+		{0, 59, nil}, // minutes
+		{0, 23, nil}, // hours
+		{1, 31, nil}, // days of month
+		{1, 12, []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+		{0, 7, []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}}, // 0 and 7 are both Sunday
+	}
+	cronMacros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+	cronSearchYears = 5 // expressions without matches in this time frame are considered to never run
+)
+
+func ParseCron(expr string) (Cron, error) {
+	var c Cron
+
+	expr = strings.TrimSpace(expr)
+	if macro, exists := cronMacros[strings.ToLower(expr)]; exists {
+		expr = macro
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return c, fmt.Errorf("cron expression '%s' must have %d fields", expr, len(cronFields))
+	}
+
+	bits := make([]uint64, len(cronFields))
+	for i, part := range parts {
+		var err error
+		bits[i], err = parseCronField(part, cronFields[i])
+		if err != nil {
+			return c, fmt.Errorf("invalid cron expression '%s': %w", expr, err)
+		}
+	}
+
+	// Sunday can be given as 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	c.minutes, c.hours, c.days, c.months, c.weekdays = bits[0], bits[1], bits[2], bits[3], bits[4]
+	c.daysAny = strings.HasPrefix(parts[2], "*")
+	c.weekdaysAny = strings.HasPrefix(parts[4], "*")
+	return c, nil
+}
+
+// returns first time matching the cron expression after the given time
+// times are evaluated in the location of the given time, so daylight saving changes do not shift runs
+// returns zero time if expression does not match within the search time frame (as in 31st of February)
+func (c Cron) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	yearLimit := after.Year() + cronSearchYears
+
+	for t.Year() <= yearLimit {
+		if c.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		// hours and minutes are added as durations, local times can be ambiguous on daylight saving changes
+		if c.hours&(1<<uint(t.Hour())) == 0 {
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			continue
+		}
+		if c.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c Cron) matchesDay(t time.Time) bool {
+	day := c.days&(1<<uint(t.Day())) != 0
+	weekday := c.weekdays&(1<<uint(t.Weekday())) != 0
+
+	if c.daysAny || c.weekdaysAny {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+func parseCronField(field string, def cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if before, after, found := strings.Cut(part, "/"); found {
+			var err error
+			step, err = strconv.Atoi(after)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step '%s'", after)
+			}
+			part = before
+		}
+
+		var lo, hi int
+		var err error
+		switch {
+		case part == "*":
+			lo, hi = def.min, def.max
+		case strings.Contains(part, "-"):
+			before, after, _ := strings.Cut(part, "-")
+			if lo, err = parseCronValue(before, def); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(after, def); err != nil {
+				return 0, err
+			}
+		default:
+			if lo, err = parseCronValue(part, def); err != nil {
+				return 0, err
+			}
+			hi = lo
+
+			// single value with step runs until end of range (as in 5/15)
+			if step != 1 {
+				hi = def.max
+			}
+		}
+
+		if lo > hi {
+			return 0, fmt.Errorf("invalid range '%s'", part)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(value string, def cronField) (int, error) {
+	for i, name := range def.names {
+		if strings.EqualFold(value, name) {
+			return def.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil || v < def.min || v > def.max {
+		return 0, fmt.Errorf("value '%s' is not within %d-%d", value, def.min, def.max)
+	}
+	return v, nil
+}
@@ -0,0 +1,167 @@
+package tools
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{"* * * * *", false},
+		{" 0 0 1 1 * ", false},
+		{"@daily", false},
+		{"@WEEKLY", false},
+		{"*/15 8-18/2 1,15 JAN-MAR mon-fri", false},
+		{"5/15 * * * *", false},
+		{"0 0 * * 7", false},
+		{"", true},
+		{"* * * *", true},
+		{"* * * * * *", true},
+		{"@sometimes", true},
+		{"60 * * * *", true},
+		{"* 24 * * *", true},
+		{"* * 0 * *", true},
+		{"* * * 13 *", true},
+		{"* * * * 8", true},
+		{"*/0 * * * *", true},
+		{"*/x * * * *", true},
+		{"5-1 * * * *", true},
+		{"* * * XYZ *", true},
+		{"1,,2 * * * *", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseCron(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	zone := time.FixedZone("UTC+2", 2*3600)
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{
+			name:  "every 15 minutes",
+			expr:  "*/15 * * * *",
+			after: time.Date(2024, 1, 1, 10, 7, 30, 0, time.UTC),
+			want:  time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name:  "exact time is skipped",
+			expr:  "*/15 * * * *",
+			after: time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC),
+			want:  time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "daily macro",
+			expr:  "@daily",
+			after: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			want:  time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "weekday by name",
+			expr:  "0 9 * * MON",
+			after: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC), // Wednesday
+			want:  time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "Sunday as 7",
+			expr:  "0 0 * * 7",
+			after: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), // Monday
+			want:  time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "hour range with step",
+			expr:  "30 8-18/4 * * *",
+			after: time.Date(2024, 1, 1, 12, 31, 0, 0, time.UTC),
+			want:  time.Date(2024, 1, 1, 16, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "day of month or day of week, if both are restricted",
+			expr:  "0 0 13 * FRI",
+			after: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:  time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "day of month and day of week, if one is unrestricted",
+			expr:  "0 0 13 * *",
+			after: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:  time.Date(2024, 1, 13, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "leap day",
+			expr:  "0 0 29 2 *",
+			after: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:  time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "end of year",
+			expr:  "@yearly",
+			after: time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC),
+			want:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "location of given time",
+			expr:  "0 6 * * *",
+			after: time.Date(2024, 1, 1, 7, 0, 0, 0, zone),
+			want:  time.Date(2024, 1, 2, 6, 0, 0, 0, zone),
+		},
+		{
+			name:  "no match",
+			expr:  "0 0 31 2 *",
+			after: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:  time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronNextDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+
+	// 02:30 does not exist on 2024-03-31, clocks change from 02:00 to 03:00
+	c, err := ParseCron("30 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2024, 4, 1, 2, 30, 0, 0, loc)
+	if got := c.Next(time.Date(2024, 3, 31, 0, 0, 0, 0, loc)); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// 02:00 exists twice on 2024-10-27, clocks change from 03:00 to 02:00
+	c, err = ParseCron("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := time.Date(2024, 10, 27, 2, 0, 0, 0, loc)
+	if got := c.Next(after); got.Sub(after) != time.Hour {
+		t.Errorf("got %v, want 1 hour after %v", got, after)
+	}
+}
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"bufio"
+	"crypto"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/ecdsa"
@@ -193,6 +194,31 @@ func ReadPubKey(filePath string) (*rsa.PublicKey, error) {
 	}
 	return key, nil
 }
+
+// parses PEM encoded private key (PKCS1, PKCS8 or EC), returns key usable for signing
+func ParsePrivKeyPem(pemText string) (crypto.Signer, error) {
+
+	data, _ := pem.Decode([]byte(pemText))
+	if data == nil {
+		return nil, errors.New("failed to decode PEM block")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(data.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(data.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(data.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return signer, nil
+}
 func readPemBlockFromFile(filePath string) (*pem.Block, error) {
 
 	file, err := os.Open(filePath)
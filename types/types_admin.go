@@ -1,6 +1,8 @@
 package types
 
 import (
+	"encoding/json"
+
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -23,6 +25,55 @@ type Log struct {
 	Date       int64       `json:"date"`
 }
 
+type AuditLog struct {
+	Id        int64           `json:"id"`
+	LoginId   int64           `json:"loginId"`
+	LoginName string          `json:"loginName"` // name at time of change, kept if login is deleted
+	Date      int64           `json:"date"`
+	Ressource string          `json:"ressource"`
+	Action    string          `json:"action"`
+	EntityId  pgtype.Text     `json:"entityId"` // ID of affected entity, if available from request or response
+	Payload   json.RawMessage `json:"payload"`  // request payload, secrets redacted
+	Diff      json.RawMessage `json:"diff"`     // changed values {"key":{"before":x,"after":y}}, only for some entities
+	HashPrev  pgtype.Text     `json:"hashPrev"`
+	Hash      string          `json:"hash"`
+}
+type AuditLogVerification struct {
+	Count      int64       `json:"count"`      // number of verified entries
+	HashLatest pgtype.Text `json:"hashLatest"` // hash of latest entry, can be stored externally to detect removal of latest entries
+	IdBroken   pgtype.Int8 `json:"idBroken"`   // first entry with broken hash chain, NULL if chain is intact
+}
+
+type SchedulerRun struct {
+	ScheduleId   int64       `json:"scheduleId"`
+	NodeName     pgtype.Text `json:"nodeName"`     // cluster node that executed the run, NULL if node was removed
+	DateStart    int64       `json:"dateStart"`    // unix time in milliseconds
+	DateEnd      int64       `json:"dateEnd"`      // unix time in milliseconds
+	DurationMs   int64       `json:"durationMs"`   // run duration in milliseconds
+	Success      bool        `json:"success"`      // run completed without error
+	ErrorText    pgtype.Text `json:"errorText"`    // error message of failed run
+	RowsAffected pgtype.Int8 `json:"rowsAffected"` // result of PG functions returning integers, NULL otherwise
+}
+
+type Job struct {
+	Id           uuid.UUID   `json:"id"`
+	PgFunctionId uuid.UUID   `json:"pgFunctionId"`
+	Queue        string      `json:"queue"`
+	Args         interface{} `json:"args"`
+	UniqueKey    pgtype.Text `json:"uniqueKey"`
+	Priority     int         `json:"priority"`
+	State        string      `json:"state"` // pending, failed
+	AttemptCount int         `json:"attemptCount"`
+	DateAdded    int64       `json:"dateAdded"`
+	DateRunAt    int64       `json:"dateRunAt"`   // earliest execution time, next retry for failed attempts
+	DateAttempt  pgtype.Int8 `json:"dateAttempt"` // time of last failed attempt
+	ErrorText    pgtype.Text `json:"errorText"`   // error message of last failed attempt
+}
+type JobQueue struct {
+	Name        string `json:"name"`
+	Concurrency int    `json:"concurrency"` // max. number of jobs executed at the same time across the cluster
+}
+
 type LoginAdmin struct {
 	Id               int64              `json:"id"`
 	LdapId           pgtype.Int4        `json:"ldapId"`
@@ -58,6 +58,7 @@ type ClusterEventTarget struct {
 	Address string                `json:"address"` // address used to connect via websocket, "" = undefined
 	Device  WebsocketClientDevice `json:"device"`  // device to affect ("browser", "fatClient"), 0 = undefined
 	LoginId int64                 `json:"loginId"` // login ID to affect, 0 = undefined
+	TokenId uuid.UUID             `json:"tokenId"` // login token (jti) to affect, nil UUID = undefined
 
 	// preferred filters, prioritize target if it matches filter, otherwise send it to others
 	PwaModuleIdPreferred uuid.UUID `json:"pwaModuleIdPreferred"` // client connecting via PWA sub host (direct app access), nil UUID = undefined
@@ -31,11 +31,14 @@ type FileType struct {
 	Portable bool `json:"portable"`
 
 	Web struct {
-		Cert          string `json:"cert"`
-		Key           string `json:"key"`
-		Listen        string `json:"listen"`
-		Port          int    `json:"port"`
-		TlsMinVersion string `json:"tlsMinVersion"`
+		AcmeHttpPort  int      `json:"acmeHttpPort"` // port for plain HTTP server to answer ACME HTTP-01 challenges (0 = disabled)
+		Cert          string   `json:"cert"`
+		ClientCa      string   `json:"clientCa"`   // CA file to verify TLS client certificates with, enables client certificate authentication
+		ClientCrls    []string `json:"clientCrls"` // certificate revocation list files for TLS client certificates
+		Key           string   `json:"key"`
+		Listen        string   `json:"listen"`
+		Port          int      `json:"port"`
+		TlsMinVersion string   `json:"tlsMinVersion"`
 	} `json:"web"`
 }
 
@@ -32,6 +32,7 @@ type DataGetFilterSide struct {
 	FtsDict         pgtype.Text `json:"ftsDict"`         // chosen dictionary (postgres regconfig), is applied on fulltext comparisons (@@) for TSQUERY
 	Query           DataGet     `json:"query"`           // sub query, optional
 	QueryAggregator pgtype.Text `json:"queryAggregator"` // sub query aggregator, optional
+	Aggregator      pgtype.Text `json:"aggregator"`      // attribute aggregator, optional, filter is applied to grouped results (HAVING)
 	Value           interface{} `json:"value"`           // fixed value, optional, filled by frontend with value of field/login ID/record/...
 }
 
@@ -60,10 +61,22 @@ type DataGetExpression struct {
 	Query DataGet `json:"query"` // a regular data GET request
 
 	// expression options
-	Aggregator pgtype.Text `json:"aggregator"` // set AGGREGATE function (min, max, avg, count, ...)
-	Distincted bool        `json:"distincted"` // set DISTINCT
-	GroupBy    bool        `json:"groupBy"`    // set GROUP BY
-	ReturnNull bool        `json:"returnNull"` // return NULL (ignores everything else)
+	Aggregator pgtype.Text   `json:"aggregator"` // set AGGREGATE function (min, max, avg, count, ...)
+	Distincted bool          `json:"distincted"` // set DISTINCT
+	GroupBy    bool          `json:"groupBy"`    // set GROUP BY
+	Percentile pgtype.Float8 `json:"percentile"` // fraction (0-1) for percentile aggregator
+	ReturnNull bool          `json:"returnNull"` // return NULL (ignores everything else)
+
+	// window function options, applied to attribute expressions
+	// window is ordered by data GET orders
+	WindowFunction   pgtype.Text              `json:"windowFunction"`   // window function (sum, rank, lag, ...)
+	WindowOffset     int                      `json:"windowOffset"`     // row offset for lag/lead (default 1)
+	WindowPartitions []DataGetWindowPartition `json:"windowPartitions"` // attributes to partition window by
+}
+
+type DataGetWindowPartition struct {
+	AttributeId uuid.UUID `json:"attributeId"`
+	Index       int       `json:"index"` // relation index attribute belongs to
 }
 
 type DataGetOrder struct {
@@ -91,11 +104,12 @@ type DataGet struct {
 	SearchDicts []string            `json:"searchDicts"` // list of fulltext search dictionaries (english, german, ...)
 }
 type DataGetResult struct {
-	IndexRecordIds     map[int]interface{} `json:"indexRecordIds"`     // IDs of relation records, key: relation index
-	IndexRecordEncKeys map[int]string      `json:"indexRecordEncKeys"` // record data keys, encrypted with login´s public key, key: relation index
-	IndexesPermNoDel   []int               `json:"indexesPermNoDel"`   // if getPerm, relation indexes of which records may not be deleted
-	IndexesPermNoSet   []int               `json:"indexesPermNoSet"`   // if getPerm, relation indexes of which records may not be updated
-	Values             []interface{}       `json:"values"`             // expression values, same order as requested expressions
+	IndexRecordIds      map[int]interface{} `json:"indexRecordIds"`      // IDs of relation records, key: relation index
+	IndexRecordEncKeys  map[int]string      `json:"indexRecordEncKeys"`  // record data keys, encrypted with login´s public key, key: relation index
+	IndexRecordVersions map[int]int64       `json:"indexRecordVersions"` // record versions, only for relations with record versions, key: relation index
+	IndexesPermNoDel    []int               `json:"indexesPermNoDel"`    // if getPerm, relation indexes of which records may not be deleted
+	IndexesPermNoSet    []int               `json:"indexesPermNoSet"`    // if getPerm, relation indexes of which records may not be updated
+	Values              []interface{}       `json:"values"`              // expression values, same order as requested expressions
 }
 type DataGetValueFile struct {
 	Id      uuid.UUID `json:"id"`
@@ -126,12 +140,13 @@ type DataSetFileChanges struct {
 	FileIdMapChange map[uuid.UUID]DataSetFileChange `json:"fileIdMapChange"`
 }
 type DataSet struct {
-	RelationId  uuid.UUID          `json:"relationId"`  // relation ID to update
-	AttributeId uuid.UUID          `json:"attributeId"` // attribute ID of relationship to join with
-	IndexFrom   int                `json:"indexFrom"`   // from relation index
-	RecordId    int64              `json:"recordId"`    // record ID to update (0 if new)
-	Attributes  []DataSetAttribute `json:"attributes"`  // attribute values to set
-	EncKeysSet  []DataSetEncKeys   `json:"encKeysSet"`  // data encryption keys to store, encrypted with login´s public key
+	RelationId    uuid.UUID          `json:"relationId"`    // relation ID to update
+	AttributeId   uuid.UUID          `json:"attributeId"`   // attribute ID of relationship to join with
+	IndexFrom     int                `json:"indexFrom"`     // from relation index
+	RecordId      int64              `json:"recordId"`      // record ID to update (0 if new)
+	RecordVersion pgtype.Int8        `json:"recordVersion"` // record version to update, optional, update is rejected if record has changed since
+	Attributes    []DataSetAttribute `json:"attributes"`    // attribute values to set
+	EncKeysSet    []DataSetEncKeys   `json:"encKeysSet"`    // data encryption keys to store, encrypted with login´s public key
 }
 type DataSetResult struct {
 	IndexRecordIds map[int]int64 `json:"indexRecordIds"` // IDs of relation records, key: relation index
@@ -146,3 +161,14 @@ type DataLog struct {
 	LoginName  string             `json:"loginName"`
 	Attributes []DataSetAttribute `json:"attributes"`
 }
+
+// data TRASH request
+type DataTrash struct {
+	Id          uuid.UUID                 `json:"id"`
+	RelationId  uuid.UUID                 `json:"relationId"`
+	RecordId    int64                     `json:"recordId"`
+	RecordCount int                       `json:"recordCount"` // count of deleted records, incl. ones deleted by cascade
+	DateDelete  int64                     `json:"dateDelete"`
+	LoginName   string                    `json:"loginName"`
+	Values      map[uuid.UUID]interface{} `json:"values"` // values of deleted record, key: attribute ID
+}
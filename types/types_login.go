@@ -1,6 +1,8 @@
 package types
 
 import (
+	"encoding/json"
+
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -16,6 +18,7 @@ type LoginAccess struct {
 	ClientEvent map[uuid.UUID]Access `json:"clientEvent"` // effective access to specific client events
 	Collection  map[uuid.UUID]Access `json:"collection"`  // effective access to specific collection
 	Menu        map[uuid.UUID]Access `json:"menu"`        // effective access to specific menus
+	PgFunction  map[uuid.UUID]Access `json:"pgFunction"`  // effective access to specific backend functions (REST RPC calls)
 	Relation    map[uuid.UUID]Access `json:"relation"`    // effective access to specific relations
 	SearchBar   map[uuid.UUID]Access `json:"searchBar"`   // effective access to specific search bars
 	Widget      map[uuid.UUID]Access `json:"widget"`      // effective access to specific widgets
@@ -28,14 +31,30 @@ type LoginAuthResult struct {
 	Token string `json:"token"` // login token
 
 	// auth types: user
-	MfaTokens []LoginMfaToken `json:"mfaTokens"` // available MFAs, filled if user auth ok, but MFA not satisfied
-	NoAuth    bool            `json:"noAuth"`    // login is without authentication (public auth with only name)
+	KeyPrivateEnc pgtype.Text            `json:"keyPrivateEnc"` // encrypted private key, filled if password expired, to be re-encrypted with new password
+	MfaRecovery   bool                   `json:"mfaRecovery"`   // recovery codes are available as MFA, filled if user auth ok, but MFA not satisfied
+	MfaTokens     []LoginMfaToken        `json:"mfaTokens"`     // available MFAs, filled if user auth ok, but MFA not satisfied
+	NoAuth        bool                   `json:"noAuth"`        // login is without authentication (public auth with only name)
+	Passwordless  bool                   `json:"-"`             // WebAuthn challenge for passwordless authentication was issued, counts as bad attempt
+	PwExpired     bool                   `json:"pwExpired"`     // password is expired, new password must be given
+	WebAuthn      LoginWebAuthnChallenge `json:"webAuthn"`      // WebAuthn assertion challenge, filled if passkey is requested as first or second factor
 
 	// auth types: user, openId
 	SaltKdf string `json:"saltKdf"`
 
+	// auth types: user, token, fixed token, openId, cert
+	TokenId uuid.UUID `json:"-"` // ID of login token (jti), nil UUID for tokens issued before token IDs were introduced
+
 	// auth types: token, fixed token
 	LanguageCode string `json:"languageCode"`
+
+	// auth types: token
+	ImpersonatorId int64 `json:"impersonatorId"` // ID of admin login acting as this login, 0 if not impersonated
+}
+type LoginClientCert struct {
+	// maps TLS client certificates to login, by certificate subject or subject alternative name (SAN)
+	Type  string `json:"type"`  // subject, san
+	Value string `json:"value"` // subject as distinguished name (CN=terminal01,O=Company), SAN as DNS name, email address or URI
 }
 type LoginClientEvent struct {
 	// login client events exist if a login has enabled a hotkey client event
@@ -49,6 +68,15 @@ type LoginFavorite struct {
 	RecordId pgtype.Int8 `json:"recordId"` // ID of record to open, NULL if no record to open
 	Title    pgtype.Text `json:"title"`    // user defined title of favorite, empty if not set
 }
+type LoginImpersonationLog struct {
+	LoginIdImpersonator   int64           `json:"loginIdImpersonator"`
+	LoginNameImpersonator pgtype.Text     `json:"loginNameImpersonator"` // NULL if impersonating login was deleted
+	Date                  int64           `json:"date"`
+	Ressource             string          `json:"ressource"`
+	Action                string          `json:"action"`
+	Payload               json.RawMessage `json:"payload"`
+	Blocked               bool            `json:"blocked"` // request was blocked as it is not allowed while impersonating
+}
 type LoginMeta struct {
 	Department    string `json:"department"`
 	Email         string `json:"email"`
@@ -71,6 +99,11 @@ type LoginOptions struct {
 	FieldId    uuid.UUID   `json:"fieldId"`
 	Options    string      `json:"options"`
 }
+type LoginPasswordNew struct {
+	// new password, set during authentication if current password has expired
+	Pw            string      `json:"pw"`
+	KeyPrivateEnc pgtype.Text `json:"keyPrivateEnc"` // private key, re-encrypted with new password, required if encryption is enabled for login
+}
 type LoginPublicKey struct {
 	LoginId   int64   `json:"loginId"`   // ID of login
 	PublicKey string  `json:"publicKey"` // public key of login (not encrypted)
@@ -84,6 +117,15 @@ type LoginRoleAssign struct {
 	RoleId       uuid.UUID `json:"roleId"`
 	SearchString string    `json:"searchString"` // if value matches this string, role is assigned
 }
+type LoginToken struct {
+	Id           uuid.UUID   `json:"id"`           // token ID (jti)
+	Address      pgtype.Text `json:"address"`      // address of most recent session using this token, NULL if not connected
+	Connected    int64       `json:"connected"`    // number of active sessions using this token
+	DateActivity int64       `json:"dateActivity"` // last activity, either authentication or session activity
+	DateCreate   int64       `json:"dateCreate"`
+	DateExpiry   int64       `json:"dateExpiry"`
+	Device       pgtype.Text `json:"device"` // device of most recent session using this token, NULL if not connected
+}
 type LoginTokenFixed struct {
 	Id         int64  `json:"id"`
 	Name       string `json:"name"`    // to identify token user/device
@@ -91,6 +133,20 @@ type LoginTokenFixed struct {
 	Token      string `json:"token"`
 	DateCreate int64  `json:"dateCreate"`
 }
+type LoginWebAuthn struct {
+	Id         int64       `json:"id"`
+	Name       string      `json:"name"`
+	DateCreate int64       `json:"dateCreate"`
+	DateUsed   pgtype.Int8 `json:"dateUsed"`
+}
+type LoginWebAuthnAssertion struct {
+	SessionId pgtype.UUID     `json:"sessionId"` // ID of WebAuthn session the challenge was issued in
+	Response  json.RawMessage `json:"response"`  // public key credential as returned by authenticator (navigator.credentials.get)
+}
+type LoginWebAuthnChallenge struct {
+	SessionId pgtype.UUID     `json:"sessionId"` // NULL if no challenge was issued
+	Options   json.RawMessage `json:"options"`   // public key credential options, to be passed to authenticator
+}
 type LoginWidgetGroupItem struct {
 	WidgetId pgtype.UUID `json:"widgetId"` // ID of a module widget, empty if system widget is used
 	ModuleId pgtype.UUID `json:"moduleId"` // ID of a module, if relevant for widget (systemModuleMenu)
@@ -6,22 +6,33 @@ import (
 )
 
 type Mail struct {
-	Id           int64       `json:"id"`
-	FromList     string      `json:"fromList"`
-	ToList       string      `json:"toList"`
-	CcList       string      `json:"ccList"`
-	BccList      string      `json:"bccList"`
-	Subject      string      `json:"subject"`
-	Body         string      `json:"body"`
-	Date         int64       `json:"date"`
-	AttemptCount int64       `json:"attemptCount"`
-	AttemptDate  int64       `json:"attemptDate"`
-	Files        int64       `json:"files"`     // number of attachments
-	FilesSize    int64       `json:"filesSize"` // combined size in KB of all attachments
-	Outgoing     bool        `json:"outgoing"`
-	AccountId    pgtype.Int4 `json:"accountId"`   // mail account to send with / got mail from
-	RecordId     pgtype.Int8 `json:"recordId"`    // record to update/get attachment of/from
-	AttributeId  pgtype.UUID `json:"attributeId"` // file attribute to update/get attachment of/from
+	Id               int64                  `json:"id"`
+	FromList         string                 `json:"fromList"`
+	ToList           string                 `json:"toList"`
+	CcList           string                 `json:"ccList"`
+	BccList          string                 `json:"bccList"`
+	Subject          string                 `json:"subject"`
+	Body             string                 `json:"body"`
+	BodyText         pgtype.Text            `json:"bodyText"` // plain text alternative to HTML body
+	Date             int64                  `json:"date"`
+	AttemptCount     int64                  `json:"attemptCount"`
+	AttemptDate      int64                  `json:"attemptDate"`
+	Files            int64                  `json:"files"`     // number of attachments
+	FilesSize        int64                  `json:"filesSize"` // combined size in KB of all attachments
+	Outgoing         bool                   `json:"outgoing"`
+	AccountId        pgtype.Int4            `json:"accountId"`        // mail account to send with / got mail from
+	RecordId         pgtype.Int8            `json:"recordId"`         // record to update/get attachment of/from
+	AttributeId      pgtype.UUID            `json:"attributeId"`      // file attribute to update/get attachment of/from
+	MessageId        pgtype.Text            `json:"messageId"`        // Message-ID header, generated once on first send attempt
+	InReplyTo        pgtype.Text            `json:"inReplyTo"`        // Message-ID of mail that this mail replies to
+	References       pgtype.Text            `json:"references"`       // space separated Message-IDs of mail thread
+	ThreadId         pgtype.Text            `json:"threadId"`         // Message-ID of first known mail in thread
+	AttachRaw        bool                   `json:"attachRaw"`        // store original message (.eml) with attachments
+	TemplateId       pgtype.Int4            `json:"templateId"`       // mail template to render subject/body from
+	TemplateLanguage pgtype.Text            `json:"templateLanguage"` // language code to render mail template with
+	TemplateValues   map[string]interface{} `json:"templateValues"`   // placeholder values for mail template
+	CallbackFncId    pgtype.UUID            `json:"callbackFncId"`    // backend function to call on delivery status reports
+	CallbackValue    pgtype.Text            `json:"callbackValue"`    // value to pass to callback function
 }
 type MailAccount struct {
 	Id            int32       `json:"id"`
@@ -36,6 +47,18 @@ type MailAccount struct {
 	HostPort      int64       `json:"hostPort"`
 	OauthClientId pgtype.Int4 `json:"oauthClientId"` // oauth client, if authmethod XOAUTH2 is used
 	Comment       pgtype.Text `json:"comment"`
+
+	// retrieval of incoming mails (IMAP only)
+	ImapFolder        pgtype.Text `json:"imapFolder"`        // folder to retrieve mails from, INBOX if empty
+	ImapFolderArchive pgtype.Text `json:"imapFolderArchive"` // folder to move processed mails to (action 'move')
+	ImapAction        string      `json:"imapAction"`        // action for processed mails: delete/move/seen
+	ImapIdle          bool        `json:"imapIdle"`          // keep connection open (IMAP IDLE) to retrieve new mails immediately
+	ImapStoreRaw      bool        `json:"imapStoreRaw"`      // store original message (.eml), required to attach it to records
+
+	// DKIM signing of outgoing mails (SMTP only)
+	DkimDomain     pgtype.Text `json:"dkimDomain"`     // signing domain (d=)
+	DkimSelector   pgtype.Text `json:"dkimSelector"`   // DNS selector (s=)
+	DkimPrivateKey pgtype.Text `json:"dkimPrivateKey"` // PEM encoded RSA or Ed25519 private key
 }
 type MailFile struct {
 	Id   uuid.UUID `json:"id"`
@@ -44,14 +67,23 @@ type MailFile struct {
 	Name string    `json:"name"`
 	Size int64     `json:"size"`
 }
+type MailTemplate struct {
+	Id       int32       `json:"id"`
+	Name     string      `json:"name"`
+	Comment  pgtype.Text `json:"comment"`
+	Captions CaptionMap  `json:"captions"` // subject/bodyHtml/bodyText -> language_code -> value
+}
 type MailTraffic struct {
-	FromList  string      `json:"fromList"`
-	ToList    string      `json:"toList"`
-	CcList    string      `json:"ccList"`
-	BccList   string      `json:"bccList"`
-	Subject   string      `json:"subject"`
-	Date      int64       `json:"date"`
-	Files     []string    `json:"files"`
-	Outgoing  bool        `json:"outgoing"`
-	AccountId pgtype.Int4 `json:"accountId"`
+	FromList       string      `json:"fromList"`
+	ToList         string      `json:"toList"`
+	CcList         string      `json:"ccList"`
+	BccList        string      `json:"bccList"`
+	Subject        string      `json:"subject"`
+	Date           int64       `json:"date"`
+	Files          []string    `json:"files"`
+	Outgoing       bool        `json:"outgoing"`
+	AccountId      pgtype.Int4 `json:"accountId"`
+	MessageId      pgtype.Text `json:"messageId"`
+	DeliveryAction pgtype.Text `json:"deliveryAction"` // last reported delivery status (outgoing only): delivered/delayed/expanded/failed/relayed
+	DeliveryDate   pgtype.Int8 `json:"deliveryDate"`   // date of last delivery status report
 }
@@ -83,8 +83,10 @@ type Relation struct {
 	Name           string           `json:"name"`           // unique (within module) relation name
 	Comment        pgtype.Text      `json:"comment"`        // author comment
 	Encryption     bool             `json:"encryption"`     // relation supports encrypted attribute values
+	RecordVersion  bool             `json:"recordVersion"`  // records are versioned, updates of outdated record versions are rejected
 	RetentionCount pgtype.Int4      `json:"retentionCount"` // minimum number of retained change events
 	RetentionDays  pgtype.Int4      `json:"retentionDays"`  // minimum age of retained change events
+	TrashDays      pgtype.Int4      `json:"trashDays"`      // deleted records are kept in recycle bin for X days, hard delete if not set
 	Attributes     []Attribute      `json:"attributes"`     // read only, all relation attributes
 	Indexes        []PgIndex        `json:"indexes"`        // read only, all relation indexes
 	Policies       []RelationPolicy `json:"policies"`       // read only, all relation policies
@@ -117,21 +119,36 @@ type PresetValue struct {
 	Value         pgtype.Text `json:"value"`
 }
 type Attribute struct {
-	Id             uuid.UUID   `json:"id"`
-	RelationId     uuid.UUID   `json:"relationId"`     // attribute belongs to this relation
-	RelationshipId pgtype.UUID `json:"relationshipId"` // ID of target relation
-	IconId         pgtype.UUID `json:"iconId"`         // default icon
-	Name           string      `json:"name"`           // name, used as table column
-	Content        string      `json:"content"`        // content (integer, varchar, text, real, uuid, files, n:1, ...)
-	ContentUse     string      `json:"contentUse"`     // content use (default, richtext, color, datetime, ...)
-	Length         int         `json:"length"`         // numeric precision (digits number + fractions) / varchar length / max file size in KB
-	LengthFract    int         `json:"lengthFract"`    // numeric scale (digits fractions)
-	Nullable       bool        `json:"nullable"`       // value is nullable
-	Encrypted      bool        `json:"encrypted"`      // value is encrypted (end-to-end for logins)
-	Def            string      `json:"def"`            // default value
-	OnUpdate       string      `json:"onUpdate"`       // relationship attribute, action on 'UPDATE'
-	OnDelete       string      `json:"onDelete"`       // relationship attribute, action on 'DELETE'
-	Captions       CaptionMap  `json:"captions"`
+	Id             uuid.UUID            `json:"id"`
+	RelationId     uuid.UUID            `json:"relationId"`     // attribute belongs to this relation
+	RelationshipId pgtype.UUID          `json:"relationshipId"` // ID of target relation
+	IconId         pgtype.UUID          `json:"iconId"`         // default icon
+	Name           string               `json:"name"`           // name, used as table column
+	Content        string               `json:"content"`        // content (integer, varchar, text, real, uuid, files, n:1, ...)
+	ContentUse     string               `json:"contentUse"`     // content use (default, richtext, color, datetime, ...)
+	Length         int                  `json:"length"`         // numeric precision (digits number + fractions) / varchar length / max file size in KB
+	LengthFract    int                  `json:"lengthFract"`    // numeric scale (digits fractions)
+	Nullable       bool                 `json:"nullable"`       // value is nullable
+	Encrypted      bool                 `json:"encrypted"`      // value is encrypted (end-to-end for logins)
+	Def            string               `json:"def"`            // default value
+	Computed       string               `json:"computed"`       // SQL expression of computed attribute, stored as generated column (empty if regular attribute)
+	OnUpdate       string               `json:"onUpdate"`       // relationship attribute, action on 'UPDATE'
+	OnDelete       string               `json:"onDelete"`       // relationship attribute, action on 'DELETE'
+	EnumValues     []AttributeEnumValue `json:"enumValues"`     // enum attribute, allowed values (keep order)
+	Validation     AttributeValidation  `json:"validation"`     // validation rules, enforced when values are set
+	Captions       CaptionMap           `json:"captions"`
+}
+type AttributeValidation struct {
+	Required     bool          `json:"required"`     // value must be set, empty strings are not accepted
+	Min          pgtype.Float8 `json:"min"`          // min. value (numbers) or length (text)
+	Max          pgtype.Float8 `json:"max"`          // max. value (numbers) or length (text)
+	Regex        pgtype.Text   `json:"regex"`        // regular expression that text values must match
+	PgFunctionId pgtype.UUID   `json:"pgFunctionId"` // backend function, called with value (JSONB), returns whether value is valid (BOOLEAN)
+}
+type AttributeEnumValue struct {
+	Id       uuid.UUID  `json:"id"`
+	Value    string     `json:"value"` // stored value
+	Captions CaptionMap `json:"captions"`
 }
 type Menu struct {
 	Id           uuid.UUID            `json:"id"`
@@ -488,15 +505,22 @@ type CollectionConsumer struct {
 	NoDisplayEmpty bool `json:"noDisplayEmpty"` // moved to flags
 }
 type Column struct {
-	Id          uuid.UUID   `json:"id"`
-	AttributeId uuid.UUID   `json:"attributeId"`
-	Index       int         `json:"index"`      // attribute index
-	GroupBy     bool        `json:"groupBy"`    // group by column attribute value?
-	Aggregator  pgtype.Text `json:"aggregator"` // aggregator (SUM, COUNT, etc.)
-	Distincted  bool        `json:"distincted"` // attribute values are distinct?
-	SubQuery    bool        `json:"subQuery"`   // column uses sub query?
-	Query       Query       `json:"query"`      // sub query
-	Captions    CaptionMap  `json:"captions"`   // column titles
+	Id          uuid.UUID     `json:"id"`
+	AttributeId uuid.UUID     `json:"attributeId"`
+	Index       int           `json:"index"`      // attribute index
+	GroupBy     bool          `json:"groupBy"`    // group by column attribute value?
+	Aggregator  pgtype.Text   `json:"aggregator"` // aggregator (SUM, COUNT, etc.)
+	Distincted  bool          `json:"distincted"` // attribute values are distinct?
+	Percentile  pgtype.Float8 `json:"percentile"` // fraction (0-1) for percentile aggregator
+	SubQuery    bool          `json:"subQuery"`   // column uses sub query?
+	Query       Query         `json:"query"`      // sub query
+	Captions    CaptionMap    `json:"captions"`   // column titles
+
+	// window function
+	WindowFunction             pgtype.Text `json:"windowFunction"`             // window function (sum, rank, lag, ...)
+	WindowOffset               int         `json:"windowOffset"`               // row offset for lag/lead
+	WindowPartitionAttributeId pgtype.UUID `json:"windowPartitionAttributeId"` // attribute to partition window by
+	WindowPartitionIndex       pgtype.Int4 `json:"windowPartitionIndex"`       // relation index of partition attribute
 
 	// presentation
 	Basis    int         `json:"basis"`    // size basis (usually width)
@@ -524,6 +548,7 @@ type Role struct {
 	AccessClientEvents map[uuid.UUID]Access `json:"accessClientEvents"`
 	AccessCollections  map[uuid.UUID]Access `json:"accessCollections"`
 	AccessMenus        map[uuid.UUID]Access `json:"accessMenus"`
+	AccessPgFunctions  map[uuid.UUID]Access `json:"accessPgFunctions"`
 	AccessRelations    map[uuid.UUID]Access `json:"accessRelations"`
 	AccessSearchBars   map[uuid.UUID]Access `json:"accessSearchBars"`
 	AccessWidgets      map[uuid.UUID]Access `json:"accessWidgets"`
@@ -550,8 +575,11 @@ type PgFunctionSchedule struct {
 	AtMinute      int       `json:"atMinute"`
 	AtHour        int       `json:"atHour"`
 	AtDay         int       `json:"atDay"`
-	IntervalType  string    `json:"intervalType"`
+	IntervalType  string    `json:"intervalType"` // seconds, minutes, hours, days, weeks, months, years, once, cron
 	IntervalValue int       `json:"intervalValue"`
+	Cron          string    `json:"cron"`     // cron expression (minute hour day month weekday), for interval type 'cron'
+	Timezone      string    `json:"timezone"` // IANA time zone that schedule times refer to, server time zone if empty
+	CatchUp       string    `json:"catchUp"`  // policy for runs missed during downtime: skip, once, all
 }
 type PgTrigger struct {
 	Id            uuid.UUID `json:"id"`
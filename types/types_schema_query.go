@@ -12,7 +12,10 @@ var (
 	QueryFilterConnectors = []string{"AND", "OR"}
 	QueryFilterOperators  = []string{"=", "<>", "<", ">", "<=", ">=", "IS NULL",
 		"IS NOT NULL", "LIKE", "ILIKE", "NOT LIKE", "NOT ILIKE", "= ANY",
-		"<> ALL", "@>", "<@", "&&", "@@", "~", "~*", "!~", "!~*"}
+		"<> ALL", "@>", "<@", "&&", "@@", "~", "~*", "!~", "!~*",
+		"WITHIN DISTANCE", "WITHIN BOX"}
+	QueryWindowFunctions = []string{"avg", "count", "dense_rank", "lag",
+		"lead", "max", "min", "rank", "row_number", "sum"}
 )
 
 // a query starts at a relation to retrieve attribute values
@@ -68,6 +71,7 @@ type QueryFilterSide struct {
 	AttributeNested int         `json:"attributeNested"` // nesting level of attribute  (0=main query, 1=1st sub query)
 	Query           Query       `json:"query"`           // sub query
 	QueryAggregator pgtype.Text `json:"queryAggregator"` // sub query aggregator (COUNT, AGG, etc.)
+	Aggregator      pgtype.Text `json:"aggregator"`      // attribute aggregator (SUM, COUNT, etc.), filter applies to grouped results (HAVING)
 
 	// for frontend processing
 	Content      string      `json:"content"`      // attribute, collection, field, language code, login, nowDate, nowDatetime, nowTime, preset, record, record new, role, subQuery, true, value
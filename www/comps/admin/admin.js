@@ -72,6 +72,12 @@ let MyAdmin = {
 				<span>{{ capApp.navigationMailSpooler }}</span>
 			</router-link>
 			
+			<!-- mail templates -->
+			<router-link class="entry clickable" tag="div" to="/admin/mail-templates">
+				<img src="images/mail.png" />
+				<span>{{ capApp.navigationMailTemplates }}</span>
+			</router-link>
+			
 			<!-- mail traffic -->
 			<router-link class="entry clickable" tag="div" to="/admin/mail-traffic">
 				<img src="images/mail_clock.png" />
@@ -96,6 +102,18 @@ let MyAdmin = {
 				<span>{{ capApp.navigationLogs }}</span>
 			</router-link>
 			
+			<!-- audit log -->
+			<router-link class="entry clickable" tag="div" to="/admin/audit-log">
+				<img src="images/fileKey.png" />
+				<span>{{ capApp.navigationAuditLog }}</span>
+			</router-link>
+			
+			<!-- jobs -->
+			<router-link class="entry clickable" tag="div" to="/admin/jobs">
+				<img src="images/tasks.png" />
+				<span>{{ capApp.navigationJobs }}</span>
+			</router-link>
+			
 			<!-- scheduler -->
 			<router-link class="entry clickable" tag="div" to="/admin/scheduler">
 				<img src="images/clock.png" />
@@ -190,6 +208,7 @@ let MyAdmin = {
 	},
 	computed:{
 		contentTitle:(s) => {
+			if(s.$route.path.includes('audit-log'))       return s.capApp.navigationAuditLog;
 			if(s.$route.path.includes('backups'))         return s.capApp.navigationBackups;
 			if(s.$route.path.includes('caption-map'))     return s.capApp.navigationCaptionMap;
 			if(s.$route.path.includes('cluster'))         return s.capApp.navigationCluster;
@@ -197,6 +216,7 @@ let MyAdmin = {
 			if(s.$route.path.includes('custom'))          return s.capApp.navigationCustom;
 			if(s.$route.path.includes('docs'))            return s.capApp.navigationDocs;
 			if(s.$route.path.includes('files'))           return s.capApp.navigationFiles;
+			if(s.$route.path.includes('jobs'))            return s.capApp.navigationJobs;
 			if(s.$route.path.includes('license'))         return s.capApp.navigationActivation;
 			if(s.$route.path.includes('logins'))          return s.capApp.navigationLogins;
 			if(s.$route.path.includes('login-sessions'))  return s.capApp.navigationLoginSessions;
@@ -205,6 +225,7 @@ let MyAdmin = {
 			if(s.$route.path.includes('ldaps'))           return s.capApp.navigationLdaps;
 			if(s.$route.path.includes('mail-accounts'))   return s.capApp.navigationMailAccounts;
 			if(s.$route.path.includes('mail-spooler'))    return s.capApp.navigationMailSpooler;
+			if(s.$route.path.includes('mail-templates'))  return s.capApp.navigationMailTemplates;
 			if(s.$route.path.includes('mail-traffic'))    return s.capApp.navigationMailTraffic;
 			if(s.$route.path.includes('modules'))         return s.capApp.navigationModules;
 			if(s.$route.path.includes('oauth-clients'))   return s.capApp.navigationOauthClients;
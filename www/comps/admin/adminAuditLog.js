@@ -0,0 +1,242 @@
+import MyInputDateWrap from '../inputDateWrap.js';
+import MyInputOffset   from '../inputOffset.js';
+import {getUnixFormat} from '../shared/time.js';
+export {MyAdminAuditLog as default};
+
+let MyAdminAuditLog = {
+	name:'my-admin-audit-log',
+	components:{MyInputDateWrap,MyInputOffset},
+	template:`<div class="contentBox admin-audit-log grow">
+
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/fileKey.png" />
+				<h1>{{ menuTitle }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area nowrap default-inputs">
+				<my-button image="refresh.png"
+					@trigger="get"
+					:caption="capGen.button.refresh"
+				/>
+				<my-button image="ok.png"
+					@trigger="verify"
+					:caption="capApp.button.verify"
+				/>
+				<my-button image="download.png"
+					@trigger="exportLogs"
+					:active="total !== 0"
+					:caption="capGen.button.export"
+				/>
+				<my-input-date-wrap class="long"
+					@set-unix-from="setDate($event,true)"
+					@set-unix-to="setDate($event,false)"
+					:isDate="true"
+					:isTime="true"
+					:isRange="true"
+					:isValid="true"
+					:unixFrom="unixFrom"
+					:unixTo="unixTo"
+				/>
+			</div>
+			<div class="area">
+				<my-input-offset
+					@input="offset = $event;get()"
+					:caption="true"
+					:limit="limit"
+					:offset="offset"
+					:total="total"
+				/>
+			</div>
+			<div class="area gap default-inputs">
+				<input class="short"
+					v-model="byString"
+					@keyup.enter="offset = 0;get()"
+					:placeholder="capGen.textSearch"
+				/>
+				<select v-model="ressource" @change="offset = 0;get()">
+					<option value="">[{{ capGen.everything }}]</option>
+					<option v-for="r in ressources" :value="r">{{ r }}</option>
+				</select>
+				<select class="short" v-model.number="limit" @change="offset = 0;get()">
+					<option value="100">100</option>
+					<option value="250">250</option>
+					<option value="500">500</option>
+					<option value="1000">1000</option>
+				</select>
+			</div>
+		</div>
+
+		<div class="content admin-audit-log-content no-padding">
+			<div class="admin-audit-log-table">
+				<table class="generic-table bright sticky-top">
+					<thead>
+						<tr class="title">
+							<th class="minimum">{{ capGen.button.show }}</th>
+							<th class="minimum">{{ capApp.date }}</th>
+							<th class="minimum">{{ capApp.login }}</th>
+							<th class="minimum">{{ capApp.request }}</th>
+							<th class="minimum">{{ capApp.entityId }}</th>
+							<th>{{ capApp.changes }}</th>
+							<th class="minimum">{{ capApp.hash }}</th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-if="logs.length === 0">
+							<td colspan="999">{{ capGen.nothingThere }}</td>
+						</tr>
+
+						<tr v-for="l in logs">
+							<td>
+								<my-button image="open.png"
+									@trigger="showEntry(l)"
+								/>
+							</td>
+							<td class="minimum">{{ displayDate(l.date) }}</td>
+							<td class="minimum">{{ l.loginName }}</td>
+							<td class="minimum">{{ l.ressource + ' / ' + l.action }}</td>
+							<td class="minimum">{{ l.entityId !== null ? l.entityId : '-' }}</td>
+							<td>{{ l.diff !== null ? Object.keys(l.diff).join(', ') : '-' }}</td>
+							<td class="minimum hash" :title="l.hash">{{ l.hash.substring(0,12) }}</td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			ressources:[
+				'api','article','attribute','clientEvent','cluster','collection','config',
+				'form','icon','job','jobQueue','jsFunction','ldap','license','login',
+				'loginClientCert','loginForm','loginSession','loginTemplate','mailAccount',
+				'mailSpooler','mailTemplate','menuTab','module','moduleMeta','oauthClient',
+				'package','pgFunction','pgIndex','pgTrigger','preset','pwaDomain','relation',
+				'repoModule','role','searchBar','task','transfer','variable','widget'
+			],
+
+			// inputs
+			byString:'',
+			limit:100,
+			offset:0,
+			ressource:'',
+			total:0,
+			unixFrom:null,
+			unixTo:null,
+
+			// data
+			logs:[]
+		};
+	},
+	mounted() {
+		this.$store.commit('pageTitle',this.menuTitle);
+
+		// set date range for log retrieval (30 days ago to now)
+		let d = new Date();
+		d.setDate(d.getDate()-30);
+		d.setHours(0,0,0);
+		this.setDate(Math.floor(d.getTime() / 1000),true);
+	},
+	computed:{
+		// stores
+		settings:(s) => s.$store.getters.settings,
+		capApp:  (s) => s.$store.getters.captions.admin.auditLog,
+		capGen:  (s) => s.$store.getters.captions.generic
+	},
+	methods:{
+		// externals
+		getUnixFormat,
+
+		displayDate(date) {
+			let format = [this.settings.dateFormat,'H:i:S'];
+			return this.getUnixFormat(date,format.join(' '));
+		},
+
+		// actions
+		setDate(unix,from) {
+			if(from) {
+				this.unixFrom = unix;
+			}
+			else {
+				this.unixTo = unix;
+
+				// add 23:59:59 to to date, if from and to date are equal
+				let d = new Date(this.unixTo * 1000);
+				if(d.getHours() === 0 && d.getMinutes() === 0 && d.getSeconds() === 0)
+					this.unixTo += 86399;
+			}
+			this.get();
+		},
+		showEntry(l) {
+			this.$store.commit('dialog',{
+				captionBody:JSON.stringify(l,null,4),
+				textDisplay:'textarea',
+				width:800
+			});
+		},
+
+		// backend calls
+		exportLogs() {
+			// export all entries matching current filters
+			ws.send('auditLog','get',{
+				byString:this.byString,
+				dateFrom:this.unixFrom,
+				dateTo:this.unixTo,
+				loginId:null,
+				ressource:this.ressource,
+				limit:0,
+				offset:0
+			},true).then(
+				res => {
+					const blob = new Blob([JSON.stringify(res.payload.logs,null,4)],{type:'application/json'});
+					const elem = window.document.createElement('a');
+					const url  = window.URL.createObjectURL(blob);
+
+					elem.href     = url;
+					elem.download = `audit_log_${this.getUnixFormat(Math.floor(new Date().getTime() / 1000),'Y-m-d_H-i')}.json`;
+
+					document.body.appendChild(elem);
+					elem.click();
+					document.body.removeChild(elem);
+					window.URL.revokeObjectURL(url);
+				},
+				this.$root.genericError
+			);
+		},
+		get() {
+			ws.send('auditLog','get',{
+				byString:this.byString,
+				dateFrom:this.unixFrom,
+				dateTo:this.unixTo,
+				loginId:null,
+				ressource:this.ressource,
+				limit:this.limit,
+				offset:this.offset
+			},true).then(
+				res => {
+					this.logs  = res.payload.logs;
+					this.total = res.payload.total;
+				},
+				this.$root.genericError
+			);
+		},
+		verify() {
+			ws.send('auditLog','verify',{},true).then(
+				res => {
+					const v = res.payload;
+					this.$store.commit('dialog',{
+						captionBody:v.idBroken === null
+							? this.capApp.dialog.verifyOk.replace('{COUNT}',v.count).replace('{HASH}',v.hashLatest !== null ? v.hashLatest : '-')
+							: this.capApp.dialog.verifyBroken.replace('{ID}',v.idBroken),
+						image:v.idBroken === null ? 'ok.png' : 'warning.png'
+					});
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
@@ -139,6 +139,39 @@ let MyAdminConfig = {
 							<td>{{ capApp.pwForceSpecial }}</td>
 							<td><my-bool-string-number v-model="configInput.pwForceSpecial" /></td>
 						</tr>
+						<tr>
+							<td>{{ capApp.pwHistoryCount }}</td>
+							<td>
+								<div class="row gap centered">
+									<input v-model="configInput.pwHistoryCount" />
+									<my-button image="question.png"
+										@trigger="showHelp(capApp.pwHistoryCountDesc)"
+									/>
+								</div>
+							</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.pwMaxAgeDays }}</td>
+							<td>
+								<div class="row gap centered">
+									<input v-model="configInput.pwMaxAgeDays" />
+									<my-button image="question.png"
+										@trigger="showHelp(capApp.pwMaxAgeDaysDesc)"
+									/>
+								</div>
+							</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.pwBreachedPath }}</td>
+							<td>
+								<div class="row gap centered">
+									<input v-model="configInput.pwBreachedPath" />
+									<my-button image="question.png"
+										@trigger="showHelp(capApp.pwBreachedPathDesc)"
+									/>
+								</div>
+							</td>
+						</tr>
 					</tbody>
 				</table>
 			</div>
@@ -349,6 +382,58 @@ let MyAdminConfig = {
 				<span v-html="capApp.bruteforceDesc"></span>
 			</div>
 			
+			<!-- ACME certificates -->
+			<div class="contentPart">
+				<div class="contentPartHeader">
+					<img class="icon" src="images/keyLocked.png" />
+					<h1>{{ capApp.acmeTitle }}</h1>
+				</div>
+				
+				<table class="default-inputs">
+					<tbody>
+						<tr>
+							<td>{{ capApp.acmeEnable }}</td>
+							<td>
+								<my-bool-string-number
+									v-model="configInput.acmeEnable"
+								/>
+							</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.acmeDirectoryUrl }}</td>
+							<td><input v-model="configInput.acmeDirectoryUrl" /></td>
+						</tr>
+						<tr>
+							<td>{{ capApp.acmeEmail }}</td>
+							<td><input v-model="configInput.acmeEmail" /></td>
+						</tr>
+						<tr>
+							<td>{{ capApp.acmeChallenge }}</td>
+							<td>
+								<select v-model="configInput.acmeChallenge">
+									<option value="tls-alpn-01">TLS-ALPN-01</option>
+									<option value="http-01">HTTP-01</option>
+								</select>
+							</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.acmeRenewDays }}</td>
+							<td><input v-model="configInput.acmeRenewDays" /></td>
+						</tr>
+						<tr>
+							<td>{{ capApp.acmeSkipVerify }}</td>
+							<td>
+								<my-bool-string-number
+									v-model="configInput.acmeSkipVerify"
+								/>
+							</td>
+						</tr>
+					</tbody>
+				</table>
+				
+				<span v-html="capApp.acmeDesc"></span>
+			</div>
+			
 			<!-- admin mails -->
 			<div class="contentPart">
 				<div class="contentPartHeader">
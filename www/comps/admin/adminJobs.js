@@ -0,0 +1,362 @@
+import {getUnixFormat} from '../shared/time.js';
+import {getCaption}    from '../shared/language.js';
+export {MyAdminJobs as default};
+
+let MyAdminJobs = {
+	name:'my-admin-jobs',
+	template:`<div class="admin-jobs contentBox grow">
+		
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/tasks.png" />
+				<h1>{{ menuTitle + ' (' + total + ')' }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area">
+				<my-button image="refresh.png"
+					@trigger="get"
+					:caption="capGen.button.refresh"
+				/>
+				<my-button image="autoRenew.png"
+					v-if="!noJobs"
+					@trigger="reset"
+					:active="jobIdsSelected.length !== 0"
+					:caption="capApp.button.reset"
+					:captionTitle="capApp.button.resetHint"
+				/>
+				<my-button image="delete.png"
+					v-if="!noJobs"
+					@trigger="del"
+					:active="jobIdsSelected.length !== 0"
+					:cancel="true"
+					:caption="capGen.button.delete"
+				/>
+			</div>
+			<div class="area default-inputs" v-if="!noJobs">
+				<my-button image="triangleLeft.png"
+					@trigger="offsetSet(false)"
+					@trigger-shift="startAtPageFirst"
+					:active="offset-limit >= 0"
+					:naked="true"
+				/>
+				
+				<span>{{ String((offset / limit) + 1) + ' / ' + pages  }}</span>
+				
+				<my-button image="triangleRight.png"
+					@trigger="offsetSet(true)"
+					@trigger-shift="startAtPageLast"
+					:active="offset+limit < total"
+					:naked="true"
+				/>
+				
+				<select v-model.number="limit" @change="startAtPageFirst">
+					<option>10</option>
+					<option>25</option>
+					<option>50</option>
+					<option>100</option>
+					<option>500</option>
+				</select>
+			</div>
+			<div class="area default-inputs">
+				<div class="row gap">
+					<my-button
+						@trigger="showOptions = !showOptions"
+						:caption="capGen.settings"
+						:image="showOptions ? 'visible1.png' : 'visible0.png'"
+					/>
+					<select v-model="state" @change="startAtPageFirst">
+						<option value="">{{ capApp.stateAll }}</option>
+						<option value="pending">{{ capApp.statePending }}</option>
+						<option value="failed">{{ capApp.stateFailed }}</option>
+					</select>
+				</div>
+			</div>
+		</div>
+		
+		<div class="content default-inputs" :class="{ 'no-padding':!noJobs && !showOptions }">
+			
+			<!-- options -->
+			<div v-if="showOptions">
+				<div class="row gap centered">
+					<span>{{ capApp.workers }}</span>
+					<input class="short" v-model="configInput.jobWorkers" />
+					<my-button image="save.png"
+						@trigger="setConfig"
+						:active="config.jobWorkers !== configInput.jobWorkers"
+						:caption="capGen.button.save"
+					/>
+				</div>
+				<p>{{ capApp.workersHint }}</p>
+				
+				<my-label image="listCog.png" :caption="capApp.queues" :large="true" />
+				<p>{{ capApp.queuesHint }}</p>
+				
+				<table class="generic-table bright shade">
+					<thead>
+						<tr>
+							<th>{{ capGen.name }}</th>
+							<th>{{ capApp.concurrency }}</th>
+							<th></th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-for="(q,i) in queuesInput">
+							<td>{{ q.name }}</td>
+							<td><input class="short" v-model.number="queuesInput[i].concurrency" /></td>
+							<td>
+								<div class="row gap">
+									<my-button image="save.png"
+										@trigger="setQueue(q)"
+										:active="q.concurrency !== queues[i].concurrency"
+									/>
+									<my-button image="delete.png"
+										@trigger="delQueue(q.name)"
+										:cancel="true"
+									/>
+								</div>
+							</td>
+						</tr>
+						<tr>
+							<td><input v-model="queueNew.name" :placeholder="capApp.queueNew" /></td>
+							<td><input class="short" v-model.number="queueNew.concurrency" /></td>
+							<td>
+								<my-button image="add.png"
+									@trigger="setQueue(queueNew)"
+									:active="queueNew.name !== ''"
+								/>
+							</td>
+						</tr>
+					</tbody>
+				</table>
+				<br />
+			</div>
+			
+			<span v-if="noJobs"><i>{{ capApp.noJobs }}</i></span>
+			
+			<table class="generic-table bright shade" v-if="!noJobs">
+				<thead>
+					<tr>
+						<th>
+							<my-button
+								@trigger="toggleJobAll"
+								:image="jobIdsSelected.length === jobs.length ? 'checkbox1.png' : 'checkbox0.png'"
+								:naked="true"
+							/>
+						</th>
+						<th>{{ capGen.application }}</th>
+						<th>{{ capApp.function }}</th>
+						<th>{{ capApp.queue }}</th>
+						<th>{{ capApp.priority }}</th>
+						<th>{{ capApp.state }}</th>
+						<th>{{ capApp.attempts }}</th>
+						<th>{{ capApp.dateAdded }}</th>
+						<th>{{ capApp.dateRunAt }}</th>
+						<th>{{ capApp.args }}</th>
+						<th>{{ capApp.error }}</th>
+					</tr>
+				</thead>
+				<tbody>
+					<tr v-for="j in jobs">
+						<td class="minimum">
+							<my-button
+								@trigger="toggleJobId(j.id)"
+								:image="jobIdsSelected.includes(j.id) ? 'checkbox1.png' : 'checkbox0.png'"
+								:naked="true"
+							/>
+						</td>
+						<td>{{ displayModuleName(j.pgFunctionId) }}</td>
+						<td>{{ displayFunctionName(j.pgFunctionId) }}</td>
+						<td :title="j.uniqueKey !== null ? j.uniqueKey : ''">{{ j.queue }}</td>
+						<td>{{ j.priority }}</td>
+						<td>{{ j.state === 'failed' ? capApp.stateFailed : capApp.statePending }}</td>
+						<td>{{ displayAttempts(j) }}</td>
+						<td>{{ displayTime(j.dateAdded) }}</td>
+						<td>{{ j.state === 'failed' ? '-' : displayTime(j.dateRunAt) }}</td>
+						<td class="minimum">
+							<my-button image="search.png"
+								@trigger="showArgs(j)"
+								:active="j.args !== null"
+							/>
+						</td>
+						<td>{{ j.errorText !== null ? j.errorText : '' }}</td>
+					</tr>
+				</tbody>
+			</table>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			// inputs
+			configInput:{},
+			limit:50,
+			offset:0,
+			showOptions:false,
+			state:'',
+			
+			// jobs
+			jobs:[],
+			jobIdsSelected:[],
+			total:0,
+			
+			// queues
+			queues:[],
+			queuesInput:[],
+			queueNew:{ name:'', concurrency:1 }
+		};
+	},
+	mounted() {
+		this.$store.commit('pageTitle',this.menuTitle);
+		this.configInput = JSON.parse(JSON.stringify(this.config));
+		
+		this.get();
+		this.getQueues();
+	},
+	computed:{
+		// simple
+		noJobs:(s) => s.total === 0,
+		pages: (s) => Math.ceil(s.total / s.limit),
+		
+		// stores
+		moduleIdMap:    (s) => s.$store.getters['schema/moduleIdMap'],
+		pgFunctionIdMap:(s) => s.$store.getters['schema/pgFunctionIdMap'],
+		capApp:         (s) => s.$store.getters.captions.admin.jobs,
+		capGen:         (s) => s.$store.getters.captions.generic,
+		config:         (s) => s.$store.getters.config,
+		settings:       (s) => s.$store.getters.settings
+	},
+	methods:{
+		// externals
+		getCaption,
+		getUnixFormat,
+		
+		// presentation
+		displayAttempts(job) {
+			if(job.attemptCount === 0) return '-';
+			return `${job.attemptCount} (${this.displayTime(job.dateAttempt)})`;
+		},
+		displayFunctionName(pgFunctionId) {
+			const f = this.pgFunctionIdMap[pgFunctionId];
+			return f === undefined ? '-' : this.getCaption('pgFunctionTitle',f.moduleId,f.id,f.captions,f.name);
+		},
+		displayModuleName(pgFunctionId) {
+			const f = this.pgFunctionIdMap[pgFunctionId];
+			if(f === undefined) return '-';
+			
+			const m = this.moduleIdMap[f.moduleId];
+			return this.getCaption('moduleTitle',m.id,m.id,m.captions,m.name);
+		},
+		displayTime(unixTime) {
+			return this.getUnixFormat(unixTime,`${this.settings.dateFormat} H:i:S`);
+		},
+		
+		// actions
+		offsetSet(add) {
+			if(add) this.offset += this.limit;
+			else    this.offset -= this.limit;
+			this.get();
+		},
+		showArgs(job) {
+			this.$store.commit('dialog',{
+				captionBody:JSON.stringify(job.args,null,2),
+				captionTop:this.capApp.args,
+				image:'tasks.png',
+				textDisplay:'textarea',
+				width:800
+			});
+		},
+		startAtPageFirst() {
+			this.offset = 0;
+			this.get();
+		},
+		startAtPageLast() {
+			this.offset = this.limit * (this.pages-1);
+			this.get();
+		},
+		toggleJobAll() {
+			if(this.jobIdsSelected.length === this.jobs.length) {
+				this.jobIdsSelected = [];
+				return;
+			}
+			
+			this.jobIdsSelected = [];
+			for(const j of this.jobs) {
+				this.jobIdsSelected.push(j.id);
+			}
+		},
+		toggleJobId(id) {
+			const pos = this.jobIdsSelected.indexOf(id);
+			
+			if(pos === -1) this.jobIdsSelected.push(id);
+			else           this.jobIdsSelected.splice(pos,1);
+		},
+		
+		// backend calls
+		del() {
+			ws.send('job','del',{ids:this.jobIdsSelected},true).then(
+				() => {
+					this.jobIdsSelected = [];
+					this.offset = 0;
+					this.get();
+				},
+				this.$root.genericError
+			);
+		},
+		delQueue(name) {
+			ws.send('jobQueue','del',{name:name},true).then(
+				this.getQueues,
+				this.$root.genericError
+			);
+		},
+		get() {
+			ws.send('job','get',{
+				limit:this.limit,
+				offset:this.offset,
+				state:this.state
+			},true).then(
+				res => {
+					this.jobs           = res.payload.jobs;
+					this.jobIdsSelected = [];
+					this.total          = res.payload.total;
+				},
+				this.$root.genericError
+			);
+		},
+		getQueues() {
+			ws.send('jobQueue','get',{},true).then(
+				res => {
+					this.queues      = res.payload;
+					this.queuesInput = JSON.parse(JSON.stringify(this.queues));
+				},
+				this.$root.genericError
+			);
+		},
+		reset() {
+			ws.send('job','reset',{ids:this.jobIdsSelected},true).then(
+				() => {
+					this.jobIdsSelected = [];
+					this.get();
+				},
+				this.$root.genericError
+			);
+		},
+		setConfig() {
+			ws.send('config','set',this.configInput,true).then(
+				() => {},
+				this.$root.genericError
+			);
+		},
+		setQueue(queue) {
+			ws.send('jobQueue','set',queue,true).then(
+				() => {
+					this.queueNew = { name:'', concurrency:1 };
+					this.getQueues();
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
\ No newline at end of file
@@ -7,6 +7,7 @@ import {dialogCloseAsk} from '../shared/dialog.js';
 import {deepIsEqual}    from '../shared/generic.js';
 import srcBase64Icon    from '../shared/image.js';
 import {getCaption}     from '../shared/language.js';
+import {getUnixFormat}  from '../shared/time.js';
 export {MyAdminLogin as default};
 
 const MyAdminLoginRole = {
@@ -99,6 +100,12 @@ const MyAdminLogin = {
 					/>
 				</div>
 				<div class="area">
+					<my-button image="personArrow.png"
+						v-if="!isNew"
+						@trigger="impersonateAsk"
+						:active="inputs.active && !inputs.noAuth && !isChanged"
+						:caption="capApp.button.impersonate"
+					/>
 					<my-button image="warning.png"
 						v-if="!isNew"
 						@trigger="resetTotpAsk"
@@ -106,6 +113,13 @@ const MyAdminLogin = {
 						:cancel="true"
 						:caption="capApp.button.resetMfa"
 					/>
+					<my-button image="warning.png"
+						v-if="!isNew"
+						@trigger="resetWebAuthnAsk"
+						:active="!inputs.noAuth && !isOauth"
+						:cancel="true"
+						:caption="capApp.button.resetWebAuthn"
+					/>
 					<my-button image="delete.png"
 						v-if="!isNew"
 						@trigger="delAsk"
@@ -178,9 +192,9 @@ const MyAdminLogin = {
 				<div class="login-details">
 					<my-tabs class="login-details-tabs"
 						v-model="tabTarget"
-						:entries="['meta','roles','properties']"
-						:entriesIcon="['images/editBox.png','images/personMultiple.png','images/personCog.png']"
-						:entriesText="[capGen.details,capApp.roles.replace('{COUNT}',roleTotalNonHidden),capGen.properties]"
+						:entries="isNew ? ['meta','roles','properties'] : ['meta','roles','properties','impersonation']"
+						:entriesIcon="['images/editBox.png','images/personMultiple.png','images/personCog.png','images/personArrow.png']"
+						:entriesText="[capGen.details,capApp.roles.replace('{COUNT}',roleTotalNonHidden),capGen.properties,capApp.impersonation]"
 					/>
 					<div class="login-details-content" :class="{ roles:tabTarget === 'roles' }">
 
@@ -331,6 +345,43 @@ const MyAdminLogin = {
 									</td>
 									<td>{{ capApp.hint.tokenExpiryHours }}</td>
 								</tr>
+								<tr v-if="!isNew">
+									<td>
+										<div class="title-cell">
+											<img src="images/fileKey.png" />
+											<span>{{ capApp.clientCerts }}</span>
+										</div>
+									</td>
+									<td class="default-inputs">
+										<div class="column gap">
+											<div class="row gap centered" v-for="c in clientCerts">
+												<my-button image="cancel.png"
+													@trigger="delClientCert(c)"
+													:cancel="true"
+													:caption="c.value"
+													:captionTitle="capApp.clientCertType[c.type]"
+													:naked="true"
+												/>
+											</div>
+											<div class="row gap centered">
+												<select v-model="clientCertInput.type">
+													<option value="subject">{{ capApp.clientCertType.subject }}</option>
+													<option value="san">{{ capApp.clientCertType.san }}</option>
+												</select>
+												<input
+													v-model="clientCertInput.value"
+													@keyup.enter="setClientCert"
+													:placeholder="capApp.clientCertValueHint[clientCertInput.type]"
+												/>
+												<my-button image="add.png"
+													@trigger="setClientCert"
+													:active="clientCertInput.value !== ''"
+												/>
+											</div>
+										</div>
+									</td>
+									<td>{{ capApp.hint.clientCerts }}</td>
+								</tr>
 
 								<tr v-if="anyAction"><td colspan="3" class="grouping">{{ capGen.actions }}</td></tr>
 								<tr v-if="isAuthR3">
@@ -358,6 +409,31 @@ const MyAdminLogin = {
 								</tr>
 							</tbody>
 						</table>
+						
+						<!-- impersonation log -->
+						<template v-if="tabTarget === 'impersonation'">
+							<span class="login-details-content-message">{{ capApp.impersonationLogDesc.replace('{COUNT}',impersonationLogsTotal) }}</span>
+							<table class="generic-table sticky-top bright">
+								<thead>
+									<tr>
+										<th>{{ capGen.date }}</th>
+										<th>{{ capApp.impersonationLog.impersonator }}</th>
+										<th>{{ capApp.impersonationLog.request }}</th>
+										<th>{{ capApp.impersonationLog.payload }}</th>
+										<th>{{ capApp.impersonationLog.blocked }}</th>
+									</tr>
+								</thead>
+								<tbody>
+									<tr v-for="l in impersonationLogs">
+										<td class="minimum">{{ getUnixFormat(l.date,settings.dateFormat+' H:i:S') }}</td>
+										<td class="minimum">{{ l.loginNameImpersonator !== null ? l.loginNameImpersonator : l.loginIdImpersonator }}</td>
+										<td class="minimum">{{ l.ressource + ' / ' + l.action }}</td>
+										<td><span class="login-details-log-payload" :title="JSON.stringify(l.payload)">{{ JSON.stringify(l.payload) }}</span></td>
+										<td class="minimum"><my-bool :modelValue="l.blocked" :readonly="true" /></td>
+									</tr>
+								</tbody>
+							</table>
+						</template>
 					</div>
 				</div>
 			</div>
@@ -374,6 +450,10 @@ const MyAdminLogin = {
 	data() {
 		return {
 			// states
+			clientCerts:[],    // TLS client certificate mappings
+			clientCertInput:{ type:'subject', value:'' },
+			impersonationLogs:[],
+			impersonationLogsTotal:0,
 			inputs:{},         // input values
 			inputsOrg:{},      // input values on load
 			notUniqueEmail:false,
@@ -430,7 +510,14 @@ const MyAdminLogin = {
 		roleIdMap:      (s) => s.$store.getters['schema/roleIdMap'],
 		capApp:         (s) => s.$store.getters.captions.admin.login,
 		capGen:         (s) => s.$store.getters.captions.generic,
-		moduleIdMapMeta:(s) => s.$store.getters.moduleIdMapMeta
+		moduleIdMapMeta:(s) => s.$store.getters.moduleIdMapMeta,
+		settings:       (s) => s.$store.getters.settings
+	},
+	watch:{
+		tabTarget(v) {
+			if(v === 'impersonation')
+				this.getImpersonationLogs();
+		}
 	},
 	mounted() {
 		window.addEventListener('keydown',this.handleHotkeys);
@@ -455,6 +542,7 @@ const MyAdminLogin = {
 		dialogCloseAsk,
 		getCaption,
 		getLoginIcon,
+		getUnixFormat,
 		srcBase64Icon,
 		
 		handleHotkeys(e) {
@@ -565,6 +653,15 @@ const MyAdminLogin = {
 		},
 		
 		// backend calls
+		delClientCert(c) {
+			ws.send('loginClientCert','del',{
+				loginId:this.loginId,
+				loginClientCert:c
+			},true).then(
+				this.getClientCerts,
+				this.$root.genericError
+			);
+		},
 		delAsk() {
 			this.$store.commit('dialog',{
 				captionBody:this.capApp.dialog.delete,
@@ -603,6 +700,26 @@ const MyAdminLogin = {
 					this.inputs    = res.payload.logins[0];
 					this.inputsOrg = JSON.parse(JSON.stringify(this.inputs));
 					this.getIsNotUnique('email',this.inputs.meta.email);
+					this.getClientCerts();
+				},
+				this.$root.genericError
+			);
+		},
+		getClientCerts() {
+			ws.send('loginClientCert','get',{loginId:this.loginId},true).then(
+				res => this.clientCerts = res.payload,
+				this.$root.genericError
+			);
+		},
+		getImpersonationLogs() {
+			ws.send('login','getImpersonationLog',{
+				id:this.loginId,
+				limit:100,
+				offset:0
+			},true).then(
+				res => {
+					this.impersonationLogs      = res.payload.logs;
+					this.impersonationLogsTotal = res.payload.total;
 				},
 				this.$root.genericError
 			);
@@ -657,6 +774,20 @@ const MyAdminLogin = {
 				this.$root.genericError
 			);
 		},
+		setClientCert() {
+			if(this.clientCertInput.value === '') return;
+			
+			ws.send('loginClientCert','set',{
+				loginId:this.loginId,
+				loginClientCert:this.clientCertInput
+			},true).then(
+				() => {
+					this.clientCertInput.value = '';
+					this.getClientCerts();
+				},
+				this.$root.genericError
+			);
+		},
 		set() {
 			let records = [];
 			for(let i = 0, j = this.loginForms.length; i < j; i++) {
@@ -693,6 +824,31 @@ const MyAdminLogin = {
 			);
 		},
 		
+		// impersonation
+		impersonateAsk() {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.impersonate.replace('{NAME}',this.inputs.name),
+				image:'personArrow.png',
+				buttons:[{
+					cancel:true,
+					caption:this.capApp.button.impersonate,
+					exec:this.impersonate,
+					keyEnter:true,
+					image:'personArrow.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
+		impersonate() {
+			ws.send('login','impersonate',{id:this.loginId},true).then(
+				res => this.$store.getters.appFunctions.impersonationStart(res.payload),
+				this.$root.genericError
+			);
+		},
+		
 		// MFA calls
 		resetTotpAsk() {
 			this.$store.commit('dialog',{
@@ -715,6 +871,28 @@ const MyAdminLogin = {
 			ws.send('login','resetTotp',{id:this.loginId},true).then(
 				res => {},this.$root.genericError
 			);
+		},
+		resetWebAuthnAsk() {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.resetWebAuthn,
+				image:'warning.png',
+				buttons:[{
+					cancel:true,
+					caption:this.capGen.button.reset,
+					exec:this.resetWebAuthn,
+					keyEnter:true,
+					image:'refresh.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
+		resetWebAuthn() {
+			ws.send('login','resetWebAuthn',{id:this.loginId},true).then(
+				res => {},this.$root.genericError
+			);
 		}
 	}
 };
\ No newline at end of file
@@ -65,6 +65,7 @@ let MyAdminSessions = {
 								<span>{{ capApp.titles[t] }}</span>
 							</div>
 						</th>
+						<th></th>
 					</tr>
 				</thead>
 				<tbody>
@@ -100,6 +101,14 @@ let MyAdminSessions = {
 							</div>
 						</td>
 						<td>{{ s.address }}</td>
+						<td>
+							<my-button image="logoff.png"
+								v-if="s.tokenId !== null"
+								@trigger="revokeAsk(s)"
+								:cancel="true"
+								:caption="capApp.button.revoke"
+							/>
+						</td>
 					</tr>
 				</tbody>
 			</table>
@@ -145,6 +154,23 @@ let MyAdminSessions = {
 		getUnixFormat,
 
 		// actions
+		revokeAsk(session) {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.revoke.replace('{NAME}',session.loginName),
+				image:'warning.png',
+				buttons:[{
+					cancel:true,
+					caption:this.capApp.button.revoke,
+					exec:() => this.revoke(session.loginId,session.tokenId),
+					keyEnter:true,
+					image:'logoff.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
 		byStringSet() {
 			this.offset = 0;
 			this.get();
@@ -179,6 +205,12 @@ let MyAdminSessions = {
 				},
 				this.$root.genericError
 			);
+		},
+		revoke(loginId,tokenId) {
+			ws.send('loginSession','revoke',{loginId:loginId,tokenId:tokenId},true).then(
+				this.get,
+				this.$root.genericError
+			);
 		}
 	}
 };
\ No newline at end of file
@@ -0,0 +1,224 @@
+import {deepIsEqual} from '../shared/generic.js';
+export {MyAdminMailTemplate as default};
+
+let MyAdminMailTemplate = {
+	name:'my-admin-mail-template',
+	template:`<div v-if="ready" class="app-sub-window under-header at-top with-margin" @mousedown.self="$emit('close')">
+		
+		<div class="contentBox admin-mail-template scroll float">
+			<div class="top">
+				<div class="area nowrap">
+					<img class="icon" src="images/mail.png" />
+					<h1 class="title">{{ isNew ? capApp.titleNew : capApp.title.replace('{NAME}',inputs.name) }}</h1>
+				</div>
+				<div class="area">
+					<my-button image="cancel.png"
+						@trigger="$emit('close')"
+						:cancel="true"
+					/>
+				</div>
+			</div>
+			<div class="top lower">
+				<div class="area">
+					<my-button image="save.png"
+						@trigger="set"
+						:active="canSave"
+						:caption="isNew ? capGen.button.create : capGen.button.save"
+					/>
+					<my-button image="refresh.png"
+						v-if="!isNew"
+						@trigger="reset"
+						:active="hasChanges"
+						:caption="capGen.button.refresh"
+					/>
+					<my-button image="add.png"
+						v-if="!isNew"
+						@trigger="$emit('makeNew')"
+						:caption="capGen.button.new"
+					/>
+				</div>
+				<div class="area">
+					<my-button image="delete.png"
+						v-if="!isNew"
+						@trigger="delAsk"
+						:cancel="true"
+						:caption="capGen.button.delete"
+					/>
+				</div>
+			</div>
+			
+			<div class="content no-padding default-inputs">
+				<table class="generic-table-vertical">
+					<tbody>
+						<tr>
+							<td>{{ capGen.name }}*</td>
+							<td><input v-model="inputs.name" v-focus /></td>
+							<td>{{ capApp.nameHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capGen.comments }}</td>
+							<td colspan="2"><textarea v-model="inputs.comment"></textarea></td>
+						</tr>
+						<tr>
+							<td>{{ capGen.language }}*</td>
+							<td>
+								<div class="row gap centered">
+									<select v-model="languageCode">
+										<option v-for="l in languageCodes" :value="l">{{ l }}</option>
+									</select>
+									<input class="short" v-model="languageCodeNew" :placeholder="capApp.languageNew" />
+									<my-button image="add.png"
+										@trigger="languageAdd"
+										:active="/^[a-z]{2}_[a-z]{2}$/.test(languageCodeNew)"
+									/>
+									<my-button image="delete.png"
+										@trigger="languageDel"
+										:active="languageCodes.length > 1"
+										:cancel="true"
+									/>
+								</div>
+							</td>
+							<td>{{ capApp.languageHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.subject }}*</td>
+							<td colspan="2"><input v-model="inputs.captions.subject[languageCode]" /></td>
+						</tr>
+						<tr>
+							<td>{{ capApp.bodyHtml }}</td>
+							<td colspan="2"><textarea class="long" v-model="inputs.captions.bodyHtml[languageCode]"></textarea></td>
+						</tr>
+						<tr>
+							<td>{{ capApp.bodyText }}</td>
+							<td colspan="2"><textarea class="long" v-model="inputs.captions.bodyText[languageCode]"></textarea></td>
+						</tr>
+						<tr>
+							<td colspan="3"><span v-html="capApp.placeholderHint"></span></td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
+		</div>
+	</div>`,
+	props:{
+		id:               { type:Number, required:true },
+		mailTemplateIdMap:{ type:Object, required:true }
+	},
+	emits:['close','makeNew'],
+	watch:{
+		id:{
+			handler(v) { this.reset(); },
+			immediate:true
+		}
+	},
+	data() {
+		return {
+			inputs:{},
+			languageCode:'',
+			languageCodeNew:'',
+			ready:false
+		};
+	},
+	computed:{
+		canSave:(s) =>
+			s.ready &&
+			s.hasChanges &&
+			s.inputs.name !== '' &&
+			s.languageCodes.every(l => s.inputs.captions.subject[l] !== ''),
+		inputsOrg:(s) => s.isNew ? {
+			id:0,
+			name:'',
+			comment:null,
+			captions:{
+				subject:{ en_us:'' },
+				bodyHtml:{ en_us:'' },
+				bodyText:{ en_us:'' }
+			}
+		} : s.mailTemplateIdMap[s.id],
+		
+		// simple states
+		hasChanges:   (s) => !s.deepIsEqual(s.inputsOrg,s.inputs),
+		isNew:        (s) => s.id === 0,
+		languageCodes:(s) => Object.keys(s.inputs.captions.subject).sort(),
+		
+		// stores
+		capApp:(s) => s.$store.getters.captions.admin.mailTemplate,
+		capGen:(s) => s.$store.getters.captions.generic
+	},
+	mounted() {
+		this.$store.commit('keyDownHandlerSleep');
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.set,key:'s',keyCtrl:true});
+		this.$store.commit('keyDownHandlerAdd',{fnc:this.close,key:'Escape'});
+	},
+	unmounted() {
+		this.$store.commit('keyDownHandlerDel',this.set);
+		this.$store.commit('keyDownHandlerDel',this.close);
+		this.$store.commit('keyDownHandlerWake');
+	},
+	methods:{
+		// external
+		deepIsEqual,
+		
+		// actions
+		close() {
+			this.$emit('close');
+		},
+		languageAdd() {
+			const l = this.languageCodeNew;
+			if(this.inputs.captions.subject[l] === undefined) {
+				// a language is defined by its subject
+				this.inputs.captions.subject[l]  = '';
+				this.inputs.captions.bodyHtml[l] = '';
+				this.inputs.captions.bodyText[l] = '';
+			}
+			this.languageCode    = l;
+			this.languageCodeNew = '';
+		},
+		languageDel() {
+			for(const k of ['subject','bodyHtml','bodyText']) {
+				delete this.inputs.captions[k][this.languageCode];
+			}
+			this.languageCode = this.languageCodes[0];
+		},
+		reset() {
+			this.inputs       = JSON.parse(JSON.stringify(this.inputsOrg));
+			this.languageCode = this.languageCodes.length !== 0 ? this.languageCodes[0] : '';
+			this.ready        = true;
+		},
+		
+		// backend calls
+		delAsk() {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.delete,
+				buttons:[{
+					cancel:true,
+					caption:this.capGen.button.delete,
+					exec:this.del,
+					image:'delete.png'
+				},{
+					caption:this.capGen.button.cancel,
+					image:'cancel.png'
+				}]
+			});
+		},
+		del() {
+			ws.send('mailTemplate','del',{id:this.id},true).then(
+				this.close,
+				this.$root.genericError
+			);
+		},
+		set() {
+			if(!this.canSave) return;
+			
+			ws.send('mailTemplate','set',{
+				id:this.id,
+				name:this.inputs.name,
+				comment:this.inputs.comment !== '' ? this.inputs.comment : null,
+				captions:this.inputs.captions
+			},true).then(
+				this.close,
+				this.$root.genericError
+			);
+		}
+	}
+};
@@ -0,0 +1,85 @@
+import MyAdminMailTemplate from './adminMailTemplate.js';
+export {MyAdminMailTemplates as default};
+
+let MyAdminMailTemplates = {
+	name:'my-admin-mail-templates',
+	components:{ MyAdminMailTemplate },
+	template:`<div class="admin-mails contentBox grow">
+		<div class="top">
+			<div class="area">
+				<img class="icon" src="images/mail.png" />
+				<h1>{{ menuTitle }}</h1>
+			</div>
+		</div>
+		<div class="top lower">
+			<div class="area">
+				<my-button image="add.png"
+					@trigger="idOpen = 0"
+					:caption="capGen.button.new"
+				/>
+				<my-button image="refresh.png"
+					@trigger="get"
+					:caption="capGen.button.refresh"
+				/>
+			</div>
+		</div>
+		
+		<div class="content grow">
+			<div class="generic-entry-list wide">
+				<div class="entry clickable"
+					v-for="t in mailTemplates"
+					@click="idOpen = t.id"
+					:key="t.id"
+					:title="t.comment !== null ? t.comment : t.name"
+				>
+					<div class="lines">
+						<span>{{ t.name }}</span>
+						<span class="subtitle">{{ Object.keys(t.captions.subject).join(', ') }}</span>
+					</div>
+				</div>
+			</div>
+			
+			<my-admin-mail-template
+				v-if="idOpen !== null"
+				@close="idOpen = null;get()"
+				@makeNew="idOpen = 0"
+				:id="idOpen"
+				:mailTemplateIdMap="mailTemplateIdMap"
+			/>
+		</div>
+	</div>`,
+	props:{
+		menuTitle:{ type:String, required:true }
+	},
+	data() {
+		return {
+			idOpen:null,
+			mailTemplates:[]
+		};
+	},
+	computed:{
+		mailTemplateIdMap:(s) => {
+			let map = {};
+			for(const t of s.mailTemplates) {
+				map[t.id] = t;
+			}
+			return map;
+		},
+		
+		// stores
+		capGen:(s) => s.$store.getters.captions.generic
+	},
+	mounted() {
+		this.get();
+		this.$store.commit('pageTitle',this.menuTitle);
+	},
+	methods:{
+		// backend calls
+		get() {
+			ws.send('mailTemplate','get',{},true).then(
+				res => this.mailTemplates = res.payload,
+				this.$root.genericError
+			);
+		}
+	}
+};
@@ -85,6 +85,7 @@ let MyAdminMailTraffic = {
 						<th>{{ capApp.bccList }}</th>
 						<th>{{ capApp.subject }}</th>
 						<th>{{ capApp.files }}</th>
+						<th>{{ capApp.delivery }}</th>
 						<th>{{ capGen.date }}</th>
 						<th>{{ capApp.account }}</th>
 					</tr>
@@ -98,6 +99,7 @@ let MyAdminMailTraffic = {
 						<td>{{ m.subject }}</td>
 						<td v-if="m.files.length === 0">-</td>
 						<td v-else><my-button image="visible1.png" @trigger="showFiles(m.files)" :caption="String(m.files.length)" /></td>
+						<td :title="m.deliveryDate !== null ? getUnixFormat(m.deliveryDate,settings.dateFormat+' H:i') : ''">{{ m.deliveryAction !== null ? m.deliveryAction : '-' }}</td>
 						<td>{{ getUnixFormat(m.date,settings.dateFormat+' H:i') }}</td>
 						<td>{{ typeof accountIdMap[m.accountId] !== 'undefined' ? accountIdMap[m.accountId].name : '-' }}</td>
 					</tr>
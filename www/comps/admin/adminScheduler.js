@@ -25,6 +25,17 @@ let MyAdminScheduler = {
 					@trigger="get"
 					:caption="capGen.button.refresh"
 				/>
+				<my-button image="log.png"
+					@trigger="runsShow(null)"
+					:caption="capApp.button.runsAll"
+				/>
+			</div>
+			<div class="area">
+				<my-button
+					@trigger="showOptions = !showOptions"
+					:caption="capGen.settings"
+					:image="showOptions ? 'visible1.png' : 'visible0.png'"
+				/>
 			</div>
 		</div>
 		
@@ -34,6 +45,93 @@ let MyAdminScheduler = {
 			<p class="message error" v-if="mirrorMode">
 				{{ capApp.mirrorMode }}
 			</p>
+			
+			<!-- options -->
+			<div class="content" v-if="showOptions">
+				<table class="default-inputs">
+					<tbody>
+						<tr>
+							<td>{{ capApp.runsKeepDays }}</td>
+							<td><input class="short" v-model="configInput.schedulerRunsKeepDays" /></td>
+							<td>{{ capApp.runsKeepDaysHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.alertFailures }}</td>
+							<td><input class="short" v-model="configInput.schedulerAlertFailures" /></td>
+							<td>{{ capApp.alertFailuresHint }}</td>
+						</tr>
+						<tr>
+							<td>{{ capApp.alertDurationSec }}</td>
+							<td><input class="short" v-model="configInput.schedulerAlertDurationSec" /></td>
+							<td>{{ capApp.alertDurationSecHint }}</td>
+						</tr>
+					</tbody>
+				</table>
+				<br />
+				<my-button image="save.png"
+					@trigger="setConfig"
+					:active="hasChangesConfig"
+					:caption="capGen.button.save"
+				/>
+			</div>
+			
+			<!-- run history -->
+			<div class="content" v-if="runsShown">
+				<div class="row gap centered">
+					<my-label image="log.png" :caption="runsTitle" :large="true" />
+					<my-button image="triangleLeft.png"
+						@trigger="runsOffsetSet(false)"
+						:active="runsOffset-runsLimit >= 0"
+						:naked="true"
+					/>
+					<span>{{ String((runsOffset / runsLimit) + 1) + ' / ' + Math.max(runsPages,1) }}</span>
+					<my-button image="triangleRight.png"
+						@trigger="runsOffsetSet(true)"
+						:active="runsOffset+runsLimit < runsTotal"
+						:naked="true"
+					/>
+					<my-button
+						@trigger="runsOnlyFailed = !runsOnlyFailed;runsOffset = 0;runsGet()"
+						:caption="capApp.runsOnlyFailed"
+						:image="runsOnlyFailed ? 'checkbox1.png' : 'checkbox0.png'"
+						:naked="true"
+					/>
+					<my-button image="cancel.png"
+						@trigger="runsShown = false"
+						:cancel="true"
+						:caption="capGen.button.close"
+					/>
+				</div>
+				<br />
+				
+				<span v-if="runsTotal === 0"><i>{{ capApp.runsEmpty }}</i></span>
+				<table class="generic-table bright shade" v-if="runsTotal !== 0">
+					<thead>
+						<tr>
+							<th v-if="runsScheduleId === null">{{ capGen.name }}</th>
+							<th>{{ capApp.runNode }}</th>
+							<th>{{ capApp.runDateStart }}</th>
+							<th>{{ capApp.runDateEnd }}</th>
+							<th>{{ capApp.runDuration }}</th>
+							<th>{{ capApp.runSuccess }}</th>
+							<th>{{ capApp.runRowsAffected }}</th>
+							<th>{{ capApp.runError }}</th>
+						</tr>
+					</thead>
+					<tbody>
+						<tr v-for="r in runs">
+							<td v-if="runsScheduleId === null">{{ displayScheduleName(r.scheduleId) }}</td>
+							<td>{{ r.nodeName !== null ? r.nodeName : '-' }}</td>
+							<td>{{ displayTime(Math.floor(r.dateStart / 1000)) }}</td>
+							<td>{{ displayTime(Math.floor(r.dateEnd / 1000)) }}</td>
+							<td>{{ displayDuration(r.durationMs) }}</td>
+							<td><img class="icon" :src="r.success ? 'images/ok.png' : 'images/warning.png'" /></td>
+							<td>{{ r.rowsAffected !== null ? r.rowsAffected : '-' }}</td>
+							<td>{{ r.errorText !== null ? r.errorText : '' }}</td>
+						</tr>
+					</tbody>
+				</table>
+			</div>
 		
 			<!-- cluster master schedules -->
 			<div class="content">
@@ -47,7 +145,7 @@ let MyAdminScheduler = {
 							<th>{{ capApp.intervalSeconds }}</th>
 							<th>{{ capApp.dateAttempt }}</th>
 							<th>{{ capApp.dateSuccess }}</th>
-							<th colspan="2">{{ capGen.active }}</th>
+							<th colspan="3">{{ capGen.active }}</th>
 						</tr>
 					</thead>
 					<tbody>
@@ -72,6 +170,12 @@ let MyAdminScheduler = {
 										:caption="capApp.button.runNow"
 									/>
 								</td>
+								<td>
+									<my-button image="log.png"
+										@trigger="runsShow(s.id)"
+										:captionTitle="capApp.button.runs"
+									/>
+								</td>
 							</tr>
 						</template>
 					</tbody>
@@ -90,7 +194,7 @@ let MyAdminScheduler = {
 							<th>{{ capApp.intervalSeconds }}</th>
 							<th>{{ capApp.dateAttempt }}</th>
 							<th>{{ capApp.dateSuccess }}</th>
-							<th colspan="2">{{ capGen.active }}</th>
+							<th colspan="3">{{ capGen.active }}</th>
 						</tr>
 					</thead>
 					<tbody>
@@ -122,13 +226,19 @@ let MyAdminScheduler = {
 											:captionTitle="capApp.button.runNowHint"
 										/>
 									</td>
+									<td>
+										<my-button image="log.png"
+											@trigger="runsShow(s.id)"
+											:captionTitle="capApp.button.runs"
+										/>
+									</td>
 								</tr>
 								<tr v-if="schedulersExpanded.includes(i)" v-for="meta in s.nodeMeta">
 									<td></td>
 									<td colspan="2">{{ meta.name }}</td>
 									<td>{{ displayTime(meta.dateAttempt) }}</td>
 									<td>{{ displayTime(meta.dateSuccess) }}</td>
-									<td colspan="3"></td>
+									<td colspan="4"></td>
 								</tr>
 							</template>
 						</template>
@@ -149,7 +259,7 @@ let MyAdminScheduler = {
 							<th>{{ capApp.interval }}</th>
 							<th>{{ capApp.dateAttempt }}</th>
 							<th>{{ capApp.dateSuccess }}</th>
-							<th></th>
+							<th colspan="2"></th>
 						</tr>
 					</thead>
 					<tbody>
@@ -170,6 +280,12 @@ let MyAdminScheduler = {
 									:caption="capApp.button.runNow"
 								/>
 							</td>
+							<td>
+								<my-button image="log.png"
+									@trigger="runsShow(s.id)"
+									:captionTitle="capApp.button.runs"
+								/>
+							</td>
 						</tr>
 					</tbody>
 				</table>
@@ -181,6 +297,18 @@ let MyAdminScheduler = {
 	},
 	data() {
 		return {
+			configInput:{},
+			showOptions:false,
+			
+			// run history
+			runs:[],
+			runsLimit:25,
+			runsOffset:0,
+			runsOnlyFailed:false,
+			runsScheduleId:null, // schedule to show runs for, all if null
+			runsShown:false,
+			runsTotal:0,
+			
 			schedulers:[],
 			schedulersInput:[],    // changes to schedulers
 			schedulersExpanded:[], // indexes of schedules that show all nodes
@@ -189,6 +317,7 @@ let MyAdminScheduler = {
 	},
 	mounted() {
 		this.get();
+		this.configInput = JSON.parse(JSON.stringify(this.config));
 		this.$store.commit('pageTitle',this.menuTitle);
 		this.$store.commit('keyDownHandlerAdd',{fnc:this.set,key:'s',keyCtrl:true});
 	},
@@ -203,9 +332,13 @@ let MyAdminScheduler = {
 			}
 			return false;
 		},
+		runsTitle:(s) => s.runsScheduleId === null
+			? s.capApp.runsAll : s.capApp.runs.replace('{NAME}',s.displayScheduleName(s.runsScheduleId)),
 
 		// simple
 		hasChanges:          (s) => JSON.stringify(s.schedulers) !== JSON.stringify(s.schedulersInput),
+		hasChangesConfig:    (s) => JSON.stringify(s.config) !== JSON.stringify(s.configInput),
+		runsPages:           (s) => Math.ceil(s.runsTotal / s.runsLimit),
 		pgFunctionSchedulers:(s) => s.schedulers.filter(v => v.taskName === '' && v.intervalType !== 'once'),
 		
 		// stores
@@ -213,6 +346,7 @@ let MyAdminScheduler = {
 		pgFunctionIdMap:(s) => s.$store.getters['schema/pgFunctionIdMap'],
 		capApp:         (s) => s.$store.getters.captions.admin.scheduler,
 		capGen:         (s) => s.$store.getters.captions.generic,
+		config:         (s) => s.$store.getters.config,
 		mirrorMode:     (s) => s.$store.getters.mirrorMode,
 		settings:       (s) => s.$store.getters.settings
 	},
@@ -227,6 +361,18 @@ let MyAdminScheduler = {
 		displayTime(unixTime) {
 			return unixTime === 0 ? '-' : this.getUnixFormat(unixTime,`${this.settings.dateFormat} H:i:S`);
 		},
+		displayDuration(ms) {
+			return ms < 1000 ? `${ms} ms` : `${(ms / 1000).toFixed(1)} s`;
+		},
+		displayScheduleName(scheduleId) {
+			for(const s of this.schedulers) {
+				if(s.id !== scheduleId)
+					continue;
+				
+				return s.taskName !== '' ? this.displayName(s.taskName) : this.displayFunctionName(s.pgFunctionId);
+			}
+			return '-';
+		},
 		displayName(name) {
 			return this.capApp.names[name] === undefined ? name : this.capApp.names[name];
 		},
@@ -255,6 +401,16 @@ let MyAdminScheduler = {
 			let parts    = [];
 			let typeName = '';
 			
+			// cron schedules are described by their expression
+			if(s.intervalType === 'cron') {
+				parts.push(this.capApp.scheduleLineCron.replace('{CRON}',s.cron));
+				
+				if(s.timezone !== '')
+					parts.push(this.capApp.scheduleLineTimezone.replace('{TZ}',s.timezone));
+				
+				return parts.join(', ');
+			}
+			
 			switch(s.intervalType) {
 				case 'days':    typeName = this.capApp.intervalTypeDays;    break;
 				case 'hours':   typeName = this.capApp.intervalTypeHours;   break;
@@ -283,8 +439,22 @@ let MyAdminScheduler = {
 					.replace('{SS}',this.getStringFilled(s.atSecond,2,'0'))
 				);
 			
+			if(['days','weeks','months','years'].includes(s.intervalType) && s.timezone !== '')
+				parts.push(this.capApp.scheduleLineTimezone.replace('{TZ}',s.timezone));
+			
 			return parts.join(', ');
 		},
+		runsOffsetSet(add) {
+			if(add) this.runsOffset += this.runsLimit;
+			else    this.runsOffset -= this.runsLimit;
+			this.runsGet();
+		},
+		runsShow(scheduleId) {
+			this.runsScheduleId = scheduleId;
+			this.runsOffset     = 0;
+			this.runsShown      = true;
+			this.runsGet();
+		},
 		expandScheduler(i) {
 			let pos = this.schedulersExpanded.indexOf(i);
 			
@@ -302,6 +472,20 @@ let MyAdminScheduler = {
 				this.$root.genericError
 			);
 		},
+		runsGet() {
+			ws.send('scheduler','getRuns',{
+				limit:this.runsLimit,
+				offset:this.runsOffset,
+				onlyFailed:this.runsOnlyFailed,
+				scheduleId:this.runsScheduleId
+			},true).then(
+				res => {
+					this.runs      = res.payload.runs;
+					this.runsTotal = res.payload.total;
+				},
+				this.$root.genericError
+			);
+		},
 		runPgFunction(pgFunctionId,pgFunctionScheduleId) {
 			ws.send('task','run',{
 				clusterMasterOnly:true,
@@ -350,6 +534,12 @@ let MyAdminScheduler = {
 				},
 				this.$root.genericError
 			);
+		},
+		setConfig() {
+			ws.send('config','set',this.configInput,true).then(
+				() => {},
+				this.$root.genericError
+			);
 		}
 	}
 };
\ No newline at end of file
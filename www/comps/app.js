@@ -390,8 +390,10 @@ export default {
 		loginBackground:    (s) => s.$store.getters['local/loginBackground'],
 		loginFavorites:     (s) => s.$store.getters['local/loginFavorites'],
 		loginKeyAes:        (s) => s.$store.getters['local/loginKeyAes'],
+		loginKeySalt:       (s) => s.$store.getters['local/loginKeySalt'],
 		loginOptions:       (s) => s.$store.getters['local/loginOptions'],
 		loginOptionsMobile: (s) => s.$store.getters['local/loginOptionsMobile'],
+		token:              (s) => s.$store.getters['local/token'],
 		languageCodes:      (s) => s.$store.getters['schema/languageCodes'],
 		modules:            (s) => s.$store.getters['schema/modules'],
 		moduleIdMap:        (s) => s.$store.getters['schema/moduleIdMap'],
@@ -413,6 +415,7 @@ export default {
 		isWithoutMenuHeader:(s) => s.$store.getters.isWithoutMenuHeader,
 		keyDownHandlers:    (s) => s.$store.getters.keyDownHandlers,
 		loginEncLocked:     (s) => s.$store.getters.loginEncLocked,
+		loginImpersonator:  (s) => s.$store.getters.loginImpersonator,
 		loginPrivateKey:    (s) => s.$store.getters.loginPrivateKey,
 		loginSessionExpired:(s) => s.$store.getters.loginSessionExpired,
 		loginSessionExpires:(s) => s.$store.getters.loginSessionExpires,
@@ -441,7 +444,9 @@ export default {
 		this.$store.commit('appFunctionsRegister',[
 			{name:'captionsReload',fnc:this.captionsReload},
 			{name:'initPublic',    fnc:this.initPublic},
-			{name:'sessionInvalid',fnc:this.sessionInvalid}
+			{name:'impersonationEnd',  fnc:this.impersonationEnd},
+			{name:'impersonationStart',fnc:this.impersonationStart},
+			{name:'sessionInvalid',    fnc:this.sessionInvalid}
 		]);
 
 		// check for getter options
@@ -520,6 +525,7 @@ export default {
 				case 'schemaLoaded':
 					this.$store.commit('busyRemove');
 					this.$store.commit('captionMapCustom',res.payload.captionMapCustom);
+					this.$store.commit('clientCertAuth',res.payload.clientCertAuth);
 					this.$store.commit('schema/presetIdMapRecordId',res.payload.presetIdMapRecordId);
 					this.initSchema(res.payload.moduleIdMapData);
 				break;
@@ -599,12 +605,47 @@ export default {
 			else
 				this.logoutInSec = 0;
 		},
+		sessionSwitch(token,loginKeyAes,loginKeySalt) {
+			// switch to another login session via its token, used for admin impersonation
+			this.$store.commit('local/loginCachesClear');
+			this.$store.commit('local/loginKeyAes',loginKeyAes);
+			this.$store.commit('local/loginKeySalt',loginKeySalt);
+			this.$store.commit('local/token',token);
+			this.$store.commit('loginPrivateKey',null);
+			this.$store.commit('loginPrivateKeyEnc',null);
+			this.$store.commit('loginPrivateKeyEncBackup',null);
+			this.$store.commit('loginPublicKey',null);
+			this.$store.commit('loginSessionExpires',null);
+			this.$store.commit('loginSwitch',true);
+			
+			// reconnect to re-authenticate with new token
+			this.wsReconnect(true);
+			this.appReady = false;
+			this.$router.push('/');
+		},
+		impersonationEnd() {
+			const s = this.loginImpersonator;
+			if(s === null) return;
+			
+			this.$store.commit('loginImpersonator',null);
+			this.sessionSwitch(s.token,s.loginKeyAes,s.loginKeySalt);
+		},
+		impersonationStart(token) {
+			// keep admin session to return to
+			this.$store.commit('loginImpersonator',{
+				token:this.token,
+				loginKeyAes:this.loginKeyAes,
+				loginKeySalt:this.loginKeySalt
+			});
+			this.sessionSwitch(token,null,null);
+		},
 		sessionInvalid(sessionExpired,returnToHome) {
 			this.$store.commit('local/loginCachesClear');
 			this.$store.commit('local/loginKeyAes',null);
 			this.$store.commit('local/loginKeySalt',null);
 			this.$store.commit('local/loginNoCred',false);
 			this.$store.commit('local/token','');
+			this.$store.commit('loginImpersonator',null);
 			this.$store.commit('loginPrivateKey',null);
 			this.$store.commit('loginPrivateKeyEnc',null);
 			this.$store.commit('loginPrivateKeyEncBackup',null);
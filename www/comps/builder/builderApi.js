@@ -66,6 +66,19 @@ let MyBuilderApiPreview = {
 					</div>
 				</td>
 			</tr>
+			<tr v-if="isGet">
+				<td>OData</td>
+				<td>
+					<div class="row centered gap">
+						<input class="long" disabled="disabled" :value="urlOdata" />
+						<my-button image="copyClipboard.png"
+							@trigger="copyToClipboard(urlOdata)"
+							:captionTitle="capGen.button.copyClipboard"
+						/>
+					</div>
+				</td>
+				<td>{{ capApp.odataHint }}</td>
+			</tr>
 			<tr v-if="isGet || isDelete">
 				<td>{{ capApp.recordId }}</td>
 				<td><input v-model.number="recordId" /></td>
@@ -213,6 +226,7 @@ let MyBuilderApiPreview = {
 			if(s.isGet && s.recordSet) base += `/${s.recordId}`;
 			return base + s.paramsUrl;
 		},
+		urlOdata:(s) => `${location.protocol}//${location.host}/odata/${s.module.name}/${s.name}/v${s.version}/`,
 		
 		// simple
 		isAuth:   (s) => s.call === 'AUTH',
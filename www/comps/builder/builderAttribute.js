@@ -10,10 +10,14 @@ import {
 	getAttributeContentUse,
 	getAttributeIcon,
 	isAttributeBoolean,
+	isAttributeEnum,
 	isAttributeFiles,
 	isAttributeFloat,
+	isAttributeGeo,
 	isAttributeInteger,
+	isAttributeJson,
 	isAttributeNumeric,
+	isAttributeRange,
 	isAttributeRegconfig,
 	isAttributeRelationship,
 	isAttributeRelationship11,
@@ -133,18 +137,23 @@ let MyBuilderAttribute = {
 											<option value="barcode"  :disabled="!isNew && !isBarcode">{{ capApp.option.barcode }}</option>
 											<option value="boolean"  :disabled="!isNew && !isBoolean">{{ capApp.option.boolean }}</option>
 											<option value="files"    :disabled="!isNew && !isFiles">{{ capApp.option.files }}</option>
+											<option value="enum"     :disabled="!isNew && !isEnum && (!isString || isDrawing || isBarcode)">{{ capApp.option.enum }}</option>
+											<option value="geo"      :disabled="!isNew && !isGeo">{{ capApp.option.geo }}</option>
 										</optgroup>
-										<optgroup :label="capApp.datetimes" :disabled="!isNew && !isInteger">
-											<option value="datetime">{{ capApp.option.datetime }}</option>
-											<option value="date">{{ capApp.option.date }}</option>
-											<option value="time">{{ capApp.option.time }}</option>
+										<optgroup :label="capApp.datetimes" :disabled="!isNew && !isInteger && !isRange">
+											<option value="datetime"  :disabled="!isNew && !isInteger">{{ capApp.option.datetime }}</option>
+											<option value="date"      :disabled="!isNew && !isInteger">{{ capApp.option.date }}</option>
+											<option value="time"      :disabled="!isNew && !isInteger">{{ capApp.option.time }}</option>
+											<option value="daterange" :disabled="!isNew && values.content !== 'daterange'">{{ capApp.option.daterange }}</option>
+											<option value="tstzrange" :disabled="!isNew && values.content !== 'tstzrange'">{{ capApp.option.tstzrange }}</option>
 										</optgroup>
 										<optgroup :label="capGen.relationships" :disabled="!isNew && !isRelationship">
 											<option value="relationshipN1">{{ capApp.option.relationshipN1 }}</option>
 											<option value="relationship11">{{ capApp.option.relationship11 }}</option>
 										</optgroup>
-										<optgroup :label="capApp.expert" :disabled="!isNew && !isFloat && !isUuid">
+										<optgroup :label="capApp.expert" :disabled="!isNew && !isFloat && !isUuid && !isRegconfig && !isJson">
 											<option value="float"     :disabled="!isNew && !isFloat">{{ capApp.option.float }}</option>
+											<option value="json"      :disabled="!isNew && !isJson">{{ capApp.option.json }}</option>
 											<option value="uuid"      :disabled="!isNew && !isUuid">{{ capApp.option.uuid }}</option>
 											<option value="regconfig" :disabled="!isNew && !isRegconfig">{{ capApp.option.regconfig }}</option>
 										</optgroup>
@@ -212,6 +221,42 @@ let MyBuilderAttribute = {
 							</tr>
 						</template>
 						
+						<!-- enum values -->
+						<tr v-if="isEnum">
+							<td>{{ capApp.enumValues }}</td>
+							<td>
+								<div class="column gap">
+									<div class="row centered gap" v-for="(v,i) in values.enumValues" :key="i">
+										<input v-model="v.value" :disabled="readonly" :placeholder="capGen.value" />
+										<my-builder-caption
+											v-model="v.captions.attributeEnumValueTitle"
+											:contentName="capGen.title"
+											:language="builderLanguage"
+											:readonly="readonly"
+										/>
+										<my-button image="arrowUp.png"
+											@trigger="enumValueMove(i)"
+											:active="!readonly && i !== 0"
+											:naked="true"
+										/>
+										<my-button image="cancel.png"
+											@trigger="values.enumValues.splice(i,1)"
+											:active="!readonly"
+											:naked="true"
+										/>
+									</div>
+									<div>
+										<my-button image="add.png"
+											@trigger="enumValueAdd"
+											:active="!readonly"
+											:caption="capGen.button.add"
+										/>
+									</div>
+								</div>
+							</td>
+							<td>{{ capApp.enumValuesHint }}</td>
+						</tr>
+						
 						<!-- bigint -->
 						<tr v-if="isInteger && !isTime">
 							<td>{{ isDate || isDatetime ? capApp.bigintDates : capApp.bigint }}</td>
@@ -219,6 +264,13 @@ let MyBuilderAttribute = {
 							<td>{{ isDate || isDatetime ? capApp.bigintDatesHint : capApp.bigintHint }}</td>
 						</tr>
 						
+						<!-- geometry -->
+						<tr v-if="isGeo">
+							<td>{{ capApp.geometry }}</td>
+							<td><my-bool v-model="geometry" :readonly="readonly || (!isNew && valuesOrg.content === 'geometry')" /></td>
+							<td>{{ capApp.geometryHint }}</td>
+						</tr>
+						
 						<!-- double precision -->
 						<tr v-if="isFloat">
 							<td>{{ capApp.doublePrecision }}</td>
@@ -275,7 +327,7 @@ let MyBuilderAttribute = {
 						</tr>
 						
 						<!-- encrypted -->
-						<tr v-if="canEncrypt">
+						<tr v-if="canEncrypt && !isComputed">
 							<td>{{ capApp.encrypted }}</td>
 							<td><my-bool v-model="values.encrypted" :readonly="readonly" /></td>
 							<td>{{ capApp.encryptedHint }}</td>
@@ -288,8 +340,21 @@ let MyBuilderAttribute = {
 							<td>{{ capApp.nullableHint }}</td>
 						</tr>
 						
+						<!-- computed -->
+						<tr v-if="canCompute">
+							<td>{{ capApp.computed }}</td>
+							<td>
+								<textarea class="long"
+									v-model="values.computed"
+									:disabled="readonly"
+									:placeholder="capApp.computedPlaceholder"
+								></textarea>
+							</td>
+							<td>{{ capApp.computedHint }}</td>
+						</tr>
+						
 						<!-- defaults -->
-						<tr v-if="!isId && !isFiles && !isRelationship">
+						<tr v-if="!isId && !isFiles && !isRelationship && !isJson && !isRange && !isGeo && !isComputed">
 							<td>{{ capApp.defaults }}</td>
 							<td>
 								<div class="column gap">
@@ -309,6 +374,52 @@ let MyBuilderAttribute = {
 							<td>{{ capApp.defaultsHint }}</td>
 						</tr>
 						
+						<!-- validation rules -->
+						<template v-if="canValidate">
+							<tr>
+								<td>{{ capApp.validationRequired }}</td>
+								<td><my-bool v-model="values.validation.required" :readonly="readonly" /></td>
+								<td>{{ capApp.validationRequiredHint }}</td>
+							</tr>
+							<tr v-if="hasValidationLimits">
+								<td>{{ capApp.validationLimits }}</td>
+								<td>
+									<div class="row centered gap">
+										<input type="number"
+											@input="setValidationLimit('min',$event.target.value)"
+											:disabled="readonly"
+											:placeholder="capApp.validationMin"
+											:value="values.validation.min"
+										/>
+										<input type="number"
+											@input="setValidationLimit('max',$event.target.value)"
+											:disabled="readonly"
+											:placeholder="capApp.validationMax"
+											:value="values.validation.max"
+										/>
+									</div>
+								</td>
+								<td>{{ isString || isEnum ? capApp.validationLimitsHintLength : capApp.validationLimitsHintValue }}</td>
+							</tr>
+							<tr v-if="isString || isEnum">
+								<td>{{ capApp.validationRegex }}</td>
+								<td><input v-model="validationRegex" :disabled="readonly" placeholder="^[A-Z]{2}\\d+$" /></td>
+								<td>{{ capApp.validationRegexHint }}</td>
+							</tr>
+							<tr v-if="!isFiles">
+								<td>{{ capApp.validationPgFunction }}</td>
+								<td>
+									<select v-model="values.validation.pgFunctionId" :disabled="readonly">
+										<option :value="null">-</option>
+										<option v-for="f in pgFunctionsValidation" :value="f.id">
+											{{ f.moduleId === module.id ? f.name : moduleIdMap[f.moduleId].name + ': ' + f.name }}
+										</option>
+									</select>
+								</td>
+								<td>{{ capApp.validationPgFunctionHint }}</td>
+							</tr>
+						</template>
+						
 						<!-- expert info -->
 						<tr>
 							<td>{{ capApp.content }}</td>
@@ -346,6 +457,10 @@ let MyBuilderAttribute = {
 			get()  { return this.values.content === 'double precision'; },
 			set(v) { this.values.content = v ? 'double precision' : 'real'; }
 		},
+		geometry:{
+			get()  { return this.values.content === 'geometry'; },
+			set(v) { this.values.content = v ? 'geometry' : 'point'; }
+		},
 		usedFor:{
 			get() { return this.getAttributeContentUse(this.values.content, this.values.contentUse); },
 			set(v) {
@@ -408,6 +523,32 @@ let MyBuilderAttribute = {
 						this.values.contentUse = 'default';
 					break;
 					
+					// enum uses
+					case 'enum':
+						this.values.content    = 'enum';
+						this.values.contentUse = 'default';
+						this.values.length     = 0;
+					break;
+					
+					// geospatial uses, falls back to plain points if PostGIS is not available
+					case 'geo':
+						this.values.content    = this.isNew ? 'geometry' : this.values.content;
+						this.values.contentUse = 'default';
+					break;
+					
+					// JSON uses
+					case 'json':
+						this.values.content    = 'jsonb';
+						this.values.contentUse = 'default';
+					break;
+					
+					// range uses
+					case 'daterange': // fallthrough
+					case 'tstzrange':
+						this.values.content    = v;
+						this.values.contentUse = 'default';
+					break;
+					
 					// files uses
 					case 'files':
 						this.values.content    = 'files';
@@ -446,11 +587,26 @@ let MyBuilderAttribute = {
 			}
 		},
 		
+		validationRegex:{
+			get()  { return this.values.validation.regex === null ? '' : this.values.validation.regex; },
+			set(v) { this.values.validation.regex = v === '' ? null : v; }
+		},
+		
 		lengthTitle:(s) => {
 			if(s.isString)  return s.capApp.lengthText;
 			if(s.isNumeric) return s.capApp.lengthNumeric;
 			return s.capApp.lengthFiles;
 		},
+		pgFunctionsValidation:(s) => {
+			let out = [];
+			for(const mod of s.getDependentModules(s.module)) {
+				for(const f of mod.pgFunctions) {
+					if(!f.isTrigger && ['boolean','bool'].includes(f.codeReturns.trim().toLowerCase()))
+						out.push(f);
+				}
+			}
+			return out;
+		},
 		nameTaken:(s) => {
 			for(let a of s.relation.attributes) {
 				if(a.id !== s.attributeId && a.name === s.values.name)
@@ -460,21 +616,34 @@ let MyBuilderAttribute = {
 		},
 		
 		// simple
+		canCompute:    (s) => !s.isId && !s.isFiles && !s.isRelationship && !s.values.encrypted,
+		canValidate:   (s) => !s.isId && !s.isComputed,
 		canEncrypt:    (s) => s.relation.encryption && s.values.content === 'text',
-		canSave:       (s) => !s.readonly && s.hasChanges && !s.nameTaken,
+		canSave:       (s) => !s.readonly && s.hasChanges && !s.nameTaken && (!s.isEnum || s.enumValuesValid),
+		enumValuesValid:(s) => {
+			if(s.values.enumValues.length === 0) return false;
+			const values = s.values.enumValues.map(v => v.value);
+			return !values.includes('') && new Set(values).size === values.length;
+		},
 		hasChanges:    (s) => s.values.name !== '' && JSON.stringify(s.values) !== JSON.stringify(s.valuesOrg),
 		hasLength:     (s) => ['decimal','files','richtext','text','textarea'].includes(s.usedFor),
 		hasLengthFract:(s) => ['decimal'].includes(s.usedFor),
+		hasValidationLimits:(s) => s.isString || s.isEnum || s.isInteger || s.isFloat || s.isNumeric,
+		isComputed:    (s) => s.values.computed !== '',
 		isId:          (s) => !s.isNew && s.values.name === 'id',
 		isNew:         (s) => s.attributeId === null,
 		title:         (s) => s.isNew ? s.capApp.new : s.capApp.edit.replace('{NAME}',s.values.name),
 		
 		// content
 		isBoolean:       (s) => s.isAttributeBoolean(s.values.content),
+		isEnum:          (s) => s.isAttributeEnum(s.values.content),
 		isFiles:         (s) => s.isAttributeFiles(s.values.content),
 		isFloat:         (s) => s.isAttributeFloat(s.values.content),
+		isGeo:           (s) => s.isAttributeGeo(s.values.content),
 		isInteger:       (s) => s.isAttributeInteger(s.values.content),
+		isJson:          (s) => s.isAttributeJson(s.values.content),
 		isNumeric:       (s) => s.isAttributeNumeric(s.values.content),
+		isRange:         (s) => s.isAttributeRange(s.values.content),
 		isRegconfig:     (s) => s.isAttributeRegconfig(s.values.content),
 		isRelationship:  (s) => s.isAttributeRelationship(s.values.content),
 		isRelationship11:(s) => s.isAttributeRelationship11(s.values.content),
@@ -523,10 +692,14 @@ let MyBuilderAttribute = {
 		getFieldMap,
 		getItemTitle,
 		isAttributeBoolean,
+		isAttributeEnum,
 		isAttributeFiles,
 		isAttributeFloat,
+		isAttributeGeo,
 		isAttributeInteger,
+		isAttributeJson,
 		isAttributeNumeric,
+		isAttributeRange,
 		isAttributeRegconfig,
 		isAttributeRelationship,
 		isAttributeRelationship11,
@@ -538,6 +711,21 @@ let MyBuilderAttribute = {
 		changedUsedFor() {
 			if(!this.isRelationship && this.values.relationshipId !== null)
 				this.values.relationshipId = null;
+			
+			if(!this.isEnum)
+				this.values.enumValues = [];
+		},
+		enumValueAdd() {
+			this.values.enumValues.push({
+				id:null,
+				value:'',
+				captions:{
+					attributeEnumValueTitle:{}
+				}
+			});
+		},
+		enumValueMove(i) {
+			this.values.enumValues.splice(i-1,0,this.values.enumValues.splice(i,1)[0]);
 		},
 		handleHotkeys(e) {
 			if(e.ctrlKey && e.key === 's' && this.canSave) {
@@ -566,8 +754,17 @@ let MyBuilderAttribute = {
 					nullable:true,
 					encrypted:false,
 					def:'',
+					computed:'',
 					onUpdate:'NO ACTION',
 					onDelete:'NO ACTION',
+					enumValues:[],
+					validation:{
+						required:false,
+						min:null,
+						max:null,
+						regex:null,
+						pgFunctionId:null
+					},
 					captions:{
 						attributeTitle:{}
 					}
@@ -585,6 +782,9 @@ let MyBuilderAttribute = {
 		resetOrg() {
 			this.valuesOrg = JSON.parse(JSON.stringify(this.values));
 		},
+		setValidationLimit(name,v) {
+			this.values.validation[name] = v === '' ? null : parseFloat(v);
+		},
 		updateDefaultsOption() {
 			switch(this.defaultsOption) {
 				case 'date':     this.values.def = 'EXTRACT(EPOCH FROM CURRENT_DATE)'; break;
@@ -710,6 +910,26 @@ let MyBuilderAttribute = {
 			if(this.values.encrypted && !this.canEncrypt)
 				this.values.encrypted = false;
 			
+			if(!this.canCompute)
+				this.values.computed = '';
+			
+			if(this.isComputed)
+				this.values.def = '';
+			
+			// remove validation rules that do not apply to the attribute content
+			if(!this.canValidate)
+				this.values.validation = { required:false, min:null, max:null, regex:null, pgFunctionId:null };
+			
+			if(!this.hasValidationLimits) {
+				this.values.validation.min = null;
+				this.values.validation.max = null;
+			}
+			if(!this.isString && !this.isEnum)
+				this.values.validation.regex = null;
+			
+			if(this.isFiles)
+				this.values.validation.pgFunctionId = null;
+			
 			ws.sendMultiple([
 				ws.prepare('attribute','set',this.values),
 				ws.prepare('schema','check',{ moduleId:this.module.id })
@@ -184,10 +184,64 @@ let MyBuilderColumnOptions = {
 						<option value="max">{{ capGen.option.aggMax }}</option>
 						<option value="min">{{ capGen.option.aggMin }}</option>
 						<option value="sum">{{ capGen.option.aggSum }}</option>
+						<option value="median">{{ capGen.option.aggMedian }}</option>
+						<option value="percentile">{{ capGen.option.aggPercentile }}</option>
+						<option value="stddev">{{ capGen.option.aggStddev }}</option>
 						<option value="array">{{ capGen.option.aggArray }}</option>
 					</select>
 				</td>
 			</tr>
+			<tr v-if="column.aggregator === 'percentile'">
+				<td>{{ capApp.columnPercentile }}</td>
+				<td>
+					<input
+						@input="setFloat('percentile',$event.target.value)"
+						:value="column.percentile"
+						max="1" min="0" step="0.05" type="number"
+					/>
+				</td>
+			</tr>
+			<template v-if="!isSubQuery">
+				<tr>
+					<td>{{ capApp.columnWindow }}</td>
+					<td>
+						<div class="column gap">
+							<select
+								@input="set('windowFunction',$event.target.value)"
+								:value="column.windowFunction"
+							>
+								<option value="">-</option>
+								<option v-for="f in windowFunctions" :value="f">{{ capApp.option.window[f] }}</option>
+							</select>
+							<span v-if="column.windowFunction !== null">{{ capApp.columnWindowHint }}</span>
+						</div>
+					</td>
+				</tr>
+				<tr v-if="column.windowFunction === 'lag' || column.windowFunction === 'lead'">
+					<td>{{ capApp.columnWindowOffset }}</td>
+					<td>
+						<input
+							@input="setInt('windowOffset',$event.target.value,false)"
+							:value="column.windowOffset"
+							min="1" type="number"
+						/>
+					</td>
+				</tr>
+				<tr v-if="column.windowFunction !== null">
+					<td>{{ capApp.columnWindowPartition }}</td>
+					<td>
+						<select
+							@change="setWindowPartition($event.target.value)"
+							:value="column.windowPartitionAttributeId !== null ? column.windowPartitionAttributeId : ''"
+						>
+							<option value="">-</option>
+							<option v-for="a in relation.attributes.filter(v => !isAttributeFiles(v.content))" :value="a.id">
+								{{ a.name }}
+							</option>
+						</select>
+					</td>
+				</tr>
+			</template>
 			<tr>
 				<td>{{ capGen.options }}</td>
 				<td>
@@ -234,6 +288,9 @@ let MyBuilderColumnOptions = {
 			? false : s.attributeIdMap[s.column.attributeId],
 		indexAttributeIds:(s) => !s.isSubQuery
 			? [] : s.getIndexAttributeIdsByJoins(s.column.query.joins),
+		relation:(s) => s.attribute === false
+			? { attributes:[] } : s.relationIdMap[s.attribute.relationId],
+		windowFunctions:() => ['sum','avg','count','min','max','rank','dense_rank','row_number','lag','lead'],
 		
 		// inputs
 		alignment:{
@@ -267,6 +324,7 @@ let MyBuilderColumnOptions = {
 		
 		// stores
 		attributeIdMap:(s) => s.$store.getters['schema/attributeIdMap'],
+		relationIdMap: (s) => s.$store.getters['schema/relationIdMap'],
 		capApp:        (s) => s.$store.getters.captions.builder.form,
 		capGen:        (s) => s.$store.getters.captions.generic
 	},
@@ -292,6 +350,9 @@ let MyBuilderColumnOptions = {
 			if(allowNull) return this.$emit('set',name,null);
 			else          return this.$emit('set',name,0);
 		},
+		setFloat(name,val) {
+			this.$emit('set',name,val !== '' ? parseFloat(val) : null);
+		},
 		setIndexAttribute(indexAttributeId) {
 			let v = indexAttributeId.split('_');
 			
@@ -303,6 +364,11 @@ let MyBuilderColumnOptions = {
 			this.set('index',parseInt(v[0]));
 			this.set('attributeId',v[1]);
 		},
+		setWindowPartition(attributeId) {
+			// partition attribute comes from the same relation index as the column attribute
+			this.set('windowPartitionAttributeId',attributeId);
+			this.set('windowPartitionIndex',attributeId !== '' ? this.column.index : null);
+		},
 		setStyle(name,val) {
 			let styles = JSON.parse(JSON.stringify(this.column.styles));
 			const pos  = styles.indexOf(name);
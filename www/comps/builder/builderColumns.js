@@ -318,11 +318,16 @@ export let MyBuilderColumnTemplates = {
 				groupBy:false,
 				aggregator:null,
 				distincted:false,
+				percentile:null,
 				subQuery:subQuery,
 				query:this.getQueryTemplate(),
 				hidden:false,
 				onMobile:true,
 				styles:['wrap'],
+				windowFunction:null,
+				windowOffset:1,
+				windowPartitionAttributeId:null,
+				windowPartitionIndex:null,
 				captions:{
 					columnTitle:{}
 				}
@@ -401,8 +401,10 @@ let MyBuilderNew = {
 						name:this.inputs.name,
 						comment:null,
 						encryption:this.inputs.encryption,
+						recordVersion:false,
 						retentionCount:null,
 						retentionDays:null,
+						trashDays:null,
 						policies:[]
 					};
 				break;
@@ -420,6 +422,7 @@ let MyBuilderNew = {
 						accessClientEvents:{},
 						accessCollections:{},
 						accessMenus:{},
+						accessPgFunctions:{},
 						accessRelations:{}
 					};
 				break;
@@ -23,14 +23,20 @@ let MyBuilderPgFunctionItemSchedule = {
 		
 		<div class="line">
 			<!-- interval at which to run -->
-			<select class="dynamic" v-model="runOnce" :disabled="readonly">
+			<select class="dynamic" v-model="runType" :disabled="readonly">
 				<optgroup :label="capApp.runType">
-					<option :value="true">{{ capApp.runOnce }}</option>
-					<option :value="false">{{ capApp.runRegular }}</option>
+					<option value="once">{{ capApp.runOnce }}</option>
+					<option value="regular">{{ capApp.runRegular }}</option>
+					<option value="cron">{{ capApp.runCron }}</option>
 				</optgroup>
 			</select>
 			
-			<template v-if="intervalType !== 'once'">
+			<!-- cron expression (minute hour day month weekday) -->
+			<template v-if="intervalType === 'cron'">
+				<input v-model="cron" :disabled="readonly" :placeholder="capApp.cronPlaceholder" :title="capApp.cronHint" />
+			</template>
+			
+			<template v-if="intervalType !== 'once' && intervalType !== 'cron'">
 				<span>{{ capApp.intervalEvery }}</span>
 				<input class="dynamic" v-model.number="intervalValue" :disabled="readonly" />
 				
@@ -71,6 +77,30 @@ let MyBuilderPgFunctionItemSchedule = {
 				:naked="true"
 			/>
 		</div>
+		
+		<div class="line" v-if="intervalType !== 'once'">
+			<!-- time zone that target days/times refer to -->
+			<template v-if="!['seconds','minutes','hours'].includes(intervalType)">
+				<span>{{ capApp.timezone }}</span>
+				<input list="builder-pg-function-timezones"
+					v-model="timezone"
+					:disabled="readonly"
+					:placeholder="capApp.timezoneServer"
+					:title="capApp.timezoneHint"
+				/>
+				<datalist id="builder-pg-function-timezones">
+					<option v-for="tz in timezones" :value="tz" />
+				</datalist>
+			</template>
+			
+			<!-- handling of runs missed during downtime -->
+			<span>{{ capApp.catchUp }}</span>
+			<select class="dynamic" v-model="catchUp" :disabled="readonly" :title="capApp.catchUpHint">
+				<option value="skip">{{ capApp.option.catchUpSkip }}</option>
+				<option value="once">{{ capApp.option.catchUpOnce }}</option>
+				<option value="all" >{{ capApp.option.catchUpAll }}</option>
+			</select>
+		</div>
 	</div>`,
 	props:{
 		modelValue:{ type:Object,  required:true },
@@ -95,6 +125,14 @@ let MyBuilderPgFunctionItemSchedule = {
 			get()  { return this.modelValue.atSecond; },
 			set(v) { this.update('atSecond',v); }
 		},
+		catchUp:{
+			get()  { return this.modelValue.catchUp; },
+			set(v) { this.update('catchUp',v); }
+		},
+		cron:{
+			get()  { return this.modelValue.cron; },
+			set(v) { this.update('cron',v); }
+		},
 		intervalType:{
 			get()  { return this.modelValue.intervalType; },
 			set(v) { this.update('intervalType',v); }
@@ -103,13 +141,22 @@ let MyBuilderPgFunctionItemSchedule = {
 			get()  { return this.modelValue.intervalValue; },
 			set(v) { this.update('intervalValue',v); }
 		},
-		runOnce:{
-			get()  { return this.intervalType === 'once'; },
-			set(v) {
-				if(v) this.update('intervalType','once');
-				else  this.update('intervalType','days');
-			}
+		runType:{
+			get() {
+				if(this.intervalType === 'once' || this.intervalType === 'cron')
+					return this.intervalType;
+				
+				return 'regular';
+			},
+			set(v) { this.update('intervalType',v === 'regular' ? 'days' : v); }
 		},
+		timezone:{
+			get()  { return this.modelValue.timezone; },
+			set(v) { this.update('timezone',v); }
+		},
+		
+		// simple
+		timezones:(s) => typeof Intl.supportedValuesOf === 'function' ? Intl.supportedValuesOf('timeZone') : [],
 		
 		// stores
 		capApp:(s) => s.$store.getters.captions.builder.function
@@ -635,8 +682,8 @@ let MyBuilderPgFunction = {
 				'abort_show_message','clean_up_e2ee_keys','file_export','file_export_text','file_import',
 				'file_import_text','file_link','file_text_read','file_text_write','file_unlink','files_get',
 				'get_e2ee_data_key_enc','get_language_code','get_name','get_public_hostname','get_role_ids',
-				'get_user_id','has_role','has_role_any','log_error','log_info','log_warning','mail_delete',
-				'mail_delete_after_attach','mail_get_next','mail_send','rest_call','update_collection',
+				'get_user_id','has_role','has_role_any','job_enqueue','log_error','log_info','log_warning','mail_delete',
+				'mail_delete_after_attach','mail_get_delivery','mail_get_next','mail_get_thread','mail_send','mail_send_template','rest_call','update_collection',
 				'user_meta_set','user_sync_all'
 			],
 			showHolderFncInstance:false,
@@ -767,7 +814,10 @@ let MyBuilderPgFunction = {
 				atHour:12,
 				atDay:1,
 				intervalType:'days',
-				intervalValue:3
+				intervalValue:3,
+				cron:'',
+				timezone:'',
+				catchUp:'once'
 			});
 		},
 		reset() {
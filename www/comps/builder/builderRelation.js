@@ -220,6 +220,25 @@ const MyBuilderRelation = {
 								<td><my-bool v-model="encryption" :readonly="true" /></td>
 								<td>{{ capApp.encryptionHint }}</td>
 							</tr>
+							<tr>
+								<td>{{ capApp.recordVersion }}</td>
+								<td><my-bool v-model="recordVersion" :readonly="readonly" /></td>
+								<td>{{ capApp.recordVersionHint }}</td>
+							</tr>
+							<tr>
+								<td>{{ capApp.trash }}</td>
+								<td>
+									<table>
+										<tbody>
+											<tr>
+												<td>{{ capApp.trashDays }}</td>
+												<td><input v-model.number="trashDays" :disabled="readonly || encryption" /></td>
+											</tr>
+										</tbody>
+									</table>
+								</td>
+								<td>{{ capApp.trashHint }}</td>
+							</tr>
 							<tr>
 								<td>{{ capApp.retention }}</td>
 								<td>
@@ -538,8 +557,10 @@ const MyBuilderRelation = {
 			indexIdEdit:false,
 			name:'',
 			policies:[],
+			recordVersion:false,
 			retentionCount:null,
 			retentionDays:null,
+			trashDays:null,
 			
 			// states
 			nameFilter:'',
@@ -661,8 +682,10 @@ const MyBuilderRelation = {
 		hasChanges:(s) => s.name          !== s.relation.name
 			|| s.comment                  !== s.relation.comment
 			|| s.encryption               !== s.relation.encryption
+			|| s.recordVersion            !== s.relation.recordVersion
 			|| s.retentionCount           !== s.relation.retentionCount
 			|| s.retentionDays            !== s.relation.retentionDays
+			|| s.trashDays                !== s.relation.trashDays
 			|| JSON.stringify(s.policies) !== JSON.stringify(s.relation.policies),
 		
 		// simple
@@ -745,8 +768,10 @@ const MyBuilderRelation = {
 			this.name           = this.relation.name;
 			this.comment        = this.relation.comment;
 			this.encryption     = this.relation.encryption;
+			this.recordVersion  = this.relation.recordVersion;
 			this.retentionCount = this.relation.retentionCount;
 			this.retentionDays  = this.relation.retentionDays;
+			this.trashDays      = this.relation.trashDays;
 			this.policies       = JSON.parse(JSON.stringify(this.relation.policies));
 			
 			if(this.tabTarget === 'data')
@@ -797,8 +822,10 @@ const MyBuilderRelation = {
 				name:this.name,
 				comment:this.comment === '' ? null : this.comment,
 				encryption:this.relation.encryption,
+				recordVersion:this.recordVersion,
 				retentionCount:this.retentionCount === '' ? null : this.retentionCount,
 				retentionDays:this.retentionDays === '' ? null : this.retentionDays,
+				trashDays:this.trashDays === '' ? null : this.trashDays,
 				policies:this.policies
 			},true).then(
 				() => {
@@ -296,8 +296,8 @@ const MyBuilderRole = {
 			<div class="column grow">
 				<my-tabs
 					v-model="tabTarget"
-					:entries="['data','menus','collections','searchBars','widgets','apis','clientEvents']"
-					:entriesIcon="['images/database.png','images/menu.png','images/tray.png','images/search.png','images/tiles.png','images/api.png','images/screen.png']"
+					:entries="['data','menus','collections','searchBars','widgets','apis','pgFunctions','clientEvents']"
+					:entriesIcon="['images/database.png','images/menu.png','images/tray.png','images/search.png','images/tiles.png','images/api.png','images/codeDatabase.png','images/screen.png']"
 					:entriesText="tabCaptions"
 				/>
 				
@@ -470,6 +470,28 @@ const MyBuilderRole = {
 						</tbody>
 					</table>
 					
+					<table class="generic-table sticky-top default-inputs" v-if="tabTarget === 'pgFunctions'">
+						<thead>
+							<tr>
+								<th :title="capApp.pgFunctionsHint">{{ capGen.pgFunction }}</th>
+								<th>{{ capApp.access }}</th>
+								<th class="maximum"></th>
+							</tr>
+						</thead>
+						<tbody>
+							<my-builder-role-access-simple
+								v-for="e in pgFunctionsRpc"
+								@apply="(...args) => apply('pgFunction',args[0],args[1])"
+								:builderLanguage="builderLanguage"
+								:id="e.id"
+								:idMapAccess="accessPgFunctions"
+								:key="role.id + '_' + e.id"
+								:name="e.name"
+								:readonly="readonly"
+							/>
+						</tbody>
+					</table>
+					
 					<table class="generic-table sticky-top default-inputs" v-if="tabTarget === 'searchBars'">
 						<thead>
 							<tr>
@@ -631,6 +653,7 @@ const MyBuilderRole = {
 			accessClientEvents:{},
 			accessCollections:{},
 			accessMenus:{},
+			accessPgFunctions:{},
 			accessRelations:{},
 			accessSearchBars:{},
 			accessWidgets:{},
@@ -659,6 +682,7 @@ const MyBuilderRole = {
 			|| JSON.stringify(s.accessClientEvents) !== JSON.stringify(s.role.accessClientEvents)
 			|| JSON.stringify(s.accessCollections)  !== JSON.stringify(s.role.accessCollections)
 			|| JSON.stringify(s.accessMenus)        !== JSON.stringify(s.role.accessMenus)
+			|| JSON.stringify(s.accessPgFunctions)  !== JSON.stringify(s.role.accessPgFunctions)
 			|| JSON.stringify(s.accessRelations)    !== JSON.stringify(s.role.accessRelations)
 			|| JSON.stringify(s.accessSearchBars)   !== JSON.stringify(s.role.accessSearchBars)
 			|| JSON.stringify(s.accessWidgets)      !== JSON.stringify(s.role.accessWidgets)
@@ -687,10 +711,14 @@ const MyBuilderRole = {
 				`${s.capGen.searchBars} (${s.module.searchBars.length})`,
 				`${s.capGen.widgets} (${s.module.widgets.length})`,
 				`${s.capGen.apis} (${s.module.apis.length})`,
+				`${s.capGen.pgFunctions} (${s.pgFunctionsRpc.length})`,
 				`${s.capGen.clientEvents} (${s.module.clientEvents.length})`
 			];
 		},
 		
+		// backend functions that can be called via REST RPC
+		pgFunctionsRpc:(s) => s.module.pgFunctions.filter(v => v.isFrontendExec && !v.isTrigger),
+		
 		// simple
 		canSave:   (s) => s.hasChanges && !s.readonly,
 		isEveryone:(s) => s.role.name === 'everyone',
@@ -717,6 +745,7 @@ const MyBuilderRole = {
 				case 'clientEvent': this.accessClientEvents[id] = access; break;
 				case 'collection':  this.accessCollections[id]  = access; break;
 				case 'menu':        this.accessMenus[id]        = access; break;
+				case 'pgFunction':  this.accessPgFunctions[id]  = access; break;
 				case 'relation':    this.accessRelations[id]    = access; break;
 				case 'searchBar':   this.accessSearchBars[id]   = access; break;
 				case 'widget':      this.accessWidgets[id]      = access; break;
@@ -740,6 +769,7 @@ const MyBuilderRole = {
 			this.accessClientEvents = JSON.parse(JSON.stringify(this.role.accessClientEvents));
 			this.accessCollections  = JSON.parse(JSON.stringify(this.role.accessCollections));
 			this.accessMenus        = JSON.parse(JSON.stringify(this.role.accessMenus));
+			this.accessPgFunctions  = JSON.parse(JSON.stringify(this.role.accessPgFunctions));
 			this.accessRelations    = JSON.parse(JSON.stringify(this.role.accessRelations));
 			this.accessSearchBars   = JSON.parse(JSON.stringify(this.role.accessSearchBars));
 			this.accessWidgets      = JSON.parse(JSON.stringify(this.role.accessWidgets));
@@ -817,6 +847,7 @@ const MyBuilderRole = {
 				accessClientEvents:this.accessClientEvents,
 				accessCollections:this.accessCollections,
 				accessMenus:this.accessMenus,
+				accessPgFunctions:this.accessPgFunctions,
 				accessRelations:this.accessRelations,
 				accessSearchBars:this.accessSearchBars,
 				accessWidgets:this.accessWidgets,
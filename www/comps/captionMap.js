@@ -741,13 +741,23 @@ const MyCaptionMap = {
 		},
 		captionsAttributesByRelations:(s) => {
 			let relIdMap = {};
-			for(let atrId in s.captionMap.attributeIdMap) {
-				const atr   = s.attributeIdMap[atrId];
-				const relId = atr.relationId;
-				if(relIdMap[relId] === undefined)
-					relIdMap[relId] = [];
-				
-				relIdMap[relId].push(s.makeItem(atrId,atr.name,s.captionMap.attributeIdMap[atrId],[]));
+			for(const rel of s.module.relations) {
+				for(const atr of rel.attributes) {
+					// enum values are children of their attribute
+					let childCaptions = [];
+					for(const v of atr.enumValues) {
+						if(s.captionMap.attributeEnumValueIdMap[v.id] !== undefined)
+							childCaptions.push(s.makeItem(v.id,v.value,s.captionMap.attributeEnumValueIdMap[v.id],[]));
+					}
+					
+					if(childCaptions.length === 0 && s.captionMap.attributeIdMap[atr.id] === undefined)
+						continue;
+					
+					if(relIdMap[rel.id] === undefined)
+						relIdMap[rel.id] = [];
+					
+					relIdMap[rel.id].push(s.makeItem(atr.id,atr.name,s.captionMap.attributeIdMap[atr.id],childCaptions));
+				}
 			}
 			
 			// return sorted by relation and attribute names
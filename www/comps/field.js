@@ -21,8 +21,12 @@ import {
 	getIndexAttributeId,
 	isAttributeBoolean,
 	isAttributeDecimal,
+	isAttributeEnum,
 	isAttributeFiles,
+	isAttributeGeo,
 	isAttributeInteger,
+	isAttributeJson,
+	isAttributeRange,
 	isAttributeRelationship,
 	isAttributeRegconfig,
 	isAttributeString,
@@ -399,6 +403,21 @@ export default {
 					:selected="value"
 				/>
 				
+				<!-- enum input -->
+				<my-input-select
+					v-if="isEnum"
+					@dropdown-show="dropdownSet"
+					@updated-text-input="enumInput = $event"
+					@update:selected="value = $event;enumInput = ''"
+					:dropdownShow="dropdownShow"
+					:inputTextSet="enumCaption"
+					:nakedIcons="true"
+					:options="enumOptions"
+					:placeholder="capGen.threeDots"
+					:readonly="isReadonly"
+					:selected="value"
+				/>
+				
 				<!-- password show action -->
 				<my-button
 					v-if="isPassword"
@@ -435,6 +454,33 @@ export default {
 					:disabled="isReadonly"
 				></textarea>
 				
+				<!-- geospatial input, points via longitude/latitude -->
+				<div class="row gap" v-if="isGeo && isGeoPoint">
+					<input class="input" data-is-input="1" step="any" type="number"
+						v-model="valueGeoLongitude"
+						@click="click"
+						:class="{ invalid:showInvalid }"
+						:disabled="isReadonly"
+						:placeholder="capGen.longitude"
+					/>
+					<input class="input" data-is-input="1" step="any" type="number"
+						v-model="valueGeoLatitude"
+						@click="click"
+						:class="{ invalid:showInvalid }"
+						:disabled="isReadonly"
+						:placeholder="capGen.latitude"
+					/>
+				</div>
+				
+				<!-- JSON input (also other geometries as GeoJSON) -->
+				<textarea class="input textarea" data-is-input="1"
+					v-if="isJson || (isGeo && !isGeoPoint)"
+					v-model="valueJson"
+					@click="click"
+					:class="{ invalid:showInvalid }"
+					:disabled="isReadonly"
+				></textarea>
+				
 				<!-- richtext input -->
 				<my-input-richtext
 					v-if="isRichtext"
@@ -502,6 +548,21 @@ export default {
 					:unixTo="valueAlt"
 				/>
 				
+				<!-- date / datetime range input -->
+				<my-input-date
+					v-if="isRange"
+					@dropdown-show="dropdownSet"
+					@set-unix-from="setValueRange(0,$event)"
+					@set-unix-to="setValueRange(1,$event)"
+					:dropdownShow="dropdownShow"
+					:isDate="true"
+					:isTime="contentData === 'tstzrange'"
+					:isRange="true"
+					:isReadonly="isReadonly"
+					:unixFrom="value !== null ? value[0] : null"
+					:unixTo="value !== null ? value[1] : null"
+				/>
+				
 				<!-- drawing input -->
 				<my-input-drawing
 					v-if="isDrawing"
@@ -706,6 +767,8 @@ export default {
 	data() {
 		return {
 			popUpFormInline:null,         // inline form for some field types (list)
+			enumInput:'',
+			jsonInput:null,               // JSON fields only: invalid JSON text while being edited
 			regconfigInput:'',
 			showPassword:false,           // for password fields
 			tabIndexFieldIdMapCounter:{}, // tabs only: counter (by tab index + field ID) of child values (like combined list row counts)
@@ -751,6 +814,38 @@ export default {
 			}
 		},
 		
+		// JSON field value as text, invalid JSON is kept as text until corrected
+		valueJson:{
+			get() {
+				if(this.jsonInput !== null) return this.jsonInput;
+				return this.value === null ? '' : JSON.stringify(this.value,null,2);
+			},
+			set(v) {
+				if(v === '') {
+					this.jsonInput = null;
+					this.value     = null;
+					return;
+				}
+				try {
+					this.value     = JSON.parse(v);
+					this.jsonInput = null;
+				}
+				catch(e) {
+					this.jsonInput = v;
+				}
+			}
+		},
+		
+		// geospatial point coordinates
+		valueGeoLatitude:{
+			get()  { return this.value === null || this.value.coordinates[1] === null ? '' : this.value.coordinates[1]; },
+			set(v) { this.setValueGeoCoordinate(1,v); }
+		},
+		valueGeoLongitude:{
+			get()  { return this.value === null || this.value.coordinates[0] === null ? '' : this.value.coordinates[0]; },
+			set(v) { this.setValueGeoCoordinate(0,v); }
+		},
+		
 		// field value for alternative data attribute (not available for variables)
 		valueAlt:{
 			get() {
@@ -869,6 +964,20 @@ export default {
 			}
 			return false;
 		},
+		enumCaption:(s) => {
+			if(s.value === null) return null;
+			const v = s.attribute.enumValues.find(v => v.value === s.value);
+			return v === undefined ? s.value : s.getCaption('attributeEnumValueTitle',s.attribute.moduleId,v.id,v.captions,v.value);
+		},
+		enumOptions:(s) => {
+			let out = [];
+			for(const v of s.attribute.enumValues) {
+				const name = s.getCaption('attributeEnumValueTitle',s.attribute.moduleId,v.id,v.captions,v.value);
+				if((s.enumInput === null || s.enumInput === '' || name.toLowerCase().includes(s.enumInput.toLowerCase())) && s.value !== v.value)
+					out.push({id:v.value,name:name});
+			}
+			return out;
+		},
 		regconfigOptions:(s) => {
 			let out = [];
 			for(let d of s.searchDictionaries) {
@@ -904,11 +1013,11 @@ export default {
 			if(s.isData && !s.isVariable && state === 'default') {
 				if(!s.inputCanWrite) state = 'readonly';
 				
-				if(s.inputCanWrite                          // can write
-					&& !s.isBulkUpdate                      // bulk update is always optional
-					&& !s.attribute.nullable                // value not optional
-					&& !s.isRelationship1N                  // not 0...n partners
-					&& (!s.isNew || s.attribute.def === '') // existing record or new one with no defaults
+				if(s.inputCanWrite                                                  // can write
+					&& !s.isBulkUpdate                                              // bulk update is always optional
+					&& (!s.attribute.nullable || s.attribute.validation.required)   // value not optional
+					&& !s.isRelationship1N                                          // not 0...n partners
+					&& (!s.isNew || s.attribute.def === '')                         // existing record or new one with no defaults
 				) state = 'required';
 			}
 
@@ -982,6 +1091,10 @@ export default {
 			// block access to protected preset value
 			if(s.presetValue !== false && s.presetValue.protected)
 				return false;
+			
+			// computed values are generated by the database
+			if(s.attribute.computed !== '')
+				return false;
 
 			// check join permissions
 			const join = s.joinsIndexMap[s.field.index];
@@ -1039,10 +1152,12 @@ export default {
 			&& !s.isTextarea     && !s.isRegconfig
 			&& !s.isRelationship && !s.isRichtext
 			&& !s.isUuid         && !s.isBarcode
-			&& !s.isRating,
+			&& !s.isRating       && !s.isEnum
+			&& !s.isJson         && !s.isRange
+			&& !s.isGeo,
 		isLineSingle:(s) => s.isData && (
 			s.isLineInput || s.isBoolean || s.isColor || s.isDateInput || s.isSlider || s.isRating ||
-			s.isLogin || s.isRegconfig || s.isUuid || (s.isRelationship && !s.isRelationship1N)
+			s.isLogin || s.isRegconfig || s.isUuid || s.isEnum || s.isRange || (s.isGeo && s.isGeoPoint) || (s.isRelationship && !s.isRelationship1N)
 		),
 		isValid:(s) => {
 			if(!s.isData || s.isReadonly) return true;
@@ -1085,6 +1200,12 @@ export default {
 				if(s.isInteger && !/^-?\d+$/.test(s.value))           return false;
 			}
 			
+			if((s.isJson || s.isGeo) && s.jsonInput !== null)
+				return false;
+			
+			if(s.isGeo && s.isGeoPoint && s.value.coordinates.includes(null))
+				return false;
+			
 			if(s.isUuid && !/^[0-9a-f]{8}\-[0-9a-f]{4}\-[0-9a-f]{4}\-[0-9a-f]{4}\-[0-9a-f]{12}$/i.test(s.value))
 				return false;
 			
@@ -1101,7 +1222,7 @@ export default {
 		dataOptions: (s) => s.entityIdMapEffect.field[s.field.id] === undefined ? 0 : s.entityIdMapEffect.field[s.field.id].data,
 		dropdownShow:(s) => s.dropdownElm === s.$refs.content,
 		hasCaption:  (s) => !s.isKanban && !s.isCalendar && !s.isAlone && s.caption !== '',
-		hasIntent:   (s) => !s.isChart && !s.isKanban && !s.isCalendar && !s.isTabs && !s.isList && !s.isDrawing && !s.isFiles && !s.isBarcode && !s.isTextarea && !s.isRichtext,
+		hasIntent:   (s) => !s.isChart && !s.isKanban && !s.isCalendar && !s.isTabs && !s.isList && !s.isDrawing && !s.isFiles && !s.isBarcode && !s.isTextarea && !s.isRichtext && !s.isJson,
 		inputRegex:  (s) => !s.isData || s.isVariable || s.field.regexCheck === null ? null : new RegExp(s.field.regexCheck),
 		link:        (s) => !s.isData ? false : s.getLinkMeta(s.field.display,s.value),
 		loginOptions:(s) => s.fieldIdMapOptions[s.field.id] === undefined ? {} : s.fieldIdMapOptions[s.field.id],
@@ -1153,10 +1274,15 @@ export default {
 		isDateInput:     (s) => s.isData && s.isDatetime || s.isDate || s.isTime,
 		isDateRange:     (s) => s.isDateInput && !s.isVariable && s.field.attributeIdAlt !== null,
 		isDecimal:       (s) => s.isData && s.isAttributeDecimal(s.contentData),
+		isEnum:          (s) => s.isData && !s.isVariable && s.isAttributeEnum(s.contentData),
 		isDrawing:       (s) => s.isData && s.contentUse === 'drawing',
 		isFiles:         (s) => s.isData && s.isAttributeFiles(s.contentData),
 		isIframe:        (s) => s.isData && s.contentUse === 'iframe',
 		isInteger:       (s) => s.isData && s.isAttributeInteger(s.contentData),
+		isGeo:           (s) => s.isData && s.isAttributeGeo(s.contentData),
+		isGeoPoint:      (s) => s.isGeo && (s.value === null || s.value.type === 'Point'),
+		isJson:          (s) => s.isData && s.isAttributeJson(s.contentData),
+		isRange:         (s) => s.isData && s.isAttributeRange(s.contentData),
 		isRelationship:  (s) => s.isData && s.isAttributeRelationship(s.contentData),
 		isRelationship1N:(s) => s.isRelationship && (s.contentData === '1:n' || (s.field.outsideIn === true && s.contentData === 'n:1')),
 		isRegconfig:     (s) => s.isData && s.isAttributeRegconfig(s.contentData),
@@ -1203,8 +1329,12 @@ export default {
 		hasAccessToAttribute,
 		isAttributeBoolean,
 		isAttributeDecimal,
+		isAttributeEnum,
 		isAttributeFiles,
+		isAttributeGeo,
 		isAttributeInteger,
+		isAttributeJson,
+		isAttributeRange,
 		isAttributeRelationship,
 		isAttributeRegconfig,
 		isAttributeString,
@@ -1397,6 +1527,18 @@ export default {
 			if(this.field.jsFunctionId !== null)
 				this.$emit('execute-function',this.field.jsFunctionId);
 		},
+		setValueGeoCoordinate(position,v) {
+			let coordinates = this.value !== null ? [...this.value.coordinates] : [null,null];
+			coordinates[position] = v === '' || v === null ? null : parseFloat(v);
+			
+			this.value = coordinates[0] === null && coordinates[1] === null
+				? null : { type:'Point', coordinates:coordinates };
+		},
+		setValueRange(position,unix) {
+			let range = this.value !== null ? [...this.value] : [null,null];
+			range[position] = unix;
+			this.value = range[0] === null && range[1] === null ? null : range;
+		},
 		triggerButton(middleClick) {
 			if(this.field.openForm !== null)
 				this.openForm([],[],middleClick,null);
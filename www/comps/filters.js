@@ -112,6 +112,11 @@ const MyFilterOperator = {
 			<optgroup :label="capApp.operatorsFts">
 				<option value="@@" :title="capApp.option.operator.fts">@@</option>
 			</optgroup>
+			
+			<optgroup :label="capApp.operatorsSpatial">
+				<option value="WITHIN DISTANCE" :title="capApp.option.operator.withinDistance">WITHIN DISTANCE</option>
+				<option value="WITHIN BOX"      :title="capApp.option.operator.withinBox"     >WITHIN BOX</option>
+			</optgroup>
 		</template>
 		
 		<!-- operators in user mode -->
@@ -367,6 +372,18 @@ const MyFilterSide = {
 					:nestingLevels="nestingLevels"
 				/>
 				
+				<!-- attribute aggregator input, filter applies to grouped results -->
+				<select v-model="aggregator" v-if="isAttribute && builderMode" :title="capApp.aggregatorHint">
+					<option value=""          >-</option>
+					<option value="avg"       >{{ capGen.option.aggAvg }}</option>
+					<option value="count"     >{{ capGen.option.aggCount }}</option>
+					<option value="max"       >{{ capGen.option.aggMax }}</option>
+					<option value="median"    >{{ capGen.option.aggMedian }}</option>
+					<option value="min"       >{{ capGen.option.aggMin }}</option>
+					<option value="stddev"    >{{ capGen.option.aggStddev }}</option>
+					<option value="sum"       >{{ capGen.option.aggSum }}</option>
+				</select>
+				
 				<!-- collection input -->
 				<select v-model="collectionId" v-if="!columnsMode && isCollection">
 					<option :value="null">-</option>
@@ -680,6 +697,10 @@ const MyFilterSide = {
 			get()  { let v = this.modelValue.queryAggregator; return v !== null ? v : ''; },
 			set(v) { this.set('queryAggregator',v === '' ? null : v); }
 		},
+		aggregator:{
+			get()  { let v = this.modelValue.aggregator; return v !== null && v !== undefined ? v : ''; },
+			set(v) { this.set('aggregator',v === '' ? null : v); }
+		},
 		roleId:{
 			get()  { return this.modelValue.roleId; },
 			set(v) { this.set('roleId',v); }
@@ -782,6 +803,7 @@ const MyFilterSide = {
 			if(v.content !== 'role')     v.roleId     = null; 
 			if(v.content !== 'value')    v.value      = null;
 			if(v.content !== 'variable') v.variableId = null;
+			if(v.content !== 'attribute') v.aggregator = null;
 			
 			if(v.content !== 'subQuery') {
 				v.query           = null;
@@ -288,6 +288,7 @@ export default {
 		<my-form-log
 			v-if="showLog"
 			@close-log="toggleLog"
+			@record-reverted="get"
 			:entityIdMapEffect
 			:fieldIdMapData
 			:fieldIdMapProcessed
@@ -296,6 +297,7 @@ export default {
 			:indexMapRecordKey
 			:joinsIndexMap
 			:moduleId
+			:readonly="buttonsReadonly || !mayUpdate || hasChanges"
 			:values
 			:variableIdMapLocal
 		/>
@@ -379,6 +381,7 @@ export default {
 			},
 			indexMapRecordId:{},          // record IDs for form, key: relation index
 			indexMapRecordKey:{},         // record en-/decryption keys, key: relation index
+			indexMapRecordVersion:{},     // record versions for concurrency checks, key: relation index
 			indexesNoDel:[],              // relation indexes with no DEL permission (via relation policy)
 			indexesNoSet:[],              // relation indexes with no SET permission (via relation policy)
 			loginIdsEncryptFor:[],        // login IDs for which data keys are encrypted (e2ee), for current form relations/records
@@ -1044,6 +1047,7 @@ export default {
 			this.indexesNoSet              = [];
 			this.indexMapRecordId          = {};
 			this.indexMapRecordKey         = {};
+			this.indexMapRecordVersion     = {};
 			this.fieldIdsTouched           = [];
 		},
 		releaseLoadingOnNextTick() {
@@ -1120,6 +1124,9 @@ export default {
 			for(let index in row.indexRecordIds) {
 				this.indexMapRecordId[index] = row.indexRecordIds[index];
 				
+				if(row.indexRecordVersions !== null && typeof row.indexRecordVersions[index] !== 'undefined')
+					this.indexMapRecordVersion[index] = row.indexRecordVersions[index];
+				
 				const indexInt = parseInt(index);
 				let pos = this.indexesNoDel.indexOf(indexInt);
 				if(pos === -1 && row.indexesPermNoDel.includes(indexInt))
@@ -1764,6 +1771,8 @@ export default {
 					attributeId:j.attributeId,
 					indexFrom:j.indexFrom,
 					recordId:j.recordId,
+					recordVersion:!isNew && typeof this.indexMapRecordVersion[index] !== 'undefined'
+						? this.indexMapRecordVersion[index] : null,
 					attributes:[],
 					encKeysSet:encLoginKeys
 				};
@@ -1783,6 +1792,9 @@ export default {
 				if(!isNew && this.valueIsEqual(this.values[k],this.valuesOrg[k]))
 					continue;
 				
+				// ignore computed values, they are generated by the database
+				if(this.attributeIdMap[d.attributeId].computed !== '') continue;
+				
 				// ignore values if join settings disallow creation/update
 				if(!j.applyCreate && j.recordId === 0) continue;
 				if(!j.applyUpdate && j.recordId !== 0) continue;
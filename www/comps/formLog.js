@@ -54,6 +54,13 @@ export default {
 				</div>
 				
 				<div class="log-fields" v-if="logsShown.includes(i)">
+					<div v-if="!readonly">
+						<my-button image="undo.png"
+							@trigger="revertAsk(l.dateChange)"
+							:caption="capApp.button.revert"
+							:naked="true"
+						/>
+					</div>
 					<template v-for="(v,ia) in l.values">
 						
 						<!-- regular attribute logs -->
@@ -131,10 +138,11 @@ export default {
 		indexMapRecordKey:  { type:Object,  required:true },
 		joinsIndexMap:      { type:Object,  required:true },
 		moduleId:           { type:String,  required:true },
+		readonly:           { type:Boolean, required:true },
 		values:             { type:Object,  required:true },
 		variableIdMapLocal: { type:Object,  required:true }
 	},
-	emits:['close-log'],
+	emits:['close-log','record-reverted'],
 	watch:{
 		formLoading(v) {
 			if(!v) this.get();
@@ -236,8 +244,46 @@ export default {
 			this.logs      = [];
 			this.logsShown = [];
 		},
+		revertAsk(dateChange) {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.dialog.revert,
+				buttons:[{
+					cancel:true,
+					caption:this.capApp.button.revert,
+					exec:() => this.revert(dateChange),
+					keyEnter:true,
+					image:'undo.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
 		
 		// backend calls
+		revert(dateChange) {
+			let requests = [];
+			for(let index in this.joinsIndexMap) {
+				const j = this.joinsIndexMap[index];
+				
+				// only revert records that can be updated on this form
+				if(j.applyUpdate && j.recordId !== 0)
+					requests.push(ws.prepare('data','revertLog',{
+						relationId:j.relationId,
+						recordId:j.recordId,
+						date:dateChange
+					}));
+			}
+			
+			if(requests.length === 0)
+				return;
+			
+			ws.sendMultiple(requests,true).then(
+				() => this.$emit('record-reverted'),
+				this.$root.genericError
+			);
+		},
 		get() {
 			if(this.formLoading)
 				return;
@@ -152,6 +152,16 @@ export default {
 				<img src="images/pageNext.png" />
 			</div>
 			
+			<!-- impersonation active -->
+			<div class="entry no-wrap clickable" tabindex="0"
+				v-if="loginImpersonator !== null"
+				@click="clickImpersonationEnd"
+				@keyup.enter="clickImpersonationEnd"
+				:title="capGen.impersonation.replace('{NAME}',loginName)"
+			>
+				<img src="images/personArrow.png" />
+			</div>
+			
 			<!-- keys locked -->
 			<div class="entry no-wrap clickable" tabindex="0"
 				v-if="keysLocked"
@@ -374,6 +384,7 @@ export default {
 		isAtMenu:            (s) => s.$store.getters.isAtMenu,
 		isMobile:            (s) => s.$store.getters.isMobile,
 		isNoAuth:            (s) => s.$store.getters.isNoAuth,
+		loginImpersonator:   (s) => s.$store.getters.loginImpersonator,
 		loginName:           (s) => s.$store.getters.loginName,
 		loginSessionExpires: (s) => s.$store.getters.loginSessionExpires,
 		moduleEntries:       (s) => s.$store.getters.moduleEntries,
@@ -456,6 +467,24 @@ export default {
 				}]
 			});
 		},
+		clickImpersonationEnd() {
+			this.$store.commit('dialog',{
+				captionBody:this.capGen.dialog.impersonationEnd.replace('{NAME}',this.loginName),
+				captionTop:this.capGen.impersonation.replace('{NAME}',this.loginName),
+				image:'personArrow.png',
+				buttons:[{
+					cancel:true,
+					caption:this.capGen.button.impersonationEnd,
+					exec:() => this.$store.getters.appFunctions.impersonationEnd(),
+					keyEnter:true,
+					image:'logoff.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
 		clickSingleModuleLink() {
 			// module active in mobile mode: toggle menu
 			if(this.moduleSingleActive && this.isMobile)
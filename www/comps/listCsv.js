@@ -1,3 +1,4 @@
+import {isAttributeGeo}      from './shared/attribute.js';
 import {resolveErrCode}      from './shared/error.js';
 import {getQueryExpressions} from './shared/query.js';
 
@@ -5,19 +6,22 @@ export default {
 	name:'my-list-csv',
 	template:`
 		<p v-if="action === 'export'">{{ capApp.message.csvExport }}</p>
+		<p v-if="action === 'exportGeo'">{{ capApp.message.geoJsonExport }}</p>
 		<p v-if="action === 'import'">{{ capApp.message.csvImport.replace('{COUNT}',columns.length) }}</p>
 		
 		<table>
 			<tbody>
-				<tr v-if="isExport && isImport">
+				<tr v-if="(isExport && isImport) || (isExport && hasGeo)">
 					<td>{{ capApp.csvAction }}</td>
 					<td>
 						<select v-model="action" @change="message = ''">
-							<option value="export">{{ capApp.option.csvExport }}</option>
-							<option value="import">{{ capApp.option.csvImport }}</option>
+							<option value="export" v-if="isExport">{{ capApp.option.csvExport }}</option>
+							<option value="exportGeo" v-if="isExport && hasGeo">{{ capApp.option.geoJsonExport }}</option>
+							<option value="import" v-if="isImport">{{ capApp.option.csvImport }}</option>
 						</select>
 					</td>
 				</tr>
+				<template v-if="action !== 'exportGeo'">
 				<tr>
 					<td>{{ capApp.csvHasHeader }}</td>
 					<td><my-bool v-model="hasHeader" /></td>
@@ -67,7 +71,8 @@ export default {
 						</select>
 					</td>
 				</tr>
-				<tr v-if="action === 'export'">
+				</template>
+				<tr v-if="action === 'export' || action === 'exportGeo'">
 					<td>{{ capApp.csvTotalLimit }}</td>
 					<td><input v-model.number="totalLimit" /></td>
 				</tr>
@@ -93,6 +98,9 @@ export default {
 		
 		<a download="export.csv" v-if="action === 'export'" :href="exportHref">
 			<my-button image="download.png" :caption="capGen.button.export" />
+		</a>
+		<a download="export.geojson" v-if="action === 'exportGeo'" :href="exportGeoHref">
+			<my-button image="download.png" :caption="capGen.button.export" />
 		</a>`,
 	props:{
 		columns:      { type:Array,  required:true },
@@ -118,6 +126,7 @@ export default {
 			hasBool:false,
 			hasDate:false,
 			hasDatetime:false,
+			hasGeo:false,
 			hasHeader:true,
 			hasTime:false,
 			message:'',
@@ -135,6 +144,7 @@ export default {
 			if(atr.contentUse === 'date')     this.hasDate     = true; 
 			if(atr.contentUse === 'datetime') this.hasDatetime = true;
 			if(atr.contentUse === 'time')     this.hasTime     = true;
+			if(this.isAttributeGeo(atr.content)) this.hasGeo   = true;
 		}
 		this.cacheDenialTimeout = setInterval(this.setCacheDenialTimestamp,1000);
 	},
@@ -171,6 +181,20 @@ export default {
 			];
 			return `/csv/download/export.csv?${getters.join('&')}`;
 		},
+		exportGeoHref:(s) => {
+			const getters = [
+				`token=${s.token}`,
+				`relation_id=${s.query.relationId}`,
+				`columns=${encodeURIComponent(JSON.stringify(s.columnsSorted))}`,
+				`joins=${encodeURIComponent(JSON.stringify(s.joins))}`,
+				`expressions=${encodeURIComponent(JSON.stringify(s.expressions))}`,
+				`filters=${encodeURIComponent(JSON.stringify(s.filters))}`,
+				`orders=${encodeURIComponent(JSON.stringify(s.orders))}`,
+				`total_limit=${s.totalLimit}`,
+				`timestamp=${s.cacheDenialTimestamp}`
+			];
+			return `/geojson/download/export.geojson?${getters.join('&')}`;
+		},
 
 		// simple
 		expressions:(s) => s.getQueryExpressions(s.columnsSorted),
@@ -186,6 +210,7 @@ export default {
 	methods:{
 		// externals
 		getQueryExpressions,
+		isAttributeGeo,
 		resolveErrCode,
 		
 		// actions
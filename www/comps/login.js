@@ -2,8 +2,11 @@ import * as oauth        from '../externals/oauth4webapi.js';
 import {getRandomString} from './shared/crypto.js';
 import {consoleError}    from './shared/error.js';
 import {
+	aesGcmDecryptBase64,
+	aesGcmEncryptBase64,
 	aesGcmExportBase64,
-	pbkdf2PassToAesGcmKey
+	pbkdf2PassToAesGcmKey,
+	webAuthnGet
 } from './shared/crypto.js';
 import {
 	getLineBreaksParsedToHtml,
@@ -70,9 +73,15 @@ export default {
 				<img src="images/warning.png" />
 				<span>{{ message.license[licenseErrCode][language] }}</span>
 			</div>
+			
+			<!-- password policy error message -->
+			<div class="message warning" v-if="pwErrCode !== null">
+				<img src="images/warning.png" />
+				<span>{{ message.pwErr[pwErrCode][language] }}</span>
+			</div>
 
 			<!-- Open ID Connect OAUTH2 clients -->
-			<template v-if="!showMfa && hasOpenIdClients">
+			<template v-if="!showMfa && !pwExpired && hasOpenIdClients">
 				<div class="message">
 					<img src="images/globe.png" />
 					<span>{{ message.authExt[language] }}</span>
@@ -86,7 +95,7 @@ export default {
 			</template>
 			
 			<!-- credentials input -->
-			<div class="credentials" v-if="!showMfa">
+			<div class="credentials" v-if="!showMfa && !pwExpired">
 				<div class="message" v-if="hasOpenIdClients">
 					<img src="images/server.png" />
 					<span>{{ message.authInt[language] }}</span>
@@ -105,23 +114,72 @@ export default {
 					v-model="password"
 					:placeholder="message.password[language]"
 				/>
+				<my-button image="key.png"
+					v-if="webAuthnAvailable"
+					@trigger="authenticatePasswordless"
+					:active="!badAuth && username !== ''"
+					:caption="message.passkeyLogin[language]"
+				/>
+			</div>
+			
+			<!-- new password input, if current password has expired -->
+			<div class="credentials" v-if="!showMfa && pwExpired">
+				<div class="message">
+					<img src="images/lock.png" />
+					<span>{{ message.pwExpired[language] }}</span>
+				</div>
+				<input autocomplete="new-password" class="placeholder-bright" type="password"
+					@keyup="pwNewChanged"
+					@keyup.enter="authenticate"
+					v-model="pwNew0"
+					v-focus
+					:placeholder="message.pwNew0[language]"
+				/>
+				<input autocomplete="new-password" class="placeholder-bright" type="password"
+					@keyup="pwNewChanged"
+					@keyup.enter="authenticate"
+					v-model="pwNew1"
+					:placeholder="message.pwNew1[language]"
+				/>
 			</div>
 			
 			<!-- MFA input -->
 			<template v-if="showMfa">
 				<h3>{{ message.mfa[language] }}</h3>
-				<select v-model.number="mfaTokenId">
-					<option v-for="t in mfaTokens" :value="t.id">
-						{{ t.name }}
-					</option>
-				</select>
-				<input autocomplete="one-time-code" class="placeholder-bright" type="text" maxlength="6"
+				<template v-if="mfaTokens.length !== 0 && mfaRecoveryCode === null">
+					<select v-model.number="mfaTokenId">
+						<option v-for="t in mfaTokens" :value="t.id">
+							{{ t.name }}
+						</option>
+					</select>
+					<input autocomplete="one-time-code" class="placeholder-bright" type="text" maxlength="6"
+						@keyup="badAuth = false"
+						@keyup.enter="authenticate"
+						v-model="mfaTokenPin"
+						v-focus
+						:placeholder="message.mfaHint[language]"
+					/>
+				</template>
+				<input class="placeholder-bright" type="text" spellcheck="false"
+					v-if="mfaRecoveryCode !== null"
 					@keyup="badAuth = false"
 					@keyup.enter="authenticate"
-					v-model="mfaTokenPin"
+					v-model="mfaRecoveryCode"
 					v-focus
-					:placeholder="message.mfaHint[language]"
+					:placeholder="message.mfaRecoveryHint[language]"
 				/>
+				<div class="row gap">
+					<my-button image="key.png"
+						v-if="mfaWebAuthn !== null"
+						@trigger="authenticateByWebAuthn(mfaWebAuthn,password)"
+						:caption="message.passkey[language]"
+					/>
+					<my-button image="lock.png"
+						v-if="mfaRecovery && mfaRecoveryCode === null"
+						@trigger="mfaRecoveryCode = ''"
+						:caption="message.mfaRecovery[language]"
+					/>
+				</div>
 			</template>
 			
 			<div class="row centered space-between">
@@ -174,7 +232,13 @@ export default {
 			mfaTokens:[],     // list of TOTP tokens to choose from, [{id:12,name:'My Phone'},{...}]
 			mfaTokenId:null,  // selected TOTP token
 			mfaTokenPin:null, // entered TOTP PIN (6 digit code)
+			mfaRecovery:false,    // recovery codes are available as alternative MFA
+			mfaRecoveryCode:null, // entered recovery code, null if not used
+			mfaWebAuthn:null,     // WebAuthn challenge for passkey as MFA, {sessionId:'...',options:{...}}
 			password:'',
+			pwNew0:'',               // new password, if current password has expired
+			pwNew1:'',               // new password, repeated
+			pwNewKeyPrivateEnc:null, // private key, re-encrypted with new password (if E2EE is used)
 			username:'',
 			
 			// states
@@ -182,6 +246,10 @@ export default {
 			badAuth:false,       // authentication failed
 			licenseErrCode:null, // error with system license
 			loading:false,
+			pwErrCode:null,      // new password was rejected by password policy
+			pwExpired:false,     // current password has expired, new password must be set
+			pwExpiredKeyPrivateEnc:null, // encrypted private key, to be re-encrypted with new password
+			pwExpiredSaltKdf:null,       // salt for login key, to decrypt private key
 			showError:false,
 			
 			// default messages
@@ -238,10 +306,52 @@ export default {
 					de:'6-stelliger Validierungs-Code',
 					en_US:'6 digit validation code'
 				},
+				mfaRecovery:{
+					de:'Wiederherstellungscode verwenden',
+					en_US:'Use recovery code'
+				},
+				mfaRecoveryHint:{
+					de:'Wiederherstellungscode',
+					en_US:'Recovery code'
+				},
+				passkey:{
+					de:'Passkey verwenden',
+					en_US:'Use passkey'
+				},
+				passkeyLogin:{
+					de:'Mit Passkey anmelden',
+					en_US:'Login with passkey'
+				},
 				password:{
 					de:'Passwort',
 					en_US:'Password'
 				},
+				pwErr:{
+					'{ERR_SEC_008}':{
+						de:'Passwort wurde kürzlich verwendet - bitte ein anderes wählen',
+						en_US:'Password was used recently - please choose a different one'
+					},
+					'{ERR_SEC_009}':{
+						de:'Passwort ist aus Datenlecks bekannt - bitte ein anderes wählen',
+						en_US:'Password is known from data breaches - please choose a different one'
+					},
+					'{ERR_SEC_010}':{
+						de:'Passwort erfüllt nicht die Passwortrichtlinien',
+						en_US:'Password does not meet the password requirements'
+					}
+				},
+				pwExpired:{
+					de:'Passwort ist abgelaufen - bitte ein neues festlegen',
+					en_US:'Password has expired - please set a new one'
+				},
+				pwNew0:{
+					de:'Neues Passwort',
+					en_US:'New password'
+				},
+				pwNew1:{
+					de:'Neues Passwort wiederholen',
+					en_US:'Repeat new password'
+				},
 				sessionExpired:{
 					de:'Sitzung abgelaufen - bitte erneut anmelden',
 					en_US:'Session expired - please login again'
@@ -274,14 +384,22 @@ export default {
 			border-color:${s.colorLogin.darken(30).toString()};
 		`,
 		isValid:(s) => {
+			if(!s.showMfa && s.pwExpired)
+				return !s.badAuth && s.pwNew0 !== '' && s.pwNew0 === s.pwNew1;
+			
 			if(!s.showMfa)
 				return !s.badAuth && s.username !== '' && s.password !== '';
 			
+			if(s.mfaRecoveryCode !== null)
+				return !s.badAuth && s.mfaRecoveryCode !== '';
+			
 			return !s.badAuth && s.mfaTokenId !== null && s.mfaTokenPin !== null;
 		},
 		hasOpenIdClients:(s) => Object.keys(s.oauthClientIdMapOpenId).length !== 0,
 		showCustom:      (s) => s.activated && (s.companyName !== '' || s.companyWelcome !== ''),
-		showMfa:         (s) => s.mfaTokens.length !== 0,
+		pwNew:           (s) => !s.pwExpired ? null : { pw:s.pwNew0, keyPrivateEnc:s.pwNewKeyPrivateEnc },
+		showMfa:         (s) => s.mfaTokens.length !== 0 || s.mfaWebAuthn !== null,
+		webAuthnAvailable:(s) => window.PublicKeyCredential !== undefined,
 		
 		// stores
 		activated:             (s) => s.$store.getters['local/activated'],
@@ -294,11 +412,13 @@ export default {
 		openIdAuthDetails:     (s) => s.$store.getters['local/openIdAuthDetails'],
 		token:                 (s) => s.$store.getters['local/token'],
 		tokenKeep:             (s) => s.$store.getters['local/tokenKeep'],
+		clientCertAuth:        (s) => s.$store.getters.clientCertAuth,
 		clusterNodeName:       (s) => s.$store.getters.clusterNodeName,
 		colorLogin:            (s) => s.$store.getters.colorLogin,
 		cryptoApiAvailable:    (s) => s.$store.getters.cryptoApiAvailable,
 		kdfIterations:         (s) => s.$store.getters.constants.kdfIterations,
 		loginSessionExpired:   (s) => s.$store.getters.loginSessionExpired,
+		loginSwitch:           (s) => s.$store.getters.loginSwitch,
 		oauthClientIdMapOpenId:(s) => s.$store.getters.oauthClientIdMapOpenId,
 		productionMode:        (s) => s.$store.getters.productionMode,
 		tokenKeepEnable:       (s) => s.$store.getters.tokenKeepEnable
@@ -342,6 +462,10 @@ export default {
 			// attempt authentication if token is available
 			if(this.token !== '')
 				return this.authenticateByToken();
+			
+			// attempt authentication via TLS client certificate, if enabled
+			if(this.clientCertAuth)
+				return this.authenticateByCert();
 		}
 	},
 	mounted() {
@@ -353,7 +477,10 @@ export default {
 		this.$store.commit('pageTitle',this.message.login[this.language]);
 		
 		// clear token & login key, if available but not to be kept
-		if(!this.tokenKeep && this.token !== '') {
+		// token is kept if login session is being switched (admin impersonation)
+		if(this.loginSwitch)
+			this.$store.commit('loginSwitch',false);
+		else if(!this.tokenKeep && this.token !== '') {
 			this.$store.commit('local/loginKeyAes',null);
 			this.$store.commit('local/loginKeySalt',null);
 			this.$store.commit('local/token','');
@@ -361,12 +488,15 @@ export default {
 	},
 	methods:{
 		// externals
+		aesGcmDecryptBase64,
+		aesGcmEncryptBase64,
 		aesGcmExportBase64,
 		consoleError,
 		getLineBreaksParsedToHtml,
 		getRandomString,
 		pbkdf2PassToAesGcmKey,
 		openLink,
+		webAuthnGet,
 		
 		// misc
 		handleError(action,msg) {
@@ -375,11 +505,41 @@ export default {
 			else
 				this.licenseErrCode = null;
 			
+			if(this.message.pwErr[msg] !== undefined)
+				this.pwErrCode = msg;
+			else
+				this.pwErrCode = null;
+			
 			switch(action) {
 				case 'aesExport': break;                      // very unexpected, should not happen
+				case 'authCert':  break;                      // cert auth failed, to be expected, client might not have a certificate
 				case 'authToken': break;                      // token auth failed, to be expected, can expire
 				case 'authUser':  this.badAuth = true; break; // user authorization failed, mark inputs invalid
 				case 'kdfCreate': break;                      // very unexpected, should not happen
+				case 'keyCrypt':  break;                      // private key could not be re-encrypted, should not happen
+			}
+			this.loading = false;
+		},
+		pwNewChanged() {
+			// re-encrypted private key depends on new password
+			this.badAuth            = false;
+			this.pwNewKeyPrivateEnc = null;
+		},
+		pwExpiredSet(payload) {
+			this.pwExpired = true;
+			
+			// encrypted private key is only returned once all factors are validated
+			// it is re-encrypted with the new password and sent again, used factors are consumed and must be given again
+			if(payload.keyPrivateEnc !== null) {
+				this.mfaRecoveryCode = null;
+				this.mfaTokenId      = null;
+				this.mfaTokenPin     = null;
+				this.mfaTokens       = [];
+				this.mfaWebAuthn     = null;
+				
+				this.pwExpiredKeyPrivateEnc = payload.keyPrivateEnc;
+				this.pwExpiredSaltKdf       = payload.saltKdf;
+				return this.reEncryptPrivateKey();
 			}
 			this.loading = false;
 		},
@@ -439,18 +599,30 @@ export default {
 		authenticate() {
 			if(!this.isValid) return;
 			
+			// private key must be re-encrypted with new password, before expired password is replaced
+			if(this.pwExpired && this.pwExpiredKeyPrivateEnc !== null && this.pwNewKeyPrivateEnc === null)
+				return this.reEncryptPrivateKey();
+			
 			ws.send('auth','user',{
 				username:this.username,
 				password:this.password,
-				mfaTokenId:this.mfaTokenId,
-				mfaTokenPin:this.mfaTokenPin
+				pwNew:this.pwNew,
+				mfaTokenId:this.mfaRecoveryCode === null ? this.mfaTokenId : null,
+				mfaTokenPin:this.mfaRecoveryCode === null ? this.mfaTokenPin : null,
+				mfaRecoveryCode:this.mfaRecoveryCode
 			},true).then(
 				res => {
-					// MFA token list returned, MFA is required
-					if(res.payload.mfaTokens.length !== 0) {
+					// current password has expired, new password is required
+					if(res.payload.pwExpired)
+						return this.pwExpiredSet(res.payload);
+					
+					// MFA token list or WebAuthn challenge returned, MFA is required
+					if(res.payload.mfaTokens.length !== 0 || res.payload.webAuthn.sessionId !== null) {
+						this.mfaRecovery = res.payload.mfaRecovery;
 						this.mfaTokens   = res.payload.mfaTokens;
-						this.mfaTokenId  = res.payload.mfaTokens[0].id;
-						this.mfaTokenPin = '';
+						this.mfaTokenId  = res.payload.mfaTokens.length !== 0 ? res.payload.mfaTokens[0].id : null;
+						this.mfaTokenPin = res.payload.mfaTokens.length !== 0 ? '' : null;
+						this.mfaWebAuthn = res.payload.webAuthn.sessionId !== null ? res.payload.webAuthn : null;
 						this.loading     = false;
 						return;
 					}
@@ -467,6 +639,82 @@ export default {
 			);
 			this.loading = true;
 		},
+		authenticatePasswordless() {
+			if(this.badAuth || this.username === '') return;
+			
+			// request WebAuthn challenge for login without password
+			ws.send('auth','user',{username:this.username},true).then(
+				res => {
+					if(res.payload.webAuthn.sessionId === null)
+						return this.handleError('authUser','');
+					
+					this.authenticateByWebAuthn(res.payload.webAuthn,'');
+				},
+				err => this.handleError('authUser',err)
+			);
+			this.loading = true;
+		},
+		authenticateByWebAuthn(challenge,password) {
+			this.loading = true;
+			this.webAuthnGet(challenge.options).then(
+				credential => {
+					const assertion = {
+						sessionId:challenge.sessionId,
+						response:credential
+					};
+					ws.send('auth','user',{
+						username:this.username,
+						password:password,
+						pwNew:password !== '' ? this.pwNew : null,
+						webAuthn:assertion
+					},true).then(
+						res => {
+							// private key must be re-encrypted, MFA is requested again with re-encrypted key
+							if(res.payload.pwExpired)
+								return this.pwExpiredSet(res.payload);
+							
+							this.authenticatedByUser(
+								res.payload.id,
+								res.payload.name,
+								res.payload.token,
+								res.payload.saltKdf,
+								password === ''
+							);
+						},
+						err => this.handleError('authUser',err)
+					);
+				},
+				err => {
+					// authenticator aborted or not available, challenge cannot be reused
+					console.warn(err);
+					this.mfaWebAuthn = null;
+					this.handleError('authUser','');
+				}
+			);
+		},
+		reEncryptPrivateKey() {
+			if(!this.cryptoApiAvailable)
+				return this.handleError('keyCrypt','');
+			
+			this.loading = true;
+			Promise.all([
+				this.pbkdf2PassToAesGcmKey(this.password,this.pwExpiredSaltKdf,this.kdfIterations,false),
+				this.pbkdf2PassToAesGcmKey(this.pwNew0,this.pwExpiredSaltKdf,this.kdfIterations,false)
+			]).then(
+				keys => {
+					this.aesGcmDecryptBase64(this.pwExpiredKeyPrivateEnc,keys[0]).then(
+						privateKeyPem => this.aesGcmEncryptBase64(privateKeyPem,keys[1])
+					).then(
+						privateKeyEnc => {
+							this.pwNewKeyPrivateEnc = privateKeyEnc;
+							this.authenticate();
+						},
+						() => this.handleError('keyCrypt','')
+					);
+				},
+				() => this.handleError('kdfCreate','')
+			);
+		},
 		authenticatePublic(username) {
 			// keep token as public user is not asked
 			this.$store.commit('local/tokenKeep',true);
@@ -503,6 +751,19 @@ export default {
 			this.loading = true;
 
 		},
+		authenticateByCert() {
+			ws.send('auth','cert',{},true).then(
+				res => this.authenticatedByUser(
+					res.payload.id,
+					res.payload.name,
+					res.payload.token,
+					res.payload.saltKdf,
+					true
+				),
+				err => this.handleError('authCert',err)
+			);
+			this.loading = true;
+		},
 		authenticateByToken() {
 			ws.send('auth','token',this.token,true).then(
 				res => this.appEnable(res.payload.id,res.payload.name),
@@ -530,7 +791,9 @@ export default {
 				return this.appEnable(loginId,loginName);
 			
 			// generate AES key from credentials and login private key salt
-			this.pbkdf2PassToAesGcmKey(this.password,saltKdf,this.kdfIterations,true).then(
+			// if expired password was replaced during login, new password is used
+			const password = this.pwExpired ? this.pwNew0 : this.password;
+			this.pbkdf2PassToAesGcmKey(password,saltKdf,this.kdfIterations,true).then(
 				key => {
 					this.aesGcmExportBase64(key).then(
 						keyBase64 => {
@@ -17,7 +17,8 @@ import {
 	pemExport,
 	pemImport,
 	pemImportPrivateEnc,
-	rsaGenerateKeys
+	rsaGenerateKeys,
+	webAuthnCreate
 } from './shared/crypto.js';
 export {MySettings as default};
 
@@ -1211,6 +1212,327 @@ const MySettingsFixedTokens = {
 	}
 };
 
+const MySettingsPasskeys = {
+	name:'my-settings-passkeys',
+	template:`<div class="column gap default-inputs">
+		<span>{{ capApp.intro }}</span>
+		
+		<span v-if="!webAuthnAvailable"><i>{{ capApp.notAvailable }}</i></span>
+		
+		<div class="settings-tokens" v-if="passkeys.length !== 0">
+			<table class="generic-table sticky-top bright">
+				<thead>
+					<tr>
+						<th>{{ capApp.titleName }}</th>
+						<th>{{ capApp.titleDateCreate }}</th>
+						<th colspan="2">{{ capApp.titleDateUsed }}</th>
+					</tr>
+				</thead>
+				<tbody>
+					<tr v-for="p in passkeys">
+						<td>{{ p.name }}</td>
+						<td><span :title="getUnixFormat(p.dateCreate,'Y-m-d H:i:S')">{{ getUnixFormat(p.dateCreate,'Y-m-d') }}</span></td>
+						<td>{{ p.dateUsed !== null ? getUnixFormat(p.dateUsed,'Y-m-d H:i:S') : '-' }}</td>
+						<td>
+							<div class="row">
+								<my-button image="delete.png"
+									@trigger="delAsk(p.id)"
+									:cancel="true"
+								/>
+							</div>
+						</td>
+					</tr>
+				</tbody>
+			</table>
+		</div>
+		
+		<div class="row gap centered" v-if="webAuthnAvailable">
+			<input v-model="passkeyName" :placeholder="capApp.nameHint" />
+			<my-button image="add.png"
+				@trigger="register"
+				:active="passkeyName !== ''"
+				:caption="capApp.button.register"
+			/>
+		</div>
+		
+		<!-- recovery codes -->
+		<br />
+		<span>{{ capApp.recoveryCodes.replace('{COUNT}',recoveryCodeCount) }}</span>
+		<div class="row">
+			<my-button image="refresh.png"
+				@trigger="setRecoveryCodesAsk"
+				:caption="capApp.button.recoveryCodes"
+			/>
+		</div>
+		<template v-if="recoveryCodes.length !== 0">
+			<span>{{ capApp.recoveryCodesNew }}</span>
+			<ul>
+				<li v-for="c in recoveryCodes"><code>{{ c }}</code></li>
+			</ul>
+		</template>
+	</div>`,
+	data() {
+		return {
+			passkeys:[],
+			passkeyIdDel:null,    // ID of passkey to delete (dialog)
+			passkeyName:'',
+			recoveryCodeCount:0,
+			recoveryCodes:[]      // newly generated recovery codes, only shown once
+		};
+	},
+	computed:{
+		webAuthnAvailable:(s) => window.PublicKeyCredential !== undefined,
+		
+		// stores
+		capApp:(s) => s.$store.getters.captions.settings.passkeys,
+		capGen:(s) => s.$store.getters.captions.generic
+	},
+	mounted() {
+		this.get();
+		this.getRecoveryCodes();
+	},
+	methods:{
+		// externals
+		getUnixFormat,
+		webAuthnCreate,
+		
+		// actions
+		delAsk(id) {
+			this.passkeyIdDel = id;
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.message.delete,
+				image:'warning.png',
+				buttons:[{
+					cancel:true,
+					caption:this.capGen.button.delete,
+					exec:this.del,
+					keyEnter:true,
+					image:'delete.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
+		setRecoveryCodesAsk() {
+			if(this.recoveryCodeCount === 0)
+				return this.setRecoveryCodes();
+			
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.message.recoveryCodes,
+				image:'warning.png',
+				buttons:[{
+					cancel:true,
+					caption:this.capGen.button.ok,
+					exec:this.setRecoveryCodes,
+					keyEnter:true,
+					image:'ok.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
+		
+		// backend calls
+		del() {
+			ws.send('loginWebAuthn','del',{id:this.passkeyIdDel},true).then(
+				this.get,
+				this.$root.genericError
+			);
+		},
+		get() {
+			ws.send('loginWebAuthn','get',{},true).then(
+				res => this.passkeys = res.payload,
+				this.$root.genericError
+			);
+		},
+		getRecoveryCodes() {
+			ws.send('loginRecoveryCode','get',{},true).then(
+				res => this.recoveryCodeCount = res.payload,
+				this.$root.genericError
+			);
+		},
+		register() {
+			ws.send('loginWebAuthn','registerBegin',{},true).then(
+				res => {
+					const challenge = res.payload;
+					this.webAuthnCreate(challenge.options).then(
+						credential => {
+							ws.send('loginWebAuthn','registerFinish',{
+								name:this.passkeyName,
+								webAuthn:{
+									sessionId:challenge.sessionId,
+									response:credential
+								}
+							},true).then(
+								() => {
+									this.passkeyName = '';
+									this.get();
+								},
+								this.$root.genericError
+							);
+						},
+						err => console.warn(err) // authenticator aborted or not available
+					);
+				},
+				this.$root.genericError
+			);
+		},
+		setRecoveryCodes() {
+			ws.send('loginRecoveryCode','set',{},true).then(
+				res => {
+					this.recoveryCodes     = res.payload;
+					this.recoveryCodeCount = res.payload.length;
+				},
+				this.$root.genericError
+			);
+		}
+	}
+};
+
+const MySettingsSessions = {
+	name:'my-settings-sessions',
+	template:`<div class="column gap">
+		<span>{{ capApp.intro }}</span>
+		
+		<div class="settings-tokens" v-if="sessions.length !== 0">
+			<table class="generic-table sticky-top bright">
+				<thead>
+					<tr>
+						<th>{{ capApp.titleDevice }}</th>
+						<th>{{ capApp.titleAddress }}</th>
+						<th>{{ capApp.titleDateCreate }}</th>
+						<th>{{ capApp.titleDateActivity }}</th>
+						<th colspan="2">{{ capApp.titleDateExpiry }}</th>
+					</tr>
+				</thead>
+				<tbody>
+					<tr v-for="s in sessions">
+						<td>
+							<div class="row gap centered">
+								<span>{{ s.device !== null ? capApp.device[s.device] : capApp.notConnected }}</span>
+								<span v-if="s.id === tokenId"><i>({{ capApp.current }})</i></span>
+							</div>
+						</td>
+						<td>{{ s.address !== null ? s.address : '-' }}</td>
+						<td><span :title="getUnixFormat(s.dateCreate,'Y-m-d H:i:S')">{{ getUnixFormat(s.dateCreate,'Y-m-d') }}</span></td>
+						<td>{{ getUnixFormat(s.dateActivity,'Y-m-d H:i:S') }}</td>
+						<td><span :title="getUnixFormat(s.dateExpiry,'Y-m-d H:i:S')">{{ getUnixFormat(s.dateExpiry,'Y-m-d') }}</span></td>
+						<td>
+							<div class="row">
+								<my-button image="logoff.png"
+									v-if="s.id !== tokenId"
+									@trigger="revokeAsk(s.id)"
+									:cancel="true"
+									:caption="capApp.button.revoke"
+								/>
+							</div>
+						</td>
+					</tr>
+				</tbody>
+			</table>
+		</div>
+		
+		<div class="row gap">
+			<my-button image="refresh.png"
+				@trigger="get"
+				:caption="capGen.button.refresh"
+			/>
+			<my-button image="logoff.png"
+				@trigger="revokeOthersAsk"
+				:active="sessions.filter(v => v.id !== tokenId).length !== 0"
+				:cancel="true"
+				:caption="capApp.button.revokeOthers"
+			/>
+		</div>
+	</div>`,
+	data() {
+		return {
+			sessions:[],
+			sessionIdRevoke:null // ID of session token to revoke (dialog)
+		};
+	},
+	computed:{
+		// ID of token used by this client, tokens issued before token IDs were introduced have none
+		tokenId:(s) => {
+			const payload = JSON.parse(atob(s.token.split('.')[1]));
+			return payload.jti !== undefined ? payload.jti : null;
+		},
+		
+		// stores
+		token: (s) => s.$store.getters['local/token'],
+		capApp:(s) => s.$store.getters.captions.settings.sessions,
+		capGen:(s) => s.$store.getters.captions.generic
+	},
+	mounted() {
+		this.get();
+	},
+	methods:{
+		// externals
+		getUnixFormat,
+		
+		// actions
+		revokeAsk(id) {
+			this.sessionIdRevoke = id;
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.message.revoke,
+				image:'warning.png',
+				buttons:[{
+					cancel:true,
+					caption:this.capApp.button.revoke,
+					exec:this.revoke,
+					keyEnter:true,
+					image:'logoff.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
+		revokeOthersAsk() {
+			this.$store.commit('dialog',{
+				captionBody:this.capApp.message.revokeOthers,
+				image:'warning.png',
+				buttons:[{
+					cancel:true,
+					caption:this.capApp.button.revokeOthers,
+					exec:this.revokeOthers,
+					keyEnter:true,
+					image:'logoff.png'
+				},{
+					caption:this.capGen.button.cancel,
+					keyEscape:true,
+					image:'cancel.png'
+				}]
+			});
+		},
+		
+		// backend calls
+		get() {
+			ws.send('loginToken','get',{},true).then(
+				res => this.sessions = res.payload,
+				this.$root.genericError
+			);
+		},
+		revoke() {
+			ws.send('loginToken','revoke',{id:this.sessionIdRevoke},true).then(
+				this.get,
+				this.$root.genericError
+			);
+		},
+		revokeOthers() {
+			ws.send('loginToken','revokeOthers',{},true).then(
+				this.get,
+				this.$root.genericError
+			);
+		}
+	}
+};
+
 const MySettings = {
 	name:'my-settings',
 	components:{
@@ -1218,7 +1540,9 @@ const MySettings = {
 		MySettingsAccount,
 		MySettingsClientEvents,
 		MySettingsEncryption,
-		MySettingsFixedTokens
+		MySettingsFixedTokens,
+		MySettingsPasskeys,
+		MySettingsSessions
 	},
 	template:`<div class="settings contentBox grow scroll float">
 		<div class="top lower">
@@ -1468,6 +1792,24 @@ const MySettings = {
 				<my-settings-fixed-tokens />
 			</div>
 			
+			<!-- passkeys & recovery codes -->
+			<div class="contentPart" v-if="isAllowedMfa">
+				<div class="contentPartHeader">
+					<img class="icon" src="images/keyLocked.png" />
+					<h1>{{ capApp.titlePasskeys }}</h1>
+				</div>
+				<my-settings-passkeys />
+			</div>
+			
+			<!-- active sessions -->
+			<div class="contentPart" v-if="!isNoAuth">
+				<div class="contentPartHeader">
+					<img class="icon" src="images/server.png" />
+					<h1>{{ capApp.titleSessions }}</h1>
+				</div>
+				<my-settings-sessions />
+			</div>
+			
 			<!-- client events (global hotkeys) -->
 			<div class="contentPart">
 				<div class="contentPartHeader">
@@ -1520,6 +1862,8 @@ const MySettings = {
 		languageCodesModules: (s) => s.$store.getters['schema/languageCodesModules'],
 		capGen:               (s) => s.$store.getters.captions.generic,
 		capApp:               (s) => s.$store.getters.captions.settings,
+		isAllowedMfa:         (s) => s.$store.getters.isAllowedMfa,
+		isNoAuth:             (s) => s.$store.getters.isNoAuth,
 		languageCodesOfficial:(s) => s.$store.getters.constants.languageCodesOfficial,
 		moduleIdMapMeta:      (s) => s.$store.getters.moduleIdMapMeta,
 		patternStyle:         (s) => s.$store.getters.patternStyle,
@@ -3,7 +3,9 @@ import MyStore from '../../stores/store.js';
 const attributeContentNames = {
 	decimal:['numeric','real','double precision'],
 	float:['real','double precision'],
+	geo:['geometry','point'],
 	integer:['integer','bigint'],
+	range:['daterange','tstzrange'],
 	relationship:['1:1','n:1','1:n'],
 	text:['varchar','text']
 };
@@ -147,6 +149,10 @@ export function getAttributeIcon(content,contentUse,outsideIn,isNm) {
 		}
 	}
 	if(isAttributeBoolean(content))        return 'bool.png';
+	if(isAttributeEnum(content))           return 'files_list1.png';
+	if(isAttributeGeo(content))            return 'globe.png';
+	if(isAttributeJson(content))           return 'code.png';
+	if(isAttributeRange(content))          return 'calendar.png';
 	if(isAttributeUuid(content))           return 'uuid.png';
 	if(isAttributeFloat(content))          return 'numbers_float.png';
 	if(isAttributeNumeric(content))        return 'numbers_decimal.png';
@@ -187,6 +193,10 @@ export function getAttributeContentUse(content,use) {
 	}
 
 	if(isAttributeBoolean(content))        return 'boolean';
+	if(isAttributeEnum(content))           return 'enum';
+	if(isAttributeGeo(content))            return 'geo';
+	if(isAttributeJson(content))           return 'json';
+	if(isAttributeRange(content))          return content;
 	if(isAttributeNumeric(content))        return 'decimal';
 	if(isAttributeFiles(content))          return 'files';
 	if(isAttributeFloat(content))          return 'float';
@@ -205,6 +215,11 @@ export function getAttributeContentsByUse(usedFor,length,largeNumbers) {
 		case 'number':         return { content:largeNumbers ? 'bigint' : 'integer',       contentUse:'default' }; break;
 		case 'boolean':        return { content:'boolean',                                 contentUse:'default' }; break;
 		case 'decimal':        return { content:'numeric',                                 contentUse:'default' }; break;
+		case 'enum':           return { content:'enum',                                    contentUse:'default' }; break;
+		case 'geo':            return { content:'geometry',                                contentUse:'default' }; break;
+		case 'json':           return { content:'jsonb',                                   contentUse:'default' }; break;
+		case 'daterange':      return { content:'daterange',                               contentUse:'default' }; break;
+		case 'tstzrange':      return { content:'tstzrange',                               contentUse:'default' }; break;
 		case 'files':          return { content:'files',                                   contentUse:'default' }; break;
 		case 'float':          return { content:largeNumbers ? 'double precision': 'real', contentUse:'default' }; break;
 		case 'regconfig':      return { content:'regconfig',                               contentUse:'default' }; break;
@@ -231,10 +246,14 @@ export function getAttributeContentsByUse(usedFor,length,largeNumbers) {
 
 export function isAttributeBoolean(content)            { return content === 'boolean'; };
 export function isAttributeDecimal(content)            { return attributeContentNames.decimal.includes(content); };
+export function isAttributeEnum(content)               { return content === 'enum'; };
 export function isAttributeFiles(content)              { return content === 'files'; };
 export function isAttributeFloat(content)              { return attributeContentNames.float.includes(content); };
+export function isAttributeGeo(content)                { return attributeContentNames.geo.includes(content); };
 export function isAttributeInteger(content)            { return attributeContentNames.integer.includes(content); };
+export function isAttributeJson(content)               { return content === 'jsonb'; };
 export function isAttributeNumeric(content)            { return content === 'numeric'; };
+export function isAttributeRange(content)              { return attributeContentNames.range.includes(content); };
 export function isAttributeRegconfig(content)          { return content === 'regconfig'; };
 export function isAttributeString(content)             { return attributeContentNames.text.includes(content); };
 export function isAttributeUuid(content)               { return content === 'uuid'; };
@@ -244,5 +263,6 @@ export function isAttributeRelationship11(content)     { return content === '1:1
 export function isAttributeRelationshipN1(content)     { return content === 'n:1'; };
 export function isAttributeRelationship1N(content)     { return content === '1:n'; };
 export function isAttributeTextSearchable(content,use) {
-	return !isAttributeBoolean(content) && !isAttributeFiles(content) && !attributeUseNoTextSearch.includes(use);
+	return !isAttributeBoolean(content) && !isAttributeFiles(content) && !isAttributeJson(content) &&
+		!isAttributeRange(content) && !isAttributeGeo(content) && !attributeUseNoTextSearch.includes(use);
 };
\ No newline at end of file
@@ -278,6 +278,55 @@ export function pbkdf2PassToAesGcmKey(passphrase,salt,iterations,exportable) {
 	});
 };
 
+// WebAuthn
+// options & credentials are exchanged with the backend as JSON, binary values as base64url
+export function webAuthnCreate(options) {
+	const o = JSON.parse(JSON.stringify(options.publicKey));
+	o.challenge = base64UrlToArrayBuffer(o.challenge);
+	o.user.id   = base64UrlToArrayBuffer(o.user.id);
+	
+	if(o.excludeCredentials !== undefined) {
+		for(let c of o.excludeCredentials) {
+			c.id = base64UrlToArrayBuffer(c.id);
+		}
+	}
+	return navigator.credentials.create({publicKey:o}).then(c => {
+		return {
+			id:c.id,
+			rawId:arrayBufferToBase64Url(c.rawId),
+			type:c.type,
+			response:{
+				attestationObject:arrayBufferToBase64Url(c.response.attestationObject),
+				clientDataJSON:arrayBufferToBase64Url(c.response.clientDataJSON),
+				transports:typeof c.response.getTransports === 'function' ? c.response.getTransports() : []
+			}
+		};
+	});
+};
+export function webAuthnGet(options) {
+	const o = JSON.parse(JSON.stringify(options.publicKey));
+	o.challenge = base64UrlToArrayBuffer(o.challenge);
+	
+	if(o.allowCredentials !== undefined) {
+		for(let c of o.allowCredentials) {
+			c.id = base64UrlToArrayBuffer(c.id);
+		}
+	}
+	return navigator.credentials.get({publicKey:o}).then(c => {
+		return {
+			id:c.id,
+			rawId:arrayBufferToBase64Url(c.rawId),
+			type:c.type,
+			response:{
+				authenticatorData:arrayBufferToBase64Url(c.response.authenticatorData),
+				clientDataJSON:arrayBufferToBase64Url(c.response.clientDataJSON),
+				signature:arrayBufferToBase64Url(c.response.signature),
+				userHandle:c.response.userHandle !== null ? arrayBufferToBase64Url(c.response.userHandle) : null
+			}
+		};
+	});
+};
+
 // helpers
 export function getRandomString(len) {
 	let chars = 'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!"§$%&/()=?_-:;#*+<>';
@@ -293,6 +342,13 @@ function arrayBufferToString(arrayBuffer) {
 	const byteArray = Array.from(new Uint8Array(arrayBuffer));
 	return byteArray.map(byte => String.fromCharCode(byte)).join('');
 };
+function arrayBufferToBase64Url(arrayBuffer) {
+	return window.btoa(arrayBufferToString(arrayBuffer))
+		.replace(/\+/g,'-').replace(/\//g,'_').replace(/=+$/,'');
+};
+function base64UrlToArrayBuffer(v) {
+	return stringToUint8Array(window.atob(v.replace(/-/g,'+').replace(/_/g,'/'))).buffer;
+};
 function ivGenerate(len) {
 	return crypto.getRandomValues(new Uint8Array(len));
 };
@@ -136,5 +136,14 @@ export function resolveErrCode(message) {
 			case '006': return cap.replace('{NAMES}',data.names); break;
 		}
 	}
+	if(errContext === 'VAL') {
+		// all validation errors refer to the affected attribute
+		const atr = MyStore.getters['schema/attributeIdMap'][data.attributeId];
+		if(atr === undefined) return message;
+		
+		const rel = MyStore.getters['schema/relationIdMap'][atr.relationId];
+		return cap.replace('{NAME}',getCaption('attributeTitle',rel.moduleId,atr.id,atr.captions,atr.name))
+			.replace('{LIMIT}',data.limit);
+	}
 	return cap;
 }
\ No newline at end of file
@@ -19,6 +19,7 @@ export function getCaptionMapName(content) {
 	switch(content) {
 		case 'articleBody':      // fallthrough
 		case 'articleTitle':     return 'articleIdMap';     break;
+		case 'attributeEnumValueTitle': return 'attributeEnumValueIdMap'; break;
 		case 'attributeTitle':   return 'attributeIdMap';   break;
 		case 'clientEventTitle': return 'clientEventIdMap'; break;
 		case 'columnTitle':      return 'columnIdMap';      break;
@@ -20,7 +20,14 @@ let getQueryExpressionAttribute = function(column) {
 		index:column.index,
 		groupBy:column.groupBy,
 		aggregator:column.aggregator,
-		distincted:column.distincted
+		distincted:column.distincted,
+		percentile:column.percentile,
+		windowFunction:column.windowFunction,
+		windowOffset:column.windowOffset,
+		windowPartitions:!column.windowPartitionAttributeId ? [] : [{
+			attributeId:column.windowPartitionAttributeId,
+			index:column.windowPartitionIndex
+		}]
 	};
 };
 
@@ -89,6 +96,7 @@ export function getQueryExpressions(columns) {
 		
 		out.push({
 			aggregator:c.aggregator,
+			percentile:c.percentile,
 			query:{
 				relationId:c.query.relationId,
 				limit:c.query.fixedLimit,
@@ -318,6 +326,7 @@ export function getQueryFilterNew() {
 			ftsDict:null,
 			query:null,
 			queryAggregator:null,
+			aggregator:null,
 			presetId:null,
 			roleId:null,
 			value:''
@@ -334,6 +343,7 @@ export function getQueryFilterNew() {
 			ftsDict:null,
 			query:null,
 			queryAggregator:null,
+			aggregator:null,
 			presetId:null,
 			roleId:null,
 			value:''
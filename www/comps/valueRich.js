@@ -1,4 +1,5 @@
 import srcBase64Icon from './shared/image.js';
+import {getCaption}  from './shared/language.js';
 import {
 	getAttributeFileThumbHref,
 	getAttributeFileVersionHref
@@ -194,6 +195,7 @@ export default {
 		colorAdjustBg,
 		getAttributeFileThumbHref,
 		getAttributeFileVersionHref,
+		getCaption,
 		getHtmlStripped,
 		getLinkMeta,
 		getNumberFormatted,
@@ -282,6 +284,42 @@ export default {
 					this.stringValueFull = this.getNumberFormatted(this.value,this.attribute);
 				break;
 				
+				// enum, shows value title
+				case 'enum':
+					if(this.value !== null) {
+						const v = this.attribute.enumValues.find(v => v.value === this.value);
+						this.stringValueFull = v === undefined ? this.value
+							: this.getCaption('attributeEnumValueTitle',this.attribute.moduleId,v.id,v.captions,v.value);
+					}
+				break;
+				
+				// geospatial, points as latitude/longitude, other geometries by type
+				case 'geometry': // fallthrough
+				case 'point':
+					if(this.value !== null)
+						this.stringValueFull = this.value.type === 'Point'
+							? `${this.value.coordinates[1]}, ${this.value.coordinates[0]}` : this.value.type;
+				break;
+				
+				// JSON
+				case 'jsonb':
+					this.stringValueFull = this.value === null ? '' : JSON.stringify(this.value);
+				break;
+				
+				// ranges, unbounded limits are shown empty
+				case 'daterange': // fallthrough
+				case 'tstzrange':
+					if(this.value !== null) {
+						const format = this.attribute.content === 'daterange'
+							? this.settings.dateFormat : this.settings.dateFormat + ' H:i';
+						
+						const bounds = this.value.map(v => v === null ? '' : this.getUnixFormat(
+							this.attribute.content === 'daterange' ? this.getUnixShifted(v,true) : v,format));
+						
+						this.stringValueFull = `${bounds[0]} - ${bounds[1]}`;
+					}
+				break;
+				
 				// others (UUID)
 				default: directValue = true; break;
 			}
@@ -18,12 +18,14 @@ import {
 
 // admin
 import MyAdmin               from './comps/admin/admin.js';
+import MyAdminAuditLog       from './comps/admin/adminAuditLog.js';
 import MyAdminBackups        from './comps/admin/adminBackups.js';
 import MyAdminCaptionMap     from './comps/admin/adminCaptionMap.js';
 import MyAdminCluster        from './comps/admin/adminCluster.js';
 import MyAdminConfig         from './comps/admin/adminConfig.js';
 import MyAdminCustom         from './comps/admin/adminCustom.js';
 import MyAdminFiles          from './comps/admin/adminFiles.js';
+import MyAdminJobs           from './comps/admin/adminJobs.js';
 import MyAdminLdaps          from './comps/admin/adminLdaps.js';
 import MyAdminLicense        from './comps/admin/adminLicense.js';
 import MyAdminLogins         from './comps/admin/adminLogins.js';
@@ -32,6 +34,7 @@ import MyAdminLoginTemplates from './comps/admin/adminLoginTemplates.js';
 import MyAdminLogs           from './comps/admin/adminLogs.js';
 import MyAdminMailAccounts   from './comps/admin/adminMailAccounts.js';
 import MyAdminMailSpooler    from './comps/admin/adminMailSpooler.js';
+import MyAdminMailTemplates  from './comps/admin/adminMailTemplates.js';
 import MyAdminMailTraffic    from './comps/admin/adminMailTraffic.js';
 import MyAdminModules        from './comps/admin/adminModules.js';
 import MyAdminOauthClients   from './comps/admin/adminOauthClients.js';
@@ -96,12 +99,14 @@ const MyRouter = VueRouter.createRouter({
 		redirect:'/admin/config',
 		component:MyAdmin,
 		children:[
+			{ path:'audit-log',       component:MyAdminAuditLog },
 			{ path:'backups',         component:MyAdminBackups },
 			{ path:'caption-map',     component:MyAdminCaptionMap },
 			{ path:'cluster',         component:MyAdminCluster },
 			{ path:'config',          component:MyAdminConfig },
 			{ path:'custom',          component:MyAdminCustom },
 			{ path:'files',           component:MyAdminFiles },
+			{ path:'jobs',            component:MyAdminJobs },
 			{ path:'ldaps',           component:MyAdminLdaps },
 			{ path:'license',         component:MyAdminLicense },
 			{ path:'logins',          component:MyAdminLogins },
@@ -110,6 +115,7 @@ const MyRouter = VueRouter.createRouter({
 			{ path:'logs',            component:MyAdminLogs },
 			{ path:'mail-accounts',   component:MyAdminMailAccounts },
 			{ path:'mail-spooler',    component:MyAdminMailSpooler },
+			{ path:'mail-templates',  component:MyAdminMailTemplates },
 			{ path:'mail-traffic',    component:MyAdminMailTraffic },
 			{ path:'modules',         component:MyAdminModules },
 			{ path:'oauth-clients',   component:MyAdminOauthClients },
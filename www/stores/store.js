@@ -27,6 +27,7 @@ const MyStore = Vuex.createStore({
 		busyCounter:0,                 // counter of calls making the app busy (WS requests, uploads, etc.)
 		captions:{},                   // all application captions in the user interface language
 		captionMapCustom:{},           // map of all custom captions from the instance
+		clientCertAuth:false,          // authentication via TLS client certificates is enabled
 		clusterNodeName:'',            // name of the cluster node that session is connected to
 		collectionIdMap:{},            // map of all collection values, key = collection ID
 		colorHeaderDefault:'262626',   // default header color, if not overwritten
@@ -41,7 +42,9 @@ const MyStore = Vuex.createStore({
 			],
 			loginLimitedFactor:3,      // factor, how many limited logins are enabled for each full login
 			loginType:{                // all login types, as defined in the backend
+				cert:'cert',
 				fixed:'fixed',
+				impersonation:'impersonation',
 				ldap:'ldap',
 				local:'local',
 				noAuth:'noAuth',
@@ -82,6 +85,7 @@ const MyStore = Vuex.createStore({
 		licenseValid:false,            // license is valid (set and within validity period)
 		loginHasClient:false,          // login has an associated client (to allow for local file handling)
 		loginId:-1,                    // user login ID
+		loginImpersonator:null,        // admin session to return to while impersonating another login, {token:'...',loginKeyAes:'...',loginKeySalt:'...'}
 		loginName:'',                  // user login name
 		loginPrivateKey:null,          // user login private key for decryption (non-exportable key)
 		loginPrivateKeyEnc:null,       // user login private key PEM, encrypted with login key
@@ -89,7 +93,8 @@ const MyStore = Vuex.createStore({
 		loginPublicKey:null,           // user login public key for encryption (exportable key)
 		loginSessionExpired:false,     // set to true, when session expires
 		loginSessionExpires:null,      // unix timestamp of session expiration date
-		loginType:null,                // user login type (local, oauth, ldap, noAuth, fixed)
+		loginSwitch:false,             // login session is being switched, token is used for next authentication
+		loginType:null,                // user login type (local, oauth, ldap, noAuth, fixed, cert)
 		loginWidgetGroups:[],          // user widgets, starting with widget groups
 		mirrorMode:false,              // instance runs in mirror mode (eg. mirrors another, likely production instance)
 		moduleEntries:[],              // module entries for header/home page
@@ -278,6 +283,7 @@ const MyStore = Vuex.createStore({
 		access:                  (state,payload) => state.access                   = payload,
 		captions:                (state,payload) => state.captions                 = payload,
 		captionMapCustom:        (state,payload) => state.captionMapCustom         = payload,
+		clientCertAuth:          (state,payload) => state.clientCertAuth           = payload,
 		clusterNodeName:         (state,payload) => state.clusterNodeName          = payload,
 		dropdownElm:             (state,payload) => state.dropdownElm              = payload,
 		feedback:                (state,payload) => state.feedback                 = payload,
@@ -300,6 +306,7 @@ const MyStore = Vuex.createStore({
 		isWithoutMenuHeader:     (state,payload) => state.isWithoutMenuHeader      = payload,
 		loginHasClient:          (state,payload) => state.loginHasClient           = payload,
 		loginId:                 (state,payload) => state.loginId                  = payload,
+		loginImpersonator:       (state,payload) => state.loginImpersonator        = payload,
 		loginName:               (state,payload) => state.loginName                = payload,
 		loginPrivateKey:         (state,payload) => state.loginPrivateKey          = payload,
 		loginPrivateKeyEnc:      (state,payload) => state.loginPrivateKeyEnc       = payload,
@@ -307,6 +314,7 @@ const MyStore = Vuex.createStore({
 		loginPublicKey:          (state,payload) => state.loginPublicKey           = payload,
 		loginSessionExpired:     (state,payload) => state.loginSessionExpired      = payload,
 		loginSessionExpires:     (state,payload) => state.loginSessionExpires      = payload,
+		loginSwitch:             (state,payload) => state.loginSwitch              = payload,
 		loginWidgetGroups:       (state,payload) => state.loginWidgetGroups        = payload,
 		mirrorMode:              (state,payload) => state.mirrorMode               = payload,
 		moduleEntries:           (state,payload) => state.moduleEntries            = payload,
@@ -441,6 +449,7 @@ const MyStore = Vuex.createStore({
 		busyCounter:             (state) => state.busyCounter,
 		captions:                (state) => state.captions,
 		captionMapCustom:        (state) => state.captionMapCustom,
+		clientCertAuth:          (state) => state.clientCertAuth,
 		clusterNodeName:         (state) => state.clusterNodeName,
 		collectionIdMap:         (state) => state.collectionIdMap,
 		config:                  (state) => state.config,
@@ -480,6 +489,7 @@ const MyStore = Vuex.createStore({
 		loginEncLocked:          (state) => state.loginPrivateKeyEnc !== null && state.loginPrivateKey === null,
 		loginHasClient:          (state) => state.loginHasClient,
 		loginId:                 (state) => state.loginId,
+		loginImpersonator:       (state) => state.loginImpersonator,
 		loginName:               (state) => state.loginName,
 		loginPrivateKey:         (state) => state.loginPrivateKey,
 		loginPrivateKeyEnc:      (state) => state.loginPrivateKeyEnc,
@@ -487,6 +497,7 @@ const MyStore = Vuex.createStore({
 		loginPublicKey:          (state) => state.loginPublicKey,
 		loginSessionExpired:     (state) => state.loginSessionExpired,
 		loginSessionExpires:     (state) => state.loginSessionExpires,
+		loginSwitch:             (state) => state.loginSwitch,
 		loginWidgetGroups:       (state) => state.loginWidgetGroups,
 		mirrorMode:              (state) => state.mirrorMode,
 		moduleEntries:           (state) => state.moduleEntries,